@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/danikg/go-todo-rest-api/app/pg"
+	"github.com/danikg/go-todo-rest-api/config"
+	"github.com/danikg/go-todo-rest-api/models"
+	"github.com/danikg/go-todo-rest-api/services"
+
+	repos "github.com/danikg/go-todo-rest-api/repositories/pg"
+	webservices "github.com/danikg/go-todo-rest-api/services/web"
+)
+
+// defaultPurgeAge keeps a month of tombstones and webhook deliveries
+const defaultPurgeAge = 30 * 24 * time.Hour
+
+var errAdminUsage = errors.New("unknown admin command")
+
+// newAdminService connects to the db of the configuration
+func newAdminService(cfg *config.Config) services.IAdminService {
+	db := pg.GetDB(cfg)
+	return webservices.NewAdminService(
+		repos.NewUserRepository(db),
+		repos.NewTodoListRepository(db),
+		repos.NewMaintenanceRepository(db),
+		repos.NewAuditRepository(db),
+	)
+}
+
+// adminArgs holds the minimum and maximum number of arguments of the admin commands
+var adminArgs = map[string][2]int{
+	"user list":     {0, 0},
+	"user create":   {1, 1},
+	"user password": {1, 1},
+	"user disable":  {1, 1},
+	"user enable":   {1, 1},
+	"list transfer": {2, 2},
+	"purge":         {0, 1},
+	"stats":         {0, 0},
+}
+
+// parseAdminCommand splits the words after admin into the command and its arguments
+func parseAdminCommand(words []string) (string, []string, error) {
+	// the user and list commands have a subcommand
+	n := 1
+	if len(words) > 1 && (words[0] == "user" || words[0] == "list") {
+		n = 2
+	}
+	n = min(n, len(words))
+	command, args := strings.Join(words[:n], " "), words[n:]
+
+	limits, ok := adminArgs[command]
+	if !ok || len(args) < limits[0] || len(args) > limits[1] {
+		return "", nil, errAdminUsage
+	}
+	return command, args, nil
+}
+
+// runAdmin runs a parsed admin command, passwords are read from the first line of stdin
+func runAdmin(ctx context.Context, admin services.IAdminService, command string, args []string, stdin io.Reader, stdout io.Writer) error {
+	w := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	switch command {
+	case "user list":
+		users, err := admin.ListUsers(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, "ID\tUSERNAME\tDISABLED\tLISTS\tCREATED")
+		for _, user := range users {
+			fmt.Fprintf(w, "%d\t%s\t%t\t%d\t%s\n", user.ID, user.Username, user.Disabled, len(user.TodoLists), user.CreatedAt.Format(time.RFC3339))
+		}
+
+	case "user create":
+		password, err := readPassword(stdin)
+		if err != nil {
+			return err
+		}
+		user := models.User{Username: args[0], Password: password}
+		if err = admin.CreateUser(ctx, &user); err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "created user %d %s\n", user.ID, user.Username)
+
+	case "user password":
+		id, err := parseAdminID(args[0])
+		if err != nil {
+			return err
+		}
+		password, err := readPassword(stdin)
+		if err != nil {
+			return err
+		}
+		user, err := admin.ResetPassword(ctx, id, password)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "reset the password of user %d %s\n", user.ID, user.Username)
+
+	case "user disable", "user enable":
+		id, err := parseAdminID(args[0])
+		if err != nil {
+			return err
+		}
+		user, err := admin.SetDisabled(ctx, id, command == "user disable")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "%sd user %d %s\n", strings.TrimPrefix(command, "user "), user.ID, user.Username)
+
+	case "list transfer":
+		listID, err := parseAdminID(args[0])
+		if err != nil {
+			return err
+		}
+		userID, err := parseAdminID(args[1])
+		if err != nil {
+			return err
+		}
+		todoList, err := admin.TransferTodoList(ctx, listID, userID)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "transferred todo list %d %s to user %d\n", todoList.ID, todoList.Name, todoList.UserID)
+
+	case "purge":
+		age := defaultPurgeAge
+		if len(args) == 1 {
+			var err error
+			if age, err = time.ParseDuration(args[0]); err != nil || age < 0 {
+				return fmt.Errorf("invalid age %q, expected a duration like 720h", args[0])
+			}
+		}
+		report, err := admin.Purge(ctx, time.Now().Add(-age))
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "soft deleted rows\t%d\n", report.SoftDeleted)
+		fmt.Fprintf(w, "tombstones\t%d\n", report.Tombstones)
+		fmt.Fprintf(w, "idempotency keys\t%d\n", report.IdempotencyKeys)
+		fmt.Fprintf(w, "webhook deliveries\t%d\n", report.WebhookDeliveries)
+		fmt.Fprintf(w, "webhook events\t%d\n", report.WebhookEvents)
+
+	case "stats":
+		stats, err := admin.Stats(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "users\t%d (%d disabled)\n", stats.Users, stats.DisabledUsers)
+		fmt.Fprintf(w, "todo lists\t%d\n", stats.TodoLists)
+		fmt.Fprintf(w, "todo items\t%d (%d completed)\n", stats.TodoItems, stats.CompletedItems)
+		fmt.Fprintf(w, "tags\t%d\n", stats.Tags)
+		fmt.Fprintf(w, "webhooks\t%d (%d pending deliveries)\n", stats.Webhooks, stats.PendingDeliveries)
+		fmt.Fprintf(w, "audit events\t%d\n", stats.AuditEvents)
+		fmt.Fprintf(w, "tombstones\t%d\n", stats.Tombstones)
+		fmt.Fprintf(w, "change sequence\t%d\n", stats.ChangeSequence)
+	}
+	return w.Flush()
+}
+
+// readPassword reads the first line of stdin, so that passwords stay out of the shell history
+func readPassword(stdin io.Reader) (string, error) {
+	line, err := bufio.NewReader(stdin).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func parseAdminID(s string) (uint, error) {
+	id, err := strconv.ParseUint(s, 10, 32)
+	if err != nil || id == 0 {
+		return 0, fmt.Errorf("invalid id %q", s)
+	}
+	return uint(id), nil
+}
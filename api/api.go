@@ -0,0 +1,4 @@
+// Package api holds the protobuf definition of the gRPC API, the Go code in todo/v1 is generated from it
+package api
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative todo/v1/todo.proto
@@ -1,13 +1,27 @@
 package app
 
 import (
-	"log"
+	"context"
+	"errors"
+	"log/slog"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
 
 	"github.com/danikg/go-todo-rest-api/app/pg"
 	"github.com/danikg/go-todo-rest-api/config"
+	"github.com/danikg/go-todo-rest-api/utils/events"
+	"github.com/danikg/go-todo-rest-api/utils/logger"
+	"github.com/danikg/go-todo-rest-api/utils/metrics"
 	"github.com/gorilla/mux"
+	"google.golang.org/grpc"
+	"gorm.io/gorm"
 
+	grpccontrollers "github.com/danikg/go-todo-rest-api/controllers/grpc"
 	controllers "github.com/danikg/go-todo-rest-api/controllers/http"
 	repos "github.com/danikg/go-todo-rest-api/repositories/pg"
 	services "github.com/danikg/go-todo-rest-api/services/web"
@@ -19,38 +33,210 @@ type App struct {
 }
 
 // NewApp ...
-func NewApp() *App {
+func NewApp(cfg *config.Config) *App {
 	return &App{
-		config: config.GetConfig(),
+		config: cfg,
 	}
 }
 
 // Run ...
 func (a *App) Run() {
+	log := logger.New(os.Stdout)
+	slog.SetDefault(log)
+
+	provider, err := setupTracing(context.Background(), a.config)
+	if err != nil {
+		log.Error("failed to setup tracing", "error", err)
+		os.Exit(1)
+	}
+	defer provider.Shutdown(context.Background())
+
 	db := pg.GetDB(a.config)
-	router := mux.NewRouter()
+	idempotencyRepo := repos.NewIdempotencyRepository(db)
+	background, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+	go purgeIdempotencyKeys(background, log, idempotencyRepo)
+
+	if sqlDB, err := db.DB(); err != nil {
+		log.Error("failed to get db pool", "error", err)
+	} else if err = metrics.RegisterDB(sqlDB, a.config.DB.Name); err != nil {
+		log.Error("failed to register db metrics", "error", err)
+	}
+	if replica := pg.GetReplicaDB(); replica != nil {
+		if err = metrics.RegisterDB(replica, a.config.DB.Name+"_replica"); err != nil {
+			log.Error("failed to register replica db metrics", "error", err)
+		}
+	}
+
+	bus := events.NewBus(a.config.Events.History)
+	router := NewRouter(a.config, log, db, bus)
+	go services.NewWebhookDispatcher(repos.NewWebhookRepository(db), a.config.Webhook).Run(background)
+
+	var grpcServer *grpc.Server
+	if a.config.GRPC.Port != 0 {
+		grpcServer = newGRPCServer(a.config, log, db, bus)
+	}
 
+	server := &http.Server{
+		Addr:         net.JoinHostPort(a.config.App.Host, strconv.Itoa(a.config.App.Port)),
+		Handler:      router,
+		ReadTimeout:  a.config.App.ReadTimeout,
+		WriteTimeout: a.config.App.WriteTimeout,
+		IdleTimeout:  a.config.App.IdleTimeout,
+	}
+	a.serve(log, server, grpcServer)
+}
+
+// NewRouter wires the repositories, services and controllers of the HTTP API on db,
+// the background workers are left to the caller
+func NewRouter(cfg *config.Config, log *slog.Logger, db *gorm.DB, bus *events.Bus) *mux.Router {
+	auditRepo := repos.NewAuditRepository(db)
 	userRepo := repos.NewUserRepository(db)
-	userService := services.NewUserService(userRepo)
+	userService := services.NewUserService(userRepo, auditRepo)
+
+	router := mux.NewRouter()
+	controllers.SetupMiddlewares(router, log, cfg, userService, repos.NewIdempotencyRepository(db))
+	controllers.SetupMetricsRoutes(router)
+	controllers.SetupOpenAPIRoutes(router, controllers.NewOpenAPIController())
+
+	eventsController := controllers.NewEventsController(bus, cfg.Events.Heartbeat)
+	controllers.SetupEventsRoutes(router, eventsController)
+
+	activityService := services.NewActivityService(auditRepo)
+	activityController := controllers.NewActivityController(activityService)
+	controllers.SetupActivityRoutes(router, activityController)
+
 	userController := controllers.NewUserController(userService)
 	controllers.SetupUserRoutes(router, userController)
 
+	authController := controllers.NewAuthController(userService, cfg.Auth.Secret, cfg.Auth.TokenTTL)
+	controllers.SetupAuthRoutes(router, authController)
+
 	todoListRepo := repos.NewTodoListRepository(db)
-	todoListService := services.NewTodoListService(userRepo, todoListRepo)
+	todoListService := services.NewTodoListService(userRepo, todoListRepo, auditRepo, bus)
 	todoListController := controllers.NewTodoListController(todoListService)
 	controllers.SetupTodoListRoutes(router, todoListController)
 
 	todoItemRepo := repos.NewTodoItemRepository(db)
-	todoItemService := services.NewTodoItemService(todoItemRepo, todoListRepo)
+	todoItemService := services.NewTodoItemService(todoItemRepo, todoListRepo, auditRepo, bus)
 	todoItemController := controllers.NewTodoItemController(todoItemService)
 	controllers.SetupTodoItemRoutes(router, todoItemController)
 
 	tagRepo := repos.NewTagRepository(db)
-	tagService := services.NewTagService(tagRepo, todoItemRepo)
+	tagService := services.NewTagService(tagRepo, todoItemRepo, userRepo, auditRepo, bus)
 	tagController := controllers.NewTagController(tagService)
 	controllers.SetupTagRoutes(router, tagController)
 
-	addr := a.config.AppHost + ":" + a.config.AppPort
-	log.Printf("starting at %s...", addr)
-	http.ListenAndServe(addr, router)
+	graphQLController := controllers.NewGraphQLController(userService, todoListService, todoItemService, tagService)
+	controllers.SetupGraphQLRoutes(router, graphQLController)
+
+	webhookRepo := repos.NewWebhookRepository(db)
+	webhookService := services.NewWebhookService(webhookRepo, userRepo, cfg.Webhook)
+	webhookController := controllers.NewWebhookController(webhookService)
+	controllers.SetupWebhookRoutes(router, webhookController)
+
+	syncRepo := repos.NewSyncRepository(db)
+	syncService := services.NewSyncService(syncRepo, auditRepo, bus)
+	syncController := controllers.NewSyncController(syncService)
+	controllers.SetupSyncRoutes(router, syncController)
+
+	exportRepo := repos.NewExportRepository(db)
+	exportService := services.NewExportService(exportRepo)
+	exportController := controllers.NewExportController(exportService)
+	controllers.SetupExportRoutes(router, exportController)
+
+	importService := services.NewImportService(repos.NewTransactor(db), userRepo, todoListRepo, tagRepo, bus)
+	importController := controllers.NewImportController(importService)
+	controllers.SetupImportRoutes(router, importController)
+
+	calendarRepo := repos.NewCalendarRepository(db)
+	calendarService := services.NewCalendarService(calendarRepo, userRepo, todoListRepo)
+	calendarController := controllers.NewCalendarController(calendarService)
+	controllers.SetupCalendarRoutes(router, calendarController)
+
+	return router
+}
+
+// newGRPCServer wires the gRPC API on db
+func newGRPCServer(cfg *config.Config, log *slog.Logger, db *gorm.DB, bus *events.Bus) *grpc.Server {
+	auditRepo := repos.NewAuditRepository(db)
+	userRepo := repos.NewUserRepository(db)
+	todoListRepo := repos.NewTodoListRepository(db)
+	todoItemRepo := repos.NewTodoItemRepository(db)
+
+	userService := services.NewUserService(userRepo, auditRepo)
+	todoListService := services.NewTodoListService(userRepo, todoListRepo, auditRepo, bus)
+	todoItemService := services.NewTodoItemService(todoItemRepo, todoListRepo, auditRepo, bus)
+	tagService := services.NewTagService(repos.NewTagRepository(db), todoItemRepo, userRepo, auditRepo, bus)
+	return grpccontrollers.NewServer(log, cfg.Auth, userService, todoListService, todoItemService, tagService)
+}
+
+// idempotencyPurgeInterval is the pause between two removals of expired idempotency keys
+const idempotencyPurgeInterval = time.Hour
+
+// purgeIdempotencyKeys removes the expired idempotency keys until ctx is done
+func purgeIdempotencyKeys(ctx context.Context, log *slog.Logger, repo *repos.IdempotencyRepository) {
+	ticker := time.NewTicker(idempotencyPurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if deleted, err := repo.DeleteExpired(ctx, now); err != nil {
+				log.Error("failed to purge idempotency keys", "error", err)
+			} else if deleted != 0 {
+				log.Info("purged idempotency keys", "deleted", deleted)
+			}
+		}
+	}
+}
+
+// serve runs the servers until SIGINT or SIGTERM and lets in-flight requests finish,
+// grpcServer is nil when the gRPC API is disabled
+func (a *App) serve(log *slog.Logger, server *http.Server, grpcServer *grpc.Server) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		log.Info("starting", "addr", server.Addr)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error("server stopped", "error", err)
+			stop()
+		}
+	}()
+
+	if grpcServer != nil {
+		go func() {
+			addr := net.JoinHostPort(a.config.App.Host, strconv.Itoa(a.config.GRPC.Port))
+			listener, err := net.Listen("tcp", addr)
+			if err != nil {
+				log.Error("grpc server stopped", "error", err)
+				stop()
+				return
+			}
+			log.Info("starting grpc", "addr", addr)
+			if err = grpcServer.Serve(listener); err != nil {
+				log.Error("grpc server stopped", "error", err)
+				stop()
+			}
+		}()
+	}
+
+	<-ctx.Done()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), a.config.App.ShutdownTimeout)
+	defer cancel()
+	if grpcServer != nil {
+		go func() {
+			<-shutdownCtx.Done()
+			grpcServer.Stop()
+		}()
+	}
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Error("failed to shutdown", "error", err)
+	}
+	if grpcServer != nil {
+		grpcServer.GracefulStop()
+	}
 }
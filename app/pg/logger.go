@@ -0,0 +1,78 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/danikg/go-todo-rest-api/utils/logger"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// gormLogger reports failed and slow queries through the request-scoped logger,
+// so every line carries the request id of the query's caller
+type gormLogger struct {
+	slowThreshold time.Duration
+	level         gormlogger.LogLevel
+}
+
+func newGormLogger(slowThreshold time.Duration) gormlogger.Interface {
+	return &gormLogger{slowThreshold: slowThreshold, level: gormlogger.Warn}
+}
+
+// LogMode ...
+func (l *gormLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	newLogger := *l
+	newLogger.level = level
+	return &newLogger
+}
+
+// Info ...
+func (l *gormLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Info {
+		logger.FromContext(ctx).Info(fmt.Sprintf(msg, args...))
+	}
+}
+
+// Warn ...
+func (l *gormLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Warn {
+		logger.FromContext(ctx).Warn(fmt.Sprintf(msg, args...))
+	}
+}
+
+// Error ...
+func (l *gormLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Error {
+		logger.FromContext(ctx).Error(fmt.Sprintf(msg, args...))
+	}
+}
+
+// Trace ...
+func (l *gormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.level <= gormlogger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound) && l.level >= gormlogger.Error:
+		sql, rows := fc()
+		logger.FromContext(ctx).Error("query failed",
+			"error", err, "sql", sql, "rows", rows, "elapsed_ms", milliseconds(elapsed))
+	case l.slowThreshold != 0 && elapsed > l.slowThreshold && l.level >= gormlogger.Warn:
+		sql, rows := fc()
+		logger.FromContext(ctx).Warn("slow query",
+			"sql", sql, "rows", rows, "elapsed_ms", milliseconds(elapsed), "threshold_ms", milliseconds(l.slowThreshold))
+	case l.level >= gormlogger.Info:
+		sql, rows := fc()
+		logger.FromContext(ctx).Info("query",
+			"sql", sql, "rows", rows, "elapsed_ms", milliseconds(elapsed))
+	}
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
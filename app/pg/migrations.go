@@ -0,0 +1,112 @@
+package pg
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/danikg/go-todo-rest-api/models"
+	"gorm.io/gorm"
+)
+
+// migrateTagVocabulary turns the tags created one per todo item into a vocabulary per user:
+// each tag gets the owner of its items and tags with the same normalized text are merged.
+// The texts are normalized here with models.NormalizeTagText, so every driver gets the same result
+func migrateTagVocabulary(db *gorm.DB) error {
+	if !db.Migrator().HasTable(&models.Tag{}) || db.Migrator().HasColumn(&models.Tag{}, "Normalized") {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Migrator().AddColumn(&models.Tag{}, "UserID"); err != nil {
+			return err
+		}
+		if err := tx.Migrator().AddColumn(&models.Tag{}, "Normalized"); err != nil {
+			return err
+		}
+
+		for _, query := range []string{
+			`UPDATE tags SET user_id = (SELECT todo_lists.user_id FROM todo_item_tags
+				JOIN todo_items ON todo_items.id = todo_item_tags.todo_item_id
+				JOIN todo_lists ON todo_lists.id = todo_items.todo_list_id
+				WHERE todo_item_tags.tag_id = tags.id LIMIT 1)`,
+			`UPDATE tags SET user_id = 0 WHERE user_id IS NULL`,
+		} {
+			if err := tx.Exec(query).Error; err != nil {
+				return err
+			}
+		}
+
+		var tags []models.Tag
+		if err := tx.Unscoped().Select("id", "user_id", "text").Order("id").Find(&tags).Error; err != nil {
+			return err
+		}
+
+		// the oldest tag of each group is kept
+		type group struct {
+			userID     uint
+			normalized string
+		}
+		kept := map[group]uint{}
+		for _, tag := range tags {
+			normalized := models.NormalizeTagText(tag.Text)
+			keepID, ok := kept[group{tag.UserID, normalized}]
+			if !ok {
+				kept[group{tag.UserID, normalized}] = tag.ID
+				if err := tx.Unscoped().Model(&models.Tag{}).Where("id = ?", tag.ID).UpdateColumn("normalized", normalized).Error; err != nil {
+					return err
+				}
+				continue
+			}
+
+			for _, query := range []string{
+				`INSERT INTO todo_item_tags (todo_item_id, tag_id)
+					SELECT todo_item_id, @keep FROM todo_item_tags WHERE tag_id = @tag
+					AND todo_item_id NOT IN (SELECT todo_item_id FROM todo_item_tags WHERE tag_id = @keep)`,
+				`DELETE FROM todo_item_tags WHERE tag_id = @tag`,
+				`DELETE FROM tags WHERE id = @tag`,
+			} {
+				if err := tx.Exec(query, sql.Named("keep", keepID), sql.Named("tag", tag.ID)).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// migrateUniqueUsernames renames the users sharing a username before the unique index is created,
+// the oldest user keeps the name and the others get their id appended
+func migrateUniqueUsernames(db *gorm.DB) error {
+	if !db.Migrator().HasTable(&models.User{}) || db.Migrator().HasIndex(&models.User{}, "Username") {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		var users []models.User
+		if err := tx.Unscoped().Select("id", "username").Order("id").Find(&users).Error; err != nil {
+			return err
+		}
+
+		taken := map[string]bool{}
+		for _, user := range users {
+			taken[user.Username] = true
+		}
+		kept := map[string]bool{}
+		for _, user := range users {
+			if !kept[user.Username] {
+				kept[user.Username] = true
+				continue
+			}
+
+			username := fmt.Sprintf("%s-%d", user.Username, user.ID)
+			for n := 2; taken[username]; n++ {
+				username = fmt.Sprintf("%s-%d-%d", user.Username, user.ID, n)
+			}
+			taken[username] = true
+			if err := tx.Unscoped().Model(&models.User{}).Where("id = ?", user.ID).Update("username", username).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -1,9 +1,13 @@
 package pg
 
 import (
+	"database/sql"
 	"fmt"
 	"log"
+	"log/slog"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/danikg/go-todo-rest-api/config"
 	"github.com/danikg/go-todo-rest-api/models"
@@ -13,25 +17,117 @@ import (
 )
 
 var (
-	db   *gorm.DB
-	once sync.Once
+	db      *gorm.DB
+	replica *sql.DB
+	once    sync.Once
 )
 
 // GetDB ...
 func GetDB(cfg *config.Config) *gorm.DB {
 	once.Do(func() {
-		dsn := fmt.Sprintf("host='%s' port=5432 user=%s password=%s dbname=%s sslmode=disable",
-			cfg.DBHost, cfg.DBUser, cfg.DBPassword, cfg.DBName)
-
 		var err error
-		if db, err = gorm.Open(postgres.Open(dsn), &gorm.Config{}); err != nil {
-			log.Fatal("failed to connect db")
+		if db, err = connect(cfg.DB, DSN(cfg.DB)); err != nil {
+			log.Fatalf("failed to connect db: %v", err)
+		}
+
+		if err = db.Use(TracingPlugin{}); err != nil {
+			log.Fatal("failed to register tracing plugin")
+		}
+
+		if cfg.DB.ReplicaDSN != "" {
+			replicaDB, err := connect(cfg.DB, cfg.DB.ReplicaDSN)
+			if err != nil {
+				log.Fatalf("failed to connect replica db: %v", err)
+			}
+
+			replica, _ = replicaDB.DB()
+			if err = db.Use(&ReplicaPlugin{Replica: replica}); err != nil {
+				log.Fatal("failed to register replica plugin")
+			}
 		}
 
-		db.AutoMigrate(&models.User{})
-		db.AutoMigrate(&models.TodoList{})
-		db.AutoMigrate(&models.TodoItem{})
-		db.AutoMigrate(&models.Tag{})
+		if err = Migrate(db); err != nil {
+			log.Fatalf("failed to migrate db: %v", err)
+		}
 	})
 	return db
 }
+
+// Migrate creates and updates the tables of the models
+func Migrate(db *gorm.DB) error {
+	if err := migrateUniqueUsernames(db); err != nil {
+		return fmt.Errorf("users: %w", err)
+	}
+	// the todo items migrate their tags too, so the vocabulary goes first
+	if err := migrateTagVocabulary(db); err != nil {
+		return fmt.Errorf("tags: %w", err)
+	}
+	if err := db.AutoMigrate(&models.User{}, &models.TodoList{}, &models.TodoItem{}); err != nil {
+		return err
+	}
+	return db.AutoMigrate(
+		&models.Tag{},
+		&models.IdempotencyKey{},
+		&models.AuditEvent{},
+		&models.Webhook{}, &models.WebhookOutboxEvent{}, &models.WebhookDelivery{},
+		&models.ChangeCounter{}, &models.Tombstone{},
+		&models.FeedToken{},
+	)
+}
+
+// GetReplicaDB returns the read replica pool, nil when no replica is configured
+func GetReplicaDB() *sql.DB {
+	return replica
+}
+
+// connect opens a configured pool, retrying with exponential backoff
+// so that the service survives starting before the database
+func connect(cfg config.DBConfig, dsn string) (*gorm.DB, error) {
+	backoff := cfg.ConnectBackoff
+	for attempt := 1; ; attempt++ {
+		conn, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+			Logger: newGormLogger(cfg.SlowQueryThreshold),
+		})
+		if err == nil {
+			sqlDB, err := conn.DB()
+			if err != nil {
+				return nil, err
+			}
+
+			sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+			sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+			sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+			sqlDB.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+			return conn, nil
+		}
+
+		if attempt >= cfg.ConnectAttempts {
+			return nil, err
+		}
+
+		slog.Warn("failed to connect db, retrying",
+			"attempt", attempt, "max_attempts", cfg.ConnectAttempts, "backoff", backoff.String(), "error", err)
+		time.Sleep(backoff)
+		if backoff *= 2; backoff > cfg.ConnectMaxBackoff {
+			backoff = cfg.ConnectMaxBackoff
+		}
+	}
+}
+
+// DSN returns the postgres connection string
+func DSN(cfg config.DBConfig) string {
+	dsn := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s connect_timeout=%d",
+		quote(cfg.Host), cfg.Port, quote(cfg.User), quote(cfg.Password), quote(cfg.Name),
+		cfg.SSLMode, int(cfg.ConnectTimeout.Seconds()))
+
+	// unknown keys are sent by pgx as session parameters
+	if cfg.StatementTimeout > 0 {
+		dsn += fmt.Sprintf(" statement_timeout=%d", cfg.StatementTimeout.Milliseconds())
+	}
+	return dsn
+}
+
+// quote escapes a libpq connection string value
+func quote(value string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
+}
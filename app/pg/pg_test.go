@@ -0,0 +1,40 @@
+package pg
+
+import (
+	"testing"
+	"time"
+
+	"github.com/danikg/go-todo-rest-api/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDSN(t *testing.T) {
+	cfg := config.Default().DB
+	cfg.Name = "todo"
+	cfg.Password = `it's a \secret`
+
+	dsn := DSN(cfg)
+	assert.Contains(t, dsn, "port=5432")
+	assert.Contains(t, dsn, "sslmode=disable")
+	assert.Contains(t, dsn, `password='it\'s a \\secret'`)
+	assert.Contains(t, dsn, "statement_timeout=30000")
+
+	cfg.StatementTimeout = 0
+	assert.NotContains(t, DSN(cfg), "statement_timeout")
+}
+
+func TestConnect_Retries(t *testing.T) {
+	cfg := config.Default().DB
+	cfg.Host = "127.0.0.1"
+	cfg.Port = 1
+	cfg.Name = "todo"
+	cfg.ConnectAttempts = 3
+	cfg.ConnectBackoff = 10 * time.Millisecond
+	cfg.ConnectMaxBackoff = 15 * time.Millisecond
+
+	start := time.Now()
+	conn, err := connect(cfg, DSN(cfg))
+	assert.Error(t, err)
+	assert.Nil(t, conn)
+	assert.GreaterOrEqual(t, int64(time.Since(start)), int64(25*time.Millisecond))
+}
@@ -0,0 +1,40 @@
+package pg
+
+import (
+	"database/sql"
+
+	"github.com/danikg/go-todo-rest-api/repositories"
+	"gorm.io/gorm"
+)
+
+// ReplicaPlugin routes the reads of contexts marked with
+// repositories.WithReadReplica to the replica pool
+type ReplicaPlugin struct {
+	Replica *sql.DB
+}
+
+// Name ...
+func (p *ReplicaPlugin) Name() string {
+	return "replica"
+}
+
+// Initialize registers the pool switch before the read operations
+func (p *ReplicaPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	if err := cb.Query().Before("gorm:query").Register("replica:query", p.switchPool); err != nil {
+		return err
+	}
+	return cb.Row().Before("gorm:row").Register("replica:row", p.switchPool)
+}
+
+func (p *ReplicaPlugin) switchPool(db *gorm.DB) {
+	if db.Statement.Context == nil || !repositories.UseReadReplica(db.Statement.Context) {
+		return
+	}
+
+	// reads inside a transaction must see its own writes
+	if _, inTx := db.Statement.ConnPool.(*sql.Tx); inTx {
+		return
+	}
+	db.Statement.ConnPool = p.Replica
+}
@@ -0,0 +1,27 @@
+package app
+
+import (
+	"context"
+
+	"github.com/danikg/go-todo-rest-api/config"
+	"github.com/danikg/go-todo-rest-api/utils/tracing"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// setupTracing installs the global tracer provider, exporting spans
+// over OTLP/HTTP when an endpoint is configured
+func setupTracing(ctx context.Context, cfg *config.Config) (*sdktrace.TracerProvider, error) {
+	var opts []sdktrace.TracerProviderOption
+	if cfg.Tracing.OTLPEndpoint != "" {
+		exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(cfg.Tracing.OTLPEndpoint))
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, sdktrace.WithBatcher(exporter))
+	}
+
+	provider := tracing.NewProvider(cfg.Tracing.ServiceName, opts...)
+	tracing.Install(provider)
+	return provider, nil
+}
@@ -0,0 +1,208 @@
+// Package client is a typed Go client of the REST API.
+//
+//	c := client.New("http://localhost:8000", client.WithToken(token))
+//	todoLists, err := c.ListTodoLists(ctx, userID)
+//
+// Requests failing with 429 or a 5xx status are retried with exponential backoff,
+// POST requests carry an Idempotency-Key so that retries are not applied twice.
+// Error responses are returned as *Error.
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danikg/go-todo-rest-api/models"
+)
+
+// Retry defaults
+const (
+	DefaultRetries    = 3
+	DefaultMinBackoff = 100 * time.Millisecond
+	DefaultMaxBackoff = 5 * time.Second
+)
+
+// Client calls the API at its base URL, it is safe for concurrent use
+type Client struct {
+	baseURL    string
+	token      string
+	http       *http.Client
+	retries    int
+	minBackoff time.Duration
+	maxBackoff time.Duration
+	pageSize   int
+}
+
+// Option configures a Client
+type Option func(c *Client)
+
+// WithToken authenticates the requests with a bearer token
+func WithToken(token string) Option {
+	return func(c *Client) { c.token = token }
+}
+
+// WithHTTPClient sends the requests with httpClient instead of a client with a 30s timeout
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) { c.http = httpClient }
+}
+
+// WithRetries sets how often a request is retried and the bounds of the backoff between attempts,
+// 0 retries disables retrying
+func WithRetries(retries int, minBackoff, maxBackoff time.Duration) Option {
+	return func(c *Client) {
+		c.retries, c.minBackoff, c.maxBackoff = retries, minBackoff, maxBackoff
+	}
+}
+
+// WithPageSize sets the number of records the iterators fetch per request, at most models.MaxPageLimit
+func WithPageSize(pageSize int) Option {
+	return func(c *Client) { c.pageSize = pageSize }
+}
+
+// New returns a client of the API at baseURL, like http://localhost:8000
+func New(baseURL string, options ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		http:       &http.Client{Timeout: 30 * time.Second},
+		retries:    DefaultRetries,
+		minBackoff: DefaultMinBackoff,
+		maxBackoff: DefaultMaxBackoff,
+		pageSize:   models.DefaultPageLimit,
+	}
+	for _, option := range options {
+		option(c)
+	}
+	return c
+}
+
+// do sends the request with in as json body and decodes the json response into out.
+// Error responses are returned as *Error, their body is decoded into out as well when errOut is set
+func (c *Client) do(ctx context.Context, method, path string, in, out interface{}) error {
+	return c.send(ctx, method, path, in, out, false)
+}
+
+func (c *Client) send(ctx context.Context, method, path string, in, out interface{}, errOut bool) error {
+	var body []byte
+	if in != nil {
+		var err error
+		if body, err = json.Marshal(in); err != nil {
+			return err
+		}
+	}
+
+	// the same key for every attempt, the server replays the response of an applied attempt
+	idempotencyKey := ""
+	if method == http.MethodPost {
+		idempotencyKey = newIdempotencyKey()
+	}
+
+	for attempt := 0; ; attempt++ {
+		req, err := c.newRequest(ctx, method, path, body, idempotencyKey)
+		if err != nil {
+			return err
+		}
+		resp, err := c.http.Do(req)
+		if retry, wait := c.shouldRetry(ctx, resp, err, attempt); retry {
+			if resp != nil {
+				resp.Body.Close()
+			}
+			timer := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			case <-timer.C:
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		defer resp.Body.Close()
+		if resp.StatusCode >= 400 {
+			data, _ := io.ReadAll(resp.Body)
+			if errOut && out != nil {
+				json.Unmarshal(data, out)
+			}
+			return newError(method, path, resp.StatusCode, data)
+		}
+		if out == nil || resp.StatusCode == http.StatusNoContent {
+			return nil
+		}
+		return json.NewDecoder(resp.Body).Decode(out)
+	}
+}
+
+func (c *Client) newRequest(ctx context.Context, method, path string, body []byte, idempotencyKey string) (*http.Request, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", idempotencyKey)
+	}
+	return req, nil
+}
+
+// shouldRetry tells whether the attempt is retried and after how long: network errors,
+// 429 and 5xx responses are, a Retry-After in seconds is honored
+func (c *Client) shouldRetry(ctx context.Context, resp *http.Response, err error, attempt int) (bool, time.Duration) {
+	if attempt >= c.retries || ctx.Err() != nil {
+		return false, 0
+	}
+	if err != nil {
+		return true, c.backoff(attempt)
+	}
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode < 500 {
+		return false, 0
+	}
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+		return true, time.Duration(seconds) * time.Second
+	}
+	return true, c.backoff(attempt)
+}
+
+// backoff returns a random wait up to minBackoff * 2^attempt, capped at maxBackoff
+func (c *Client) backoff(attempt int) time.Duration {
+	limit := c.minBackoff << attempt
+	if limit > c.maxBackoff || limit <= 0 {
+		limit = c.maxBackoff
+	}
+	if limit <= 0 {
+		return 0
+	}
+	n, err := rand.Int(rand.Reader, big.NewInt(int64(limit)))
+	if err != nil {
+		return limit
+	}
+	return time.Duration(n.Int64())
+}
+
+func newIdempotencyKey() string {
+	key := make([]byte, 16)
+	if _, err := rand.Read(key); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(key)
+}
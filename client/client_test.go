@@ -0,0 +1,245 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/danikg/go-todo-rest-api/models"
+	"github.com/danikg/go-todo-rest-api/services/mocks"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+
+	controllers "github.com/danikg/go-todo-rest-api/controllers/http"
+)
+
+// newTestClient returns a client of the controllers over the mocked services
+func newTestClient(t *testing.T) *Client {
+	router := mux.NewRouter()
+	userService := &mocks.UserServiceMock{}
+	controllers.SetupUserRoutes(router, controllers.NewUserController(userService))
+	controllers.SetupAuthRoutes(router, controllers.NewAuthController(userService, "secret", time.Hour))
+	controllers.SetupTodoListRoutes(router, controllers.NewTodoListController(&mocks.TodoListServiceMock{}))
+	controllers.SetupTodoItemRoutes(router, controllers.NewTodoItemController(&mocks.TodoItemServiceMock{}))
+	controllers.SetupTagRoutes(router, controllers.NewTagController(&mocks.TagServiceMock{}))
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return New(server.URL, WithRetries(0, 0, 0))
+}
+
+func TestClient_Users(t *testing.T) {
+	c := newTestClient(t)
+	ctx := context.Background()
+
+	users, err := c.ListUsers(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, users, 2)
+	assert.Equal(t, "user2", users[1].Username)
+
+	user, err := c.GetUser(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "user1", user.Username)
+
+	_, err = c.GetUser(ctx, 2)
+	assert.True(t, errors.Is(err, ErrNotFound))
+
+	user, err = c.CreateUser(ctx, models.User{Username: "user3", Password: "password"})
+	assert.NoError(t, err)
+	assert.Equal(t, "user3", user.Username)
+
+	user, err = c.UpdateUser(ctx, 1, models.User{Username: "renamed"})
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), user.ID)
+
+	assert.NoError(t, c.DeleteUser(ctx, 1))
+	assert.True(t, errors.Is(c.DeleteUser(ctx, 2), ErrNotFound))
+
+	token, err := c.Authenticate(ctx, "user1", "password")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, token.Token)
+
+	_, err = c.Authenticate(ctx, "user1", "wrong")
+	assert.True(t, errors.Is(err, ErrUnauthorized))
+}
+
+func TestClient_TodoListsAndItems(t *testing.T) {
+	c := newTestClient(t)
+	ctx := context.Background()
+
+	todoLists, err := c.ListTodoLists(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "list2", todoLists[1].Name)
+
+	todoList, err := c.CreateTodoList(ctx, 1, models.TodoList{Name: "groceries"})
+	assert.NoError(t, err)
+	assert.Equal(t, "groceries", todoList.Name)
+
+	_, err = c.CreateTodoList(ctx, 2, models.TodoList{Name: "groceries"})
+	assert.True(t, errors.Is(err, ErrServer))
+
+	todoList, err = c.UpdateTodoList(ctx, 1, models.TodoList{Name: "renamed"})
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), todoList.ID)
+	assert.NoError(t, c.DeleteTodoList(ctx, 1))
+
+	todoItems, err := c.ListTodoItems(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "desc2", todoItems[1].Description)
+
+	_, err = c.CreateTodoItem(ctx, 1, models.TodoItem{Title: "milk", Recurrence: "FREQ=DAILY"})
+	assert.True(t, errors.Is(err, ErrBadRequest))
+
+	completed := true
+	todoItem, err := c.UpdateTodoItem(ctx, 1, models.TodoItemUpdate{Completed: &completed})
+	assert.NoError(t, err)
+	assert.Equal(t, "item1", todoItem.Title)
+	assert.NoError(t, c.DeleteTodoItem(ctx, 1))
+}
+
+func TestClient_Tags(t *testing.T) {
+	c := newTestClient(t)
+	ctx := context.Background()
+
+	tags, err := c.ListTags(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "tag2", tags[1].Text)
+
+	usages, err := c.ListUserTags(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), usages[0].UsageCount)
+
+	tag, err := c.CreateUserTag(ctx, 1, models.Tag{Text: "work"})
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), tag.ID)
+
+	_, err = c.UpdateTag(ctx, 1, models.Tag{Text: "tag2"})
+	assert.True(t, errors.Is(err, ErrConflict))
+	var apiErr *Error
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusConflict, apiErr.Status)
+	assert.Equal(t, "PUT", apiErr.Method)
+	assert.Equal(t, "/tags/1", apiErr.Path)
+
+	tag, err = c.MergeTag(ctx, 1, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(2), tag.ID)
+	_, err = c.MergeTag(ctx, 1, 1)
+	assert.True(t, errors.Is(err, ErrBadRequest))
+
+	_, err = c.TagTodoItem(ctx, 1, models.Tag{Text: "home"})
+	assert.NoError(t, err)
+	assert.NoError(t, c.UntagTodoItem(ctx, 1, 1))
+	assert.NoError(t, c.DeleteTag(ctx, 1))
+
+	todoItems, err := c.UserTodoItems(1, models.TagFilter{Tags: []string{"tag1"}, MatchAll: true}).All(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, todoItems, 2)
+
+	_, err = c.TagTodoItems(2).All(ctx)
+	assert.True(t, errors.Is(err, ErrNotFound))
+}
+
+func TestClient_Retries(t *testing.T) {
+	tests := []struct {
+		title    string
+		statuses []int
+		retries  int
+		attempts int
+		err      error
+	}{
+		{title: "Retry server errors", statuses: []int{500, 503, 201}, retries: 3, attempts: 3},
+		{title: "Retry too many requests", statuses: []int{429, 201}, retries: 3, attempts: 2},
+		{title: "Give up after the retries", statuses: []int{502, 502, 502}, retries: 2, attempts: 3, err: ErrServer},
+		{title: "Do not retry client errors", statuses: []int{409, 201}, retries: 3, attempts: 1, err: ErrConflict},
+		{title: "Retries disabled", statuses: []int{500, 201}, retries: 0, attempts: 1, err: ErrServer},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.title, func(t *testing.T) {
+			var (
+				mu   sync.Mutex
+				keys []string
+			)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				keys = append(keys, r.Header.Get("Idempotency-Key"))
+				status := tc.statuses[len(keys)-1]
+				mu.Unlock()
+
+				if status == http.StatusTooManyRequests {
+					w.Header().Set("Retry-After", "0")
+				}
+				w.WriteHeader(status)
+				fmt.Fprintf(w, `{"status": %d, "message": "attempt %d", "Name": "list1"}`, status, len(keys))
+			}))
+			defer server.Close()
+
+			c := New(server.URL, WithRetries(tc.retries, time.Millisecond, 5*time.Millisecond))
+			todoList, err := c.CreateTodoList(context.Background(), 1, models.TodoList{Name: "list1"})
+			assert.Len(t, keys, tc.attempts)
+			if tc.err != nil {
+				assert.True(t, errors.Is(err, tc.err), err)
+				assert.Contains(t, err.Error(), "attempt "+strconv.Itoa(tc.attempts))
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "list1", todoList.Name)
+
+			// the server sees one request however often it is sent
+			for _, key := range keys {
+				assert.Equal(t, keys[0], key)
+			}
+			assert.NotEmpty(t, keys[0])
+		})
+	}
+}
+
+func TestClient_RetryCanceled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	c := New(server.URL, WithRetries(10, time.Second, time.Second))
+	_, err := c.ListUsers(ctx)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), err)
+}
+
+func TestClient_Iterator(t *testing.T) {
+	var pages []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pages = append(pages, r.URL.RawQuery)
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+
+		todoItems := []models.TodoItem{}
+		for i := offset; i < offset+limit && i < 5; i++ {
+			todoItem := models.TodoItem{Title: fmt.Sprintf("item%d", i+1)}
+			todoItem.ID = uint(i + 1)
+			todoItems = append(todoItems, todoItem)
+		}
+		json.NewEncoder(w).Encode(todoItems)
+	}))
+	defer server.Close()
+
+	c := New(server.URL, WithPageSize(2))
+	it := c.TagTodoItems(1)
+	titles := []string{}
+	for it.Next(context.Background()) {
+		titles = append(titles, it.Value().Title)
+	}
+	assert.NoError(t, it.Err())
+	assert.Equal(t, []string{"item1", "item2", "item3", "item4", "item5"}, titles)
+	assert.Equal(t, []string{"limit=2&offset=0", "limit=2&offset=2", "limit=2&offset=4"}, pages)
+	assert.False(t, it.Next(context.Background()))
+}
@@ -0,0 +1,58 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/danikg/go-todo-rest-api/utils/response"
+)
+
+// Errors matched by the status of an *Error with errors.Is
+var (
+	ErrBadRequest      = errors.New("bad request")
+	ErrUnauthorized    = errors.New("unauthorized")
+	ErrNotFound        = errors.New("not found")
+	ErrConflict        = errors.New("conflict")
+	ErrUnprocessable   = errors.New("unprocessable entity")
+	ErrTooManyRequests = errors.New("too many requests")
+	ErrServer          = errors.New("server error")
+)
+
+var statusErrors = map[int]error{
+	http.StatusBadRequest:          ErrBadRequest,
+	http.StatusUnauthorized:        ErrUnauthorized,
+	http.StatusNotFound:            ErrNotFound,
+	http.StatusConflict:            ErrConflict,
+	http.StatusUnprocessableEntity: ErrUnprocessable,
+	http.StatusTooManyRequests:     ErrTooManyRequests,
+}
+
+// Error is an error response of the API, the HTTPError body the server sent with the request it answered
+type Error struct {
+	response.HTTPError
+	Method string
+	Path   string
+}
+
+func newError(method, path string, status int, body []byte) *Error {
+	e := &Error{Method: method, Path: path}
+	if json.Unmarshal(body, &e.HTTPError) != nil || e.Message == "" {
+		e.Message = http.StatusText(status)
+	}
+	e.Status = status
+	return e
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s %s: %d %s", e.Method, e.Path, e.Status, e.Message)
+}
+
+// Is matches the error of the status, like ErrNotFound for 404 and ErrServer for all 5xx
+func (e *Error) Is(target error) bool {
+	if e.Status >= 500 {
+		return target == ErrServer
+	}
+	return statusErrors[e.Status] == target
+}
@@ -0,0 +1,82 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/danikg/go-todo-rest-api/models"
+)
+
+// Iterator walks a paginated listing, pages are fetched as Next needs them.
+//
+//	it := c.TagTodoItems(tagID)
+//	for it.Next(ctx) {
+//		todoItem := it.Value()
+//	}
+//	if err := it.Err(); err != nil {
+type Iterator[T any] struct {
+	fetch   func(ctx context.Context, page models.Page) ([]T, error)
+	page    models.Page
+	items   []T
+	current T
+	last    bool
+	err     error
+}
+
+func newIterator[T any](pageSize int, fetch func(ctx context.Context, page models.Page) ([]T, error)) *Iterator[T] {
+	return &Iterator[T]{fetch: fetch, page: models.Page{Limit: pageSize}}
+}
+
+// Next advances to the next value, it returns false at the end of the listing or on an error
+func (it *Iterator[T]) Next(ctx context.Context) bool {
+	if it.err != nil {
+		return false
+	}
+	if len(it.items) == 0 {
+		if it.last {
+			return false
+		}
+		if it.items, it.err = it.fetch(ctx, it.page); it.err != nil {
+			return false
+		}
+		// a short page is the last one
+		it.last = len(it.items) < it.page.Limit
+		it.page.Offset += len(it.items)
+		if len(it.items) == 0 {
+			return false
+		}
+	}
+	it.current, it.items = it.items[0], it.items[1:]
+	return true
+}
+
+// Value returns the current value
+func (it *Iterator[T]) Value() T {
+	return it.current
+}
+
+// Err returns the error that stopped the iteration
+func (it *Iterator[T]) Err() error {
+	return it.err
+}
+
+// All collects the remaining values
+func (it *Iterator[T]) All(ctx context.Context) ([]T, error) {
+	values := []T{}
+	for it.Next(ctx) {
+		values = append(values, it.Value())
+	}
+	return values, it.Err()
+}
+
+// pagePath adds the limit and offset of the page to the query of path
+func pagePath(path string, query url.Values, page models.Page) string {
+	if query == nil {
+		query = url.Values{}
+	}
+	query.Set("limit", strconv.Itoa(page.Limit))
+	query.Set("offset", strconv.Itoa(page.Offset))
+	return fmt.Sprintf("%s?%s", path, query.Encode())
+}
@@ -0,0 +1,95 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/danikg/go-todo-rest-api/models"
+
+	controllers "github.com/danikg/go-todo-rest-api/controllers/http"
+)
+
+// ListTags returns the tags of the todo item
+func (c *Client) ListTags(ctx context.Context, itemID uint) ([]models.Tag, error) {
+	tags := []models.Tag{}
+	err := c.do(ctx, "GET", fmt.Sprintf("/todo_items/%d/tags", itemID), nil, &tags)
+	return tags, err
+}
+
+// TagTodoItem attaches the tag to the todo item, creating it in the vocabulary of the user if missing
+func (c *Client) TagTodoItem(ctx context.Context, itemID uint, tag models.Tag) (models.Tag, error) {
+	err := c.do(ctx, "POST", fmt.Sprintf("/todo_items/%d/tags", itemID), &tag, &tag)
+	return tag, err
+}
+
+// UntagTodoItem detaches the tag from the todo item
+func (c *Client) UntagTodoItem(ctx context.Context, itemID, tagID uint) error {
+	return c.do(ctx, "DELETE", fmt.Sprintf("/todo_items/%d/tags/%d", itemID, tagID), nil, nil)
+}
+
+// ListUserTags returns the tag vocabulary of the user with usage counts
+func (c *Client) ListUserTags(ctx context.Context, userID uint) ([]models.TagUsage, error) {
+	usages := []models.TagUsage{}
+	err := c.do(ctx, "GET", fmt.Sprintf("/users/%d/tags", userID), nil, &usages)
+	return usages, err
+}
+
+// CreateUserTag adds a tag to the vocabulary of the user
+func (c *Client) CreateUserTag(ctx context.Context, userID uint, tag models.Tag) (models.Tag, error) {
+	err := c.do(ctx, "POST", fmt.Sprintf("/users/%d/tags", userID), &tag, &tag)
+	return tag, err
+}
+
+// GetTag ...
+func (c *Client) GetTag(ctx context.Context, id uint) (models.Tag, error) {
+	tag := models.Tag{}
+	err := c.do(ctx, "GET", fmt.Sprintf("/tags/%d", id), nil, &tag)
+	return tag, err
+}
+
+// UpdateTag ...
+func (c *Client) UpdateTag(ctx context.Context, id uint, tagData models.Tag) (models.Tag, error) {
+	tag := models.Tag{}
+	err := c.do(ctx, "PUT", fmt.Sprintf("/tags/%d", id), &tagData, &tag)
+	return tag, err
+}
+
+// MergeTag moves the todo items and child tags of the tag to the target tag and deletes the tag
+func (c *Client) MergeTag(ctx context.Context, id, targetID uint) (models.Tag, error) {
+	tag := models.Tag{}
+	err := c.do(ctx, "POST", fmt.Sprintf("/tags/%d/merge", id), &controllers.TagMerge{TargetID: targetID}, &tag)
+	return tag, err
+}
+
+// DeleteTag ...
+func (c *Client) DeleteTag(ctx context.Context, id uint) error {
+	return c.do(ctx, "DELETE", fmt.Sprintf("/tags/%d", id), nil, nil)
+}
+
+// TagTodoItems iterates over the todo items carrying the tag or one of its descendants
+func (c *Client) TagTodoItems(tagID uint) *Iterator[models.TodoItem] {
+	return newIterator(c.pageSize, func(ctx context.Context, page models.Page) ([]models.TodoItem, error) {
+		todoItems := []models.TodoItem{}
+		err := c.do(ctx, "GET", pagePath(fmt.Sprintf("/tags/%d/todo_items", tagID), nil, page), nil, &todoItems)
+		return todoItems, err
+	})
+}
+
+// UserTodoItems iterates over the todo items of the user matching the tag filter
+func (c *Client) UserTodoItems(userID uint, filter models.TagFilter) *Iterator[models.TodoItem] {
+	query := url.Values{}
+	if len(filter.Tags) > 0 {
+		query.Set("tag", strings.Join(filter.Tags, ","))
+	}
+	if filter.MatchAll {
+		query.Set("match", "all")
+	}
+
+	return newIterator(c.pageSize, func(ctx context.Context, page models.Page) ([]models.TodoItem, error) {
+		todoItems := []models.TodoItem{}
+		err := c.do(ctx, "GET", pagePath(fmt.Sprintf("/users/%d/todo_items", userID), query, page), nil, &todoItems)
+		return todoItems, err
+	})
+}
@@ -0,0 +1,48 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/danikg/go-todo-rest-api/models"
+)
+
+// ListTodoItems returns the todo items of the todo list
+func (c *Client) ListTodoItems(ctx context.Context, listID uint) ([]models.TodoItem, error) {
+	todoItems := []models.TodoItem{}
+	err := c.do(ctx, "GET", fmt.Sprintf("/todo_lists/%d/todo_items", listID), nil, &todoItems)
+	return todoItems, err
+}
+
+// GetTodoItem ...
+func (c *Client) GetTodoItem(ctx context.Context, id uint) (models.TodoItem, error) {
+	todoItem := models.TodoItem{}
+	err := c.do(ctx, "GET", fmt.Sprintf("/todo_items/%d", id), nil, &todoItem)
+	return todoItem, err
+}
+
+// CreateTodoItem creates a todo item in the todo list
+func (c *Client) CreateTodoItem(ctx context.Context, listID uint, todoItem models.TodoItem) (models.TodoItem, error) {
+	err := c.do(ctx, "POST", fmt.Sprintf("/todo_lists/%d/todo_items", listID), &todoItem, &todoItem)
+	return todoItem, err
+}
+
+// UpdateTodoItem changes the todo item, empty strings and nil values keep the stored values
+func (c *Client) UpdateTodoItem(ctx context.Context, id uint, todoItemData models.TodoItemUpdate) (models.TodoItem, error) {
+	todoItem := models.TodoItem{}
+	err := c.do(ctx, "PUT", fmt.Sprintf("/todo_items/%d", id), &todoItemData, &todoItem)
+	return todoItem, err
+}
+
+// DeleteTodoItem ...
+func (c *Client) DeleteTodoItem(ctx context.Context, id uint) error {
+	return c.do(ctx, "DELETE", fmt.Sprintf("/todo_items/%d", id), nil, nil)
+}
+
+// BulkTodoItems applies the operations in one transaction. When the request is rolled back
+// the report is returned with an *Error matching ErrUnprocessable
+func (c *Client) BulkTodoItems(ctx context.Context, request models.BulkRequest) (models.BulkReport, error) {
+	report := models.BulkReport{}
+	err := c.send(ctx, "POST", "/todo_items/bulk", &request, &report, true)
+	return report, err
+}
@@ -0,0 +1,40 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/danikg/go-todo-rest-api/models"
+)
+
+// ListTodoLists returns the todo lists of the user
+func (c *Client) ListTodoLists(ctx context.Context, userID uint) ([]models.TodoList, error) {
+	todoLists := []models.TodoList{}
+	err := c.do(ctx, "GET", fmt.Sprintf("/users/%d/todo_lists", userID), nil, &todoLists)
+	return todoLists, err
+}
+
+// GetTodoList ...
+func (c *Client) GetTodoList(ctx context.Context, id uint) (models.TodoList, error) {
+	todoList := models.TodoList{}
+	err := c.do(ctx, "GET", fmt.Sprintf("/todo_lists/%d", id), nil, &todoList)
+	return todoList, err
+}
+
+// CreateTodoList creates a todo list of the user
+func (c *Client) CreateTodoList(ctx context.Context, userID uint, todoList models.TodoList) (models.TodoList, error) {
+	err := c.do(ctx, "POST", fmt.Sprintf("/users/%d/todo_lists", userID), &todoList, &todoList)
+	return todoList, err
+}
+
+// UpdateTodoList ...
+func (c *Client) UpdateTodoList(ctx context.Context, id uint, todoListData models.TodoList) (models.TodoList, error) {
+	todoList := models.TodoList{}
+	err := c.do(ctx, "PUT", fmt.Sprintf("/todo_lists/%d", id), &todoListData, &todoList)
+	return todoList, err
+}
+
+// DeleteTodoList deletes the todo list with its todo items
+func (c *Client) DeleteTodoList(ctx context.Context, id uint) error {
+	return c.do(ctx, "DELETE", fmt.Sprintf("/todo_lists/%d", id), nil, nil)
+}
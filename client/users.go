@@ -0,0 +1,49 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/danikg/go-todo-rest-api/models"
+
+	controllers "github.com/danikg/go-todo-rest-api/controllers/http"
+)
+
+// Authenticate issues a token for the credentials, pass it to WithToken
+func (c *Client) Authenticate(ctx context.Context, username, password string) (controllers.Token, error) {
+	token := controllers.Token{}
+	err := c.do(ctx, "POST", "/auth/token", &controllers.Credentials{Username: username, Password: password}, &token)
+	return token, err
+}
+
+// ListUsers ...
+func (c *Client) ListUsers(ctx context.Context) ([]models.User, error) {
+	users := []models.User{}
+	err := c.do(ctx, "GET", "/users", nil, &users)
+	return users, err
+}
+
+// GetUser ...
+func (c *Client) GetUser(ctx context.Context, id uint) (models.User, error) {
+	user := models.User{}
+	err := c.do(ctx, "GET", fmt.Sprintf("/users/%d", id), nil, &user)
+	return user, err
+}
+
+// CreateUser ...
+func (c *Client) CreateUser(ctx context.Context, user models.User) (models.User, error) {
+	err := c.do(ctx, "POST", "/users", &user, &user)
+	return user, err
+}
+
+// UpdateUser changes the username and, when set, the password of the user
+func (c *Client) UpdateUser(ctx context.Context, id uint, userData models.User) (models.User, error) {
+	user := models.User{}
+	err := c.do(ctx, "PUT", fmt.Sprintf("/users/%d", id), &userData, &user)
+	return user, err
+}
+
+// DeleteUser ...
+func (c *Client) DeleteUser(ctx context.Context, id uint) error {
+	return c.do(ctx, "DELETE", fmt.Sprintf("/users/%d", id), nil, nil)
+}
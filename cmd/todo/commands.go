@@ -0,0 +1,461 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/danikg/go-todo-rest-api/client"
+	"github.com/danikg/go-todo-rest-api/models"
+)
+
+var (
+	userHeader  = []string{"ID", "USERNAME", "CREATED"}
+	listHeader  = []string{"ID", "NAME", "USER"}
+	itemHeader  = []string{"ID", "DONE", "TITLE", "DUE", "TAGS"}
+	tagHeader   = []string{"ID", "TEXT", "COLOR", "PARENT"}
+	usageHeader = []string{"ID", "TEXT", "COLOR", "PARENT", "ITEMS"}
+)
+
+func userRows(users ...models.User) [][]string {
+	rows := [][]string{}
+	for _, user := range users {
+		rows = append(rows, []string{id(user.ID), user.Username, date(&user.CreatedAt)})
+	}
+	return rows
+}
+
+func listRows(todoLists ...models.TodoList) [][]string {
+	rows := [][]string{}
+	for _, todoList := range todoLists {
+		rows = append(rows, []string{id(todoList.ID), todoList.Name, id(todoList.UserID)})
+	}
+	return rows
+}
+
+func itemRows(todoItems ...models.TodoItem) [][]string {
+	rows := [][]string{}
+	for _, todoItem := range todoItems {
+		tags := make([]string, len(todoItem.Tags))
+		for i, tag := range todoItem.Tags {
+			tags[i] = tag.Text
+		}
+		rows = append(rows, []string{id(todoItem.ID), check(todoItem.Completed), todoItem.Title, date(todoItem.Due), strings.Join(tags, ",")})
+	}
+	return rows
+}
+
+func tagRow(tag models.Tag) []string {
+	parent := ""
+	if tag.ParentID != nil {
+		parent = id(*tag.ParentID)
+	}
+	return []string{id(tag.ID), tag.Text, tag.Color, parent}
+}
+
+func tagRows(tags ...models.Tag) [][]string {
+	rows := [][]string{}
+	for _, tag := range tags {
+		rows = append(rows, tagRow(tag))
+	}
+	return rows
+}
+
+// parseIDs reads the id arguments at the start of args
+func parseIDs(args []string, n int) ([]uint, error) {
+	ids := make([]uint, n)
+	for i := range ids {
+		var err error
+		if ids[i], err = parseID(args[i]); err != nil {
+			return nil, err
+		}
+	}
+	return ids, nil
+}
+
+func usersList(c *cli, args []string) error {
+	if _, err := c.parse(c.flags(), args, 0, 0); err != nil {
+		return err
+	}
+	users, err := c.client.ListUsers(c.ctx)
+	if err != nil {
+		return err
+	}
+	return c.print(users, userHeader, userRows(users...))
+}
+
+func usersAdd(c *cli, args []string) error {
+	fs := c.flags()
+	password := fs.String("password", "", "password, required to log in when authentication is enabled")
+	args, err := c.parse(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+
+	user, err := c.client.CreateUser(c.ctx, models.User{Username: args[0], Password: *password})
+	if err != nil {
+		return err
+	}
+	return c.print(user, userHeader, userRows(user))
+}
+
+func usersEdit(c *cli, args []string) error {
+	fs := c.flags()
+	username := fs.String("username", "", "new username")
+	password := fs.String("password", "", "new password")
+	args, err := c.parse(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+	ids, err := parseIDs(args, 1)
+	if err != nil {
+		return err
+	}
+
+	user, err := c.client.UpdateUser(c.ctx, ids[0], models.User{Username: *username, Password: *password})
+	if err != nil {
+		return err
+	}
+	return c.print(user, userHeader, userRows(user))
+}
+
+func usersRemove(c *cli, args []string) error {
+	return c.remove(args, (*client.Client).DeleteUser)
+}
+
+// remove deletes the resource of the id argument
+func (c *cli) remove(args []string, delete func(c *client.Client, ctx context.Context, id uint) error) error {
+	args, err := c.parse(c.flags(), args, 1, 1)
+	if err != nil {
+		return err
+	}
+	ids, err := parseIDs(args, 1)
+	if err != nil {
+		return err
+	}
+	return delete(c.client, c.ctx, ids[0])
+}
+
+func listsList(c *cli, args []string) error {
+	fs := c.flags()
+	userID := userFlag(fs)
+	if _, err := c.parse(fs, args, 0, 0); err != nil {
+		return err
+	}
+	user, err := c.user(*userID)
+	if err != nil {
+		return err
+	}
+
+	todoLists, err := c.client.ListTodoLists(c.ctx, user)
+	if err != nil {
+		return err
+	}
+	return c.print(todoLists, listHeader, listRows(todoLists...))
+}
+
+func listsAdd(c *cli, args []string) error {
+	fs := c.flags()
+	userID := userFlag(fs)
+	args, err := c.parse(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+	user, err := c.user(*userID)
+	if err != nil {
+		return err
+	}
+
+	todoList, err := c.client.CreateTodoList(c.ctx, user, models.TodoList{Name: args[0]})
+	if err != nil {
+		return err
+	}
+	return c.print(todoList, listHeader, listRows(todoList))
+}
+
+func listsEdit(c *cli, args []string) error {
+	args, err := c.parse(c.flags(), args, 2, 2)
+	if err != nil {
+		return err
+	}
+	ids, err := parseIDs(args, 1)
+	if err != nil {
+		return err
+	}
+
+	todoList, err := c.client.UpdateTodoList(c.ctx, ids[0], models.TodoList{Name: args[1]})
+	if err != nil {
+		return err
+	}
+	return c.print(todoList, listHeader, listRows(todoList))
+}
+
+func listsRemove(c *cli, args []string) error {
+	return c.remove(args, (*client.Client).DeleteTodoList)
+}
+
+func itemsList(c *cli, args []string) error {
+	fs := c.flags()
+	open := fs.Bool("open", false, "only the todo items that are not done")
+	args, err := c.parse(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+	ids, err := parseIDs(args, 1)
+	if err != nil {
+		return err
+	}
+
+	todoItems, err := c.client.ListTodoItems(c.ctx, ids[0])
+	if err != nil {
+		return err
+	}
+	if *open {
+		pending := []models.TodoItem{}
+		for _, todoItem := range todoItems {
+			if !todoItem.Completed {
+				pending = append(pending, todoItem)
+			}
+		}
+		todoItems = pending
+	}
+	return c.print(todoItems, itemHeader, itemRows(todoItems...))
+}
+
+func itemsAdd(c *cli, args []string) error {
+	fs := c.flags()
+	description := fs.String("description", "", "description")
+	due := fs.String("due", "", "due date, 2006-01-02 or an RFC 3339 time")
+	recurrence := fs.String("recurrence", "", "RRULE like FREQ=WEEKLY;BYDAY=MO, needs -due")
+	args, err := c.parse(fs, args, 2, 2)
+	if err != nil {
+		return err
+	}
+	ids, err := parseIDs(args, 1)
+	if err != nil {
+		return err
+	}
+
+	todoItem := models.TodoItem{Title: args[1], Description: *description, Recurrence: *recurrence}
+	if todoItem.Due, err = parseDue(*due); err != nil {
+		return err
+	}
+	if todoItem, err = c.client.CreateTodoItem(c.ctx, ids[0], todoItem); err != nil {
+		return err
+	}
+	return c.print(todoItem, itemHeader, itemRows(todoItem))
+}
+
+// updateItem reads the todo item, lets change modify it and stores it
+func (c *cli) updateItem(itemID uint, change func(todoItem *models.TodoItem) error) error {
+	todoItem, err := c.client.GetTodoItem(c.ctx, itemID)
+	if err != nil {
+		return err
+	}
+	if err = change(&todoItem); err != nil {
+		return err
+	}
+	todoItemData := models.TodoItemUpdate{
+		Title:       todoItem.Title,
+		Description: todoItem.Description,
+		Completed:   &todoItem.Completed,
+		Due:         todoItem.Due,
+		Recurrence:  todoItem.Recurrence,
+	}
+	if todoItem, err = c.client.UpdateTodoItem(c.ctx, itemID, todoItemData); err != nil {
+		return err
+	}
+	return c.print(todoItem, itemHeader, itemRows(todoItem))
+}
+
+func itemsDone(c *cli, args []string) error {
+	fs := c.flags()
+	undo := fs.Bool("undo", false, "reopen the todo item")
+	args, err := c.parse(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+	ids, err := parseIDs(args, 1)
+	if err != nil {
+		return err
+	}
+
+	return c.updateItem(ids[0], func(todoItem *models.TodoItem) error {
+		todoItem.Completed = !*undo
+		return nil
+	})
+}
+
+func itemsEdit(c *cli, args []string) error {
+	fs := c.flags()
+	title := fs.String("title", "", "new title")
+	description := fs.String("description", "", "new description")
+	due := fs.String("due", "", "new due date, 2006-01-02 or an RFC 3339 time")
+	recurrence := fs.String("recurrence", "", "new RRULE")
+	args, err := c.parse(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+	ids, err := parseIDs(args, 1)
+	if err != nil {
+		return err
+	}
+	dueTime, err := parseDue(*due)
+	if err != nil {
+		return err
+	}
+
+	return c.updateItem(ids[0], func(todoItem *models.TodoItem) error {
+		if *title == "" && *description == "" && dueTime == nil && *recurrence == "" {
+			return errors.New("nothing to change, pass -title, -description, -due or -recurrence")
+		}
+		// empty values keep the stored ones
+		todoItem.Title, todoItem.Description, todoItem.Due, todoItem.Recurrence = *title, *description, dueTime, *recurrence
+		return nil
+	})
+}
+
+func itemsRemove(c *cli, args []string) error {
+	return c.remove(args, (*client.Client).DeleteTodoItem)
+}
+
+func itemsTag(c *cli, args []string) error {
+	fs := c.flags()
+	color := fs.String("color", "", "color of new tags, like #ff8800")
+	args, err := c.parse(fs, args, 2, -1)
+	if err != nil {
+		return err
+	}
+	ids, err := parseIDs(args, 1)
+	if err != nil {
+		return err
+	}
+
+	tags := []models.Tag{}
+	for _, text := range args[1:] {
+		tag, err := c.client.TagTodoItem(c.ctx, ids[0], models.Tag{Text: text, Color: *color})
+		if err != nil {
+			return err
+		}
+		tags = append(tags, tag)
+	}
+	return c.print(tags, tagHeader, tagRows(tags...))
+}
+
+func itemsUntag(c *cli, args []string) error {
+	args, err := c.parse(c.flags(), args, 2, 2)
+	if err != nil {
+		return err
+	}
+	ids, err := parseIDs(args, 2)
+	if err != nil {
+		return err
+	}
+	return c.client.UntagTodoItem(c.ctx, ids[0], ids[1])
+}
+
+func tagsList(c *cli, args []string) error {
+	fs := c.flags()
+	userID := userFlag(fs)
+	itemID := fs.Uint("item", 0, "list the tags of this todo item")
+	if _, err := c.parse(fs, args, 0, 0); err != nil {
+		return err
+	}
+
+	if *itemID != 0 {
+		tags, err := c.client.ListTags(c.ctx, *itemID)
+		if err != nil {
+			return err
+		}
+		return c.print(tags, tagHeader, tagRows(tags...))
+	}
+
+	user, err := c.user(*userID)
+	if err != nil {
+		return err
+	}
+	usages, err := c.client.ListUserTags(c.ctx, user)
+	if err != nil {
+		return err
+	}
+	rows := [][]string{}
+	for _, usage := range usages {
+		rows = append(rows, append(tagRow(usage.Tag), fmt.Sprint(usage.UsageCount)))
+	}
+	return c.print(usages, usageHeader, rows)
+}
+
+func tagsAdd(c *cli, args []string) error {
+	fs := c.flags()
+	userID := userFlag(fs)
+	color := fs.String("color", "", "color, like #ff8800")
+	parent := fs.Uint("parent", 0, "id of the parent tag")
+	args, err := c.parse(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+	user, err := c.user(*userID)
+	if err != nil {
+		return err
+	}
+
+	tag := models.Tag{Text: args[0], Color: *color}
+	if *parent != 0 {
+		tag.ParentID = parent
+	}
+	if tag, err = c.client.CreateUserTag(c.ctx, user, tag); err != nil {
+		return err
+	}
+	return c.print(tag, tagHeader, tagRows(tag))
+}
+
+func tagsEdit(c *cli, args []string) error {
+	fs := c.flags()
+	text := fs.String("text", "", "new text")
+	color := fs.String("color", "", "new color, like #ff8800")
+	args, err := c.parse(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+	ids, err := parseIDs(args, 1)
+	if err != nil {
+		return err
+	}
+
+	tag, err := c.client.GetTag(c.ctx, ids[0])
+	if err != nil {
+		return err
+	}
+	if *text != "" {
+		tag.Text = *text
+	}
+	if *color != "" {
+		tag.Color = *color
+	}
+	if tag, err = c.client.UpdateTag(c.ctx, ids[0], tag); err != nil {
+		return err
+	}
+	return c.print(tag, tagHeader, tagRows(tag))
+}
+
+func tagsMerge(c *cli, args []string) error {
+	args, err := c.parse(c.flags(), args, 2, 2)
+	if err != nil {
+		return err
+	}
+	ids, err := parseIDs(args, 2)
+	if err != nil {
+		return err
+	}
+
+	tag, err := c.client.MergeTag(c.ctx, ids[0], ids[1])
+	if err != nil {
+		return err
+	}
+	return c.print(tag, tagHeader, tagRows(tag))
+}
+
+func tagsRemove(c *cli, args []string) error {
+	return c.remove(args, (*client.Client).DeleteTag)
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// completion prints a script completing the commands and actions, zsh uses the bash script through bashcompinit
+func completion(c *cli, args []string) error {
+	args, err := c.parse(c.flags(), args, 1, 1)
+	if err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	switch args[0] {
+	case "bash", "zsh":
+		if args[0] == "zsh" {
+			b.WriteString("autoload -U +X bashcompinit && bashcompinit\n")
+		}
+		b.WriteString("_todo() {\n")
+		b.WriteString("  local cur=${COMP_WORDS[COMP_CWORD]}\n")
+		b.WriteString("  case $COMP_CWORD in\n")
+		fmt.Fprintf(&b, "    1) COMPREPLY=($(compgen -W %q -- \"$cur\")) ;;\n", strings.Join(groupNames(), " "))
+		b.WriteString("    2) case ${COMP_WORDS[1]} in\n")
+		for _, g := range groups {
+			if words := completionWords(g); words != "" {
+				fmt.Fprintf(&b, "         %s) COMPREPLY=($(compgen -W %q -- \"$cur\")) ;;\n", g.name, words)
+			}
+		}
+		b.WriteString("       esac ;;\n")
+		b.WriteString("  esac\n")
+		b.WriteString("}\n")
+		b.WriteString("complete -F _todo todo\n")
+
+	case "fish":
+		b.WriteString("complete -c todo -f\n")
+		for _, g := range groups {
+			fmt.Fprintf(&b, "complete -c todo -n __fish_use_subcommand -a %s\n", g.name)
+			if words := completionWords(g); words != "" {
+				fmt.Fprintf(&b, "complete -c todo -n '__fish_seen_subcommand_from %s' -a '%s'\n", g.name, words)
+			}
+		}
+
+	default:
+		return fmt.Errorf("unknown shell %q, expected bash, zsh or fish", args[0])
+	}
+
+	_, err = fmt.Fprint(c.stdout, b.String())
+	return err
+}
+
+func groupNames() []string {
+	names := make([]string, len(groups))
+	for i, g := range groups {
+		names[i] = g.name
+	}
+	return names
+}
+
+// completionWords returns the actions of the group, or the shells of the completion command
+func completionWords(g group) string {
+	if g.name == "completion" {
+		return "bash zsh fish"
+	}
+	words := []string{}
+	for _, a := range g.actions {
+		if a.name != "" {
+			words = append(words, a.name)
+		}
+	}
+	return strings.Join(words, " ")
+}
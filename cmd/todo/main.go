@@ -0,0 +1,235 @@
+// Command todo is a command-line client of the API.
+//
+// The server address, token and default user are stored in a config file,
+// see "todo config" and "todo login".
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/danikg/go-todo-rest-api/client"
+)
+
+// errUsage is returned for wrong arguments after the usage was printed
+var errUsage = errors.New("usage")
+
+// action is a subcommand of a group, like "add" of "todo items"
+type action struct {
+	name    string
+	args    string
+	summary string
+	run     func(c *cli, args []string) error
+}
+
+// group is a set of actions on a resource, groups with a single unnamed action are top level commands
+type group struct {
+	name    string
+	actions []action
+}
+
+// groups are the commands of the cli in the order of the usage,
+// set by init as the completion command reads them
+var groups []group
+
+func init() {
+	groups = []group{
+		{"users", []action{
+			{"ls", "", "list the users", usersList},
+			{"add", "USERNAME", "create a user", usersAdd},
+			{"edit", "ID", "change the username or password of a user", usersEdit},
+			{"rm", "ID", "delete a user", usersRemove},
+		}},
+		{"lists", []action{
+			{"ls", "", "list the todo lists of the user", listsList},
+			{"add", "NAME", "create a todo list", listsAdd},
+			{"edit", "ID NAME", "rename a todo list", listsEdit},
+			{"rm", "ID", "delete a todo list", listsRemove},
+		}},
+		{"items", []action{
+			{"ls", "LIST_ID", "list the todo items of a todo list", itemsList},
+			{"add", "LIST_ID TITLE", "create a todo item", itemsAdd},
+			{"done", "ID", "complete a todo item, -undo reopens it", itemsDone},
+			{"edit", "ID", "change a todo item", itemsEdit},
+			{"rm", "ID", "delete a todo item", itemsRemove},
+			{"tag", "ID TAG...", "attach tags to a todo item", itemsTag},
+			{"untag", "ID TAG_ID", "detach a tag from a todo item", itemsUntag},
+		}},
+		{"tags", []action{
+			{"ls", "", "list the tags of the user, -item lists those of a todo item", tagsList},
+			{"add", "TEXT", "create a tag of the user", tagsAdd},
+			{"edit", "ID", "change a tag", tagsEdit},
+			{"merge", "ID TARGET_ID", "merge a tag into another tag", tagsMerge},
+			{"rm", "ID", "delete a tag", tagsRemove},
+		}},
+		{"login", []action{{"", "USERNAME", "get a token and store it with the user", login}}},
+		{"config", []action{
+			{"show", "", "print the settings", configShow},
+			{"set", "KEY VALUE", "set server, token or user", configSet},
+		}},
+		{"completion", []action{{"", "bash|zsh|fish", "print the shell completion script", completion}}},
+	}
+}
+
+// cli holds the global flags and the streams of a run
+type cli struct {
+	ctx            context.Context
+	stdin          io.Reader
+	stdout, stderr io.Writer
+
+	action     action
+	configPath string
+	output     string
+	server     string
+	token      string
+	settings   settings
+	baseURL    string
+	client     *client.Client
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	os.Exit(run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run executes the command and returns the exit code: 1 for failures, 2 for wrong arguments
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	c := &cli{ctx: ctx, stdin: stdin, stdout: stdout, stderr: stderr}
+
+	a, rest, ok := findAction(args)
+	if !ok {
+		c.usage()
+		return 2
+	}
+
+	c.action = a
+	err := a.run(c, rest)
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, flag.ErrHelp):
+		return 0
+	case errors.Is(err, errUsage):
+		return 2
+	default:
+		fmt.Fprintln(stderr, "todo:", err)
+		return 1
+	}
+}
+
+// findAction returns the action named by the leading arguments and the remaining arguments
+func findAction(args []string) (action, []string, bool) {
+	if len(args) == 0 {
+		return action{}, nil, false
+	}
+	for _, g := range groups {
+		if g.name != args[0] {
+			continue
+		}
+		if len(g.actions) == 1 && g.actions[0].name == "" {
+			a := g.actions[0]
+			a.name = g.name
+			return a, args[1:], true
+		}
+		if len(args) < 2 {
+			return action{}, nil, false
+		}
+		for _, a := range g.actions {
+			if a.name == args[1] {
+				a.name = g.name + " " + a.name
+				return a, args[2:], true
+			}
+		}
+	}
+	return action{}, nil, false
+}
+
+func (c *cli) usage() {
+	fmt.Fprintln(c.stderr, "usage: todo COMMAND [ARGS] [flags]\n\ncommands:")
+	for _, g := range groups {
+		for _, a := range g.actions {
+			name := strings.Join(strings.Fields(g.name+" "+a.name+" "+a.args), " ")
+			fmt.Fprintf(c.stderr, "  %-32s %s\n", name, a.summary)
+		}
+	}
+	fmt.Fprintln(c.stderr, "\nrun \"todo COMMAND -h\" to list the flags of a command")
+}
+
+// flags returns the flag set of the action with the global flags
+func (c *cli) flags() *flag.FlagSet {
+	a := c.action
+	fs := flag.NewFlagSet(a.name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(c.stderr, "usage: todo %s %s [flags]\n\n%s\n\nflags:\n", a.name, a.args, a.summary)
+		fs.PrintDefaults()
+	}
+	fs.StringVar(&c.output, "o", "table", "output format, table or json")
+	fs.StringVar(&c.configPath, "config", defaultConfigPath(), "config file")
+	fs.StringVar(&c.server, "server", os.Getenv("TODO_SERVER"), "server URL, overrides the config file")
+	fs.StringVar(&c.token, "token", os.Getenv("TODO_TOKEN"), "access token, overrides the config file")
+	return fs
+}
+
+// parse reads the flags wherever they are given, checks the number of arguments and loads the settings
+func (c *cli) parse(fs *flag.FlagSet, args []string, min, max int) ([]string, error) {
+	positional := []string{}
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return nil, err
+			}
+			return nil, errUsage
+		}
+		if args = fs.Args(); len(args) == 0 {
+			break
+		}
+		positional, args = append(positional, args[0]), args[1:]
+	}
+
+	if len(positional) < min || max >= 0 && len(positional) > max {
+		fs.Usage()
+		return nil, errUsage
+	}
+	if c.output != "table" && c.output != "json" {
+		fmt.Fprintln(c.stderr, "-o must be table or json")
+		return nil, errUsage
+	}
+
+	var err error
+	if c.settings, err = loadSettings(c.configPath); err != nil {
+		return nil, err
+	}
+	token := c.settings.Token
+	c.baseURL = c.settings.Server
+	if c.server != "" {
+		c.baseURL = c.server
+	}
+	if c.token != "" {
+		token = c.token
+	}
+	c.client = client.New(c.baseURL, client.WithToken(token))
+	return positional, nil
+}
+
+// userFlag adds the -user flag, defaulting to the user of the settings
+func userFlag(fs *flag.FlagSet) *uint {
+	return fs.Uint("user", 0, "user id, defaults to the user stored by login")
+}
+
+func (c *cli) user(flagValue uint) (uint, error) {
+	if flagValue != 0 {
+		return flagValue, nil
+	}
+	if c.settings.User == 0 {
+		return 0, errors.New("no user given, pass -user or run todo login")
+	}
+	return c.settings.User, nil
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danikg/go-todo-rest-api/services/mocks"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+
+	controllers "github.com/danikg/go-todo-rest-api/controllers/http"
+)
+
+type cliTest struct {
+	title    string
+	args     []string
+	stdin    string
+	code     int
+	contains []string
+	stderr   string
+}
+
+// newTestServer serves the controllers over the mocked services
+func newTestServer(t *testing.T) *httptest.Server {
+	router := mux.NewRouter()
+	userService := &mocks.UserServiceMock{}
+	controllers.SetupUserRoutes(router, controllers.NewUserController(userService))
+	controllers.SetupAuthRoutes(router, controllers.NewAuthController(userService, "secret", time.Hour))
+	controllers.SetupTodoListRoutes(router, controllers.NewTodoListController(&mocks.TodoListServiceMock{}))
+	controllers.SetupTodoItemRoutes(router, controllers.NewTodoItemController(&mocks.TodoItemServiceMock{}))
+	controllers.SetupTagRoutes(router, controllers.NewTagController(&mocks.TagServiceMock{}))
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func runCLI(t *testing.T, tc cliTest) {
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), tc.args, strings.NewReader(tc.stdin), &stdout, &stderr)
+	assert.Equal(t, tc.code, code, stderr.String())
+	for _, s := range tc.contains {
+		assert.Contains(t, stdout.String(), s)
+	}
+	if tc.stderr != "" {
+		assert.Contains(t, stderr.String(), tc.stderr)
+	}
+}
+
+func TestCLI_Commands(t *testing.T) {
+	server := newTestServer(t)
+	t.Setenv("TODO_CONFIG", filepath.Join(t.TempDir(), "config.yaml"))
+	t.Setenv("TODO_SERVER", server.URL)
+	t.Setenv("TODO_TOKEN", "")
+
+	tests := []cliTest{
+		{title: "No command", code: 2, stderr: "usage: todo COMMAND"},
+		{title: "Unknown command", args: []string{"todos", "ls"}, code: 2, stderr: "usage: todo COMMAND"},
+		{title: "List users", args: []string{"users", "ls"}, contains: []string{"ID  USERNAME", "1   user1", "2   user2"}},
+		{title: "List users as json", args: []string{"users", "ls", "-o", "json"}, contains: []string{`"Username": "user2"`}},
+		{title: "Wrong output", args: []string{"users", "ls", "-o", "xml"}, code: 2, stderr: "-o must be table or json"},
+		{title: "Add user", args: []string{"users", "add", "user3", "-password", "secret"}, contains: []string{"user3"}},
+		{title: "Edit non-existent user", args: []string{"users", "edit", "2", "-username", "x"}, code: 1, stderr: "PUT /users/2: 404 err"},
+		{title: "Remove user", args: []string{"users", "rm", "1"}},
+		{title: "Remove user, wrong id", args: []string{"users", "rm", "a"}, code: 1, stderr: `invalid id "a"`},
+		{title: "Remove user, missing id", args: []string{"users", "rm"}, code: 2, stderr: "usage: todo users rm ID"},
+		{title: "List lists without user", args: []string{"lists", "ls"}, code: 1, stderr: "pass -user or run todo login"},
+		{title: "List lists", args: []string{"lists", "ls", "-user", "1"}, contains: []string{"list1", "list2"}},
+		{title: "Add list", args: []string{"lists", "add", "groceries", "-user", "1"}, contains: []string{"groceries"}},
+		{title: "Edit list", args: []string{"lists", "edit", "1", "renamed"}, contains: []string{"list1"}},
+		{title: "List items", args: []string{"items", "ls", "1"}, contains: []string{"TITLE", "item1", "item2"}},
+		{title: "Add item with flags after the arguments", args: []string{"items", "add", "1", "milk", "-due", "2030-01-02"}, contains: []string{"milk", "2030-01-02 00:00"}},
+		{title: "Add item, wrong due", args: []string{"items", "add", "1", "milk", "-due", "tomorrow"}, code: 1, stderr: `invalid due "tomorrow"`},
+		{title: "Add recurring item without due", args: []string{"items", "add", "1", "milk", "-recurrence", "FREQ=DAILY"}, code: 1, stderr: "400"},
+		{title: "Complete item", args: []string{"items", "done", "1"}, contains: []string{"item1"}},
+		{title: "Complete non-existent item", args: []string{"items", "done", "2"}, code: 1, stderr: "GET /todo_items/2: 404"},
+		{title: "Edit item without changes", args: []string{"items", "edit", "1"}, code: 1, stderr: "nothing to change"},
+		{title: "Edit item", args: []string{"items", "edit", "1", "-title", "bread"}, contains: []string{"item1"}},
+		{title: "Tag item", args: []string{"items", "tag", "1", "home", "urgent"}, contains: []string{"home", "urgent"}},
+		{title: "Untag item", args: []string{"items", "untag", "1", "2"}},
+		{title: "List tags of the user", args: []string{"tags", "ls", "-user", "1"}, contains: []string{"ITEMS", "tag1"}},
+		{title: "List tags of an item", args: []string{"tags", "ls", "-item", "1"}, contains: []string{"tag1", "tag2"}},
+		{title: "Add tag", args: []string{"tags", "add", "work", "-user", "1", "-color", "#ff8800"}, contains: []string{"work", "#ff8800"}},
+		{title: "Edit tag, duplicate text", args: []string{"tags", "edit", "1", "-text", "tag2"}, code: 1, stderr: "409"},
+		{title: "Merge tag into itself", args: []string{"tags", "merge", "1", "1"}, code: 1, stderr: "400"},
+		{title: "Merge tag", args: []string{"tags", "merge", "1", "2"}, contains: []string{"tag2"}},
+		{title: "Completion for an unknown shell", args: []string{"completion", "tcsh"}, code: 1, stderr: `unknown shell "tcsh"`},
+		{title: "Bash completion", args: []string{"completion", "bash"}, contains: []string{"complete -F _todo todo", `items) COMPREPLY=($(compgen -W "ls add done edit rm tag untag"`}},
+		{title: "Fish completion", args: []string{"completion", "fish"}, contains: []string{"__fish_seen_subcommand_from tags"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.title, func(t *testing.T) {
+			runCLI(t, tc)
+		})
+	}
+}
+
+func TestCLI_Login(t *testing.T) {
+	server := newTestServer(t)
+	configPath := filepath.Join(t.TempDir(), "todo", "config.yaml")
+	t.Setenv("TODO_CONFIG", configPath)
+	t.Setenv("TODO_SERVER", "")
+	t.Setenv("TODO_TOKEN", "")
+
+	runCLI(t, cliTest{args: []string{"config", "set", "server", server.URL}})
+	runCLI(t, cliTest{args: []string{"login", "user1"}, stdin: "wrong\n", code: 1, stderr: "401"})
+	runCLI(t, cliTest{args: []string{"login", "user1"}, stdin: "password\n", contains: []string{"logged in as user1 (user 1)"}})
+
+	info, err := os.Stat(configPath)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	// the stored user is the default of the lists commands
+	runCLI(t, cliTest{args: []string{"lists", "ls"}, contains: []string{"list1"}})
+	runCLI(t, cliTest{args: []string{"config", "show"}, contains: []string{server.URL, "<redacted>", configPath}})
+	runCLI(t, cliTest{args: []string{"config", "set", "colour", "x"}, code: 1, stderr: `unknown key "colour"`})
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// print writes v as indented json with -o json, otherwise the rows as a table under the header
+func (c *cli) print(v interface{}, header []string, rows [][]string) error {
+	if c.output == "json" {
+		encoder := json.NewEncoder(c.stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v)
+	}
+
+	w := tabwriter.NewWriter(c.stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	return w.Flush()
+}
+
+func id(value uint) string {
+	return strconv.FormatUint(uint64(value), 10)
+}
+
+func check(done bool) string {
+	if done {
+		return "x"
+	}
+	return ""
+}
+
+func date(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
+
+// parseID reads an id argument
+func parseID(value string) (uint, error) {
+	n, err := strconv.ParseUint(value, 10, 32)
+	if err != nil || n == 0 {
+		return 0, fmt.Errorf("invalid id %q", value)
+	}
+	return uint(n), nil
+}
+
+// parseDue reads an RFC 3339 time or a date, dates are due at local midnight
+func parseDue(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return nil, fmt.Errorf("invalid due %q, expected 2006-01-02 or an RFC 3339 time", value)
+	}
+	return &t, nil
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/danikg/go-todo-rest-api/client"
+)
+
+// login stores a token of the user and the user as default of the lists and tags commands,
+// the password is read from the first line of stdin unless -password is given
+func login(c *cli, args []string) error {
+	fs := c.flags()
+	password := fs.String("password", "", "password, read from stdin when empty")
+	args, err := c.parse(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+
+	username := args[0]
+	if *password == "" {
+		fmt.Fprint(c.stderr, "password: ")
+		line, err := bufio.NewReader(c.stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return errors.New("no password given")
+		}
+		*password = strings.TrimRight(line, "\r\n")
+	}
+
+	token, err := c.client.Authenticate(c.ctx, username, *password)
+	if err != nil {
+		return err
+	}
+	users, err := client.New(c.baseURL, client.WithToken(token.Token)).ListUsers(c.ctx)
+	if err != nil {
+		return err
+	}
+	c.settings.User = 0
+	for _, user := range users {
+		if user.Username == username {
+			c.settings.User = user.ID
+		}
+	}
+
+	c.settings.Token = token.Token
+	if c.server != "" {
+		c.settings.Server = c.server
+	}
+	if err = saveSettings(c.configPath, c.settings); err != nil {
+		return err
+	}
+	fmt.Fprintf(c.stdout, "logged in as %s (user %d) until %s\n", username, c.settings.User, token.ExpiresAt.Local().Format("2006-01-02 15:04"))
+	return nil
+}
+
+func configShow(c *cli, args []string) error {
+	if _, err := c.parse(c.flags(), args, 0, 0); err != nil {
+		return err
+	}
+
+	shown := c.settings
+	if shown.Token != "" {
+		shown.Token = "<redacted>"
+	}
+	return c.print(shown, []string{"CONFIG", "SERVER", "TOKEN", "USER"},
+		[][]string{{c.configPath, shown.Server, shown.Token, id(shown.User)}})
+}
+
+func configSet(c *cli, args []string) error {
+	args, err := c.parse(c.flags(), args, 2, 2)
+	if err != nil {
+		return err
+	}
+
+	switch key, value := args[0], args[1]; key {
+	case "server":
+		c.settings.Server = value
+	case "token":
+		c.settings.Token = value
+	case "user":
+		if c.settings.User, err = parseID(value); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown key %q, expected server, token or user", key)
+	}
+	return saveSettings(c.configPath, c.settings)
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v2"
+)
+
+// defaultServer is the address of a local API started with docker-compose
+const defaultServer = "http://localhost:8000"
+
+// settings are stored in the config file, TODO_SERVER and TODO_TOKEN override them
+type settings struct {
+	Server string `yaml:"server"`
+	Token  string `yaml:"token,omitempty"`
+	// User is the default user of the lists and tags commands
+	User uint `yaml:"user,omitempty"`
+}
+
+// defaultConfigPath returns TODO_CONFIG or todo/config.yaml in the user config directory
+func defaultConfigPath() string {
+	if path := os.Getenv("TODO_CONFIG"); path != "" {
+		return path
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = "."
+	}
+	return filepath.Join(dir, "todo", "config.yaml")
+}
+
+// loadSettings reads the config file, a missing file gives the defaults
+func loadSettings(path string) (settings, error) {
+	s := settings{Server: defaultServer}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return s, err
+	}
+	if err = yaml.UnmarshalStrict(data, &s); err != nil {
+		return s, err
+	}
+	if s.Server == "" {
+		s.Server = defaultServer
+	}
+	return s, nil
+}
+
+// saveSettings writes the config file readable by the user only, it holds the token
+func saveSettings(path string, s settings) error {
+	data, err := yaml.Marshal(s)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
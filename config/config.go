@@ -1,36 +1,153 @@
 package config
 
-import (
-	"os"
-	"sync"
-)
+import "time"
 
-// Config ...
+// Config holds the effective settings of the service.
+// Values are layered: defaults < config file < environment < flags
 type Config struct {
-	DBName     string
-	DBUser     string
-	DBPassword string
-	DBHost     string
-	AppHost    string
-	AppPort    string
-}
-
-var (
-	configInstance *Config
-	once           sync.Once
-)
-
-// GetConfig returns config
-func GetConfig() *Config {
-	once.Do(func() {
-		configInstance = &Config{
-			DBName:     os.Getenv("DB_NAME"),
-			DBUser:     os.Getenv("DB_USER"),
-			DBPassword: os.Getenv("DB_PASSWORD"),
-			DBHost:     os.Getenv("DB_HOST"),
-			AppHost:    os.Getenv("APP_HOST"),
-			AppPort:    os.Getenv("APP_PORT"),
-		}
-	})
-	return configInstance
+	App         AppConfig         `yaml:"app" toml:"app"`
+	DB          DBConfig          `yaml:"db" toml:"db"`
+	CORS        CORSConfig        `yaml:"cors" toml:"cors"`
+	Auth        AuthConfig        `yaml:"auth" toml:"auth"`
+	Tracing     TracingConfig     `yaml:"tracing" toml:"tracing"`
+	Idempotency IdempotencyConfig `yaml:"idempotency" toml:"idempotency"`
+	Webhook     WebhookConfig     `yaml:"webhook" toml:"webhook"`
+	Events      EventsConfig      `yaml:"events" toml:"events"`
+	GRPC        GRPCConfig        `yaml:"grpc" toml:"grpc"`
+}
+
+// AppConfig ...
+type AppConfig struct {
+	Host            string        `yaml:"host" toml:"host" env:"APP_HOST" flag:"app-host" usage:"address to listen on"`
+	Port            int           `yaml:"port" toml:"port" env:"APP_PORT" flag:"app-port" usage:"port to listen on"`
+	ReadTimeout     time.Duration `yaml:"read_timeout" toml:"read_timeout" env:"APP_READ_TIMEOUT" flag:"app-read-timeout" usage:"maximum duration for reading a request"`
+	WriteTimeout    time.Duration `yaml:"write_timeout" toml:"write_timeout" env:"APP_WRITE_TIMEOUT" flag:"app-write-timeout" usage:"maximum duration for writing a response"`
+	IdleTimeout     time.Duration `yaml:"idle_timeout" toml:"idle_timeout" env:"APP_IDLE_TIMEOUT" flag:"app-idle-timeout" usage:"keep-alive connections idle timeout"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout" env:"APP_SHUTDOWN_TIMEOUT" flag:"app-shutdown-timeout" usage:"time given to in-flight requests on shutdown"`
+}
+
+// DBConfig ...
+type DBConfig struct {
+	Host               string        `yaml:"host" toml:"host" env:"DB_HOST" flag:"db-host" usage:"database host"`
+	Port               int           `yaml:"port" toml:"port" env:"DB_PORT" flag:"db-port" usage:"database port"`
+	Name               string        `yaml:"name" toml:"name" env:"DB_NAME" flag:"db-name" usage:"database name"`
+	User               string        `yaml:"user" toml:"user" env:"DB_USER" flag:"db-user" usage:"database user"`
+	Password           string        `yaml:"password" toml:"password" env:"DB_PASSWORD" flag:"db-password" usage:"database password" secret:"true"`
+	SSLMode            string        `yaml:"sslmode" toml:"sslmode" env:"DB_SSLMODE" flag:"db-sslmode" usage:"postgres sslmode"`
+	MaxOpenConns       int           `yaml:"max_open_conns" toml:"max_open_conns" env:"DB_MAX_OPEN_CONNS" flag:"db-max-open-conns" usage:"maximum open connections, 0 is unlimited"`
+	MaxIdleConns       int           `yaml:"max_idle_conns" toml:"max_idle_conns" env:"DB_MAX_IDLE_CONNS" flag:"db-max-idle-conns" usage:"maximum idle connections"`
+	ConnMaxLifetime    time.Duration `yaml:"conn_max_lifetime" toml:"conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME" flag:"db-conn-max-lifetime" usage:"connections older than this are closed, 0 keeps them forever"`
+	ConnMaxIdleTime    time.Duration `yaml:"conn_max_idle_time" toml:"conn_max_idle_time" env:"DB_CONN_MAX_IDLE_TIME" flag:"db-conn-max-idle-time" usage:"connections idle for longer are closed, 0 keeps them forever"`
+	StatementTimeout   time.Duration `yaml:"statement_timeout" toml:"statement_timeout" env:"DB_STATEMENT_TIMEOUT" flag:"db-statement-timeout" usage:"postgres aborts statements running longer, 0 disables"`
+	ConnectTimeout     time.Duration `yaml:"connect_timeout" toml:"connect_timeout" env:"DB_CONNECT_TIMEOUT" flag:"db-connect-timeout" usage:"timeout of a single connection attempt"`
+	ConnectAttempts    int           `yaml:"connect_attempts" toml:"connect_attempts" env:"DB_CONNECT_ATTEMPTS" flag:"db-connect-attempts" usage:"connection attempts on startup before giving up"`
+	ConnectBackoff     time.Duration `yaml:"connect_backoff" toml:"connect_backoff" env:"DB_CONNECT_BACKOFF" flag:"db-connect-backoff" usage:"wait after the first failed attempt, doubled after each failure"`
+	ConnectMaxBackoff  time.Duration `yaml:"connect_max_backoff" toml:"connect_max_backoff" env:"DB_CONNECT_MAX_BACKOFF" flag:"db-connect-max-backoff" usage:"maximum wait between two attempts"`
+	ReplicaDSN         string        `yaml:"replica_dsn" toml:"replica_dsn" env:"DB_REPLICA_DSN" flag:"db-replica-dsn" usage:"read replica connection string serving GET requests, empty disables" secret:"true"`
+	SlowQueryThreshold time.Duration `yaml:"slow_query_threshold" toml:"slow_query_threshold" env:"DB_SLOW_QUERY_THRESHOLD" flag:"db-slow-query-threshold" usage:"queries slower than this are logged, 0 disables"`
+}
+
+// CORSConfig ...
+type CORSConfig struct {
+	AllowedOrigins   []string      `yaml:"allowed_origins" toml:"allowed_origins" env:"CORS_ALLOWED_ORIGINS" flag:"cors-allowed-origins" usage:"comma separated origins, * allows any, empty disables CORS"`
+	AllowedMethods   []string      `yaml:"allowed_methods" toml:"allowed_methods" env:"CORS_ALLOWED_METHODS" flag:"cors-allowed-methods" usage:"comma separated methods"`
+	AllowedHeaders   []string      `yaml:"allowed_headers" toml:"allowed_headers" env:"CORS_ALLOWED_HEADERS" flag:"cors-allowed-headers" usage:"comma separated request headers"`
+	AllowCredentials bool          `yaml:"allow_credentials" toml:"allow_credentials" env:"CORS_ALLOW_CREDENTIALS" flag:"cors-allow-credentials" usage:"allow cookies and authorization headers"`
+	MaxAge           time.Duration `yaml:"max_age" toml:"max_age" env:"CORS_MAX_AGE" flag:"cors-max-age" usage:"how long preflight responses may be cached"`
+}
+
+// AuthConfig ...
+type AuthConfig struct {
+	Enabled  bool          `yaml:"enabled" toml:"enabled" env:"AUTH_ENABLED" flag:"auth-enabled" usage:"require a bearer token on every non public route, without it requests lacking a token may access the data of every user"`
+	Secret   string        `yaml:"secret" toml:"secret" env:"AUTH_SECRET" flag:"auth-secret" usage:"key signing the access tokens" secret:"true"`
+	TokenTTL time.Duration `yaml:"token_ttl" toml:"token_ttl" env:"AUTH_TOKEN_TTL" flag:"auth-token-ttl" usage:"lifetime of the access tokens"`
+}
+
+// TracingConfig ...
+type TracingConfig struct {
+	OTLPEndpoint string `yaml:"otlp_endpoint" toml:"otlp_endpoint" env:"OTEL_EXPORTER_OTLP_ENDPOINT" flag:"otlp-endpoint" usage:"OTLP/HTTP collector url, spans are not exported when empty"`
+	ServiceName  string `yaml:"service_name" toml:"service_name" env:"OTEL_SERVICE_NAME" flag:"service-name" usage:"service name of the exported spans"`
+}
+
+// IdempotencyConfig ...
+type IdempotencyConfig struct {
+	TTL time.Duration `yaml:"ttl" toml:"ttl" env:"IDEMPOTENCY_TTL" flag:"idempotency-ttl" usage:"how long responses of POST requests with an Idempotency-Key are replayed"`
+}
+
+// WebhookConfig ...
+type WebhookConfig struct {
+	PollInterval        time.Duration `yaml:"poll_interval" toml:"poll_interval" env:"WEBHOOK_POLL_INTERVAL" flag:"webhook-poll-interval" usage:"pause between two runs of the webhook dispatcher"`
+	Timeout             time.Duration `yaml:"timeout" toml:"timeout" env:"WEBHOOK_TIMEOUT" flag:"webhook-timeout" usage:"timeout of a single webhook delivery attempt"`
+	MaxAttempts         int           `yaml:"max_attempts" toml:"max_attempts" env:"WEBHOOK_MAX_ATTEMPTS" flag:"webhook-max-attempts" usage:"delivery attempts before a webhook delivery is given up"`
+	RetryBackoff        time.Duration `yaml:"retry_backoff" toml:"retry_backoff" env:"WEBHOOK_RETRY_BACKOFF" flag:"webhook-retry-backoff" usage:"wait after the first failed delivery attempt, doubled after each failure"`
+	MaxRetryBackoff     time.Duration `yaml:"max_retry_backoff" toml:"max_retry_backoff" env:"WEBHOOK_MAX_RETRY_BACKOFF" flag:"webhook-max-retry-backoff" usage:"maximum wait between two delivery attempts"`
+	AllowPrivateTargets bool          `yaml:"allow_private_targets" toml:"allow_private_targets" env:"WEBHOOK_ALLOW_PRIVATE_TARGETS" flag:"webhook-allow-private-targets" usage:"allow webhook URLs on loopback, private and link-local addresses"`
+}
+
+// EventsConfig ...
+type EventsConfig struct {
+	History   int           `yaml:"history" toml:"history" env:"EVENTS_HISTORY" flag:"events-history" usage:"latest change events kept for clients resuming with Last-Event-ID"`
+	Heartbeat time.Duration `yaml:"heartbeat" toml:"heartbeat" env:"EVENTS_HEARTBEAT" flag:"events-heartbeat" usage:"pause between two keep-alive messages on idle event streams"`
+}
+
+// GRPCConfig ...
+type GRPCConfig struct {
+	Port int `yaml:"port" toml:"port" env:"GRPC_PORT" flag:"grpc-port" usage:"port of the gRPC API on app.host, 0 disables it"`
+}
+
+// Default returns the configuration used when nothing overrides it
+func Default() *Config {
+	return &Config{
+		App: AppConfig{
+			Port:            8000,
+			ReadTimeout:     15 * time.Second,
+			WriteTimeout:    15 * time.Second,
+			IdleTimeout:     60 * time.Second,
+			ShutdownTimeout: 10 * time.Second,
+		},
+		DB: DBConfig{
+			Host:               "localhost",
+			Port:               5432,
+			User:               "postgres",
+			SSLMode:            "disable",
+			MaxOpenConns:       20,
+			MaxIdleConns:       5,
+			ConnMaxLifetime:    30 * time.Minute,
+			ConnMaxIdleTime:    5 * time.Minute,
+			StatementTimeout:   30 * time.Second,
+			ConnectTimeout:     5 * time.Second,
+			ConnectAttempts:    10,
+			ConnectBackoff:     500 * time.Millisecond,
+			ConnectMaxBackoff:  10 * time.Second,
+			SlowQueryThreshold: 200 * time.Millisecond,
+		},
+		CORS: CORSConfig{
+			AllowedMethods: []string{"GET", "POST", "PUT", "DELETE"},
+			AllowedHeaders: []string{"Authorization", "Content-Type", "X-Request-ID", "Idempotency-Key"},
+			MaxAge:         10 * time.Minute,
+		},
+		Auth: AuthConfig{
+			TokenTTL: 24 * time.Hour,
+		},
+		Tracing: TracingConfig{
+			ServiceName: "go-todo-rest-api",
+		},
+		Idempotency: IdempotencyConfig{
+			TTL: 24 * time.Hour,
+		},
+		Webhook: WebhookConfig{
+			PollInterval:    5 * time.Second,
+			Timeout:         10 * time.Second,
+			MaxAttempts:     8,
+			RetryBackoff:    30 * time.Second,
+			MaxRetryBackoff: time.Hour,
+		},
+		Events: EventsConfig{
+			History:   1000,
+			Heartbeat: 25 * time.Second,
+		},
+		GRPC: GRPCConfig{
+			Port: 9000,
+		},
+	}
 }
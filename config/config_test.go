@@ -0,0 +1,119 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func TestLoad_Layers(t *testing.T) {
+	path := writeFile(t, "config.yaml", `
+db:
+  host: file-host
+  port: 5433
+  name: file-db
+  max_open_conns: 50
+app:
+  read_timeout: 30s
+`)
+	t.Setenv("DB_HOST", "env-host")
+	t.Setenv("DB_NAME", "env-db")
+	t.Setenv("CORS_ALLOWED_ORIGINS", "https://a.example.com, https://b.example.com")
+
+	cfg, err := Load([]string{"--config", path, "--db-name", "flag-db", "--auth-enabled", "--auth-secret", "0123456789abcdef0123456789abcdef"})
+	assert.NoError(t, err)
+
+	assert.Equal(t, 5432, Default().DB.Port)
+	assert.Equal(t, 5433, cfg.DB.Port)
+	assert.Equal(t, 50, cfg.DB.MaxOpenConns)
+	assert.Equal(t, 30*time.Second, cfg.App.ReadTimeout)
+	assert.Equal(t, 15*time.Second, cfg.App.WriteTimeout)
+	assert.Equal(t, "env-host", cfg.DB.Host)
+	assert.Equal(t, "flag-db", cfg.DB.Name)
+	assert.Equal(t, []string{"https://a.example.com", "https://b.example.com"}, cfg.CORS.AllowedOrigins)
+	assert.True(t, cfg.Auth.Enabled)
+}
+
+func TestLoad_TOML(t *testing.T) {
+	path := writeFile(t, "config.toml", `
+[db]
+name = "toml-db"
+connect_timeout = "2s"
+`)
+	t.Setenv(FileEnv, path)
+
+	cfg, err := Load(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "toml-db", cfg.DB.Name)
+	assert.Equal(t, 2*time.Second, cfg.DB.ConnectTimeout)
+}
+
+func TestLoad_ReportsAllProblems(t *testing.T) {
+	t.Setenv("DB_PORT", "abc")
+	t.Setenv("DB_SSLMODE", "sometimes")
+
+	cfg, err := Load([]string{"--db-name", "db", "--app-port", "70000", "--grpc-port", "-1", "--auth-enabled", "--cors-allowed-origins", "not an origin"})
+	assert.NotNil(t, cfg)
+	assert.Error(t, err)
+	for _, problem := range []string{"DB_PORT", "db.sslmode", "app.port", "grpc.port", "auth.secret", "cors.allowed_origins"} {
+		assert.Contains(t, err.Error(), problem)
+	}
+}
+
+func TestLoad_UnknownFileKey(t *testing.T) {
+	path := writeFile(t, "config.yml", "db:\n  hots: typo\n")
+	cfg, err := Load([]string{"--config", path})
+	assert.NotNil(t, cfg)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "hots")
+	}
+}
+
+func TestLoad_UnknownTOMLKey(t *testing.T) {
+	path := writeFile(t, "config.toml", "[db]\nhots = \"typo\"\n\n[corss]\nallowed_origins = []\n")
+	cfg, err := Load([]string{"--config", path, "--app-port", "70000"})
+	assert.NotNil(t, cfg)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "db.hots")
+		assert.Contains(t, err.Error(), "corss")
+		assert.Contains(t, err.Error(), "app.port")
+	}
+}
+
+func TestLoad_MissingFile(t *testing.T) {
+	cfg, err := Load([]string{"--config", filepath.Join(t.TempDir(), "missing.yml"), "--app-port", "70000"})
+	assert.NotNil(t, cfg)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "missing.yml")
+		assert.Contains(t, err.Error(), "app.port")
+	}
+}
+
+func TestConfig_Print(t *testing.T) {
+	cfg := Default()
+	cfg.DB.Name = "db"
+	cfg.DB.Password = "hunter2"
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, cfg.Print(buf))
+	assert.NotContains(t, buf.String(), "hunter2")
+	assert.Contains(t, buf.String(), "password: <redacted>")
+	assert.Contains(t, buf.String(), "secret: \"\"")
+	assert.Contains(t, buf.String(), "slow_query_threshold: 200ms")
+
+	// the printed configuration is a valid config file
+	path := writeFile(t, "printed.yaml", buf.String())
+	printed, err := Load([]string{"--config", path})
+	assert.NoError(t, err)
+	assert.Equal(t, "db", printed.DB.Name)
+}
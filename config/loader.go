@@ -0,0 +1,206 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v2"
+)
+
+// FileEnv is the environment variable pointing to the config file
+const FileEnv = "CONFIG_FILE"
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// field is a single setting reachable from the environment and the command line
+type field struct {
+	path   string
+	env    string
+	flag   string
+	usage  string
+	secret bool
+	value  reflect.Value
+}
+
+// Load builds the configuration from the defaults, the config file, the environment and args.
+// Every file, parsing and validation problem is reported in the returned error, the
+// configuration is only nil when args cannot be parsed, the flag set then printed the problem
+func Load(args []string) (*Config, error) {
+	cfg := Default()
+	fields := cfg.fields()
+
+	fs := flag.NewFlagSet("todo-api", flag.ContinueOnError)
+	path := fs.String("config", os.Getenv(FileEnv), "YAML or TOML config file")
+	flagValues := map[string]string{}
+	for _, f := range fields {
+		fs.Var(&rawValue{name: f.flag, values: flagValues, isBool: f.value.Kind() == reflect.Bool}, f.flag, f.usage)
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	if *path != "" {
+		if err := loadFile(cfg, *path); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	for _, f := range fields {
+		// empty variables are treated as unset, so a blank line in .env keeps the default
+		if value := os.Getenv(f.env); value != "" {
+			if err := f.set(value); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", f.env, err))
+			}
+		}
+	}
+
+	for _, f := range fields {
+		if value, ok := flagValues[f.flag]; ok {
+			if err := f.set(value); err != nil {
+				errs = append(errs, fmt.Errorf("--%s: %w", f.flag, err))
+			}
+		}
+	}
+
+	errs = append(errs, cfg.Validate()...)
+	return cfg, errors.Join(errs...)
+}
+
+func loadFile(cfg *Config, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	switch ext := filepath.Ext(path); ext {
+	case ".yaml", ".yml":
+		err = yaml.UnmarshalStrict(data, cfg)
+	case ".toml":
+		err = decodeTOML(data, cfg)
+	default:
+		err = fmt.Errorf("unsupported config file extension %q", ext)
+	}
+
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// decodeTOML decodes data into cfg, keys that match no setting are reported like the strict YAML decoding does
+func decodeTOML(data []byte, cfg *Config) error {
+	md, err := toml.Decode(string(data), cfg)
+	if err != nil {
+		return err
+	}
+
+	// an unknown table is reported through its keys
+	var unknown []string
+	undecoded := md.Undecoded()
+	for i, key := range undecoded {
+		if i+1 < len(undecoded) && strings.HasPrefix(undecoded[i+1].String(), key.String()+".") {
+			continue
+		}
+		unknown = append(unknown, key.String())
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("unknown keys %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// fields lists the leaf settings of every section
+func (c *Config) fields() []field {
+	var fields []field
+	sections := reflect.ValueOf(c).Elem()
+	for i := 0; i < sections.NumField(); i++ {
+		section := sections.Field(i)
+		sectionName := tagName(sections.Type().Field(i).Tag.Get("yaml"))
+
+		for j := 0; j < section.NumField(); j++ {
+			sf := section.Type().Field(j)
+			fields = append(fields, field{
+				path:   sectionName + "." + tagName(sf.Tag.Get("yaml")),
+				env:    sf.Tag.Get("env"),
+				flag:   sf.Tag.Get("flag"),
+				usage:  sf.Tag.Get("usage"),
+				secret: sf.Tag.Get("secret") == "true",
+				value:  section.Field(j),
+			})
+		}
+	}
+	return fields
+}
+
+func tagName(tag string) string {
+	return strings.Split(tag, ",")[0]
+}
+
+// set parses s according to the type of the field
+func (f field) set(s string) error {
+	switch {
+	case f.value.Type() == durationType:
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		f.value.SetInt(int64(d))
+	case f.value.Kind() == reflect.String:
+		f.value.SetString(s)
+	case f.value.Kind() == reflect.Int:
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return fmt.Errorf("%q is not an integer", s)
+		}
+		f.value.SetInt(int64(n))
+	case f.value.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("%q is not a boolean", s)
+		}
+		f.value.SetBool(b)
+	case f.value.Kind() == reflect.Slice:
+		items := []string{}
+		for _, item := range strings.Split(s, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		f.value.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported type %s", f.value.Type())
+	}
+	return nil
+}
+
+// rawValue stores the flags as given, they are applied after the file and the environment
+type rawValue struct {
+	name   string
+	values map[string]string
+	isBool bool
+}
+
+// String ...
+func (r *rawValue) String() string {
+	return ""
+}
+
+// Set ...
+func (r *rawValue) Set(s string) error {
+	r.values[r.name] = s
+	return nil
+}
+
+// IsBoolFlag allows boolean flags without a value
+func (r *rawValue) IsBoolFlag() bool {
+	return r.isBool
+}
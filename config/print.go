@@ -0,0 +1,43 @@
+package config
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+const redacted = "<redacted>"
+
+// Print writes the configuration as YAML with the secrets redacted
+func (c *Config) Print(w io.Writer) error {
+	sections := yaml.MapSlice{}
+	for _, f := range c.fields() {
+		sectionName, key, _ := strings.Cut(f.path, ".")
+		if len(sections) == 0 || sections[len(sections)-1].Key != sectionName {
+			sections = append(sections, yaml.MapItem{Key: sectionName, Value: yaml.MapSlice{}})
+		}
+
+		var value interface{} = f.value.Interface()
+		switch {
+		case f.secret && f.value.String() != "":
+			value = redacted
+		case f.value.Type() == durationType:
+			value = time.Duration(f.value.Int()).String()
+		case f.value.Kind() == reflect.Slice && f.value.Len() == 0:
+			value = []string{}
+		}
+
+		section := &sections[len(sections)-1]
+		section.Value = append(section.Value.(yaml.MapSlice), yaml.MapItem{Key: key, Value: value})
+	}
+
+	out, err := yaml.Marshal(sections)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(out)
+	return err
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+var sslModes = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+
+// minSecretLength is the minimum length of the token signing key
+const minSecretLength = 32
+
+// Validate returns every problem found in the configuration
+func (c *Config) Validate() []error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(validPort(c.App.Port), "app.port: %d is not a valid port", c.App.Port)
+	check(c.App.ReadTimeout > 0, "app.read_timeout: must be positive")
+	check(c.App.WriteTimeout > 0, "app.write_timeout: must be positive")
+	check(c.App.IdleTimeout > 0, "app.idle_timeout: must be positive")
+	check(c.App.ShutdownTimeout > 0, "app.shutdown_timeout: must be positive")
+
+	check(c.DB.Host != "", "db.host: is required")
+	check(validPort(c.DB.Port), "db.port: %d is not a valid port", c.DB.Port)
+	check(c.DB.Name != "", "db.name: is required")
+	check(c.DB.User != "", "db.user: is required")
+	check(contains(sslModes, c.DB.SSLMode), "db.sslmode: %q is not one of %s", c.DB.SSLMode, strings.Join(sslModes, ", "))
+	check(c.DB.MaxOpenConns >= 0, "db.max_open_conns: must not be negative")
+	check(c.DB.MaxIdleConns >= 0, "db.max_idle_conns: must not be negative")
+	check(c.DB.MaxOpenConns == 0 || c.DB.MaxIdleConns <= c.DB.MaxOpenConns,
+		"db.max_idle_conns: %d exceeds db.max_open_conns %d", c.DB.MaxIdleConns, c.DB.MaxOpenConns)
+	check(c.DB.ConnMaxLifetime >= 0, "db.conn_max_lifetime: must not be negative")
+	check(c.DB.ConnMaxIdleTime >= 0, "db.conn_max_idle_time: must not be negative")
+	check(c.DB.StatementTimeout >= 0, "db.statement_timeout: must not be negative")
+	check(c.DB.ConnectTimeout >= 0, "db.connect_timeout: must not be negative")
+	check(c.DB.ConnectAttempts > 0, "db.connect_attempts: must be positive")
+	check(c.DB.ConnectBackoff > 0, "db.connect_backoff: must be positive")
+	check(c.DB.ConnectMaxBackoff >= c.DB.ConnectBackoff, "db.connect_max_backoff: must not be lower than db.connect_backoff")
+	check(c.DB.SlowQueryThreshold >= 0, "db.slow_query_threshold: must not be negative")
+
+	for _, origin := range c.CORS.AllowedOrigins {
+		check(origin == "*" || validURL(origin), "cors.allowed_origins: %q is not an origin", origin)
+	}
+	check(!c.CORS.AllowCredentials || !contains(c.CORS.AllowedOrigins, "*"),
+		"cors.allow_credentials: cannot be combined with the * origin")
+	check(c.CORS.MaxAge >= 0, "cors.max_age: must not be negative")
+
+	if c.Auth.Enabled {
+		check(len(c.Auth.Secret) >= minSecretLength, "auth.secret: must be at least %d characters when auth is enabled", minSecretLength)
+	}
+	check(c.Auth.TokenTTL > 0, "auth.token_ttl: must be positive")
+
+	if c.Tracing.OTLPEndpoint != "" {
+		check(validURL(c.Tracing.OTLPEndpoint), "tracing.otlp_endpoint: %q is not a url", c.Tracing.OTLPEndpoint)
+	}
+	check(c.Tracing.ServiceName != "", "tracing.service_name: is required")
+
+	check(c.Idempotency.TTL > 0, "idempotency.ttl: must be positive")
+
+	check(c.Webhook.PollInterval > 0, "webhook.poll_interval: must be positive")
+	check(c.Webhook.Timeout > 0, "webhook.timeout: must be positive")
+	check(c.Webhook.MaxAttempts > 0, "webhook.max_attempts: must be positive")
+	check(c.Webhook.RetryBackoff > 0, "webhook.retry_backoff: must be positive")
+	check(c.Webhook.MaxRetryBackoff >= c.Webhook.RetryBackoff, "webhook.max_retry_backoff: must not be lower than webhook.retry_backoff")
+
+	check(c.Events.History >= 0, "events.history: must not be negative")
+	check(c.Events.Heartbeat > 0, "events.heartbeat: must be positive")
+
+	check(c.GRPC.Port == 0 || validPort(c.GRPC.Port), "grpc.port: %d is not a valid port", c.GRPC.Port)
+	check(c.GRPC.Port != c.App.Port, "grpc.port: must differ from app.port")
+	return errs
+}
+
+func validPort(port int) bool {
+	return port > 0 && port < 1<<16
+}
+
+func validURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,153 @@
+package grpc
+
+import (
+	"fmt"
+	"time"
+
+	todov1 "github.com/danikg/go-todo-rest-api/api/todo/v1"
+	"github.com/danikg/go-todo-rest-api/models"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func toUser(user models.User) *todov1.User {
+	return &todov1.User{
+		Id:        uint64(user.ID),
+		Username:  user.Username,
+		CreatedAt: toTimestamp(user.CreatedAt),
+		UpdatedAt: toTimestamp(user.UpdatedAt),
+	}
+}
+
+func toTodoList(todoList models.TodoList) *todov1.TodoList {
+	return &todov1.TodoList{
+		Id:        uint64(todoList.ID),
+		Name:      todoList.Name,
+		UserId:    uint64(todoList.UserID),
+		Version:   todoList.Version,
+		CreatedAt: toTimestamp(todoList.CreatedAt),
+		UpdatedAt: toTimestamp(todoList.UpdatedAt),
+	}
+}
+
+func toTodoItem(todoItem models.TodoItem) *todov1.TodoItem {
+	item := &todov1.TodoItem{
+		Id:          uint64(todoItem.ID),
+		Title:       todoItem.Title,
+		Description: todoItem.Description,
+		Completed:   todoItem.Completed,
+		Recurrence:  todoItem.Recurrence,
+		TodoListId:  uint64(todoItem.TodoListID),
+		Tags:        make([]*todov1.Tag, len(todoItem.Tags)),
+		Version:     todoItem.Version,
+		CreatedAt:   toTimestamp(todoItem.CreatedAt),
+		UpdatedAt:   toTimestamp(todoItem.UpdatedAt),
+	}
+	if todoItem.Due != nil {
+		item.Due = timestamppb.New(*todoItem.Due)
+	}
+	for i, tag := range todoItem.Tags {
+		item.Tags[i] = toTag(tag)
+	}
+	return item
+}
+
+func toTodoItems(todoItems []models.TodoItem) *todov1.TodoItems {
+	result := &todov1.TodoItems{TodoItems: make([]*todov1.TodoItem, len(todoItems))}
+	for i, todoItem := range todoItems {
+		result.TodoItems[i] = toTodoItem(todoItem)
+	}
+	return result
+}
+
+func toTag(tag models.Tag) *todov1.Tag {
+	result := &todov1.Tag{
+		Id:        uint64(tag.ID),
+		Text:      tag.Text,
+		Color:     tag.Color,
+		UserId:    uint64(tag.UserID),
+		Version:   tag.Version,
+		CreatedAt: toTimestamp(tag.CreatedAt),
+		UpdatedAt: toTimestamp(tag.UpdatedAt),
+	}
+	if tag.ParentID != nil {
+		parentID := uint64(*tag.ParentID)
+		result.ParentId = &parentID
+	}
+	return result
+}
+
+func toBulkReport(report models.BulkReport) *todov1.BulkReport {
+	result := &todov1.BulkReport{
+		Committed: report.Committed,
+		Results:   make([]*todov1.BulkResult, len(report.Results)),
+	}
+	for i, r := range report.Results {
+		result.Results[i] = &todov1.BulkResult{Index: int32(r.Index), Id: uint64(r.ID), Ok: r.OK, Error: r.Error}
+	}
+	return result
+}
+
+func fromBulkRequest(request *todov1.BulkRequest) *models.BulkRequest {
+	result := &models.BulkRequest{
+		Operations:   make([]models.BulkOperation, len(request.GetOperations())),
+		AllOrNothing: request.GetAllOrNothing(),
+	}
+	for i, op := range request.GetOperations() {
+		result.Operations[i] = models.BulkOperation{
+			Op:          op.GetOp(),
+			ID:          uint(op.GetId()),
+			Title:       op.GetTitle(),
+			Description: op.GetDescription(),
+			Completed:   op.Completed,
+			ListID:      uint(op.GetListId()),
+			Tag:         op.GetTag(),
+			TagID:       uint(op.GetTagId()),
+		}
+	}
+	return result
+}
+
+// toTimestamp leaves zero times unset
+func toTimestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+// fromTimestamp returns nil for unset timestamps
+func fromTimestamp(ts *timestamppb.Timestamp) (*time.Time, error) {
+	if ts == nil {
+		return nil, nil
+	}
+	if err := ts.CheckValid(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	t := ts.AsTime()
+	return &t, nil
+}
+
+func fromParentID(parentID *uint64) *uint {
+	if parentID == nil {
+		return nil
+	}
+	id := uint(*parentID)
+	return &id
+}
+
+// fromPage applies the defaults and limits of the limit and offset query parameters of the REST API
+func fromPage(page *todov1.Page) (models.Page, error) {
+	result := models.Page{Limit: models.DefaultPageLimit, Offset: int(page.GetOffset())}
+	if limit := page.GetLimit(); limit != 0 {
+		if limit < 1 || limit > models.MaxPageLimit {
+			return result, status.Error(codes.InvalidArgument, fmt.Sprintf("limit must be between 1 and %d", models.MaxPageLimit))
+		}
+		result.Limit = int(limit)
+	}
+	if result.Offset < 0 {
+		return result, status.Error(codes.InvalidArgument, "offset must be a non-negative integer")
+	}
+	return result, nil
+}
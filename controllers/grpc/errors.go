@@ -0,0 +1,35 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+
+	"github.com/danikg/go-todo-rest-api/repositories"
+	"github.com/danikg/go-todo-rest-api/services"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errorStatus maps the validation errors of the services like the REST controllers do,
+// other errors get fallback
+func errorStatus(err error, fallback codes.Code) error {
+	code := fallback
+	switch {
+	case errors.Is(err, context.Canceled):
+		code = codes.Canceled
+	case errors.Is(err, context.DeadlineExceeded):
+		code = codes.DeadlineExceeded
+	case errors.Is(err, services.ErrEmptyTag),
+		errors.Is(err, services.ErrInvalidTagColor),
+		errors.Is(err, services.ErrInvalidBulkOperation),
+		errors.Is(err, services.ErrInvalidRecurrence),
+		errors.Is(err, repositories.ErrInvalidTagParent),
+		errors.Is(err, repositories.ErrInvalidTagMerge):
+		code = codes.InvalidArgument
+	case errors.Is(err, repositories.ErrDuplicateTag):
+		code = codes.AlreadyExists
+	case errors.Is(err, services.ErrForbidden):
+		code = codes.PermissionDenied
+	}
+	return status.Error(code, err.Error())
+}
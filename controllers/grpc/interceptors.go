@@ -0,0 +1,125 @@
+package grpc
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	todov1 "github.com/danikg/go-todo-rest-api/api/todo/v1"
+	"github.com/danikg/go-todo-rest-api/config"
+	"github.com/danikg/go-todo-rest-api/services"
+	"github.com/danikg/go-todo-rest-api/utils/auth"
+	"github.com/danikg/go-todo-rest-api/utils/logger"
+	"github.com/danikg/go-todo-rest-api/utils/requestid"
+	"github.com/danikg/go-todo-rest-api/utils/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// publicMethods can be called without a token when authentication is enabled
+var publicMethods = map[string]bool{
+	todov1.UserService_CreateUser_FullMethodName:   true,
+	todov1.UserService_Authenticate_FullMethodName: true,
+}
+
+// TracingInterceptor starts a server span per call
+func TracingInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, span := tracing.Start(ctx, info.FullMethod,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(attribute.String("rpc.system", "grpc"), attribute.String("rpc.method", info.FullMethod)))
+	defer span.End()
+
+	resp, err := handler(ctx, req)
+	code := status.Code(err)
+	span.SetAttributes(attribute.Int("rpc.grpc.status_code", int(code)))
+	if code == codes.Internal || code == codes.Unknown {
+		span.SetStatus(otelcodes.Error, code.String())
+	}
+	return resp, err
+}
+
+// LoggingInterceptor propagates the client x-request-id metadata or assigns a new id,
+// writes one log line per call and makes a call-scoped logger available through logger.FromContext
+func LoggingInterceptor(log *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		id := metadataValue(ctx, requestid.Header)
+		if !requestid.Valid(id) {
+			id = requestid.New()
+		}
+		grpc.SetHeader(ctx, metadata.Pairs(requestid.Header, id))
+
+		callLog := log.With("request_id", id)
+		if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+			callLog = callLog.With("trace_id", sc.TraceID().String())
+		}
+		ctx = logger.NewEntry(logger.NewContext(requestid.NewContext(ctx, id), callLog))
+
+		resp, err := handler(ctx, req)
+
+		callLog.Info("call",
+			"method", info.FullMethod,
+			"code", status.Code(err).String(),
+			"latency_ms", float64(time.Since(start).Microseconds())/1000,
+			"user", logger.User(ctx),
+		)
+		return resp, err
+	}
+}
+
+// AuthInterceptor identifies the user from the bearer token of the authorization metadata,
+// users checks that the token was not revoked.
+// Calls without a token are rejected on non public methods when authentication is enabled,
+// public methods ignore tokens that are not accepted
+func AuthInterceptor(cfg config.AuthConfig, users services.IUserService) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if cfg.Enabled {
+			ctx = auth.RequireUser(ctx)
+		}
+		token := bearerToken(ctx)
+		if token == "" || cfg.Secret == "" {
+			if cfg.Enabled && !publicMethods[info.FullMethod] {
+				return nil, status.Error(codes.Unauthenticated, "missing bearer token")
+			}
+			return handler(ctx, req)
+		}
+
+		userID, generation, err := auth.Verify(cfg.Secret, token, time.Now())
+		if err == nil {
+			err = users.CheckToken(ctx, userID, generation)
+		}
+		if err != nil && publicMethods[info.FullMethod] {
+			// signing in again must work with the revoked token still sent along
+			return handler(ctx, req)
+		}
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+
+		logger.SetUser(ctx, strconv.FormatUint(uint64(userID), 10))
+		return handler(auth.NewContext(ctx, userID), req)
+	}
+}
+
+func bearerToken(ctx context.Context) string {
+	value := metadataValue(ctx, "authorization")
+	if len(value) > 7 && strings.EqualFold(value[:7], "bearer ") {
+		return strings.TrimSpace(value[7:])
+	}
+	return ""
+}
+
+// metadataValue returns the first value of the incoming metadata key
+func metadataValue(ctx context.Context, key string) string {
+	if values := metadata.ValueFromIncomingContext(ctx, key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
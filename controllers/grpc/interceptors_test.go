@@ -0,0 +1,127 @@
+package grpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	todov1 "github.com/danikg/go-todo-rest-api/api/todo/v1"
+	"github.com/danikg/go-todo-rest-api/config"
+	"github.com/danikg/go-todo-rest-api/utils/auth"
+	"github.com/danikg/go-todo-rest-api/utils/requestid"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func TestAuthInterceptor(t *testing.T) {
+	cfg := config.AuthConfig{Enabled: true, Secret: testSecret, TokenTTL: time.Hour}
+	client := todov1.NewUserServiceClient(newTestConn(t, cfg, &bytes.Buffer{}))
+	valid := auth.Issue(testSecret, 1, 0, time.Now().Add(time.Hour))
+	expired := auth.Issue(testSecret, 1, 0, time.Now().Add(-time.Hour))
+	revoked := auth.Issue(testSecret, 1, 1, time.Now().Add(time.Hour))
+
+	tests := []struct {
+		title         string
+		authorization string
+		call          func(ctx context.Context) error
+		code          codes.Code
+	}{
+		{
+			title: "Reject call without token",
+			call: func(ctx context.Context) error {
+				_, err := client.ListUsers(ctx, &emptypb.Empty{})
+				return err
+			},
+			code: codes.Unauthenticated,
+		},
+		{
+			title:         "Accept call with valid token",
+			authorization: "Bearer " + valid,
+			call: func(ctx context.Context) error {
+				_, err := client.ListUsers(ctx, &emptypb.Empty{})
+				return err
+			},
+			code: codes.OK,
+		},
+		{
+			title:         "Reject call with expired token",
+			authorization: "Bearer " + expired,
+			call: func(ctx context.Context) error {
+				_, err := client.ListUsers(ctx, &emptypb.Empty{})
+				return err
+			},
+			code: codes.Unauthenticated,
+		},
+		{
+			title:         "Reject call with revoked token",
+			authorization: "Bearer " + revoked,
+			call: func(ctx context.Context) error {
+				_, err := client.ListUsers(ctx, &emptypb.Empty{})
+				return err
+			},
+			code: codes.Unauthenticated,
+		},
+		{
+			title: "Allow public method without token",
+			call: func(ctx context.Context) error {
+				_, err := client.CreateUser(ctx, &todov1.CreateUserRequest{Username: "user3", Password: "password"})
+				return err
+			},
+			code: codes.OK,
+		},
+		{
+			title: "Allow authentication without token",
+			call: func(ctx context.Context) error {
+				_, err := client.Authenticate(ctx, &todov1.AuthenticateRequest{Username: "user1", Password: "password"})
+				return err
+			},
+			code: codes.OK,
+		},
+		{
+			title:         "Allow authentication with revoked token",
+			authorization: "Bearer " + revoked,
+			call: func(ctx context.Context) error {
+				_, err := client.Authenticate(ctx, &todov1.AuthenticateRequest{Username: "user1", Password: "password"})
+				return err
+			},
+			code: codes.OK,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.title, func(t *testing.T) {
+			ctx := context.Background()
+			if tc.authorization != "" {
+				ctx = metadata.AppendToOutgoingContext(ctx, "authorization", tc.authorization)
+			}
+			assertCode(t, tc.code, tc.call(ctx))
+		})
+	}
+}
+
+func TestLoggingInterceptor(t *testing.T) {
+	buf := &bytes.Buffer{}
+	cfg := config.AuthConfig{Secret: testSecret, TokenTTL: time.Hour}
+	client := todov1.NewTodoListServiceClient(newTestConn(t, cfg, buf))
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(),
+		"authorization", "Bearer "+auth.Issue(testSecret, 1, 0, time.Now().Add(time.Hour)),
+		requestid.Header, "abc-123")
+	var header metadata.MD
+	_, err := client.GetTodoList(ctx, &todov1.IdRequest{Id: 2}, grpc.Header(&header))
+	assertCode(t, codes.NotFound, err)
+	assert.Equal(t, []string{"abc-123"}, header.Get(requestid.Header))
+
+	line := map[string]interface{}{}
+	assert.NoError(t, json.NewDecoder(buf).Decode(&line))
+	assert.Equal(t, "call", line["msg"])
+	assert.Equal(t, todov1.TodoListService_GetTodoList_FullMethodName, line["method"])
+	assert.Equal(t, "NotFound", line["code"])
+	assert.Equal(t, "abc-123", line["request_id"])
+	assert.Equal(t, "1", line["user"])
+}
@@ -0,0 +1,33 @@
+package grpc
+
+import (
+	"log/slog"
+
+	todov1 "github.com/danikg/go-todo-rest-api/api/todo/v1"
+	"github.com/danikg/go-todo-rest-api/config"
+	"github.com/danikg/go-todo-rest-api/services"
+	"google.golang.org/grpc"
+)
+
+// NewServer returns a gRPC server exposing the user, todo list, todo item and tag services
+// behind the tracing, logging and authentication interceptors
+func NewServer(
+	log *slog.Logger,
+	cfg config.AuthConfig,
+	userService services.IUserService,
+	todoListService services.ITodoListService,
+	todoItemService services.ITodoItemService,
+	tagService services.ITagService,
+) *grpc.Server {
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		TracingInterceptor,
+		LoggingInterceptor(log),
+		AuthInterceptor(cfg, userService),
+	))
+
+	todov1.RegisterUserServiceServer(server, NewUserServer(userService, cfg.Secret, cfg.TokenTTL))
+	todov1.RegisterTodoListServiceServer(server, NewTodoListServer(todoListService))
+	todov1.RegisterTodoItemServiceServer(server, NewTodoItemServer(todoItemService))
+	todov1.RegisterTagServiceServer(server, NewTagServer(tagService))
+	return server
+}
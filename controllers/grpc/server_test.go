@@ -0,0 +1,41 @@
+package grpc
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"testing"
+
+	"github.com/danikg/go-todo-rest-api/config"
+	"github.com/danikg/go-todo-rest-api/services/mocks"
+	"github.com/danikg/go-todo-rest-api/utils/logger"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newTestConn serves the mocked services on an in-memory listener and returns a client connection to it
+func newTestConn(t *testing.T, cfg config.AuthConfig, buf *bytes.Buffer) *grpc.ClientConn {
+	listener := bufconn.Listen(1 << 20)
+	server := NewServer(logger.New(buf), cfg,
+		&mocks.UserServiceMock{}, &mocks.TodoListServiceMock{}, &mocks.TodoItemServiceMock{}, &mocks.TagServiceMock{})
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// assertCode checks the status code of a call, codes.OK expects no error
+func assertCode(t *testing.T, code codes.Code, err error) {
+	assert.Equal(t, code, status.Code(err), "%v", err)
+}
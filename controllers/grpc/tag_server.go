@@ -0,0 +1,148 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+
+	todov1 "github.com/danikg/go-todo-rest-api/api/todo/v1"
+	"github.com/danikg/go-todo-rest-api/models"
+	"github.com/danikg/go-todo-rest-api/services"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// TagServer ...
+type TagServer struct {
+	todov1.UnimplementedTagServiceServer
+	TagService services.ITagService
+}
+
+// NewTagServer ...
+func NewTagServer(tagService services.ITagService) *TagServer {
+	return &TagServer{TagService: tagService}
+}
+
+// ListTags returns all tags by todo item id
+func (s *TagServer) ListTags(ctx context.Context, request *todov1.ListTagsRequest) (*todov1.Tags, error) {
+	tags, err := s.TagService.GetAll(ctx, uint(request.GetItemId()))
+	if err != nil {
+		return nil, errorStatus(err, codes.NotFound)
+	}
+
+	result := &todov1.Tags{Tags: make([]*todov1.Tag, len(tags))}
+	for i, tag := range tags {
+		result.Tags[i] = toTag(tag)
+	}
+	return result, nil
+}
+
+// ListUserTags returns the tag vocabulary of the user with usage counts
+func (s *TagServer) ListUserTags(ctx context.Context, request *todov1.ListUserTagsRequest) (*todov1.TagUsages, error) {
+	usages, err := s.TagService.GetAllByUser(ctx, uint(request.GetUserId()))
+	if err != nil {
+		return nil, errorStatus(err, codes.NotFound)
+	}
+
+	result := &todov1.TagUsages{Tags: make([]*todov1.TagUsage, len(usages))}
+	for i, usage := range usages {
+		result.Tags[i] = &todov1.TagUsage{Tag: toTag(usage.Tag), UsageCount: usage.UsageCount}
+	}
+	return result, nil
+}
+
+// GetTag returns a single tag by id
+func (s *TagServer) GetTag(ctx context.Context, request *todov1.IdRequest) (*todov1.Tag, error) {
+	tag, err := s.TagService.GetSingle(ctx, uint(request.GetId()))
+	if err != nil {
+		return nil, errorStatus(err, codes.NotFound)
+	}
+	return toTag(tag), nil
+}
+
+// ListTaggedTodoItems returns the todo items the tag is attached to
+func (s *TagServer) ListTaggedTodoItems(ctx context.Context, request *todov1.ListTaggedTodoItemsRequest) (*todov1.TodoItems, error) {
+	page, err := fromPage(request.GetPage())
+	if err != nil {
+		return nil, err
+	}
+
+	todoItems, err := s.TagService.GetTodoItems(ctx, uint(request.GetTagId()), page)
+	if err != nil {
+		return nil, errorStatus(err, codes.NotFound)
+	}
+	return toTodoItems(todoItems), nil
+}
+
+// ListUserTaggedTodoItems returns the todo items across all lists of the user filtered by tags
+func (s *TagServer) ListUserTaggedTodoItems(ctx context.Context, request *todov1.ListUserTaggedTodoItemsRequest) (*todov1.TodoItems, error) {
+	page, err := fromPage(request.GetPage())
+	if err != nil {
+		return nil, err
+	}
+
+	filter := models.TagFilter{MatchAll: request.GetMatchAll()}
+	for _, tag := range request.GetTags() {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			filter.Tags = append(filter.Tags, tag)
+		}
+	}
+
+	todoItems, err := s.TagService.GetTodoItemsByUser(ctx, uint(request.GetUserId()), filter, page)
+	if err != nil {
+		return nil, errorStatus(err, codes.NotFound)
+	}
+	return toTodoItems(todoItems), nil
+}
+
+// CreateTag attaches a tag to the todo item
+func (s *TagServer) CreateTag(ctx context.Context, request *todov1.CreateTagRequest) (*todov1.Tag, error) {
+	tag := models.Tag{Text: request.GetText(), Color: request.GetColor(), ParentID: fromParentID(request.ParentId)}
+	if err := s.TagService.Create(ctx, uint(request.GetItemId()), &tag); err != nil {
+		return nil, errorStatus(err, codes.Internal)
+	}
+	return toTag(tag), nil
+}
+
+// CreateUserTag adds a tag to the user's vocabulary
+func (s *TagServer) CreateUserTag(ctx context.Context, request *todov1.CreateUserTagRequest) (*todov1.Tag, error) {
+	tag := models.Tag{Text: request.GetText(), Color: request.GetColor(), ParentID: fromParentID(request.ParentId)}
+	if err := s.TagService.CreateForUser(ctx, uint(request.GetUserId()), &tag); err != nil {
+		return nil, errorStatus(err, codes.NotFound)
+	}
+	return toTag(tag), nil
+}
+
+// UpdateTag updates the tag by id
+func (s *TagServer) UpdateTag(ctx context.Context, request *todov1.UpdateTagRequest) (*todov1.Tag, error) {
+	tagData := models.Tag{Text: request.GetText(), Color: request.GetColor(), ParentID: fromParentID(request.ParentId)}
+	tag, err := s.TagService.Update(ctx, uint(request.GetId()), &tagData)
+	if err != nil {
+		return nil, errorStatus(err, codes.NotFound)
+	}
+	return toTag(tag), nil
+}
+
+// MergeTags moves the todo items and child tags of the tag to the target tag and deletes the tag
+func (s *TagServer) MergeTags(ctx context.Context, request *todov1.MergeTagsRequest) (*todov1.Tag, error) {
+	tag, err := s.TagService.Merge(ctx, uint(request.GetId()), uint(request.GetTargetId()))
+	if err != nil {
+		return nil, errorStatus(err, codes.NotFound)
+	}
+	return toTag(tag), nil
+}
+
+// RemoveTag removes the tag from the todo item
+func (s *TagServer) RemoveTag(ctx context.Context, request *todov1.RemoveTagRequest) (*emptypb.Empty, error) {
+	if err := s.TagService.Remove(ctx, uint(request.GetItemId()), uint(request.GetTagId())); err != nil {
+		return nil, errorStatus(err, codes.NotFound)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// DeleteTag removes the tag from the db
+func (s *TagServer) DeleteTag(ctx context.Context, request *todov1.IdRequest) (*emptypb.Empty, error) {
+	if err := s.TagService.Delete(ctx, uint(request.GetId())); err != nil {
+		return nil, errorStatus(err, codes.NotFound)
+	}
+	return &emptypb.Empty{}, nil
+}
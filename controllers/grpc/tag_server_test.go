@@ -0,0 +1,133 @@
+package grpc
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	todov1 "github.com/danikg/go-todo-rest-api/api/todo/v1"
+	"github.com/danikg/go-todo-rest-api/config"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+)
+
+func TestTagServer(t *testing.T) {
+	client := todov1.NewTagServiceClient(newTestConn(t, config.AuthConfig{}, &bytes.Buffer{}))
+	ctx := context.Background()
+
+	tags, err := client.ListTags(ctx, &todov1.ListTagsRequest{ItemId: 1})
+	assertCode(t, codes.OK, err)
+	assert.Len(t, tags.GetTags(), 2)
+
+	usages, err := client.ListUserTags(ctx, &todov1.ListUserTagsRequest{UserId: 1})
+	assertCode(t, codes.OK, err)
+	if assert.Len(t, usages.GetTags(), 1) {
+		assert.Equal(t, "tag1", usages.GetTags()[0].GetTag().GetText())
+		assert.Equal(t, int64(2), usages.GetTags()[0].GetUsageCount())
+	}
+
+	todoItems, err := client.ListTaggedTodoItems(ctx, &todov1.ListTaggedTodoItemsRequest{TagId: 1})
+	assertCode(t, codes.OK, err)
+	assert.Len(t, todoItems.GetTodoItems(), 2)
+	_, err = client.ListTaggedTodoItems(ctx, &todov1.ListTaggedTodoItemsRequest{TagId: 1, Page: &todov1.Page{Limit: 1000}})
+	assertCode(t, codes.InvalidArgument, err)
+	_, err = client.ListUserTaggedTodoItems(ctx, &todov1.ListUserTaggedTodoItemsRequest{UserId: 1, Tags: []string{"tag1"}, MatchAll: true})
+	assertCode(t, codes.OK, err)
+	_, err = client.ListUserTaggedTodoItems(ctx, &todov1.ListUserTaggedTodoItemsRequest{UserId: 1, Page: &todov1.Page{Offset: -1}})
+	assertCode(t, codes.InvalidArgument, err)
+
+	tests := []struct {
+		title string
+		call  func() (*todov1.Tag, error)
+		code  codes.Code
+		id    uint64
+	}{
+		{
+			title: "Get tag",
+			call:  func() (*todov1.Tag, error) { return client.GetTag(ctx, &todov1.IdRequest{Id: 1}) },
+			code:  codes.OK,
+			id:    1,
+		},
+		{
+			title: "Get tag not found",
+			call:  func() (*todov1.Tag, error) { return client.GetTag(ctx, &todov1.IdRequest{Id: 2}) },
+			code:  codes.NotFound,
+		},
+		{
+			title: "Create tag",
+			call: func() (*todov1.Tag, error) {
+				return client.CreateTag(ctx, &todov1.CreateTagRequest{ItemId: 1, Text: "tag3"})
+			},
+			code: codes.OK,
+		},
+		{
+			title: "Create user tag",
+			call: func() (*todov1.Tag, error) {
+				return client.CreateUserTag(ctx, &todov1.CreateUserTagRequest{UserId: 1, Text: "tag3"})
+			},
+			code: codes.OK,
+			id:   1,
+		},
+		{
+			title: "Create empty user tag",
+			call: func() (*todov1.Tag, error) {
+				return client.CreateUserTag(ctx, &todov1.CreateUserTagRequest{UserId: 1, Text: " "})
+			},
+			code: codes.InvalidArgument,
+		},
+		{
+			title: "Update tag",
+			call: func() (*todov1.Tag, error) {
+				return client.UpdateTag(ctx, &todov1.UpdateTagRequest{Id: 1, Text: "tag1"})
+			},
+			code: codes.OK,
+			id:   1,
+		},
+		{
+			title: "Update tag to a duplicate text",
+			call: func() (*todov1.Tag, error) {
+				return client.UpdateTag(ctx, &todov1.UpdateTagRequest{Id: 1, Text: "tag2"})
+			},
+			code: codes.AlreadyExists,
+		},
+		{
+			title: "Update tag with an invalid color",
+			call: func() (*todov1.Tag, error) {
+				return client.UpdateTag(ctx, &todov1.UpdateTagRequest{Id: 1, Text: "tag1", Color: "red"})
+			},
+			code: codes.InvalidArgument,
+		},
+		{
+			title: "Merge tags",
+			call: func() (*todov1.Tag, error) {
+				return client.MergeTags(ctx, &todov1.MergeTagsRequest{Id: 1, TargetId: 2})
+			},
+			code: codes.OK,
+			id:   2,
+		},
+		{
+			title: "Merge tag into itself",
+			call: func() (*todov1.Tag, error) {
+				return client.MergeTags(ctx, &todov1.MergeTagsRequest{Id: 1, TargetId: 1})
+			},
+			code: codes.InvalidArgument,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.title, func(t *testing.T) {
+			tag, err := tc.call()
+			assertCode(t, tc.code, err)
+			if tc.code == codes.OK {
+				assert.Equal(t, tc.id, tag.GetId())
+			}
+		})
+	}
+
+	_, err = client.RemoveTag(ctx, &todov1.RemoveTagRequest{ItemId: 1, TagId: 1})
+	assertCode(t, codes.OK, err)
+	_, err = client.RemoveTag(ctx, &todov1.RemoveTagRequest{ItemId: 2, TagId: 1})
+	assertCode(t, codes.NotFound, err)
+	_, err = client.DeleteTag(ctx, &todov1.IdRequest{Id: 1})
+	assertCode(t, codes.OK, err)
+}
@@ -0,0 +1,99 @@
+package grpc
+
+import (
+	"context"
+
+	todov1 "github.com/danikg/go-todo-rest-api/api/todo/v1"
+	"github.com/danikg/go-todo-rest-api/models"
+	"github.com/danikg/go-todo-rest-api/services"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// TodoItemServer ...
+type TodoItemServer struct {
+	todov1.UnimplementedTodoItemServiceServer
+	TodoItemService services.ITodoItemService
+}
+
+// NewTodoItemServer ...
+func NewTodoItemServer(todoItemService services.ITodoItemService) *TodoItemServer {
+	return &TodoItemServer{TodoItemService: todoItemService}
+}
+
+// ListTodoItems returns all todo items by todo list id
+func (s *TodoItemServer) ListTodoItems(ctx context.Context, request *todov1.ListTodoItemsRequest) (*todov1.TodoItems, error) {
+	todoItems, err := s.TodoItemService.GetAll(ctx, uint(request.GetListId()))
+	if err != nil {
+		return nil, errorStatus(err, codes.NotFound)
+	}
+	return toTodoItems(todoItems), nil
+}
+
+// GetTodoItem returns a single todo item by id
+func (s *TodoItemServer) GetTodoItem(ctx context.Context, request *todov1.IdRequest) (*todov1.TodoItem, error) {
+	todoItem, err := s.TodoItemService.GetSingle(ctx, uint(request.GetId()))
+	if err != nil {
+		return nil, errorStatus(err, codes.NotFound)
+	}
+	return toTodoItem(todoItem), nil
+}
+
+// CreateTodoItem creates a new todo item in the todo list
+func (s *TodoItemServer) CreateTodoItem(ctx context.Context, request *todov1.CreateTodoItemRequest) (*todov1.TodoItem, error) {
+	due, err := fromTimestamp(request.GetDue())
+	if err != nil {
+		return nil, err
+	}
+
+	todoItem := models.TodoItem{
+		Title:       request.GetTitle(),
+		Description: request.GetDescription(),
+		Completed:   request.GetCompleted(),
+		Due:         due,
+		Recurrence:  request.GetRecurrence(),
+	}
+	if err = s.TodoItemService.Create(ctx, uint(request.GetListId()), &todoItem); err != nil {
+		return nil, errorStatus(err, codes.Internal)
+	}
+	return toTodoItem(todoItem), nil
+}
+
+// UpdateTodoItem updates the todo item by id
+func (s *TodoItemServer) UpdateTodoItem(ctx context.Context, request *todov1.UpdateTodoItemRequest) (*todov1.TodoItem, error) {
+	due, err := fromTimestamp(request.GetDue())
+	if err != nil {
+		return nil, err
+	}
+
+	todoItemData := models.TodoItemUpdate{
+		Title:       request.GetTitle(),
+		Description: request.GetDescription(),
+		Completed:   request.Completed,
+		Due:         due,
+		Recurrence:  request.GetRecurrence(),
+	}
+	todoItem, err := s.TodoItemService.Update(ctx, uint(request.GetId()), &todoItemData)
+	if err != nil {
+		return nil, errorStatus(err, codes.NotFound)
+	}
+	return toTodoItem(todoItem), nil
+}
+
+// DeleteTodoItem removes the todo item by id
+func (s *TodoItemServer) DeleteTodoItem(ctx context.Context, request *todov1.IdRequest) (*emptypb.Empty, error) {
+	if err := s.TodoItemService.Delete(ctx, uint(request.GetId())); err != nil {
+		return nil, errorStatus(err, codes.NotFound)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// BulkTodoItems applies a list of operations on todo items in one transaction,
+// a rolled back all-or-nothing request is reported with committed false
+func (s *TodoItemServer) BulkTodoItems(ctx context.Context, request *todov1.BulkRequest) (*todov1.BulkReport, error) {
+	report, err := s.TodoItemService.Bulk(ctx, fromBulkRequest(request))
+	if err != nil {
+		return nil, errorStatus(err, codes.Internal)
+	}
+	return toBulkReport(report), nil
+}
@@ -0,0 +1,140 @@
+package grpc
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	todov1 "github.com/danikg/go-todo-rest-api/api/todo/v1"
+	"github.com/danikg/go-todo-rest-api/config"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestTodoItemServer(t *testing.T) {
+	client := todov1.NewTodoItemServiceClient(newTestConn(t, config.AuthConfig{}, &bytes.Buffer{}))
+	ctx := context.Background()
+	due := time.Date(2024, 5, 6, 9, 0, 0, 0, time.UTC)
+
+	todoItems, err := client.ListTodoItems(ctx, &todov1.ListTodoItemsRequest{ListId: 1})
+	assertCode(t, codes.OK, err)
+	if assert.Len(t, todoItems.GetTodoItems(), 2) {
+		assert.Equal(t, "desc2", todoItems.GetTodoItems()[1].GetDescription())
+	}
+	_, err = client.ListTodoItems(ctx, &todov1.ListTodoItemsRequest{ListId: 2})
+	assertCode(t, codes.NotFound, err)
+
+	tests := []struct {
+		title     string
+		call      func() (*todov1.TodoItem, error)
+		code      codes.Code
+		todoTitle string
+	}{
+		{
+			title:     "Get todo item",
+			call:      func() (*todov1.TodoItem, error) { return client.GetTodoItem(ctx, &todov1.IdRequest{Id: 1}) },
+			code:      codes.OK,
+			todoTitle: "item1",
+		},
+		{
+			title: "Get todo item not found",
+			call:  func() (*todov1.TodoItem, error) { return client.GetTodoItem(ctx, &todov1.IdRequest{Id: 2}) },
+			code:  codes.NotFound,
+		},
+		{
+			title: "Create todo item",
+			call: func() (*todov1.TodoItem, error) {
+				return client.CreateTodoItem(ctx, &todov1.CreateTodoItemRequest{
+					ListId: 1, Title: "item3", Due: timestamppb.New(due), Recurrence: "FREQ=WEEKLY",
+				})
+			},
+			code:      codes.OK,
+			todoTitle: "item3",
+		},
+		{
+			title: "Create recurring todo item without due date",
+			call: func() (*todov1.TodoItem, error) {
+				return client.CreateTodoItem(ctx, &todov1.CreateTodoItemRequest{ListId: 1, Title: "item3", Recurrence: "FREQ=WEEKLY"})
+			},
+			code: codes.InvalidArgument,
+		},
+		{
+			title: "Create todo item with an invalid due date",
+			call: func() (*todov1.TodoItem, error) {
+				return client.CreateTodoItem(ctx, &todov1.CreateTodoItemRequest{ListId: 1, Title: "item3", Due: &timestamppb.Timestamp{Nanos: -1}})
+			},
+			code: codes.InvalidArgument,
+		},
+		{
+			title: "Create todo item in an unknown list",
+			call: func() (*todov1.TodoItem, error) {
+				return client.CreateTodoItem(ctx, &todov1.CreateTodoItemRequest{ListId: 2, Title: "item3"})
+			},
+			code: codes.Internal,
+		},
+		{
+			title: "Update todo item",
+			call: func() (*todov1.TodoItem, error) {
+				return client.UpdateTodoItem(ctx, &todov1.UpdateTodoItemRequest{Id: 1, Completed: proto.Bool(true)})
+			},
+			code:      codes.OK,
+			todoTitle: "item1",
+		},
+		{
+			title: "Update todo item with an invalid recurrence",
+			call: func() (*todov1.TodoItem, error) {
+				return client.UpdateTodoItem(ctx, &todov1.UpdateTodoItemRequest{Id: 1, Recurrence: "FREQ=SOMETIMES"})
+			},
+			code: codes.InvalidArgument,
+		},
+		{
+			title: "Update todo item not found",
+			call: func() (*todov1.TodoItem, error) {
+				return client.UpdateTodoItem(ctx, &todov1.UpdateTodoItemRequest{Id: 2})
+			},
+			code: codes.NotFound,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.title, func(t *testing.T) {
+			todoItem, err := tc.call()
+			assertCode(t, tc.code, err)
+			if tc.code == codes.OK {
+				assert.Equal(t, tc.todoTitle, todoItem.GetTitle())
+			}
+		})
+	}
+
+	_, err = client.DeleteTodoItem(ctx, &todov1.IdRequest{Id: 1})
+	assertCode(t, codes.OK, err)
+	_, err = client.DeleteTodoItem(ctx, &todov1.IdRequest{Id: 2})
+	assertCode(t, codes.NotFound, err)
+}
+
+func TestTodoItemServer_Bulk(t *testing.T) {
+	client := todov1.NewTodoItemServiceClient(newTestConn(t, config.AuthConfig{}, &bytes.Buffer{}))
+	ctx := context.Background()
+	completed := true
+
+	report, err := client.BulkTodoItems(ctx, &todov1.BulkRequest{
+		Operations: []*todov1.BulkOperation{
+			{Op: "complete", Id: 1, Completed: &completed},
+			{Op: "delete", Id: 2},
+		},
+		AllOrNothing: true,
+	})
+	assertCode(t, codes.OK, err)
+	assert.False(t, report.GetCommitted())
+	if assert.Len(t, report.GetResults(), 2) {
+		assert.True(t, report.GetResults()[0].GetOk())
+		assert.Equal(t, int32(1), report.GetResults()[1].GetIndex())
+		assert.Equal(t, "not found", report.GetResults()[1].GetError())
+	}
+
+	_, err = client.BulkTodoItems(ctx, &todov1.BulkRequest{})
+	assertCode(t, codes.InvalidArgument, err)
+}
@@ -0,0 +1,72 @@
+package grpc
+
+import (
+	"context"
+
+	todov1 "github.com/danikg/go-todo-rest-api/api/todo/v1"
+	"github.com/danikg/go-todo-rest-api/models"
+	"github.com/danikg/go-todo-rest-api/services"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// TodoListServer ...
+type TodoListServer struct {
+	todov1.UnimplementedTodoListServiceServer
+	TodoListService services.ITodoListService
+}
+
+// NewTodoListServer ...
+func NewTodoListServer(todoListService services.ITodoListService) *TodoListServer {
+	return &TodoListServer{TodoListService: todoListService}
+}
+
+// ListTodoLists returns all todo lists by user id
+func (s *TodoListServer) ListTodoLists(ctx context.Context, request *todov1.ListTodoListsRequest) (*todov1.TodoLists, error) {
+	todoLists, err := s.TodoListService.GetAll(ctx, uint(request.GetUserId()))
+	if err != nil {
+		return nil, errorStatus(err, codes.NotFound)
+	}
+
+	result := &todov1.TodoLists{TodoLists: make([]*todov1.TodoList, len(todoLists))}
+	for i, todoList := range todoLists {
+		result.TodoLists[i] = toTodoList(todoList)
+	}
+	return result, nil
+}
+
+// GetTodoList returns a single todo list by id
+func (s *TodoListServer) GetTodoList(ctx context.Context, request *todov1.IdRequest) (*todov1.TodoList, error) {
+	todoList, err := s.TodoListService.GetSingle(ctx, uint(request.GetId()))
+	if err != nil {
+		return nil, errorStatus(err, codes.NotFound)
+	}
+	return toTodoList(todoList), nil
+}
+
+// CreateTodoList creates a new todo list of the user
+func (s *TodoListServer) CreateTodoList(ctx context.Context, request *todov1.CreateTodoListRequest) (*todov1.TodoList, error) {
+	todoList := models.TodoList{Name: request.GetName()}
+	if err := s.TodoListService.Create(ctx, uint(request.GetUserId()), &todoList); err != nil {
+		return nil, errorStatus(err, codes.Internal)
+	}
+	return toTodoList(todoList), nil
+}
+
+// UpdateTodoList updates the todo list by id
+func (s *TodoListServer) UpdateTodoList(ctx context.Context, request *todov1.UpdateTodoListRequest) (*todov1.TodoList, error) {
+	todoListData := models.TodoList{Name: request.GetName()}
+	todoList, err := s.TodoListService.Update(ctx, uint(request.GetId()), &todoListData)
+	if err != nil {
+		return nil, errorStatus(err, codes.NotFound)
+	}
+	return toTodoList(todoList), nil
+}
+
+// DeleteTodoList removes the todo list by id
+func (s *TodoListServer) DeleteTodoList(ctx context.Context, request *todov1.IdRequest) (*emptypb.Empty, error) {
+	if err := s.TodoListService.Delete(ctx, uint(request.GetId())); err != nil {
+		return nil, errorStatus(err, codes.NotFound)
+	}
+	return &emptypb.Empty{}, nil
+}
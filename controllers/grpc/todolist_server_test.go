@@ -0,0 +1,90 @@
+package grpc
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	todov1 "github.com/danikg/go-todo-rest-api/api/todo/v1"
+	"github.com/danikg/go-todo-rest-api/config"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+)
+
+func TestTodoListServer(t *testing.T) {
+	client := todov1.NewTodoListServiceClient(newTestConn(t, config.AuthConfig{}, &bytes.Buffer{}))
+	ctx := context.Background()
+
+	todoLists, err := client.ListTodoLists(ctx, &todov1.ListTodoListsRequest{UserId: 1})
+	assertCode(t, codes.OK, err)
+	if assert.Len(t, todoLists.GetTodoLists(), 2) {
+		assert.Equal(t, "list2", todoLists.GetTodoLists()[1].GetName())
+		assert.Equal(t, uint64(1), todoLists.GetTodoLists()[1].GetUserId())
+	}
+	_, err = client.ListTodoLists(ctx, &todov1.ListTodoListsRequest{UserId: 2})
+	assertCode(t, codes.NotFound, err)
+
+	tests := []struct {
+		title string
+		call  func() (*todov1.TodoList, error)
+		code  codes.Code
+		name  string
+	}{
+		{
+			title: "Get todo list",
+			call:  func() (*todov1.TodoList, error) { return client.GetTodoList(ctx, &todov1.IdRequest{Id: 1}) },
+			code:  codes.OK,
+			name:  "list1",
+		},
+		{
+			title: "Get todo list not found",
+			call:  func() (*todov1.TodoList, error) { return client.GetTodoList(ctx, &todov1.IdRequest{Id: 2}) },
+			code:  codes.NotFound,
+		},
+		{
+			title: "Create todo list",
+			call: func() (*todov1.TodoList, error) {
+				return client.CreateTodoList(ctx, &todov1.CreateTodoListRequest{UserId: 1, Name: "list3"})
+			},
+			code: codes.OK,
+			name: "list3",
+		},
+		{
+			title: "Create todo list of an unknown user",
+			call: func() (*todov1.TodoList, error) {
+				return client.CreateTodoList(ctx, &todov1.CreateTodoListRequest{UserId: 2, Name: "list3"})
+			},
+			code: codes.Internal,
+		},
+		{
+			title: "Update todo list",
+			call: func() (*todov1.TodoList, error) {
+				return client.UpdateTodoList(ctx, &todov1.UpdateTodoListRequest{Id: 1, Name: "list1"})
+			},
+			code: codes.OK,
+			name: "list1",
+		},
+		{
+			title: "Update todo list not found",
+			call: func() (*todov1.TodoList, error) {
+				return client.UpdateTodoList(ctx, &todov1.UpdateTodoListRequest{Id: 2, Name: "list1"})
+			},
+			code: codes.NotFound,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.title, func(t *testing.T) {
+			todoList, err := tc.call()
+			assertCode(t, tc.code, err)
+			if tc.code == codes.OK {
+				assert.Equal(t, tc.name, todoList.GetName())
+			}
+		})
+	}
+
+	_, err = client.DeleteTodoList(ctx, &todov1.IdRequest{Id: 1})
+	assertCode(t, codes.OK, err)
+	_, err = client.DeleteTodoList(ctx, &todov1.IdRequest{Id: 2})
+	assertCode(t, codes.NotFound, err)
+}
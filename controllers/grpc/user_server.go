@@ -0,0 +1,101 @@
+package grpc
+
+import (
+	"context"
+	"time"
+
+	todov1 "github.com/danikg/go-todo-rest-api/api/todo/v1"
+	"github.com/danikg/go-todo-rest-api/models"
+	"github.com/danikg/go-todo-rest-api/services"
+	"github.com/danikg/go-todo-rest-api/utils/auth"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// UserServer ...
+type UserServer struct {
+	todov1.UnimplementedUserServiceServer
+	UserService services.IUserService
+	Secret      string
+	TokenTTL    time.Duration
+}
+
+// NewUserServer ...
+func NewUserServer(userService services.IUserService, secret string, tokenTTL time.Duration) *UserServer {
+	return &UserServer{
+		UserService: userService,
+		Secret:      secret,
+		TokenTTL:    tokenTTL,
+	}
+}
+
+// ListUsers returns all users
+func (s *UserServer) ListUsers(ctx context.Context, _ *emptypb.Empty) (*todov1.Users, error) {
+	users, err := s.UserService.GetAll(ctx)
+	if err != nil {
+		return nil, errorStatus(err, codes.Internal)
+	}
+
+	result := &todov1.Users{Users: make([]*todov1.User, len(users))}
+	for i, user := range users {
+		result.Users[i] = toUser(user)
+	}
+	return result, nil
+}
+
+// GetUser returns a single user by id
+func (s *UserServer) GetUser(ctx context.Context, request *todov1.IdRequest) (*todov1.User, error) {
+	user, err := s.UserService.GetSingle(ctx, uint(request.GetId()))
+	if err != nil {
+		return nil, errorStatus(err, codes.NotFound)
+	}
+	return toUser(user), nil
+}
+
+// CreateUser creates a new user
+func (s *UserServer) CreateUser(ctx context.Context, request *todov1.CreateUserRequest) (*todov1.User, error) {
+	user := models.User{Username: request.GetUsername(), Password: request.GetPassword()}
+	if err := s.UserService.Create(ctx, &user); err != nil {
+		return nil, errorStatus(err, codes.Internal)
+	}
+	return toUser(user), nil
+}
+
+// UpdateUser updates the user by id
+func (s *UserServer) UpdateUser(ctx context.Context, request *todov1.UpdateUserRequest) (*todov1.User, error) {
+	userData := models.User{Username: request.GetUsername(), Password: request.GetPassword()}
+	user, err := s.UserService.Update(ctx, uint(request.GetId()), &userData)
+	if err != nil {
+		return nil, errorStatus(err, codes.NotFound)
+	}
+	return toUser(user), nil
+}
+
+// DeleteUser removes the user by id
+func (s *UserServer) DeleteUser(ctx context.Context, request *todov1.IdRequest) (*emptypb.Empty, error) {
+	if err := s.UserService.Delete(ctx, uint(request.GetId())); err != nil {
+		return nil, errorStatus(err, codes.NotFound)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// Authenticate issues an access token for valid credentials
+func (s *UserServer) Authenticate(ctx context.Context, request *todov1.AuthenticateRequest) (*todov1.AuthenticateResponse, error) {
+	if s.Secret == "" {
+		return nil, status.Error(codes.Unimplemented, "authentication is not configured")
+	}
+
+	user, err := s.UserService.Authenticate(ctx, request.GetUsername(), request.GetPassword())
+	if err != nil {
+		return nil, errorStatus(err, codes.Unauthenticated)
+	}
+
+	expiresAt := time.Now().Add(s.TokenTTL).UTC().Truncate(time.Second)
+	return &todov1.AuthenticateResponse{
+		User:        toUser(user),
+		AccessToken: auth.Issue(s.Secret, user.ID, user.TokenGeneration, expiresAt),
+		ExpiresAt:   timestamppb.New(expiresAt),
+	}, nil
+}
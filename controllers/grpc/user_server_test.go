@@ -0,0 +1,105 @@
+package grpc
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	todov1 "github.com/danikg/go-todo-rest-api/api/todo/v1"
+	"github.com/danikg/go-todo-rest-api/config"
+	"github.com/danikg/go-todo-rest-api/utils/auth"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+func TestUserServer(t *testing.T) {
+	client := todov1.NewUserServiceClient(newTestConn(t, config.AuthConfig{}, &bytes.Buffer{}))
+	ctx := context.Background()
+
+	users, err := client.ListUsers(ctx, &emptypb.Empty{})
+	assertCode(t, codes.OK, err)
+	assert.Len(t, users.GetUsers(), 2)
+	assert.Equal(t, "user2", users.GetUsers()[1].GetUsername())
+
+	tests := []struct {
+		title string
+		call  func() (*todov1.User, error)
+		code  codes.Code
+		id    uint64
+	}{
+		{
+			title: "Get user",
+			call:  func() (*todov1.User, error) { return client.GetUser(ctx, &todov1.IdRequest{Id: 1}) },
+			code:  codes.OK,
+			id:    1,
+		},
+		{
+			title: "Get user not found",
+			call:  func() (*todov1.User, error) { return client.GetUser(ctx, &todov1.IdRequest{Id: 2}) },
+			code:  codes.NotFound,
+		},
+		{
+			title: "Create user",
+			call: func() (*todov1.User, error) {
+				return client.CreateUser(ctx, &todov1.CreateUserRequest{Username: "user3", Password: "password"})
+			},
+			code: codes.OK,
+		},
+		{
+			title: "Update user",
+			call: func() (*todov1.User, error) {
+				return client.UpdateUser(ctx, &todov1.UpdateUserRequest{Id: 1, Username: "user1"})
+			},
+			code: codes.OK,
+			id:   1,
+		},
+		{
+			title: "Update user not found",
+			call: func() (*todov1.User, error) {
+				return client.UpdateUser(ctx, &todov1.UpdateUserRequest{Id: 2, Username: "user2"})
+			},
+			code: codes.NotFound,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.title, func(t *testing.T) {
+			user, err := tc.call()
+			assertCode(t, tc.code, err)
+			if tc.code == codes.OK {
+				assert.Equal(t, tc.id, user.GetId())
+			}
+		})
+	}
+
+	_, err = client.DeleteUser(ctx, &todov1.IdRequest{Id: 1})
+	assertCode(t, codes.OK, err)
+	_, err = client.DeleteUser(ctx, &todov1.IdRequest{Id: 2})
+	assertCode(t, codes.NotFound, err)
+}
+
+func TestUserServer_Authenticate(t *testing.T) {
+	ctx := context.Background()
+
+	client := todov1.NewUserServiceClient(newTestConn(t, config.AuthConfig{}, &bytes.Buffer{}))
+	_, err := client.Authenticate(ctx, &todov1.AuthenticateRequest{Username: "user1", Password: "password"})
+	assertCode(t, codes.Unimplemented, err)
+
+	client = todov1.NewUserServiceClient(newTestConn(t, config.AuthConfig{Secret: testSecret, TokenTTL: time.Hour}, &bytes.Buffer{}))
+	_, err = client.Authenticate(ctx, &todov1.AuthenticateRequest{Username: "user1", Password: "wrong"})
+	assertCode(t, codes.Unauthenticated, err)
+
+	response, err := client.Authenticate(ctx, &todov1.AuthenticateRequest{Username: "user1", Password: "password"})
+	assertCode(t, codes.OK, err)
+	assert.Equal(t, uint64(1), response.GetUser().GetId())
+	assert.WithinDuration(t, time.Now().Add(time.Hour), response.GetExpiresAt().AsTime(), time.Minute)
+
+	userID, generation, err := auth.Verify(testSecret, response.GetAccessToken(), time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), userID)
+	assert.Zero(t, generation)
+}
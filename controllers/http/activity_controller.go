@@ -0,0 +1,74 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/danikg/go-todo-rest-api/models"
+	"github.com/danikg/go-todo-rest-api/services"
+	"github.com/danikg/go-todo-rest-api/utils/response"
+	"github.com/danikg/go-todo-rest-api/utils/route"
+)
+
+// ActivityController ...
+type ActivityController struct {
+	ActivityService services.IActivityService
+}
+
+// NewActivityController ...
+func NewActivityController(activityService services.IActivityService) *ActivityController {
+	return &ActivityController{ActivityService: activityService}
+}
+
+// GetByTodoList returns the audit events of the todo list and its items, also after the list was deleted
+func (c *ActivityController) GetByTodoList(w http.ResponseWriter, r *http.Request) {
+	var (
+		id     uint
+		page   models.Page
+		events []models.AuditEvent
+		err    error
+	)
+
+	if id, err = route.GetRouteVar(r, "id"); err != nil {
+		response.SendErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if page, err = route.GetPage(r); err != nil {
+		response.SendErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if events, err = c.ActivityService.GetByTodoList(r.Context(), id, page); err != nil {
+		response.SendErrorResponse(w, accessErrorStatus(err, http.StatusInternalServerError), err)
+		return
+	}
+
+	response.SendResponse(w, events, 0)
+}
+
+// GetByUser returns the audit events on the data of the user and the changes made by the user
+func (c *ActivityController) GetByUser(w http.ResponseWriter, r *http.Request) {
+	var (
+		id     uint
+		page   models.Page
+		events []models.AuditEvent
+		err    error
+	)
+
+	if id, err = route.GetRouteVar(r, "id"); err != nil {
+		response.SendErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if page, err = route.GetPage(r); err != nil {
+		response.SendErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if events, err = c.ActivityService.GetByUser(r.Context(), id, page); err != nil {
+		response.SendErrorResponse(w, accessErrorStatus(err, http.StatusInternalServerError), err)
+		return
+	}
+
+	response.SendResponse(w, events, 0)
+}
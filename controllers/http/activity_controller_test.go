@@ -0,0 +1,115 @@
+package http
+
+import (
+	"encoding/json"
+	. "net/http"
+	"testing"
+
+	"github.com/danikg/go-todo-rest-api/models"
+	"github.com/danikg/go-todo-rest-api/services/mocks"
+	"github.com/danikg/go-todo-rest-api/utils/test"
+	"github.com/stretchr/testify/assert"
+)
+
+type activityTest struct {
+	title      string
+	path       string
+	route      string
+	shouldPass bool
+	statusCode int
+	events     int
+}
+
+func testActivityResult(t *testing.T, tc activityTest, controller func(ResponseWriter, *Request)) {
+	w, r := test.NewRequest("GET", tc.path, nil)
+	test.MakeRequest(tc.route, controller, w, r)
+	assert.Equal(t, tc.statusCode, w.Code)
+
+	if tc.shouldPass {
+		var result []models.AuditEvent
+		json.NewDecoder(w.Body).Decode(&result)
+		assert.Len(t, result, tc.events)
+	}
+}
+
+func TestActivityController_GetByTodoList(t *testing.T) {
+	tests := []activityTest{
+		{
+			title:      "Get todo list activity",
+			path:       "/todo_lists/1/activity?limit=10&offset=0",
+			route:      "/todo_lists/{id}/activity",
+			shouldPass: true,
+			statusCode: StatusOK,
+			events:     2,
+		},
+		{
+			title:      "Get todo list activity, wrong id",
+			path:       "/todo_lists/a/activity",
+			route:      "/todo_lists/{id}/activity",
+			shouldPass: false,
+			statusCode: StatusBadRequest,
+		},
+		{
+			title:      "Get todo list activity, wrong limit",
+			path:       "/todo_lists/1/activity?limit=0",
+			route:      "/todo_lists/{id}/activity",
+			shouldPass: false,
+			statusCode: StatusBadRequest,
+		},
+		{
+			title:      "Get todo list activity, internal error",
+			path:       "/todo_lists/2/activity",
+			route:      "/todo_lists/{id}/activity",
+			shouldPass: false,
+			statusCode: StatusInternalServerError,
+		},
+	}
+
+	activityController := NewActivityController(&mocks.ActivityServiceMock{})
+	for _, tc := range tests {
+		t.Run(tc.title, func(t *testing.T) {
+			testActivityResult(t, tc, activityController.GetByTodoList)
+		})
+	}
+}
+
+func TestActivityController_GetByUser(t *testing.T) {
+	tests := []activityTest{
+		{
+			title:      "Get user activity",
+			path:       "/users/1/activity",
+			route:      "/users/{id}/activity",
+			shouldPass: true,
+			statusCode: StatusOK,
+			events:     1,
+		},
+		{
+			title:      "Get user activity, wrong id",
+			path:       "/users/a/activity",
+			route:      "/users/{id}/activity",
+			shouldPass: false,
+			statusCode: StatusBadRequest,
+		},
+		{
+			title:      "Get user activity, wrong offset",
+			path:       "/users/1/activity?offset=a",
+			route:      "/users/{id}/activity",
+			shouldPass: false,
+			statusCode: StatusBadRequest,
+		},
+		{
+			title:      "Get user activity, internal error",
+			path:       "/users/2/activity",
+			route:      "/users/{id}/activity",
+			shouldPass: false,
+			statusCode: StatusInternalServerError,
+		},
+	}
+
+	activityController := NewActivityController(&mocks.ActivityServiceMock{})
+	for _, tc := range tests {
+		t.Run(tc.title, func(t *testing.T) {
+			testActivityResult(t, tc, activityController.GetByUser)
+		})
+	}
+}
@@ -0,0 +1,9 @@
+package http
+
+import "github.com/gorilla/mux"
+
+// SetupActivityRoutes ...
+func SetupActivityRoutes(router *mux.Router, controller *ActivityController) {
+	router.HandleFunc("/todo_lists/{id}/activity", controller.GetByTodoList).Methods("GET")
+	router.HandleFunc("/users/{id}/activity", controller.GetByUser).Methods("GET")
+}
@@ -0,0 +1,72 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/danikg/go-todo-rest-api/services"
+	"github.com/danikg/go-todo-rest-api/utils/auth"
+	"github.com/danikg/go-todo-rest-api/utils/response"
+)
+
+var errAuthNotConfigured = errors.New("authentication is not configured")
+
+// Credentials is the body of a token request
+type Credentials struct {
+	Username string
+	Password string
+}
+
+// Token is an issued access token
+type Token struct {
+	Token     string
+	ExpiresAt time.Time
+}
+
+// AuthController ...
+type AuthController struct {
+	UserService services.IUserService
+	Secret      string
+	TokenTTL    time.Duration
+}
+
+// NewAuthController ...
+func NewAuthController(userService services.IUserService, secret string, tokenTTL time.Duration) *AuthController {
+	return &AuthController{
+		UserService: userService,
+		Secret:      secret,
+		TokenTTL:    tokenTTL,
+	}
+}
+
+// Token issues an access token for valid credentials
+func (c *AuthController) Token(w http.ResponseWriter, r *http.Request) {
+	var (
+		credentials Credentials
+		err         error
+	)
+
+	if c.Secret == "" {
+		response.SendErrorResponse(w, http.StatusNotImplemented, errAuthNotConfigured)
+		return
+	}
+
+	if err = json.NewDecoder(r.Body).Decode(&credentials); err != nil {
+		response.SendErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	user, err := c.UserService.Authenticate(r.Context(), credentials.Username, credentials.Password)
+	if err != nil {
+		response.SendErrorResponse(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	expiresAt := time.Now().Add(c.TokenTTL).UTC().Truncate(time.Second)
+	response.SendResponse(w, Token{
+		Token:     auth.Issue(c.Secret, user.ID, user.TokenGeneration, expiresAt),
+		ExpiresAt: expiresAt,
+	}, http.StatusCreated)
+}
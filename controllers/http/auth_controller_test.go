@@ -0,0 +1,74 @@
+package http
+
+import (
+	"encoding/json"
+	. "net/http"
+	"testing"
+	"time"
+
+	"github.com/danikg/go-todo-rest-api/services/mocks"
+	"github.com/danikg/go-todo-rest-api/utils/auth"
+	"github.com/danikg/go-todo-rest-api/utils/test"
+	"github.com/stretchr/testify/assert"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+func TestAuthController_Token(t *testing.T) {
+	tests := []struct {
+		title      string
+		secret     string
+		body       []byte
+		shouldPass bool
+		statusCode int
+	}{
+		{
+			title:      "Issue token",
+			secret:     testSecret,
+			body:       []byte(`{"Username": "user1", "Password": "password"}`),
+			shouldPass: true,
+			statusCode: StatusCreated,
+		},
+		{
+			title:      "Issue token, wrong password",
+			secret:     testSecret,
+			body:       []byte(`{"Username": "user1", "Password": "wrong"}`),
+			shouldPass: false,
+			statusCode: StatusUnauthorized,
+		},
+		{
+			title:      "Issue token, wrong body",
+			secret:     testSecret,
+			body:       []byte{},
+			shouldPass: false,
+			statusCode: StatusBadRequest,
+		},
+		{
+			title:      "Issue token, auth not configured",
+			secret:     "",
+			body:       []byte(`{"Username": "user1", "Password": "password"}`),
+			shouldPass: false,
+			statusCode: StatusNotImplemented,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.title, func(t *testing.T) {
+			authController := NewAuthController(&mocks.UserServiceMock{}, tc.secret, time.Hour)
+			w, r := test.NewRequest("POST", "/auth/token", tc.body)
+			test.MakeRequest("/auth/token", authController.Token, w, r)
+			assert.Equal(t, tc.statusCode, w.Code)
+
+			if tc.shouldPass {
+				var token Token
+				json.NewDecoder(w.Body).Decode(&token)
+				userID, generation, err := auth.Verify(testSecret, token.Token, time.Now())
+				assert.NoError(t, err)
+				assert.Equal(t, uint(1), userID)
+				assert.Zero(t, generation)
+				assert.Zero(t, generation)
+				assert.True(t, token.ExpiresAt.After(time.Now()))
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danikg/go-todo-rest-api/config"
+	"github.com/danikg/go-todo-rest-api/services"
+	"github.com/danikg/go-todo-rest-api/utils/auth"
+	"github.com/danikg/go-todo-rest-api/utils/logger"
+	"github.com/danikg/go-todo-rest-api/utils/response"
+	"github.com/gorilla/mux"
+)
+
+// publicRoutes can be called without a token when authentication is enabled,
+// the calendar feeds are authenticated by the feed token in their path
+var publicRoutes = map[string]bool{
+	"POST /users":             true,
+	"POST /auth/token":        true,
+	"GET /metrics":            true,
+	"GET /openapi.json":       true,
+	"GET /docs":               true,
+	"GET /feeds/{token}.ics":  true,
+	"HEAD /feeds/{token}.ics": true,
+	"GET /feeds/{token}/todo_lists/{list_id}.ics":  true,
+	"HEAD /feeds/{token}/todo_lists/{list_id}.ics": true,
+}
+
+// queryTokenRoutes also accept the token as access_token query parameter,
+// browsers cannot set headers on EventSource and WebSocket connections
+var queryTokenRoutes = map[string]bool{
+	"GET /events": true,
+}
+
+var errMissingToken = errors.New("missing bearer token")
+
+// AuthMiddleware identifies the user from the bearer token, users checks that the token was not revoked.
+// Requests without a token are rejected on non public routes when authentication is enabled,
+// public routes ignore tokens that are not accepted
+func AuthMiddleware(cfg config.AuthConfig, users services.IUserService) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if cfg.Enabled {
+				r = r.WithContext(auth.RequireUser(r.Context()))
+			}
+			token := bearerToken(r)
+			public := publicRoutes[r.Method+" "+routeTemplate(r)]
+			if token == "" || cfg.Secret == "" {
+				if cfg.Enabled && !public {
+					response.SendErrorResponse(w, http.StatusUnauthorized, errMissingToken)
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			userID, generation, err := auth.Verify(cfg.Secret, token, time.Now())
+			if err == nil {
+				err = users.CheckToken(r.Context(), userID, generation)
+			}
+			if err != nil && public {
+				// signing in again must work with the revoked token still sent along
+				next.ServeHTTP(w, r)
+				return
+			}
+			if err != nil {
+				response.SendErrorResponse(w, http.StatusUnauthorized, err)
+				return
+			}
+
+			logger.SetUser(r.Context(), strconv.FormatUint(uint64(userID), 10))
+			next.ServeHTTP(w, r.WithContext(auth.NewContext(r.Context(), userID)))
+		})
+	}
+}
+
+func bearerToken(r *http.Request) string {
+	header := r.Header.Get("Authorization")
+	if len(header) > 7 && strings.EqualFold(header[:7], "bearer ") {
+		return strings.TrimSpace(header[7:])
+	}
+	if queryTokenRoutes[r.Method+" "+routeTemplate(r)] {
+		return r.URL.Query().Get("access_token")
+	}
+	return ""
+}
+
+// accessErrorStatus answers the requests on the data of another user with 403, other errors get fallback
+func accessErrorStatus(err error, fallback int) int {
+	if errors.Is(err, services.ErrForbidden) {
+		return http.StatusForbidden
+	}
+	return fallback
+}
@@ -0,0 +1,8 @@
+package http
+
+import "github.com/gorilla/mux"
+
+// SetupAuthRoutes ...
+func SetupAuthRoutes(router *mux.Router, controller *AuthController) {
+	router.HandleFunc("/auth/token", controller.Token).Methods("POST")
+}
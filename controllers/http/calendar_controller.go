@@ -0,0 +1,96 @@
+package http
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"github.com/danikg/go-todo-rest-api/models"
+	"github.com/danikg/go-todo-rest-api/services"
+	"github.com/danikg/go-todo-rest-api/utils/ical"
+	"github.com/danikg/go-todo-rest-api/utils/response"
+	"github.com/danikg/go-todo-rest-api/utils/route"
+	"github.com/gorilla/mux"
+)
+
+// CalendarController ...
+type CalendarController struct {
+	calendarService services.ICalendarService
+}
+
+// NewCalendarController ...
+func NewCalendarController(calendarService services.ICalendarService) *CalendarController {
+	return &CalendarController{calendarService: calendarService}
+}
+
+// PostFeed creates the feed token of the user, replacing the previous one
+func (c *CalendarController) PostFeed(w http.ResponseWriter, r *http.Request) {
+	var (
+		id   uint
+		feed models.Feed
+		err  error
+	)
+
+	if id, err = route.GetRouteVar(r, "id"); err != nil {
+		response.SendErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if feed, err = c.calendarService.CreateFeed(r.Context(), id); err != nil {
+		response.SendErrorResponse(w, accessErrorStatus(err, http.StatusNotFound), err)
+		return
+	}
+
+	response.SendResponse(w, feed, http.StatusCreated)
+}
+
+// DeleteFeed revokes the feed token of the user
+func (c *CalendarController) DeleteFeed(w http.ResponseWriter, r *http.Request) {
+	var (
+		id  uint
+		err error
+	)
+
+	if id, err = route.GetRouteVar(r, "id"); err != nil {
+		response.SendErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err = c.calendarService.DeleteFeed(r.Context(), id); err != nil {
+		response.SendErrorResponse(w, accessErrorStatus(err, http.StatusNotFound), err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Feed serves the read-only iCalendar feed of a feed token, for all todo lists or for the one in the path.
+// The token in the path authenticates the request, calendar apps cannot send bearer tokens
+func (c *CalendarController) Feed(w http.ResponseWriter, r *http.Request) {
+	var (
+		listID uint
+		feed   []byte
+		err    error
+	)
+
+	if _, ok := mux.Vars(r)["list_id"]; ok {
+		if listID, err = route.GetRouteVar(r, "list_id"); err != nil {
+			response.SendErrorResponse(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	if feed, err = c.calendarService.Feed(r.Context(), mux.Vars(r)["token"], listID); err != nil {
+		response.SendErrorResponse(w, http.StatusNotFound, err)
+		return
+	}
+
+	// calendar apps poll the feed, unchanged feeds are answered with 304
+	sum := sha256.Sum256(feed)
+	w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:16])+`"`)
+	w.Header().Set("Content-Type", ical.ContentType)
+	w.Header().Set("Cache-Control", "private, max-age=300")
+	http.ServeContent(w, r, "todos.ics", time.Time{}, bytes.NewReader(feed))
+}
@@ -0,0 +1,137 @@
+package http
+
+import (
+	"encoding/json"
+	. "net/http"
+	"testing"
+
+	"github.com/danikg/go-todo-rest-api/models"
+	"github.com/danikg/go-todo-rest-api/services/mocks"
+	"github.com/danikg/go-todo-rest-api/utils/test"
+	"github.com/stretchr/testify/assert"
+)
+
+type calendarTest struct {
+	title      string
+	method     string
+	path       string
+	route      string
+	statusCode int
+}
+
+func TestCalendarController_Feeds(t *testing.T) {
+	calendarController := NewCalendarController(&mocks.CalendarServiceMock{})
+
+	w, r := test.NewRequest("POST", "/users/1/feed", nil)
+	test.MakeRequest("/users/{id}/feed", calendarController.PostFeed, w, r)
+	assert.Equal(t, StatusCreated, w.Code)
+	feed := models.Feed{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &feed))
+	assert.Equal(t, "/feeds/token1.ics", feed.Path)
+
+	tests := []calendarTest{
+		{
+			title:      "Create feed, wrong id",
+			method:     "POST",
+			path:       "/users/a/feed",
+			route:      "/users/{id}/feed",
+			statusCode: StatusBadRequest,
+		},
+		{
+			title:      "Create feed, non-existent user",
+			method:     "POST",
+			path:       "/users/2/feed",
+			route:      "/users/{id}/feed",
+			statusCode: StatusNotFound,
+		},
+		{
+			title:      "Delete feed",
+			method:     "DELETE",
+			path:       "/users/1/feed",
+			route:      "/users/{id}/feed",
+			statusCode: StatusNoContent,
+		},
+		{
+			title:      "Delete feed, non-existent user",
+			method:     "DELETE",
+			path:       "/users/2/feed",
+			route:      "/users/{id}/feed",
+			statusCode: StatusNotFound,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.title, func(t *testing.T) {
+			w, r := test.NewRequest(tc.method, tc.path, nil)
+			handler := calendarController.PostFeed
+			if tc.method == "DELETE" {
+				handler = calendarController.DeleteFeed
+			}
+			test.MakeRequest(tc.route, handler, w, r)
+			assert.Equal(t, tc.statusCode, w.Code)
+		})
+	}
+}
+
+func TestCalendarController_Feed(t *testing.T) {
+	tests := []calendarTest{
+		{
+			title:      "Get user feed",
+			path:       "/feeds/token1.ics",
+			route:      "/feeds/{token}.ics",
+			statusCode: StatusOK,
+		},
+		{
+			title:      "Get todo list feed",
+			path:       "/feeds/token1/todo_lists/1.ics",
+			route:      "/feeds/{token}/todo_lists/{list_id}.ics",
+			statusCode: StatusOK,
+		},
+		{
+			title:      "Get feed, unknown token",
+			path:       "/feeds/token2.ics",
+			route:      "/feeds/{token}.ics",
+			statusCode: StatusNotFound,
+		},
+		{
+			title:      "Get todo list feed, wrong list_id",
+			path:       "/feeds/token1/todo_lists/a.ics",
+			route:      "/feeds/{token}/todo_lists/{list_id}.ics",
+			statusCode: StatusBadRequest,
+		},
+		{
+			title:      "Get todo list feed, non-existent list",
+			path:       "/feeds/token1/todo_lists/2.ics",
+			route:      "/feeds/{token}/todo_lists/{list_id}.ics",
+			statusCode: StatusNotFound,
+		},
+	}
+
+	calendarController := NewCalendarController(&mocks.CalendarServiceMock{})
+	for _, tc := range tests {
+		t.Run(tc.title, func(t *testing.T) {
+			w, r := test.NewRequest("GET", tc.path, nil)
+			test.MakeRequest(tc.route, calendarController.Feed, w, r)
+			assert.Equal(t, tc.statusCode, w.Code)
+			if tc.statusCode == StatusOK {
+				assert.Equal(t, "text/calendar; charset=utf-8", w.Header().Get("Content-Type"))
+				assert.Equal(t, "BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestCalendarController_FeedNotModified(t *testing.T) {
+	calendarController := NewCalendarController(&mocks.CalendarServiceMock{})
+
+	w, r := test.NewRequest("GET", "/feeds/token1.ics", nil)
+	test.MakeRequest("/feeds/{token}.ics", calendarController.Feed, w, r)
+	etag := w.Header().Get("ETag")
+	assert.NotEmpty(t, etag)
+
+	w, r = test.NewRequest("GET", "/feeds/token1.ics", nil)
+	r.Header.Set("If-None-Match", etag)
+	test.MakeRequest("/feeds/{token}.ics", calendarController.Feed, w, r)
+	assert.Equal(t, StatusNotModified, w.Code)
+	assert.Empty(t, w.Body.String())
+}
@@ -0,0 +1,11 @@
+package http
+
+import "github.com/gorilla/mux"
+
+// SetupCalendarRoutes ...
+func SetupCalendarRoutes(router *mux.Router, controller *CalendarController) {
+	router.HandleFunc("/users/{id}/feed", controller.PostFeed).Methods("POST")
+	router.HandleFunc("/users/{id}/feed", controller.DeleteFeed).Methods("DELETE")
+	router.HandleFunc("/feeds/{token}.ics", controller.Feed).Methods("GET", "HEAD")
+	router.HandleFunc("/feeds/{token}/todo_lists/{list_id}.ics", controller.Feed).Methods("GET", "HEAD")
+}
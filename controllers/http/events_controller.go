@@ -0,0 +1,206 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danikg/go-todo-rest-api/models"
+	"github.com/danikg/go-todo-rest-api/utils/auth"
+	"github.com/danikg/go-todo-rest-api/utils/events"
+	"github.com/danikg/go-todo-rest-api/utils/response"
+	"github.com/gorilla/websocket"
+)
+
+// LastEventIDHeader is sent by EventSource clients when they reconnect
+const LastEventIDHeader = "Last-Event-ID"
+
+// EventsController ...
+type EventsController struct {
+	bus       *events.Bus
+	heartbeat time.Duration
+}
+
+// NewEventsController ...
+func NewEventsController(bus *events.Bus, heartbeat time.Duration) *EventsController {
+	return &EventsController{bus: bus, heartbeat: heartbeat}
+}
+
+// Stream pushes the change events of the authenticated user as Server-Sent Events, or as JSON
+// messages over a WebSocket when the request asks for an upgrade. Without authentication, like
+// the rest of the API, the events of every user are pushed. A Last-Event-ID header or
+// last_event_id parameter replays the missed events, a reset event tells that some are lost
+func (c *EventsController) Stream(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserID(r.Context())
+	if !ok && auth.Required(r.Context()) {
+		response.SendErrorResponse(w, http.StatusUnauthorized, errMissingToken)
+		return
+	}
+
+	lastEventID, err := getLastEventID(r)
+	if err != nil {
+		response.SendErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	sub, replay, complete := c.bus.SubscribeAll(lastEventID)
+	if ok {
+		sub, replay, complete = c.bus.Subscribe(userID, lastEventID)
+	}
+	defer sub.Close()
+	if !complete {
+		replay = append([]models.ChangeEvent{{Type: models.EventReset}}, replay...)
+	}
+
+	if websocket.IsWebSocketUpgrade(r) {
+		c.streamWebSocket(w, r, sub, replay)
+		return
+	}
+	c.streamSSE(w, r, sub, replay)
+}
+
+func (c *EventsController) streamSSE(w http.ResponseWriter, r *http.Request, sub *events.Subscription, replay []models.ChangeEvent) {
+	rc := http.NewResponseController(w)
+	// the stream outlives the server timeouts, the read deadline would cancel the request context
+	rc.SetReadDeadline(time.Time{})
+	rc.SetWriteDeadline(time.Time{})
+
+	h := w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	rc.Flush()
+
+	send := func(event models.ChangeEvent) bool {
+		return writeSSE(w, event) == nil && rc.Flush() == nil
+	}
+	for _, event := range replay {
+		if !send(event) {
+			return
+		}
+	}
+
+	heartbeat := time.NewTicker(c.heartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-sub.C:
+			// a dropped subscriber reconnects and resumes from its last event id
+			if !ok || !send(event) {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := io.WriteString(w, ": heartbeat\n\n"); err != nil || rc.Flush() != nil {
+				return
+			}
+		}
+	}
+}
+
+func (c *EventsController) streamWebSocket(w http.ResponseWriter, r *http.Request, sub *events.Subscription, replay []models.ChangeEvent) {
+	// cross origin clients are accepted when the CORS middleware allowed their origin
+	corsAllowed := w.Header().Get("Access-Control-Allow-Origin") != ""
+	upgrader := websocket.Upgrader{CheckOrigin: func(r *http.Request) bool {
+		return corsAllowed || sameOrigin(r)
+	}}
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// the upgrader already answered with an error
+		return
+	}
+	defer conn.Close()
+
+	// the client sends nothing, reading only handles control frames and notices when it goes away
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	send := func(event models.ChangeEvent) bool {
+		conn.SetWriteDeadline(time.Now().Add(c.heartbeat))
+		return conn.WriteJSON(event) == nil
+	}
+	for _, event := range replay {
+		if !send(event) {
+			return
+		}
+	}
+
+	heartbeat := time.NewTicker(c.heartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-closed:
+			return
+		case event, ok := <-sub.C:
+			if !ok {
+				message := websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "too far behind, resume with last_event_id")
+				conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(time.Second))
+				return
+			}
+			if !send(event) {
+				return
+			}
+		case <-heartbeat.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(c.heartbeat)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// writeSSE writes the event in the text/event-stream format, the reset event carries no id
+func writeSSE(w io.Writer, event models.ChangeEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	if event.ID != 0 {
+		if _, err = fmt.Fprintf(w, "id: %d\n", event.ID); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+	return err
+}
+
+// getLastEventID returns the id of the last event the client received, 0 for new clients
+func getLastEventID(r *http.Request) (uint64, error) {
+	value := r.Header.Get(LastEventIDHeader)
+	if value == "" {
+		value = r.URL.Query().Get("last_event_id")
+	}
+	if value == "" {
+		return 0, nil
+	}
+
+	id, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid last event id %q", value)
+	}
+	return id, nil
+}
+
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
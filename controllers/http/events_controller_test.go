@@ -0,0 +1,177 @@
+package http
+
+import (
+	"bufio"
+	"encoding/json"
+	. "net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danikg/go-todo-rest-api/models"
+	"github.com/danikg/go-todo-rest-api/utils/auth"
+	"github.com/danikg/go-todo-rest-api/utils/events"
+	"github.com/danikg/go-todo-rest-api/utils/test"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+// newEventsServer serves the stream of user 1 behind a status recording middleware
+func newEventsServer(bus *events.Bus) *httptest.Server {
+	controller := NewEventsController(bus, time.Minute)
+	return httptest.NewServer(MetricsMiddleware(HandlerFunc(func(w ResponseWriter, r *Request) {
+		controller.Stream(w, r.WithContext(auth.NewContext(r.Context(), 1)))
+	})))
+}
+
+// publishEvents publishes an event for user 1, one for user 2 and another one for user 1,
+// returning the events of user 1
+func publishEvents(t *testing.T, bus *events.Bus) []models.ChangeEvent {
+	sub, _, _ := bus.Subscribe(1, 0)
+	defer sub.Close()
+
+	bus.Publish(models.ChangeEvent{Type: models.EventTodoItemCreated, UserID: 1, TodoListID: 1})
+	bus.Publish(models.ChangeEvent{Type: models.EventTodoItemCreated, UserID: 2, TodoListID: 2})
+	bus.Publish(models.ChangeEvent{Type: models.EventTodoItemUpdated, UserID: 1, TodoListID: 1})
+	return []models.ChangeEvent{<-sub.C, <-sub.C}
+}
+
+// readSSE returns the fields of the next event of the stream, skipping comments
+func readSSE(t *testing.T, reader *bufio.Reader) map[string]string {
+	fields := map[string]string{}
+	for {
+		line, err := reader.ReadString('\n')
+		assert.NoError(t, err)
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			if len(fields) != 0 {
+				return fields
+			}
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+		name, value, _ := strings.Cut(line, ": ")
+		fields[name] = value
+	}
+}
+
+func TestEventsController_Stream(t *testing.T) {
+	controller := NewEventsController(events.NewBus(10), time.Minute)
+
+	w, r := test.NewRequest("GET", "/events", nil)
+	r = r.WithContext(auth.RequireUser(r.Context()))
+	test.MakeRequest("/events", controller.Stream, w, r)
+	assert.Equal(t, StatusUnauthorized, w.Code)
+
+	w, r = test.NewRequest("GET", "/events?last_event_id=a", nil)
+	r = r.WithContext(auth.NewContext(r.Context(), 1))
+	test.MakeRequest("/events", controller.Stream, w, r)
+	assert.Equal(t, StatusBadRequest, w.Code)
+}
+
+func TestEventsController_SSE(t *testing.T) {
+	bus := events.NewBus(10)
+	published := publishEvents(t, bus)
+	server := newEventsServer(bus)
+	defer server.Close()
+
+	req, _ := NewRequest("GET", server.URL, nil)
+	req.Header.Set(LastEventIDHeader, strconv.FormatUint(published[0].ID, 10))
+	resp, err := DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	reader := bufio.NewReader(resp.Body)
+
+	// the missed event of the user is replayed, then live events follow
+	event := readSSE(t, reader)
+	assert.Equal(t, strconv.FormatUint(published[1].ID, 10), event["id"])
+	assert.Equal(t, models.EventTodoItemUpdated, event["event"])
+	var data models.ChangeEvent
+	assert.NoError(t, json.Unmarshal([]byte(event["data"]), &data))
+	assert.Equal(t, published[1].ID, data.ID)
+	assert.Equal(t, uint(1), data.TodoListID)
+
+	bus.Publish(models.ChangeEvent{Type: models.EventTodoListDeleted, UserID: 2, TodoListID: 2})
+	bus.Publish(models.ChangeEvent{Type: models.EventTodoListDeleted, UserID: 1, TodoListID: 1})
+	event = readSSE(t, reader)
+	assert.Equal(t, models.EventTodoListDeleted, event["event"])
+	assert.Contains(t, event["data"], `"TodoListID":1`)
+}
+
+func TestEventsController_SSEWithoutAuth(t *testing.T) {
+	bus := events.NewBus(10)
+	published := publishEvents(t, bus)
+	controller := NewEventsController(bus, time.Minute)
+	server := httptest.NewServer(HandlerFunc(controller.Stream))
+	defer server.Close()
+
+	req, _ := NewRequest("GET", server.URL, nil)
+	req.Header.Set(LastEventIDHeader, strconv.FormatUint(published[0].ID, 10))
+	resp, err := DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, StatusOK, resp.StatusCode)
+	reader := bufio.NewReader(resp.Body)
+
+	// without authentication the events of every user are pushed
+	event := readSSE(t, reader)
+	assert.Contains(t, event["data"], `"TodoListID":2`)
+	event = readSSE(t, reader)
+	assert.Equal(t, strconv.FormatUint(published[1].ID, 10), event["id"])
+}
+
+func TestEventsController_SSEReset(t *testing.T) {
+	bus := events.NewBus(1)
+	published := publishEvents(t, bus)
+	server := newEventsServer(bus)
+	defer server.Close()
+
+	// the first event after the client's last one is not remembered anymore
+	req, _ := NewRequest("GET", server.URL, nil)
+	req.Header.Set(LastEventIDHeader, strconv.FormatUint(published[0].ID, 10))
+	resp, err := DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	reader := bufio.NewReader(resp.Body)
+
+	event := readSSE(t, reader)
+	assert.Equal(t, models.EventReset, event["event"])
+	assert.Empty(t, event["id"])
+	event = readSSE(t, reader)
+	assert.Equal(t, strconv.FormatUint(published[1].ID, 10), event["id"])
+}
+
+func TestEventsController_WebSocket(t *testing.T) {
+	bus := events.NewBus(10)
+	published := publishEvents(t, bus)
+	server := newEventsServer(bus)
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "?last_event_id=" + strconv.FormatUint(published[0].ID, 10)
+	conn, resp, err := websocket.DefaultDialer.Dial(url, nil)
+	assert.NoError(t, err)
+	defer conn.Close()
+	assert.Equal(t, StatusSwitchingProtocols, resp.StatusCode)
+
+	var event models.ChangeEvent
+	assert.NoError(t, conn.ReadJSON(&event))
+	assert.Equal(t, published[1].ID, event.ID)
+	assert.Equal(t, models.EventTodoItemUpdated, event.Type)
+
+	bus.Publish(models.ChangeEvent{Type: models.EventTodoItemDeleted, UserID: 1, TodoListID: 1})
+	assert.NoError(t, conn.ReadJSON(&event))
+	assert.Equal(t, models.EventTodoItemDeleted, event.Type)
+	assert.Greater(t, event.ID, published[1].ID)
+
+	// other origins are rejected unless the CORS middleware allowed them
+	header := Header{"Origin": []string{"http://evil.example"}}
+	_, resp, err = websocket.DefaultDialer.Dial(url, header)
+	assert.Error(t, err)
+	assert.Equal(t, StatusForbidden, resp.StatusCode)
+}
@@ -0,0 +1,8 @@
+package http
+
+import "github.com/gorilla/mux"
+
+// SetupEventsRoutes ...
+func SetupEventsRoutes(router *mux.Router, controller *EventsController) {
+	router.HandleFunc("/events", controller.Stream).Methods("GET")
+}
@@ -0,0 +1,73 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/danikg/go-todo-rest-api/services"
+	"github.com/danikg/go-todo-rest-api/utils/export"
+	"github.com/danikg/go-todo-rest-api/utils/logger"
+	"github.com/danikg/go-todo-rest-api/utils/response"
+	"github.com/danikg/go-todo-rest-api/utils/route"
+)
+
+// ExportController ...
+type ExportController struct {
+	exportService services.IExportService
+}
+
+// NewExportController ...
+func NewExportController(exportService services.IExportService) *ExportController {
+	return &ExportController{exportService: exportService}
+}
+
+// exportOutput remembers whether the export started writing the response,
+// afterwards a failure can only cut the download short
+type exportOutput struct {
+	http.ResponseWriter
+	written bool
+}
+
+func (o *exportOutput) Write(p []byte) (int, error) {
+	o.written = true
+	return o.ResponseWriter.Write(p)
+}
+
+// Export streams all todo lists, todo items and tags of the user as a json (default), csv or markdown download
+func (c *ExportController) Export(w http.ResponseWriter, r *http.Request) {
+	id, err := route.GetRouteVar(r, "id")
+	if err != nil {
+		response.SendErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = export.JSON
+	}
+
+	// large exports outlive the server timeouts, the read deadline would cancel the request context
+	rc := http.NewResponseController(w)
+	rc.SetReadDeadline(time.Time{})
+	rc.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", export.ContentType(format))
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="export-%d.%s"`, id, export.Extension(format)))
+
+	out := &exportOutput{ResponseWriter: w}
+	err = c.exportService.Export(r.Context(), id, format, out)
+	switch {
+	case err == nil:
+	case out.written:
+		logger.FromContext(r.Context()).Error("export failed while streaming", "error", err, "user_id", id)
+	default:
+		w.Header().Del("Content-Disposition")
+		status := accessErrorStatus(err, http.StatusNotFound)
+		if errors.Is(err, services.ErrInvalidExportFormat) {
+			status = http.StatusBadRequest
+		}
+		response.SendErrorResponse(w, status, err)
+	}
+}
@@ -0,0 +1,88 @@
+package http
+
+import (
+	. "net/http"
+	"testing"
+
+	"github.com/danikg/go-todo-rest-api/services/mocks"
+	"github.com/danikg/go-todo-rest-api/utils/test"
+	"github.com/stretchr/testify/assert"
+)
+
+type exportTest struct {
+	title       string
+	path        string
+	statusCode  int
+	contentType string
+	disposition string
+	body        string
+}
+
+func TestExportController_Export(t *testing.T) {
+	tests := []exportTest{
+		{
+			title:       "Export as json by default",
+			path:        "/users/1/export",
+			statusCode:  StatusOK,
+			contentType: "application/json",
+			disposition: `attachment; filename="export-1.json"`,
+			body:        "export of user1",
+		},
+		{
+			title:       "Export as csv",
+			path:        "/users/1/export?format=csv",
+			statusCode:  StatusOK,
+			contentType: "text/csv; charset=utf-8",
+			disposition: `attachment; filename="export-1.csv"`,
+			body:        "export of user1",
+		},
+		{
+			title:       "Export as markdown",
+			path:        "/users/1/export?format=markdown",
+			statusCode:  StatusOK,
+			contentType: "text/markdown; charset=utf-8",
+			disposition: `attachment; filename="export-1.md"`,
+			body:        "export of user1",
+		},
+		{
+			title:       "Export, unknown format",
+			path:        "/users/1/export?format=xml",
+			statusCode:  StatusBadRequest,
+			contentType: "application/json",
+		},
+		{
+			title:       "Export, wrong id",
+			path:        "/users/a/export",
+			statusCode:  StatusBadRequest,
+			contentType: "application/json",
+		},
+		{
+			title:       "Export, non-existent user",
+			path:        "/users/2/export",
+			statusCode:  StatusNotFound,
+			contentType: "application/json",
+		},
+		{
+			title:       "Export, failure while streaming",
+			path:        "/users/3/export",
+			statusCode:  StatusOK,
+			contentType: "application/json",
+			disposition: `attachment; filename="export-3.json"`,
+			body:        "partial",
+		},
+	}
+
+	exportController := NewExportController(&mocks.ExportServiceMock{})
+	for _, tc := range tests {
+		t.Run(tc.title, func(t *testing.T) {
+			w, r := test.NewRequest("GET", tc.path, nil)
+			test.MakeRequest("/users/{id}/export", exportController.Export, w, r)
+			assert.Equal(t, tc.statusCode, w.Code)
+			assert.Equal(t, tc.contentType, w.Header().Get("Content-Type"))
+			assert.Equal(t, tc.disposition, w.Header().Get("Content-Disposition"))
+			if tc.body != "" {
+				assert.Equal(t, tc.body, w.Body.String())
+			}
+		})
+	}
+}
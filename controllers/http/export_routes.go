@@ -0,0 +1,8 @@
+package http
+
+import "github.com/gorilla/mux"
+
+// SetupExportRoutes ...
+func SetupExportRoutes(router *mux.Router, controller *ExportController) {
+	router.HandleFunc("/users/{id}/export", controller.Export).Methods("GET")
+}
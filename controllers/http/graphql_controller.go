@@ -0,0 +1,58 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/danikg/go-todo-rest-api/services"
+	"github.com/danikg/go-todo-rest-api/utils/graphql"
+	"github.com/danikg/go-todo-rest-api/utils/response"
+)
+
+// GraphQLController ...
+type GraphQLController struct {
+	UserService     services.IUserService
+	TodoListService services.ITodoListService
+	TodoItemService services.ITodoItemService
+	TagService      services.ITagService
+
+	schema *graphql.Schema
+}
+
+// NewGraphQLController ...
+func NewGraphQLController(userService services.IUserService, todoListService services.ITodoListService,
+	todoItemService services.ITodoItemService, tagService services.ITagService) *GraphQLController {
+	c := &GraphQLController{
+		UserService:     userService,
+		TodoListService: todoListService,
+		TodoItemService: todoItemService,
+		TagService:      tagService,
+	}
+	c.schema = c.newGraphQLSchema()
+	return c
+}
+
+// Query executes a GraphQL query or mutation, requests that cannot be parsed or validated
+// are answered with 400, field errors are reported next to the data with 200
+func (c *GraphQLController) Query(w http.ResponseWriter, r *http.Request) {
+	var request graphql.Request
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		response.SendErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ctx := context.WithValue(r.Context(), graphQLLoadersKey{}, c.newLoaders())
+	result := c.schema.Execute(ctx, request)
+	if result.Data == nil {
+		response.SendResponse(w, result, http.StatusBadRequest)
+		return
+	}
+	response.SendResponse(w, result, http.StatusOK)
+}
+
+// Schema returns the schema in the GraphQL schema language
+func (c *GraphQLController) Schema(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write([]byte(c.schema.String()))
+}
@@ -0,0 +1,180 @@
+package http
+
+import (
+	"encoding/json"
+	. "net/http"
+	"testing"
+
+	"github.com/danikg/go-todo-rest-api/services/mocks"
+	"github.com/danikg/go-todo-rest-api/utils/test"
+	"github.com/stretchr/testify/assert"
+)
+
+type graphQLTest struct {
+	title      string
+	body       string
+	statusCode int
+	data       string
+	errors     []string
+}
+
+type graphQLResult struct {
+	Data   json.RawMessage
+	Errors []struct{ Message string }
+}
+
+func newTestGraphQLController() (*GraphQLController, *mocks.TodoListServiceMock, *mocks.TodoItemServiceMock) {
+	todoListService := &mocks.TodoListServiceMock{}
+	todoItemService := &mocks.TodoItemServiceMock{}
+	controller := NewGraphQLController(&mocks.UserServiceMock{}, todoListService, todoItemService, &mocks.TagServiceMock{})
+	return controller, todoListService, todoItemService
+}
+
+func TestGraphQLController_Query(t *testing.T) {
+	tests := []graphQLTest{
+		{
+			title:      "Query user",
+			body:       `{"query": "{ user(id: 1) { id username } }"}`,
+			statusCode: StatusOK,
+			data:       `{"user":{"id":1,"username":"user1"}}`,
+		},
+		{
+			title:      "Query user with a variable and an alias",
+			body:       `{"query": "query User($id: Int!) { first: user(id: $id) { username } }", "variables": {"id": 1}}`,
+			statusCode: StatusOK,
+			data:       `{"first":{"username":"user1"}}`,
+		},
+		{
+			title:      "Query non-existent user",
+			body:       `{"query": "{ user(id: 3) { id } }"}`,
+			statusCode: StatusOK,
+			data:       `{"user":null}`,
+			errors:     []string{"err"},
+		},
+		{
+			title:      "Query todo list with its todo items",
+			body:       `{"query": "{ todoList(id: 1) { name todoItems { title todoList { id } } } }"}`,
+			statusCode: StatusOK,
+			data:       `{"todoList":{"name":"list1","todoItems":[{"title":"item1","todoList":{"id":1}},{"title":"item2","todoList":{"id":1}}]}}`,
+		},
+		{
+			title:      "Query tags with a fragment",
+			body:       `{"query": "{ tags(userId: 1) { ...usage } } fragment usage on TagUsage { usageCount tag { text } }"}`,
+			statusCode: StatusOK,
+			data:       `{"tags":[{"usageCount":2,"tag":{"text":"tag1"}}]}`,
+		},
+		{
+			title:      "Query tagged todo items, limit out of range",
+			body:       `{"query": "{ taggedTodoItems(tagId: 1, limit: 1000) { id } }"}`,
+			statusCode: StatusOK,
+			data:       `{"taggedTodoItems":null}`,
+			errors:     []string{"limit must be between 1 and 100"},
+		},
+		{
+			title:      "Create user",
+			body:       `{"query": "mutation { createUser(username: \"user3\", password: \"password\") { username } }"}`,
+			statusCode: StatusOK,
+			data:       `{"createUser":{"username":"user3"}}`,
+		},
+		{
+			title:      "Delete todo items one after the other",
+			body:       `{"query": "mutation { a: deleteTodoItem(id: 1) b: deleteTodoItem(id: 2) }"}`,
+			statusCode: StatusOK,
+			data:       `{"a":true,"b":null}`,
+			errors:     []string{"err"},
+		},
+		{
+			title:      "Merge a tag into itself",
+			body:       `{"query": "mutation { mergeTags(id: 1, targetId: 1) { id } }"}`,
+			statusCode: StatusOK,
+			data:       `{"mergeTags":null}`,
+			errors:     []string{"a tag can only be merged into another tag of the same user that is not nested below it"},
+		},
+		{
+			title:      "Unknown field",
+			body:       `{"query": "{ user(id: 1) { password } }"}`,
+			statusCode: StatusBadRequest,
+			errors:     []string{`cannot query field "password" on type User`},
+		},
+		{
+			title:      "Missing argument",
+			body:       `{"query": "{ user { id } }"}`,
+			statusCode: StatusBadRequest,
+			errors:     []string{`argument "id" of field user of type Int! is required`},
+		},
+		{
+			title:      "Syntax error",
+			body:       `{"query": "{ user(id: 1) { id }"}`,
+			statusCode: StatusBadRequest,
+			errors:     []string{"syntax error at 1:21: expected a name, found end of document"},
+		},
+		{
+			title:      "Too deep",
+			body:       `{"query": "{ todoItem(id: 1) { todoList { todoItems { todoList { todoItems { todoList { todoItems { todoList { todoItems { todoList { id } } } } } } } } } } }"}`,
+			statusCode: StatusBadRequest,
+			errors:     []string{"the query is nested deeper than 10 levels"},
+		},
+		{
+			title:      "Wrong body",
+			body:       `{"query": 1}`,
+			statusCode: StatusBadRequest,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.title, func(t *testing.T) {
+			controller, _, _ := newTestGraphQLController()
+			w, r := test.NewRequest("POST", "/graphql", []byte(tc.body))
+			test.MakeRequest("/graphql", controller.Query, w, r)
+			assert.Equal(t, tc.statusCode, w.Code)
+
+			var result graphQLResult
+			json.NewDecoder(w.Body).Decode(&result)
+			if tc.data != "" {
+				assert.JSONEq(t, tc.data, string(result.Data))
+			} else {
+				assert.Empty(t, result.Data)
+			}
+			messages := []string{}
+			for _, err := range result.Errors {
+				messages = append(messages, err.Message)
+			}
+			if tc.errors != nil {
+				assert.Equal(t, tc.errors, messages)
+			}
+		})
+	}
+}
+
+func TestGraphQLController_QueryBatching(t *testing.T) {
+	controller, todoListService, todoItemService := newTestGraphQLController()
+	body := `{"query": "{ users { username todoLists { name todoItems { title tags { text } } } } }"}`
+	w, r := test.NewRequest("POST", "/graphql", []byte(body))
+	test.MakeRequest("/graphql", controller.Query, w, r)
+	assert.Equal(t, StatusOK, w.Code)
+
+	var result graphQLResult
+	json.NewDecoder(w.Body).Decode(&result)
+	assert.Empty(t, result.Errors)
+	assert.JSONEq(t, `{"users":[
+		{"username":"user1","todoLists":[
+			{"name":"list1","todoItems":[{"title":"item1","tags":[]},{"title":"item2","tags":[]}]},
+			{"name":"list2","todoItems":[]}
+		]},
+		{"username":"user2","todoLists":[]}
+	]}`, string(result.Data))
+
+	// one call per depth however many users and todo lists there are
+	assert.Equal(t, 1, todoListService.BatchCalls)
+	assert.Equal(t, 1, todoItemService.BatchCalls)
+}
+
+func TestGraphQLController_Schema(t *testing.T) {
+	controller, _, _ := newTestGraphQLController()
+	w, r := test.NewRequest("GET", "/graphql/schema", nil)
+	test.MakeRequest("/graphql/schema", controller.Schema, w, r)
+	assert.Equal(t, StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "type Query {")
+	assert.Contains(t, w.Body.String(), "todoLists: [TodoList!]!")
+	assert.Contains(t, w.Body.String(), "input BulkOperationInput {")
+}
@@ -0,0 +1,9 @@
+package http
+
+import "github.com/gorilla/mux"
+
+// SetupGraphQLRoutes ...
+func SetupGraphQLRoutes(router *mux.Router, controller *GraphQLController) {
+	router.HandleFunc("/graphql", controller.Query).Methods("POST")
+	router.HandleFunc("/graphql/schema", controller.Schema).Methods("GET")
+}
@@ -0,0 +1,512 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/danikg/go-todo-rest-api/models"
+	"github.com/danikg/go-todo-rest-api/utils/graphql"
+)
+
+// graphQLMaxDepth limits the nesting of GraphQL queries
+const graphQLMaxDepth = 10
+
+// graphQLMaxFields limits the fields selected by GraphQL queries
+const graphQLMaxFields = 500
+
+type graphQLLoadersKey struct{}
+
+// graphQLLoaders batch the nested lists of a GraphQL request: the todo lists of all users
+// and the todo items of all todo lists of the same depth are loaded in one call each
+type graphQLLoaders struct {
+	todoLists *graphql.Loader[uint, []models.TodoList]
+	todoItems *graphql.Loader[uint, []models.TodoItem]
+}
+
+func (c *GraphQLController) newLoaders() *graphQLLoaders {
+	return &graphQLLoaders{
+		todoLists: graphql.NewLoader(func(ctx context.Context, userIDs []uint) (map[uint][]models.TodoList, error) {
+			todoLists, err := c.TodoListService.GetAllByUsers(ctx, userIDs)
+			result := map[uint][]models.TodoList{}
+			for _, todoList := range todoLists {
+				result[todoList.UserID] = append(result[todoList.UserID], todoList)
+			}
+			return result, err
+		}),
+		todoItems: graphql.NewLoader(func(ctx context.Context, listIDs []uint) (map[uint][]models.TodoItem, error) {
+			todoItems, err := c.TodoItemService.GetAllByLists(ctx, listIDs)
+			result := map[uint][]models.TodoItem{}
+			for _, todoItem := range todoItems {
+				result[todoItem.TodoListID] = append(result[todoItem.TodoListID], todoItem)
+			}
+			return result, err
+		}),
+	}
+}
+
+func loadersFromContext(ctx context.Context) *graphQLLoaders {
+	return ctx.Value(graphQLLoadersKey{}).(*graphQLLoaders)
+}
+
+// newGraphQLSchema mirrors the user, todo list, todo item and tag services,
+// fields without a resolver read the model field of the same name
+func (c *GraphQLController) newGraphQLSchema() *graphql.Schema {
+	user := &graphql.Object{
+		Name: "User",
+		Fields: []*graphql.Field{
+			{Name: "id", Type: "Int!"},
+			{Name: "username", Type: "String!"},
+			{Name: "createdAt", Type: "Time!"},
+			{Name: "updatedAt", Type: "Time!"},
+			{
+				Name: "todoLists", Type: "[TodoList!]!",
+				Resolve: func(p graphql.Params) (interface{}, error) {
+					return loadersFromContext(p.Context).todoLists.Load(p.Context, p.Source.(models.User).ID), nil
+				},
+			},
+		},
+	}
+
+	todoList := &graphql.Object{
+		Name: "TodoList",
+		Fields: []*graphql.Field{
+			{Name: "id", Type: "Int!"},
+			{Name: "name", Type: "String!"},
+			{Name: "userId", Type: "Int!"},
+			{Name: "version", Type: "Int!"},
+			{Name: "createdAt", Type: "Time!"},
+			{Name: "updatedAt", Type: "Time!"},
+			{
+				Name: "todoItems", Type: "[TodoItem!]!",
+				Resolve: func(p graphql.Params) (interface{}, error) {
+					return loadersFromContext(p.Context).todoItems.Load(p.Context, p.Source.(models.TodoList).ID), nil
+				},
+			},
+		},
+	}
+
+	todoItem := &graphql.Object{
+		Name: "TodoItem",
+		Fields: []*graphql.Field{
+			{Name: "id", Type: "Int!"},
+			{Name: "title", Type: "String!"},
+			{Name: "description", Type: "String!"},
+			{Name: "completed", Type: "Boolean!"},
+			{Name: "due", Type: "Time"},
+			{Name: "recurrence", Type: "String!"},
+			{Name: "todoListId", Type: "Int!"},
+			{Name: "version", Type: "Int!"},
+			{Name: "createdAt", Type: "Time!"},
+			{Name: "updatedAt", Type: "Time!"},
+			{
+				Name: "todoList", Type: "TodoList!",
+				Resolve: func(p graphql.Params) (interface{}, error) {
+					// the todo list is joined when todo items are read
+					item := p.Source.(models.TodoItem)
+					if item.TodoList.ID != 0 {
+						return item.TodoList, nil
+					}
+					return c.TodoListService.GetSingle(p.Context, item.TodoListID)
+				},
+			},
+			{Name: "tags", Type: "[Tag!]!"},
+		},
+	}
+
+	tag := &graphql.Object{
+		Name: "Tag",
+		Fields: []*graphql.Field{
+			{Name: "id", Type: "Int!"},
+			{Name: "text", Type: "String!"},
+			{Name: "color", Type: "String!"},
+			{Name: "parentId", Type: "Int"},
+			{Name: "userId", Type: "Int!"},
+			{Name: "version", Type: "Int!"},
+			{Name: "createdAt", Type: "Time!"},
+			{Name: "updatedAt", Type: "Time!"},
+		},
+	}
+
+	tagUsage := &graphql.Object{
+		Name: "TagUsage",
+		Fields: []*graphql.Field{
+			{Name: "tag", Type: "Tag!"},
+			{Name: "usageCount", Type: "Int!"},
+		},
+	}
+
+	bulkResult := &graphql.Object{
+		Name: "BulkResult",
+		Fields: []*graphql.Field{
+			{Name: "index", Type: "Int!"},
+			{Name: "id", Type: "Int!"},
+			{Name: "ok", Type: "Boolean!"},
+			{Name: "error", Type: "String!"},
+		},
+	}
+
+	bulkReport := &graphql.Object{
+		Name: "BulkReport",
+		Fields: []*graphql.Field{
+			{Name: "committed", Type: "Boolean!"},
+			{Name: "results", Type: "[BulkResult!]!"},
+		},
+	}
+
+	bulkOperation := &graphql.Input{
+		Name:        "BulkOperationInput",
+		Description: "op is one of update, complete, move, add_tag, remove_tag and delete",
+		Fields: []*graphql.Arg{
+			{Name: "op", Type: "String!"},
+			{Name: "id", Type: "Int!"},
+			{Name: "title", Type: "String"},
+			{Name: "description", Type: "String"},
+			{Name: "completed", Type: "Boolean"},
+			{Name: "listId", Type: "Int"},
+			{Name: "tag", Type: "String"},
+			{Name: "tagId", Type: "Int"},
+		},
+	}
+
+	page := []*graphql.Arg{{Name: "limit", Type: "Int"}, {Name: "offset", Type: "Int"}}
+
+	query := &graphql.Object{
+		Name: "Query",
+		Fields: []*graphql.Field{
+			{
+				Name: "users", Type: "[User!]!",
+				Resolve: func(p graphql.Params) (interface{}, error) {
+					return c.UserService.GetAll(p.Context)
+				},
+			},
+			{
+				Name: "user", Type: "User", Args: []*graphql.Arg{{Name: "id", Type: "Int!"}},
+				Resolve: func(p graphql.Params) (interface{}, error) {
+					return c.UserService.GetSingle(p.Context, argUint(p.Args, "id"))
+				},
+			},
+			{
+				Name: "todoList", Type: "TodoList", Args: []*graphql.Arg{{Name: "id", Type: "Int!"}},
+				Resolve: func(p graphql.Params) (interface{}, error) {
+					return c.TodoListService.GetSingle(p.Context, argUint(p.Args, "id"))
+				},
+			},
+			{
+				Name: "todoItem", Type: "TodoItem", Args: []*graphql.Arg{{Name: "id", Type: "Int!"}},
+				Resolve: func(p graphql.Params) (interface{}, error) {
+					return c.TodoItemService.GetSingle(p.Context, argUint(p.Args, "id"))
+				},
+			},
+			{
+				Name: "tag", Type: "Tag", Args: []*graphql.Arg{{Name: "id", Type: "Int!"}},
+				Resolve: func(p graphql.Params) (interface{}, error) {
+					return c.TagService.GetSingle(p.Context, argUint(p.Args, "id"))
+				},
+			},
+			{
+				Name: "tags", Description: "the tag vocabulary of the user with usage counts",
+				Type: "[TagUsage!]!", Args: []*graphql.Arg{{Name: "userId", Type: "Int!"}},
+				Resolve: func(p graphql.Params) (interface{}, error) {
+					return c.TagService.GetAllByUser(p.Context, argUint(p.Args, "userId"))
+				},
+			},
+			{
+				Name: "taggedTodoItems", Description: "the todo items carrying the tag or one of its descendants",
+				Type: "[TodoItem!]!", Args: append([]*graphql.Arg{{Name: "tagId", Type: "Int!"}}, page...),
+				Resolve: func(p graphql.Params) (interface{}, error) {
+					page, err := argPage(p.Args)
+					if err != nil {
+						return nil, err
+					}
+					return c.TagService.GetTodoItems(p.Context, argUint(p.Args, "tagId"), page)
+				},
+			},
+			{
+				Name: "userTodoItems", Description: "the todo items of the user carrying any or, with matchAll, all of the tags",
+				Type: "[TodoItem!]!",
+				Args: append([]*graphql.Arg{
+					{Name: "userId", Type: "Int!"},
+					{Name: "tags", Type: "[String!]"},
+					{Name: "matchAll", Type: "Boolean"},
+				}, page...),
+				Resolve: func(p graphql.Params) (interface{}, error) {
+					page, err := argPage(p.Args)
+					if err != nil {
+						return nil, err
+					}
+					filter := models.TagFilter{MatchAll: argBool(p.Args, "matchAll")}
+					tags, _ := p.Args["tags"].([]interface{})
+					for _, tag := range tags {
+						filter.Tags = append(filter.Tags, tag.(string))
+					}
+					return c.TagService.GetTodoItemsByUser(p.Context, argUint(p.Args, "userId"), filter, page)
+				},
+			},
+		},
+	}
+
+	mutation := &graphql.Object{
+		Name: "Mutation",
+		Fields: []*graphql.Field{
+			{
+				Name: "createUser", Type: "User!",
+				Args: []*graphql.Arg{{Name: "username", Type: "String!"}, {Name: "password", Type: "String"}},
+				Resolve: func(p graphql.Params) (interface{}, error) {
+					user := models.User{Username: argString(p.Args, "username"), Password: argString(p.Args, "password")}
+					err := c.UserService.Create(p.Context, &user)
+					return user, err
+				},
+			},
+			{
+				Name: "updateUser", Type: "User!",
+				Args: []*graphql.Arg{{Name: "id", Type: "Int!"}, {Name: "username", Type: "String"}, {Name: "password", Type: "String"}},
+				Resolve: func(p graphql.Params) (interface{}, error) {
+					userData := models.User{Username: argString(p.Args, "username"), Password: argString(p.Args, "password")}
+					return c.UserService.Update(p.Context, argUint(p.Args, "id"), &userData)
+				},
+			},
+			{
+				Name: "deleteUser", Type: "Boolean!", Args: []*graphql.Arg{{Name: "id", Type: "Int!"}},
+				Resolve: func(p graphql.Params) (interface{}, error) {
+					return deleted(c.UserService.Delete(p.Context, argUint(p.Args, "id")))
+				},
+			},
+			{
+				Name: "createTodoList", Type: "TodoList!",
+				Args: []*graphql.Arg{{Name: "userId", Type: "Int!"}, {Name: "name", Type: "String!"}},
+				Resolve: func(p graphql.Params) (interface{}, error) {
+					todoList := models.TodoList{Name: argString(p.Args, "name")}
+					err := c.TodoListService.Create(p.Context, argUint(p.Args, "userId"), &todoList)
+					return todoList, err
+				},
+			},
+			{
+				Name: "updateTodoList", Type: "TodoList!",
+				Args: []*graphql.Arg{{Name: "id", Type: "Int!"}, {Name: "name", Type: "String!"}},
+				Resolve: func(p graphql.Params) (interface{}, error) {
+					todoListData := models.TodoList{Name: argString(p.Args, "name")}
+					return c.TodoListService.Update(p.Context, argUint(p.Args, "id"), &todoListData)
+				},
+			},
+			{
+				Name: "deleteTodoList", Type: "Boolean!", Args: []*graphql.Arg{{Name: "id", Type: "Int!"}},
+				Resolve: func(p graphql.Params) (interface{}, error) {
+					return deleted(c.TodoListService.Delete(p.Context, argUint(p.Args, "id")))
+				},
+			},
+			{
+				Name: "createTodoItem", Type: "TodoItem!",
+				Args: []*graphql.Arg{
+					{Name: "listId", Type: "Int!"},
+					{Name: "title", Type: "String!"},
+					{Name: "description", Type: "String"},
+					{Name: "completed", Type: "Boolean"},
+					{Name: "due", Type: "Time"},
+					{Name: "recurrence", Type: "String"},
+				},
+				Resolve: func(p graphql.Params) (interface{}, error) {
+					todoItem := todoItemFromArgs(p.Args)
+					err := c.TodoItemService.Create(p.Context, argUint(p.Args, "listId"), &todoItem)
+					return todoItem, err
+				},
+			},
+			{
+				Name: "updateTodoItem", Type: "TodoItem!", Description: "fields left out are kept",
+				Args: []*graphql.Arg{
+					{Name: "id", Type: "Int!"},
+					{Name: "title", Type: "String"},
+					{Name: "description", Type: "String"},
+					{Name: "completed", Type: "Boolean"},
+					{Name: "due", Type: "Time"},
+					{Name: "recurrence", Type: "String"},
+				},
+				Resolve: func(p graphql.Params) (interface{}, error) {
+					todoItemData := todoItemUpdateFromArgs(p.Args)
+					return c.TodoItemService.Update(p.Context, argUint(p.Args, "id"), &todoItemData)
+				},
+			},
+			{
+				Name: "deleteTodoItem", Type: "Boolean!", Args: []*graphql.Arg{{Name: "id", Type: "Int!"}},
+				Resolve: func(p graphql.Params) (interface{}, error) {
+					return deleted(c.TodoItemService.Delete(p.Context, argUint(p.Args, "id")))
+				},
+			},
+			{
+				Name: "bulkTodoItems", Type: "BulkReport!",
+				Description: "applies the operations in one transaction, allOrNothing rolls all of them back when one fails",
+				Args: []*graphql.Arg{
+					{Name: "operations", Type: "[BulkOperationInput!]!"},
+					{Name: "allOrNothing", Type: "Boolean"},
+				},
+				Resolve: func(p graphql.Params) (interface{}, error) {
+					request := models.BulkRequest{AllOrNothing: argBool(p.Args, "allOrNothing")}
+					for _, value := range p.Args["operations"].([]interface{}) {
+						op := value.(map[string]interface{})
+						operation := models.BulkOperation{
+							Op:          argString(op, "op"),
+							ID:          argUint(op, "id"),
+							Title:       argString(op, "title"),
+							Description: argString(op, "description"),
+							ListID:      argUint(op, "listId"),
+							Tag:         argString(op, "tag"),
+							TagID:       argUint(op, "tagId"),
+						}
+						if completed, ok := op["completed"].(bool); ok {
+							operation.Completed = &completed
+						}
+						request.Operations = append(request.Operations, operation)
+					}
+					return c.TodoItemService.Bulk(p.Context, &request)
+				},
+			},
+			{
+				Name: "createTag", Type: "Tag!", Description: "attaches the tag to the todo item, creating it in the vocabulary of the user if missing",
+				Args: []*graphql.Arg{
+					{Name: "itemId", Type: "Int!"},
+					{Name: "text", Type: "String!"},
+					{Name: "color", Type: "String"},
+					{Name: "parentId", Type: "Int"},
+				},
+				Resolve: func(p graphql.Params) (interface{}, error) {
+					tag := tagFromArgs(p.Args)
+					err := c.TagService.Create(p.Context, argUint(p.Args, "itemId"), &tag)
+					return tag, err
+				},
+			},
+			{
+				Name: "createUserTag", Type: "Tag!",
+				Args: []*graphql.Arg{
+					{Name: "userId", Type: "Int!"},
+					{Name: "text", Type: "String!"},
+					{Name: "color", Type: "String"},
+					{Name: "parentId", Type: "Int"},
+				},
+				Resolve: func(p graphql.Params) (interface{}, error) {
+					tag := tagFromArgs(p.Args)
+					err := c.TagService.CreateForUser(p.Context, argUint(p.Args, "userId"), &tag)
+					return tag, err
+				},
+			},
+			{
+				Name: "updateTag", Type: "Tag!", Description: "replaces the tag, an omitted color or parent is cleared",
+				Args: []*graphql.Arg{
+					{Name: "id", Type: "Int!"},
+					{Name: "text", Type: "String!"},
+					{Name: "color", Type: "String"},
+					{Name: "parentId", Type: "Int"},
+				},
+				Resolve: func(p graphql.Params) (interface{}, error) {
+					tagData := tagFromArgs(p.Args)
+					return c.TagService.Update(p.Context, argUint(p.Args, "id"), &tagData)
+				},
+			},
+			{
+				Name: "mergeTags", Type: "Tag!", Description: "moves the todo items and child tags of the tag to the target tag and deletes the tag",
+				Args: []*graphql.Arg{{Name: "id", Type: "Int!"}, {Name: "targetId", Type: "Int!"}},
+				Resolve: func(p graphql.Params) (interface{}, error) {
+					return c.TagService.Merge(p.Context, argUint(p.Args, "id"), argUint(p.Args, "targetId"))
+				},
+			},
+			{
+				Name: "removeTag", Type: "Boolean!", Description: "detaches the tag from the todo item",
+				Args: []*graphql.Arg{{Name: "itemId", Type: "Int!"}, {Name: "tagId", Type: "Int!"}},
+				Resolve: func(p graphql.Params) (interface{}, error) {
+					return deleted(c.TagService.Remove(p.Context, argUint(p.Args, "itemId"), argUint(p.Args, "tagId")))
+				},
+			},
+			{
+				Name: "deleteTag", Type: "Boolean!", Args: []*graphql.Arg{{Name: "id", Type: "Int!"}},
+				Resolve: func(p graphql.Params) (interface{}, error) {
+					return deleted(c.TagService.Delete(p.Context, argUint(p.Args, "id")))
+				},
+			},
+		},
+	}
+
+	schema := graphql.NewSchema(query, mutation,
+		[]*graphql.Object{user, todoList, todoItem, tag, tagUsage, bulkReport, bulkResult},
+		[]*graphql.Input{bulkOperation})
+	schema.MaxDepth = graphQLMaxDepth
+	schema.MaxFields = graphQLMaxFields
+	return schema
+}
+
+func todoItemFromArgs(args map[string]interface{}) models.TodoItem {
+	todoItem := models.TodoItem{
+		Title:       argString(args, "title"),
+		Description: argString(args, "description"),
+		Completed:   argBool(args, "completed"),
+		Recurrence:  argString(args, "recurrence"),
+	}
+	if due, ok := args["due"].(time.Time); ok {
+		todoItem.Due = &due
+	}
+	return todoItem
+}
+
+func todoItemUpdateFromArgs(args map[string]interface{}) models.TodoItemUpdate {
+	todoItemData := models.TodoItemUpdate{
+		Title:       argString(args, "title"),
+		Description: argString(args, "description"),
+		Recurrence:  argString(args, "recurrence"),
+	}
+	if completed, ok := args["completed"].(bool); ok {
+		todoItemData.Completed = &completed
+	}
+	if due, ok := args["due"].(time.Time); ok {
+		todoItemData.Due = &due
+	}
+	return todoItemData
+}
+
+func tagFromArgs(args map[string]interface{}) models.Tag {
+	tag := models.Tag{Text: argString(args, "text"), Color: argString(args, "color")}
+	if _, ok := args["parentId"].(int); ok {
+		parentID := argUint(args, "parentId")
+		tag.ParentID = &parentID
+	}
+	return tag
+}
+
+// deleted turns the result of a deletion into the Boolean of the mutation
+func deleted(err error) (interface{}, error) {
+	return err == nil, err
+}
+
+func argUint(args map[string]interface{}, name string) uint {
+	n, _ := args[name].(int)
+	if n < 0 {
+		return 0
+	}
+	return uint(n)
+}
+
+func argString(args map[string]interface{}, name string) string {
+	s, _ := args[name].(string)
+	return s
+}
+
+func argBool(args map[string]interface{}, name string) bool {
+	b, _ := args[name].(bool)
+	return b
+}
+
+// argPage applies the defaults and limits of the limit and offset query parameters
+func argPage(args map[string]interface{}) (models.Page, error) {
+	page := models.Page{Limit: models.DefaultPageLimit}
+	if limit, ok := args["limit"].(int); ok {
+		if limit < 1 || limit > models.MaxPageLimit {
+			return page, fmt.Errorf("limit must be between 1 and %d", models.MaxPageLimit)
+		}
+		page.Limit = limit
+	}
+	if offset, ok := args["offset"].(int); ok {
+		if offset < 0 {
+			return page, errors.New("offset must be a non-negative integer")
+		}
+		page.Offset = offset
+	}
+	return page, nil
+}
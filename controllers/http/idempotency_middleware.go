@@ -0,0 +1,175 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/danikg/go-todo-rest-api/models"
+	"github.com/danikg/go-todo-rest-api/repositories"
+	"github.com/danikg/go-todo-rest-api/utils/auth"
+	"github.com/danikg/go-todo-rest-api/utils/logger"
+	"github.com/danikg/go-todo-rest-api/utils/response"
+	"github.com/gorilla/mux"
+)
+
+// IdempotencyKeyHeader carries the client chosen key of a POST request
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotentReplayedHeader is set on responses replayed from an earlier request
+const IdempotentReplayedHeader = "Idempotent-Replayed"
+
+// maxIdempotencyKeyLength bounds the accepted keys
+const maxIdempotencyKeyLength = 255
+
+// maxIdempotentBodySize is the largest body read to deduplicate a request, the import files are the largest bodies
+const maxIdempotentBodySize = maxImportSize
+
+// secretRoutes respond with a bearer token, a feed token or a signing secret,
+// their responses are not stored so the key is ignored there
+var secretRoutes = map[string]bool{
+	"POST /auth/token":               true,
+	"POST /users/{id}/feed":          true,
+	"POST /users/{user_id}/webhooks": true,
+}
+
+var (
+	errIdempotencyKeyTooLong    = errors.New("idempotency key is too long")
+	errIdempotencyKeyReused     = errors.New("idempotency key was already used for a different request")
+	errIdempotencyKeyInProgress = errors.New("a request with this idempotency key is still in progress")
+)
+
+// IdempotencyMiddleware replays the stored response when a POST request is retried with the same Idempotency-Key.
+// Keys are scoped by the authenticated user, or by the client address without authentication,
+// reusing a key for another request is rejected with 422 and larger bodies than maxIdempotentBodySize with 413.
+// The routes responding with secrets are not deduplicated
+func IdempotencyMiddleware(store repositories.IIdempotencyRepository, ttl time.Duration) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(IdempotencyKeyHeader)
+			if r.Method != http.MethodPost || key == "" || secretRoutes[r.Method+" "+routeTemplate(r)] {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > maxIdempotencyKeyLength {
+				response.SendErrorResponse(w, http.StatusBadRequest, errIdempotencyKeyTooLong)
+				return
+			}
+
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxIdempotentBodySize))
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				response.SendErrorResponse(w, http.StatusRequestEntityTooLarge, err)
+				return
+			}
+			if err != nil {
+				response.SendErrorResponse(w, http.StatusBadRequest, err)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			ctx := r.Context()
+			userID, ok := auth.UserID(ctx)
+			if !ok {
+				// anonymous clients do not share their keys
+				key = clientHost(r) + " " + key
+			}
+			hash := sha256.Sum256(body)
+			now := time.Now()
+			record := models.IdempotencyKey{
+				UserID:      userID,
+				Key:         key,
+				Method:      r.Method,
+				Path:        r.URL.Path,
+				RequestHash: hex.EncodeToString(hash[:]),
+				CreatedAt:   now,
+				ExpiresAt:   now.Add(ttl),
+			}
+
+			claimed, err := store.Claim(ctx, &record)
+			if err != nil {
+				response.SendErrorResponse(w, http.StatusInternalServerError, err)
+				return
+			}
+			if !claimed {
+				replayIdempotentResponse(w, r, store, &record)
+				return
+			}
+
+			rec := &responseCapture{statusRecorder: statusRecorder{ResponseWriter: w}}
+			defer func() {
+				// the response is lost when the handler panics, let the client retry
+				if p := recover(); p != nil {
+					releaseIdempotencyKey(ctx, store, &record)
+					panic(p)
+				}
+			}()
+			next.ServeHTTP(rec, r)
+
+			if rec.Status() >= http.StatusInternalServerError {
+				releaseIdempotencyKey(ctx, store, &record)
+				return
+			}
+
+			record.StatusCode = rec.Status()
+			record.ContentType = rec.Header().Get("Content-Type")
+			record.Body = rec.body.Bytes()
+			if err = store.Complete(context.WithoutCancel(ctx), &record); err != nil {
+				logger.FromContext(ctx).Error("failed to store idempotent response", "error", err)
+			}
+		})
+	}
+}
+
+// replayIdempotentResponse answers a retried request from the stored key
+func replayIdempotentResponse(w http.ResponseWriter, r *http.Request, store repositories.IIdempotencyRepository, record *models.IdempotencyKey) {
+	stored, err := store.Get(r.Context(), record.UserID, record.Key)
+	switch {
+	case err != nil:
+		response.SendErrorResponse(w, http.StatusInternalServerError, err)
+	case stored.Method != record.Method || stored.Path != record.Path || stored.RequestHash != record.RequestHash:
+		response.SendErrorResponse(w, http.StatusUnprocessableEntity, errIdempotencyKeyReused)
+	case stored.StatusCode == 0:
+		response.SendErrorResponse(w, http.StatusConflict, errIdempotencyKeyInProgress)
+	default:
+		if stored.ContentType != "" {
+			w.Header().Set("Content-Type", stored.ContentType)
+		}
+		w.Header().Set(IdempotentReplayedHeader, "true")
+		w.WriteHeader(stored.StatusCode)
+		w.Write(stored.Body)
+	}
+}
+
+// clientHost returns the address of the client without its port
+func clientHost(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func releaseIdempotencyKey(ctx context.Context, store repositories.IIdempotencyRepository, record *models.IdempotencyKey) {
+	if err := store.Release(context.WithoutCancel(ctx), record); err != nil {
+		logger.FromContext(ctx).Error("failed to release idempotency key", "error", err)
+	}
+}
+
+// responseCapture keeps a copy of the response body
+type responseCapture struct {
+	statusRecorder
+	body bytes.Buffer
+}
+
+// Write ...
+func (c *responseCapture) Write(b []byte) (int, error) {
+	c.body.Write(b)
+	return c.statusRecorder.Write(b)
+}
@@ -0,0 +1,78 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/danikg/go-todo-rest-api/services"
+	"github.com/danikg/go-todo-rest-api/utils/importer"
+	"github.com/danikg/go-todo-rest-api/utils/response"
+	"github.com/danikg/go-todo-rest-api/utils/route"
+)
+
+// maxImportSize is the largest import file accepted
+const maxImportSize = 10 << 20
+
+// defaultImportList receives the imported todo items that name no list
+const defaultImportList = "Inbox"
+
+// ImportController ...
+type ImportController struct {
+	importService services.IImportService
+}
+
+// NewImportController ...
+func NewImportController(importService services.IImportService) *ImportController {
+	return &ImportController{importService: importService}
+}
+
+// Import reads a todotxt, csv or json file from the body into the todo lists of the user,
+// with dry_run=true only the preview is returned
+func (c *ImportController) Import(w http.ResponseWriter, r *http.Request) {
+	id, err := route.GetRouteVar(r, "id")
+	if err != nil {
+		response.SendErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	query := r.URL.Query()
+	dryRun := false
+	if value := query.Get("dry_run"); value != "" {
+		if dryRun, err = strconv.ParseBool(value); err != nil {
+			response.SendErrorResponse(w, http.StatusBadRequest, errors.New("dry_run must be true or false"))
+			return
+		}
+	}
+
+	options := importer.Options{DefaultList: query.Get("list")}
+	if options.DefaultList == "" {
+		options.DefaultList = defaultImportList
+	}
+	if options.Columns, err = importer.ParseColumns(query.Get("columns")); err != nil {
+		response.SendErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	body := http.MaxBytesReader(w, r.Body, maxImportSize)
+	report, err := c.importService.Import(r.Context(), id, query.Get("format"), body, options, dryRun)
+
+	var tooLarge *http.MaxBytesError
+	switch {
+	case err == nil:
+		status := http.StatusCreated
+		if dryRun {
+			status = http.StatusOK
+		}
+		response.SendResponse(w, report, status)
+	case errors.Is(err, services.ErrInvalidImport) && report.Errors != nil:
+		// the report tells which lines to fix
+		response.SendResponse(w, report, http.StatusUnprocessableEntity)
+	case errors.As(err, &tooLarge):
+		response.SendErrorResponse(w, http.StatusRequestEntityTooLarge, err)
+	case errors.Is(err, services.ErrInvalidImport), errors.Is(err, importer.ErrUnknownFormat):
+		response.SendErrorResponse(w, http.StatusBadRequest, err)
+	default:
+		response.SendErrorResponse(w, tagErrorStatus(err, http.StatusNotFound), err)
+	}
+}
@@ -0,0 +1,104 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	. "net/http"
+	"testing"
+
+	"github.com/danikg/go-todo-rest-api/models"
+	"github.com/danikg/go-todo-rest-api/services/mocks"
+	"github.com/danikg/go-todo-rest-api/utils/test"
+	"github.com/stretchr/testify/assert"
+)
+
+type importTest struct {
+	title      string
+	path       string
+	body       []byte
+	statusCode int
+	report     *models.ImportReport
+}
+
+func TestImportController_Import(t *testing.T) {
+	tests := []importTest{
+		{
+			title:      "Import todo.txt",
+			path:       "/users/1/import?format=todotxt",
+			body:       []byte("(A) Call mom +family @phone\nBuy milk\n"),
+			statusCode: StatusCreated,
+			report:     &models.ImportReport{Committed: true, Items: 2, Errors: []models.ImportError{}},
+		},
+		{
+			title:      "Import csv with a column mapping, dry run",
+			path:       "/users/1/import?format=csv&dry_run=true&columns=title:Task,completed:Done",
+			body:       []byte("Task,Done\nMilk,yes\n,no\n"),
+			statusCode: StatusOK,
+			report:     &models.ImportReport{DryRun: true, Items: 1, Errors: []models.ImportError{{Line: 3, Error: "missing title"}}},
+		},
+		{
+			title:      "Import json with unreadable items",
+			path:       "/users/1/import?format=json",
+			body:       []byte(`{"Lists":[{"Name":"list","Items":[{"Title":""}]}]}`),
+			statusCode: StatusUnprocessableEntity,
+			report:     &models.ImportReport{Errors: []models.ImportError{{Line: 1, Error: "missing title"}}},
+		},
+		{
+			title:      "Import, invalid json",
+			path:       "/users/1/import?format=json",
+			body:       []byte(`{"Lists":`),
+			statusCode: StatusBadRequest,
+		},
+		{
+			title:      "Import, unknown format",
+			path:       "/users/1/import?format=xml",
+			body:       []byte("item"),
+			statusCode: StatusBadRequest,
+		},
+		{
+			title:      "Import, invalid dry_run",
+			path:       "/users/1/import?format=todotxt&dry_run=maybe",
+			body:       []byte("item"),
+			statusCode: StatusBadRequest,
+		},
+		{
+			title:      "Import, invalid column mapping",
+			path:       "/users/1/import?format=csv&columns=owner:Owner",
+			body:       []byte("title\nitem\n"),
+			statusCode: StatusBadRequest,
+		},
+		{
+			title:      "Import, file too large",
+			path:       "/users/1/import?format=todotxt",
+			body:       bytes.Repeat([]byte("item\n"), maxImportSize/5+1),
+			statusCode: StatusRequestEntityTooLarge,
+		},
+		{
+			title:      "Import, wrong id",
+			path:       "/users/a/import?format=todotxt",
+			body:       []byte("item"),
+			statusCode: StatusBadRequest,
+		},
+		{
+			title:      "Import, non-existent user",
+			path:       "/users/2/import?format=todotxt",
+			body:       []byte("item"),
+			statusCode: StatusNotFound,
+		},
+	}
+
+	importController := NewImportController(&mocks.ImportServiceMock{})
+	for _, tc := range tests {
+		t.Run(tc.title, func(t *testing.T) {
+			w, r := test.NewRequest("POST", tc.path, tc.body)
+			test.MakeRequest("/users/{id}/import", importController.Import, w, r)
+			assert.Equal(t, tc.statusCode, w.Code)
+
+			if tc.report != nil {
+				report := models.ImportReport{}
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+				assert.Equal(t, *tc.report, report)
+			}
+		})
+	}
+}
@@ -0,0 +1,8 @@
+package http
+
+import "github.com/gorilla/mux"
+
+// SetupImportRoutes ...
+func SetupImportRoutes(router *mux.Router, controller *ImportController) {
+	router.HandleFunc("/users/{id}/import", controller.Import).Methods("POST")
+}
@@ -0,0 +1,11 @@
+package http
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// SetupMetricsRoutes ...
+func SetupMetricsRoutes(router *mux.Router) {
+	router.Handle("/metrics", promhttp.Handler()).Methods("GET")
+}
@@ -0,0 +1,379 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	. "net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danikg/go-todo-rest-api/config"
+	"github.com/danikg/go-todo-rest-api/models"
+	"github.com/danikg/go-todo-rest-api/repositories"
+	repomocks "github.com/danikg/go-todo-rest-api/repositories/mocks"
+	"github.com/danikg/go-todo-rest-api/services/mocks"
+	"github.com/danikg/go-todo-rest-api/utils/auth"
+	"github.com/danikg/go-todo-rest-api/utils/logger"
+	"github.com/danikg/go-todo-rest-api/utils/metrics"
+	"github.com/danikg/go-todo-rest-api/utils/requestid"
+	"github.com/danikg/go-todo-rest-api/utils/test"
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+func newLoggedRouter(buf *bytes.Buffer) *mux.Router {
+	router := mux.NewRouter()
+	SetupMiddlewares(router, logger.New(buf), config.Default(), &mocks.UserServiceMock{}, &repomocks.IdempotencyRepositoryMock{})
+	router.HandleFunc("/users/{id}", func(w ResponseWriter, r *Request) {
+		logger.SetUser(r.Context(), "user1")
+		logger.FromContext(r.Context()).Info("inside handler")
+		w.WriteHeader(StatusTeapot)
+		w.Write([]byte("body"))
+	}).Methods("GET")
+	return router
+}
+
+func decodeLogLines(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	var lines []map[string]interface{}
+	dec := json.NewDecoder(buf)
+	for dec.More() {
+		line := map[string]interface{}{}
+		assert.NoError(t, dec.Decode(&line))
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func TestMiddleware_RequestID(t *testing.T) {
+	tests := []struct {
+		title     string
+		requestID string
+		preserved bool
+	}{
+		{title: "Propagate client request id", requestID: "abc-123", preserved: true},
+		{title: "Generate missing request id", requestID: "", preserved: false},
+		{title: "Replace invalid request id", requestID: "bad id", preserved: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.title, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			w, r := test.NewRequest("GET", "/users/1", nil)
+			if tc.requestID != "" {
+				r.Header.Set(requestid.Header, tc.requestID)
+			}
+			newLoggedRouter(buf).ServeHTTP(w, r)
+
+			id := w.Header().Get(requestid.Header)
+			assert.True(t, requestid.Valid(id))
+			if tc.preserved {
+				assert.Equal(t, tc.requestID, id)
+			} else {
+				assert.NotEqual(t, tc.requestID, id)
+			}
+
+			for _, line := range decodeLogLines(t, buf) {
+				assert.Equal(t, id, line["request_id"])
+			}
+		})
+	}
+}
+
+func TestMiddleware_Logging(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w, r := test.NewRequest("GET", "/users/1", nil)
+	newLoggedRouter(buf).ServeHTTP(w, r)
+
+	lines := decodeLogLines(t, buf)
+	assert.Len(t, lines, 2)
+	assert.Equal(t, "inside handler", lines[0]["msg"])
+
+	access := lines[1]
+	assert.Equal(t, "request", access["msg"])
+	assert.Equal(t, "GET", access["method"])
+	assert.Equal(t, "/users/{id}", access["route"])
+	assert.Equal(t, float64(StatusTeapot), access["status"])
+	assert.Equal(t, float64(4), access["bytes"])
+	assert.Equal(t, "user1", access["user"])
+	assert.Contains(t, access, "latency_ms")
+}
+
+func TestMiddleware_LoggingNotFound(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w, r := test.NewRequest("GET", "/unknown", nil)
+	newLoggedRouter(buf).ServeHTTP(w, r)
+	assert.Equal(t, StatusNotFound, w.Code)
+
+	lines := decodeLogLines(t, buf)
+	assert.Len(t, lines, 1)
+	assert.Equal(t, float64(StatusNotFound), lines[0]["status"])
+	assert.NotEmpty(t, w.Header().Get(requestid.Header))
+}
+
+func TestMiddleware_Metrics(t *testing.T) {
+	counter := metrics.HTTPRequests.WithLabelValues("GET", "/users/{id}", "418")
+	before := testutil.ToFloat64(counter)
+
+	w, r := test.NewRequest("GET", "/users/1", nil)
+	newLoggedRouter(&bytes.Buffer{}).ServeHTTP(w, r)
+	assert.Equal(t, before+1, testutil.ToFloat64(counter))
+
+	router := mux.NewRouter()
+	SetupMetricsRoutes(router)
+	w, r = test.NewRequest("GET", "/metrics", nil)
+	router.ServeHTTP(w, r)
+	assert.Equal(t, StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `todo_api_http_requests_total{method="GET",route="/users/{id}",status="418"}`)
+}
+
+func TestMiddleware_Tracing(t *testing.T) {
+	exporter := test.NewSpanExporter()
+	traceID := "4bf92f3577b34da6a3ce929d0e0e4736"
+
+	w, r := test.NewRequest("GET", "/users/1", nil)
+	r.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	buf := &bytes.Buffer{}
+	newLoggedRouter(buf).ServeHTTP(w, r)
+
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "GET /users/{id}", spans[0].Name)
+	assert.Equal(t, traceID, spans[0].SpanContext.TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", spans[0].Parent.SpanID().String())
+	assert.Contains(t, spans[0].Attributes, semconv.HTTPResponseStatusCode(StatusTeapot))
+
+	for _, line := range decodeLogLines(t, buf) {
+		assert.Equal(t, traceID, line["trace_id"])
+	}
+}
+
+func TestMiddleware_CORS(t *testing.T) {
+	cfg := config.Default()
+	cfg.CORS.AllowedOrigins = []string{"https://app.example.com"}
+	router := mux.NewRouter()
+	SetupMiddlewares(router, logger.New(&bytes.Buffer{}), cfg, &mocks.UserServiceMock{}, &repomocks.IdempotencyRepositoryMock{})
+	router.HandleFunc("/users", func(w ResponseWriter, r *Request) {}).Methods("GET")
+
+	w, r := test.NewRequest("OPTIONS", "/users", nil)
+	r.Header.Set("Origin", "https://app.example.com")
+	r.Header.Set("Access-Control-Request-Method", "GET")
+	router.ServeHTTP(w, r)
+	assert.Equal(t, StatusNoContent, w.Code)
+	assert.Equal(t, "https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "GET, POST, PUT, DELETE", w.Header().Get("Access-Control-Allow-Methods"))
+
+	w, r = test.NewRequest("GET", "/users", nil)
+	r.Header.Set("Origin", "https://evil.example.com")
+	router.ServeHTTP(w, r)
+	assert.Equal(t, StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestMiddleware_Auth(t *testing.T) {
+	validToken := auth.Issue(testSecret, 1, 0, time.Now().Add(time.Hour))
+	expiredToken := auth.Issue(testSecret, 1, 0, time.Now().Add(-time.Hour))
+	revokedToken := auth.Issue(testSecret, 1, 1, time.Now().Add(time.Hour))
+
+	tests := []struct {
+		title      string
+		enabled    bool
+		method     string
+		path       string
+		token      string
+		statusCode int
+		user       string
+	}{
+		{title: "Valid token", enabled: true, method: "GET", path: "/users", token: validToken, statusCode: StatusOK, user: "1"},
+		{title: "Missing token", enabled: true, method: "GET", path: "/users", statusCode: StatusUnauthorized},
+		{title: "Expired token", enabled: true, method: "GET", path: "/users", token: expiredToken, statusCode: StatusUnauthorized},
+		{title: "Revoked token", enabled: true, method: "GET", path: "/users", token: revokedToken, statusCode: StatusUnauthorized},
+		{title: "Forged token", enabled: true, method: "GET", path: "/users", token: validToken + "x", statusCode: StatusUnauthorized},
+		{title: "Public route", enabled: true, method: "POST", path: "/users", statusCode: StatusOK},
+		{title: "Public route ignores revoked token", enabled: true, method: "POST", path: "/users", token: revokedToken, statusCode: StatusOK},
+		{title: "Auth disabled", enabled: false, method: "GET", path: "/users", statusCode: StatusOK},
+		{title: "Auth disabled, token identifies user", enabled: false, method: "GET", path: "/users", token: validToken, statusCode: StatusOK, user: "1"},
+		{title: "Query token on event stream", enabled: true, method: "GET", path: "/events?access_token=" + validToken, statusCode: StatusOK, user: "1"},
+		{title: "Query token elsewhere", enabled: true, method: "GET", path: "/users?access_token=" + validToken, statusCode: StatusUnauthorized},
+		{title: "Calendar feed", enabled: true, method: "GET", path: "/feeds/token1.ics", statusCode: StatusOK},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.title, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.Auth.Enabled = tc.enabled
+			cfg.Auth.Secret = testSecret
+
+			buf := &bytes.Buffer{}
+			router := mux.NewRouter()
+			SetupMiddlewares(router, logger.New(buf), cfg, &mocks.UserServiceMock{}, &repomocks.IdempotencyRepositoryMock{})
+			handler := func(w ResponseWriter, r *Request) {
+				assert.Equal(t, tc.enabled, auth.Required(r.Context()))
+				userID, ok := auth.UserID(r.Context())
+				assert.Equal(t, tc.user != "", ok)
+				if ok {
+					assert.Equal(t, tc.user, strconv.FormatUint(uint64(userID), 10))
+				}
+			}
+			router.HandleFunc("/users", handler).Methods("GET", "POST")
+			router.HandleFunc("/events", handler).Methods("GET")
+			router.HandleFunc("/feeds/{token}.ics", handler).Methods("GET")
+
+			w, r := test.NewRequest(tc.method, tc.path, nil)
+			if tc.token != "" {
+				r.Header.Set("Authorization", "Bearer "+tc.token)
+			}
+			router.ServeHTTP(w, r)
+			assert.Equal(t, tc.statusCode, w.Code)
+
+			lines := decodeLogLines(t, buf)
+			assert.Equal(t, tc.user, lines[len(lines)-1]["user"])
+		})
+	}
+}
+
+func TestMiddleware_ReadReplica(t *testing.T) {
+	router := mux.NewRouter()
+	SetupMiddlewares(router, logger.New(&bytes.Buffer{}), config.Default(), &mocks.UserServiceMock{}, &repomocks.IdempotencyRepositoryMock{})
+	router.HandleFunc("/users", func(w ResponseWriter, r *Request) {
+		assert.Equal(t, r.Method == "GET", repositories.UseReadReplica(r.Context()))
+	}).Methods("GET", "POST")
+
+	for _, method := range []string{"GET", "POST"} {
+		w, r := test.NewRequest(method, "/users", nil)
+		router.ServeHTTP(w, r)
+		assert.Equal(t, StatusOK, w.Code)
+	}
+}
+
+func TestMiddleware_Idempotency(t *testing.T) {
+	store := &repomocks.IdempotencyRepositoryMock{}
+	cfg := config.Default()
+	cfg.Auth.Secret = testSecret
+
+	calls := 0
+	router := mux.NewRouter()
+	SetupMiddlewares(router, logger.New(&bytes.Buffer{}), cfg, &mocks.UserServiceMock{}, store)
+	router.HandleFunc("/todo_lists/{list_id}/todo_items", func(w ResponseWriter, r *Request) {
+		calls++
+		if r.Header.Get("X-Fail") != "" {
+			w.WriteHeader(StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(StatusCreated)
+		w.Write([]byte(`{"ID": ` + strconv.Itoa(calls) + `}`))
+	}).Methods("POST")
+
+	remoteAddr := ""
+	post := func(key, body, token string, fail bool) *httptest.ResponseRecorder {
+		w, r := test.NewRequest("POST", "/todo_lists/1/todo_items", []byte(body))
+		if remoteAddr != "" {
+			r.RemoteAddr = remoteAddr
+		}
+		if key != "" {
+			r.Header.Set(IdempotencyKeyHeader, key)
+		}
+		if token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		if fail {
+			r.Header.Set("X-Fail", "1")
+		}
+		router.ServeHTTP(w, r)
+		return w
+	}
+
+	w := post("key1", `{"Title": "item"}`, "", false)
+	assert.Equal(t, StatusCreated, w.Code)
+	assert.Equal(t, `{"ID": 1}`, w.Body.String())
+	assert.Empty(t, w.Header().Get(IdempotentReplayedHeader))
+
+	w = post("key1", `{"Title": "item"}`, "", false)
+	assert.Equal(t, StatusCreated, w.Code)
+	assert.Equal(t, `{"ID": 1}`, w.Body.String())
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.Equal(t, "true", w.Header().Get(IdempotentReplayedHeader))
+	assert.Equal(t, 1, calls)
+
+	w = post("key1", `{"Title": "other"}`, "", false)
+	assert.Equal(t, StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, 1, calls)
+
+	// keys are scoped by user
+	w = post("key1", `{"Title": "item"}`, auth.Issue(testSecret, 2, 0, time.Now().Add(time.Hour)), false)
+	assert.Equal(t, StatusCreated, w.Code)
+	assert.Equal(t, `{"ID": 2}`, w.Body.String())
+
+	// anonymous keys are scoped by the client address
+	remoteAddr = "198.51.100.7:4321"
+	w = post("key1", `{"Title": "item"}`, "", false)
+	assert.Equal(t, StatusCreated, w.Code)
+	assert.Equal(t, `{"ID": 3}`, w.Body.String())
+	remoteAddr = ""
+
+	// requests without a key are not deduplicated
+	post("", `{"Title": "item"}`, "", false)
+	post("", `{"Title": "item"}`, "", false)
+	assert.Equal(t, 5, calls)
+
+	// server errors release the key so that the retry is handled
+	w = post("key2", `{"Title": "item"}`, "", true)
+	assert.Equal(t, StatusInternalServerError, w.Code)
+	w = post("key2", `{"Title": "item"}`, "", false)
+	assert.Equal(t, StatusCreated, w.Code)
+	assert.Equal(t, 7, calls)
+
+	// a request still in progress is not handled twice
+	now := time.Now()
+	hash := sha256.Sum256([]byte(`{"Title": "item"}`))
+	claimed, err := store.Claim(context.Background(), &models.IdempotencyKey{
+		Key:         "192.0.2.1 key3",
+		Method:      "POST",
+		Path:        "/todo_lists/1/todo_items",
+		RequestHash: hex.EncodeToString(hash[:]),
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(time.Hour),
+	})
+	assert.NoError(t, err)
+	assert.True(t, claimed)
+	w = post("key3", `{"Title": "item"}`, "", false)
+	assert.Equal(t, StatusConflict, w.Code)
+	assert.Equal(t, 7, calls)
+
+	// expired keys can be used again
+	deleted, err := store.DeleteExpired(context.Background(), now.Add(2*cfg.Idempotency.TTL))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), deleted)
+	w = post("key1", `{"Title": "other"}`, "", false)
+	assert.Equal(t, StatusCreated, w.Code)
+
+	w = post(strings.Repeat("k", 256), `{"Title": "item"}`, "", false)
+	assert.Equal(t, StatusBadRequest, w.Code)
+	w = post("key5", strings.Repeat("k", maxIdempotentBodySize+1), "", false)
+	assert.Equal(t, StatusRequestEntityTooLarge, w.Code)
+	assert.Equal(t, 8, calls)
+
+	// the responses carrying secrets are not stored
+	tokens := 0
+	router.HandleFunc("/auth/token", func(w ResponseWriter, r *Request) {
+		tokens++
+		w.WriteHeader(StatusCreated)
+	}).Methods("POST")
+	for i := 0; i < 2; i++ {
+		w, r := test.NewRequest("POST", "/auth/token", []byte(`{"Username": "user1"}`))
+		r.Header.Set(IdempotencyKeyHeader, "key4")
+		router.ServeHTTP(w, r)
+		assert.Empty(t, w.Header().Get(IdempotentReplayedHeader))
+	}
+	assert.Equal(t, 2, tokens)
+	_, err = store.Get(context.Background(), 0, "192.0.2.1 key4")
+	assert.Error(t, err)
+}
@@ -0,0 +1,245 @@
+package http
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/danikg/go-todo-rest-api/models"
+	"github.com/danikg/go-todo-rest-api/utils/graphql"
+	"github.com/danikg/go-todo-rest-api/utils/openapi"
+	"github.com/danikg/go-todo-rest-api/utils/response"
+	"gorm.io/gorm"
+)
+
+// Media types of the documented request and response bodies
+const (
+	mediaJSON     = "application/json"
+	mediaText     = "text/plain"
+	mediaCSV      = "text/csv"
+	mediaMarkdown = "text/markdown"
+	mediaCalendar = "text/calendar"
+	mediaEvents   = "text/event-stream"
+	mediaHTML     = "text/html"
+)
+
+// apiOperation describes a route of the API, the path parameters are read from the path.
+// Results are sent with status, error responses carry a response.HTTPError
+type apiOperation struct {
+	method  string
+	path    string
+	tag     string
+	summary string
+	query   []*openapi.Parameter
+	body    interface{}
+	// bodyTypes default to json
+	bodyTypes []string
+	status    int
+	result    interface{}
+	// resultTypes default to json, results without types and result are empty
+	resultTypes []string
+	// results sent with other statuses, like the report of a failed bulk request
+	otherResults map[int]string
+	errors       []int
+}
+
+var (
+	pageParameters = []*openapi.Parameter{
+		queryParameter("limit", "page size, 1 to 100, default 50", &openapi.Schema{Type: "integer"}),
+		queryParameter("offset", "number of records to skip", &openapi.Schema{Type: "integer"}),
+	}
+	tagFilterParameters = append([]*openapi.Parameter{
+		queryParameter("tag", "tag texts, comma separated or repeated", &openapi.Schema{Type: "string"}),
+		queryParameter("match", "any (default) or all of the tags", &openapi.Schema{Type: "string", Enum: []string{"any", "all"}}),
+	}, pageParameters...)
+)
+
+// apiOperations lists every route registered by the Setup*Routes functions
+var apiOperations = []apiOperation{
+	{method: "GET", path: "/openapi.json", tag: "docs", summary: "This document", status: 200, result: map[string]interface{}{}},
+	{method: "GET", path: "/docs", tag: "docs", summary: "Swagger UI for this document", status: 200, resultTypes: []string{mediaHTML}},
+	{method: "GET", path: "/metrics", tag: "monitoring", summary: "Prometheus metrics", status: 200, resultTypes: []string{mediaText}},
+
+	{method: "POST", path: "/auth/token", tag: "auth", summary: "Issue an access token", body: Credentials{}, status: 201, result: Token{}, errors: []int{400, 401, 501}},
+
+	{method: "GET", path: "/users", tag: "users", summary: "List the users", status: 200, result: []models.User{}, errors: []int{500}},
+	{method: "POST", path: "/users", tag: "users", summary: "Create a user", body: models.User{}, status: 201, result: models.User{}, errors: []int{400, 500}},
+	{method: "GET", path: "/users/{id}", tag: "users", summary: "Get a user", status: 200, result: models.User{}, errors: []int{400, 404}},
+	{method: "PUT", path: "/users/{id}", tag: "users", summary: "Update a user", body: models.User{}, status: 200, result: models.User{}, errors: []int{400, 404}},
+	{method: "DELETE", path: "/users/{id}", tag: "users", summary: "Delete a user", status: 204, errors: []int{400, 404}},
+
+	{method: "GET", path: "/users/{user_id}/todo_lists", tag: "todo lists", summary: "List the todo lists of a user", status: 200, result: []models.TodoList{}, errors: []int{400, 404}},
+	{method: "POST", path: "/users/{user_id}/todo_lists", tag: "todo lists", summary: "Create a todo list", body: models.TodoList{}, status: 201, result: models.TodoList{}, errors: []int{400, 500}},
+	{method: "GET", path: "/todo_lists/{id}", tag: "todo lists", summary: "Get a todo list", status: 200, result: models.TodoList{}, errors: []int{400, 404}},
+	{method: "PUT", path: "/todo_lists/{id}", tag: "todo lists", summary: "Update a todo list", body: models.TodoList{}, status: 200, result: models.TodoList{}, errors: []int{400, 404}},
+	{method: "DELETE", path: "/todo_lists/{id}", tag: "todo lists", summary: "Delete a todo list", status: 204, errors: []int{400, 404}},
+
+	{method: "GET", path: "/todo_lists/{list_id}/todo_items", tag: "todo items", summary: "List the todo items of a todo list", status: 200, result: []models.TodoItem{}, errors: []int{400, 404}},
+	{method: "POST", path: "/todo_lists/{list_id}/todo_items", tag: "todo items", summary: "Create a todo item", body: models.TodoItem{}, status: 201, result: models.TodoItem{}, errors: []int{400, 500}},
+	{method: "POST", path: "/todo_items/bulk", tag: "todo items", summary: "Apply operations to many todo items in one transaction", body: models.BulkRequest{}, status: 200, result: models.BulkReport{}, otherResults: map[int]string{422: "the operations were rolled back"}, errors: []int{400, 500}},
+	{method: "GET", path: "/todo_items/{id}", tag: "todo items", summary: "Get a todo item", status: 200, result: models.TodoItem{}, errors: []int{400, 404}},
+	{method: "PUT", path: "/todo_items/{id}", tag: "todo items", summary: "Update a todo item", body: models.TodoItemUpdate{}, status: 200, result: models.TodoItem{}, errors: []int{400, 404}},
+	{method: "DELETE", path: "/todo_items/{id}", tag: "todo items", summary: "Delete a todo item", status: 204, errors: []int{400, 404}},
+
+	{method: "GET", path: "/todo_items/{item_id}/tags", tag: "tags", summary: "List the tags of a todo item", status: 200, result: []models.Tag{}, errors: []int{400, 404}},
+	{method: "POST", path: "/todo_items/{item_id}/tags", tag: "tags", summary: "Attach a tag to a todo item", body: models.Tag{}, status: 201, result: models.Tag{}, errors: []int{400, 409, 500}},
+	{method: "DELETE", path: "/todo_items/{item_id}/tags/{tag_id}", tag: "tags", summary: "Detach a tag from a todo item", status: 204, errors: []int{400, 404}},
+	{method: "GET", path: "/users/{user_id}/tags", tag: "tags", summary: "List the tags of a user with their usage counts", status: 200, result: []models.TagUsage{}, errors: []int{400, 404}},
+	{method: "POST", path: "/users/{user_id}/tags", tag: "tags", summary: "Create a tag of a user", body: models.Tag{}, status: 201, result: models.Tag{}, errors: []int{400, 404, 409}},
+	{method: "GET", path: "/users/{user_id}/todo_items", tag: "tags", summary: "List the todo items of a user by tags", query: tagFilterParameters, status: 200, result: []models.TodoItem{}, errors: []int{400, 404}},
+	{method: "GET", path: "/tags/{id}", tag: "tags", summary: "Get a tag", status: 200, result: models.Tag{}, errors: []int{400, 404}},
+	{method: "GET", path: "/tags/{id}/todo_items", tag: "tags", summary: "List the todo items of a tag and its descendants", query: pageParameters, status: 200, result: []models.TodoItem{}, errors: []int{400, 404}},
+	{method: "POST", path: "/tags/{id}/merge", tag: "tags", summary: "Merge a tag into another tag", body: TagMerge{}, status: 200, result: models.Tag{}, errors: []int{400, 404}},
+	{method: "PUT", path: "/tags/{id}", tag: "tags", summary: "Replace a tag, an omitted color or parent is cleared", body: models.Tag{}, status: 200, result: models.Tag{}, errors: []int{400, 404, 409}},
+	{method: "DELETE", path: "/tags/{id}", tag: "tags", summary: "Delete a tag", status: 204, errors: []int{400, 404}},
+
+	{method: "GET", path: "/todo_lists/{id}/activity", tag: "activity", summary: "List the audit events of a todo list", query: pageParameters, status: 200, result: []models.AuditEvent{}, errors: []int{400, 500}},
+	{method: "GET", path: "/users/{id}/activity", tag: "activity", summary: "List the audit events of a user", query: pageParameters, status: 200, result: []models.AuditEvent{}, errors: []int{400, 500}},
+
+	{method: "GET", path: "/users/{user_id}/webhooks", tag: "webhooks", summary: "List the webhooks of a user", status: 200, result: []models.Webhook{}, errors: []int{400, 404}},
+	{method: "POST", path: "/users/{user_id}/webhooks", tag: "webhooks", summary: "Create a webhook", body: models.Webhook{}, status: 201, result: models.Webhook{}, errors: []int{400, 404}},
+	{method: "GET", path: "/webhooks/{id}", tag: "webhooks", summary: "Get a webhook", status: 200, result: models.Webhook{}, errors: []int{400, 404}},
+	{method: "PUT", path: "/webhooks/{id}", tag: "webhooks", summary: "Update a webhook", body: models.Webhook{}, status: 200, result: models.Webhook{}, errors: []int{400, 404}},
+	{method: "DELETE", path: "/webhooks/{id}", tag: "webhooks", summary: "Delete a webhook", status: 204, errors: []int{400, 404}},
+	{method: "GET", path: "/webhooks/{id}/deliveries", tag: "webhooks", summary: "List the delivery attempts of a webhook", query: pageParameters, status: 200, result: []models.WebhookDelivery{}, errors: []int{400, 404}},
+
+	{
+		method: "GET", path: "/events", tag: "events", summary: "Stream the change events of the user as Server-Sent Events or over a WebSocket",
+		query: []*openapi.Parameter{
+			queryParameter("last_event_id", "replays the events after this id, like the Last-Event-ID header", &openapi.Schema{Type: "integer"}),
+			queryParameter("access_token", "the bearer token, for clients that cannot set headers", &openapi.Schema{Type: "string"}),
+		},
+		status: 200, result: models.ChangeEvent{}, resultTypes: []string{mediaEvents}, errors: []int{400, 401},
+	},
+	{
+		method: "GET", path: "/sync", tag: "sync", summary: "Get the changes after a version",
+		query:  []*openapi.Parameter{queryParameter("since", "the version of the last sync, 0 for all records", &openapi.Schema{Type: "integer"})},
+		status: 200, result: models.SyncChanges{}, errors: []int{400, 401, 500},
+	},
+	{method: "POST", path: "/sync", tag: "sync", summary: "Push client changes", body: models.SyncPush{}, status: 200, result: models.SyncReport{}, errors: []int{400, 401, 500}},
+
+	{
+		method: "GET", path: "/users/{id}/export", tag: "export", summary: "Download all data of a user",
+		query:  []*openapi.Parameter{queryParameter("format", "json (default), csv or markdown", &openapi.Schema{Type: "string", Enum: []string{"json", "csv", "markdown"}})},
+		status: 200, result: map[string]interface{}{}, resultTypes: []string{mediaJSON, mediaCSV, mediaMarkdown}, errors: []int{400, 404},
+	},
+	{
+		method: "POST", path: "/users/{id}/import", tag: "import", summary: "Import a todo.txt, csv or json file",
+		query: []*openapi.Parameter{
+			queryParameter("format", "todotxt, csv or json", &openapi.Schema{Type: "string", Enum: []string{"todotxt", "csv", "json"}}),
+			queryParameter("list", "the todo list of items that name none", &openapi.Schema{Type: "string"}),
+			queryParameter("columns", "csv column mapping like title:Task,list:Project", &openapi.Schema{Type: "string"}),
+			queryParameter("dry_run", "preview the import without committing it", &openapi.Schema{Type: "boolean"}),
+		},
+		body: map[string]interface{}{}, bodyTypes: []string{mediaText, mediaCSV, mediaJSON},
+		status: 201, result: models.ImportReport{}, otherResults: map[int]string{200: "the preview of a dry run", 422: "the lines that could not be read"},
+		errors: []int{400, 404, 409, 413},
+	},
+
+	{method: "POST", path: "/users/{id}/feed", tag: "calendar", summary: "Create the calendar feed token of a user", status: 201, result: models.Feed{}, errors: []int{400, 404}},
+	{method: "DELETE", path: "/users/{id}/feed", tag: "calendar", summary: "Revoke the calendar feed token of a user", status: 204, errors: []int{400, 404}},
+	{method: "GET", path: "/feeds/{token}.ics", tag: "calendar", summary: "The iCalendar feed of all todo lists", status: 200, resultTypes: []string{mediaCalendar}, errors: []int{404}},
+	{method: "HEAD", path: "/feeds/{token}.ics", tag: "calendar", summary: "Check the iCalendar feed of all todo lists", status: 200, errors: []int{404}},
+	{method: "GET", path: "/feeds/{token}/todo_lists/{list_id}.ics", tag: "calendar", summary: "The iCalendar feed of a todo list", status: 200, resultTypes: []string{mediaCalendar}, errors: []int{400, 404}},
+	{method: "HEAD", path: "/feeds/{token}/todo_lists/{list_id}.ics", tag: "calendar", summary: "Check the iCalendar feed of a todo list", status: 200, errors: []int{400, 404}},
+
+	{method: "POST", path: "/graphql", tag: "graphql", summary: "Execute a GraphQL query or mutation", body: graphql.Request{}, status: 200, result: graphql.Response{}, otherResults: map[int]string{400: "the request could not be parsed or validated"}, errors: []int{400}},
+	{method: "GET", path: "/graphql/schema", tag: "graphql", summary: "The GraphQL schema", status: 200, resultTypes: []string{mediaText}},
+}
+
+// pathParameter matches the variables of a mux path template
+var pathParameter = regexp.MustCompile(`{([a-z_]+)}`)
+
+// newOpenAPIDocument describes the operations, the routes in publicRoutes need no token
+func newOpenAPIDocument(operations []apiOperation) *openapi.Document {
+	schemas := openapi.NewSchemas()
+	schemas.Define(gorm.DeletedAt{}, &openapi.Schema{Type: "string", Format: "date-time", Nullable: true})
+	schemas.Define(models.AuditChanges{}, &openapi.Schema{Type: "array", Items: schemas.Of(models.AuditChange{})})
+	errorSchema := schemas.Of(response.HTTPError{})
+
+	doc := &openapi.Document{
+		OpenAPI: openapi.Version,
+		Info: openapi.Info{
+			Title:       "go-todo-rest-api",
+			Description: "Users, todo lists, todo items and tags. Errors are sent as HTTPError.",
+			Version:     "1.0.0",
+		},
+		Paths: map[string]map[string]*openapi.Operation{},
+		Components: openapi.Components{
+			SecuritySchemes: map[string]*openapi.SecurityScheme{
+				"bearer": {Type: "http", Scheme: "bearer"},
+			},
+		},
+		Security: []map[string][]string{{"bearer": {}}},
+	}
+
+	for _, o := range operations {
+		operation := &openapi.Operation{
+			Summary:   o.summary,
+			Tags:      []string{o.tag},
+			Responses: map[string]*openapi.Response{},
+		}
+		if publicRoutes[o.method+" "+o.path] {
+			// an empty requirement allows anonymous requests
+			operation.Security = []map[string][]string{{}}
+		}
+
+		for _, match := range pathParameter.FindAllStringSubmatch(o.path, -1) {
+			schema := &openapi.Schema{Type: "integer"}
+			if match[1] == "token" {
+				schema = &openapi.Schema{Type: "string"}
+			}
+			operation.Parameters = append(operation.Parameters, &openapi.Parameter{Name: match[1], In: "path", Required: true, Schema: schema})
+		}
+		operation.Parameters = append(operation.Parameters, o.query...)
+
+		if o.body != nil {
+			operation.RequestBody = &openapi.RequestBody{Required: true, Content: content(schemas.Of(o.body), o.bodyTypes)}
+		}
+
+		result := &openapi.Response{Description: http.StatusText(o.status)}
+		if o.result != nil || o.resultTypes != nil {
+			result.Content = content(schemas.Of(o.result), o.resultTypes)
+		}
+		operation.Responses[strconv.Itoa(o.status)] = result
+		for status, description := range o.otherResults {
+			operation.Responses[strconv.Itoa(status)] = &openapi.Response{Description: description, Content: result.Content}
+		}
+		for _, status := range o.errors {
+			if _, ok := operation.Responses[strconv.Itoa(status)]; !ok {
+				operation.Responses[strconv.Itoa(status)] = &openapi.Response{
+					Description: http.StatusText(status),
+					Content:     openapi.Content(mediaJSON, errorSchema),
+				}
+			}
+		}
+
+		if doc.Paths[o.path] == nil {
+			doc.Paths[o.path] = map[string]*openapi.Operation{}
+		}
+		doc.Paths[o.path][strings.ToLower(o.method)] = operation
+	}
+
+	doc.Components.Schemas = schemas.Components()
+	return doc
+}
+
+func content(schema *openapi.Schema, mediaTypes []string) map[string]*openapi.MediaType {
+	if len(mediaTypes) == 0 {
+		mediaTypes = []string{mediaJSON}
+	}
+	result := map[string]*openapi.MediaType{}
+	for _, mediaType := range mediaTypes {
+		if mediaType == mediaJSON || mediaType == mediaEvents {
+			result[mediaType] = &openapi.MediaType{Schema: schema}
+		} else {
+			result[mediaType] = &openapi.MediaType{Schema: &openapi.Schema{Type: "string"}}
+		}
+	}
+	return result
+}
+
+func queryParameter(name, description string, schema *openapi.Schema) *openapi.Parameter {
+	return &openapi.Parameter{Name: name, In: "query", Description: description, Schema: schema}
+}
@@ -0,0 +1,52 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// swaggerUIVersion is the swagger-ui-dist release loaded by the docs page
+const swaggerUIVersion = "5.17.14"
+
+// docsPage renders /openapi.json with Swagger UI
+const docsPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>go-todo-rest-api</title>
+<link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@` + swaggerUIVersion + `/swagger-ui.css">
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="https://unpkg.com/swagger-ui-dist@` + swaggerUIVersion + `/swagger-ui-bundle.js" crossorigin></script>
+<script>
+window.onload = function () {
+  window.ui = SwaggerUIBundle({url: "openapi.json", dom_id: "#swagger-ui"});
+};
+</script>
+</body>
+</html>
+`
+
+// OpenAPIController ...
+type OpenAPIController struct {
+	document []byte
+}
+
+// NewOpenAPIController ...
+func NewOpenAPIController() *OpenAPIController {
+	document, _ := json.Marshal(newOpenAPIDocument(apiOperations))
+	return &OpenAPIController{document: document}
+}
+
+// Document returns the OpenAPI 3 document of the API
+func (c *OpenAPIController) Document(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(c.document)
+}
+
+// Docs serves Swagger UI for the document
+func (c *OpenAPIController) Docs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(docsPage))
+}
@@ -0,0 +1,139 @@
+package http
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	. "net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/danikg/go-todo-rest-api/utils/openapi"
+	"github.com/danikg/go-todo-rest-api/utils/test"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+// setupAllRoutes registers the routes of every Setup*Routes function, the handlers are never called
+var setupAllRoutes = map[string]func(router *mux.Router){
+	"SetupActivityRoutes": func(router *mux.Router) { SetupActivityRoutes(router, &ActivityController{}) },
+	"SetupAuthRoutes":     func(router *mux.Router) { SetupAuthRoutes(router, &AuthController{}) },
+	"SetupCalendarRoutes": func(router *mux.Router) { SetupCalendarRoutes(router, &CalendarController{}) },
+	"SetupEventsRoutes":   func(router *mux.Router) { SetupEventsRoutes(router, &EventsController{}) },
+	"SetupExportRoutes":   func(router *mux.Router) { SetupExportRoutes(router, &ExportController{}) },
+	"SetupGraphQLRoutes":  func(router *mux.Router) { SetupGraphQLRoutes(router, &GraphQLController{}) },
+	"SetupImportRoutes":   func(router *mux.Router) { SetupImportRoutes(router, &ImportController{}) },
+	"SetupMetricsRoutes":  SetupMetricsRoutes,
+	"SetupOpenAPIRoutes":  func(router *mux.Router) { SetupOpenAPIRoutes(router, &OpenAPIController{}) },
+	"SetupSyncRoutes":     func(router *mux.Router) { SetupSyncRoutes(router, &SyncController{}) },
+	"SetupTagRoutes":      func(router *mux.Router) { SetupTagRoutes(router, &TagController{}) },
+	"SetupTodoItemRoutes": func(router *mux.Router) { SetupTodoItemRoutes(router, &TodoItemController{}) },
+	"SetupTodoListRoutes": func(router *mux.Router) { SetupTodoListRoutes(router, &TodoListController{}) },
+	"SetupUserRoutes":     func(router *mux.Router) { SetupUserRoutes(router, &UserController{}) },
+	"SetupWebhookRoutes":  func(router *mux.Router) { SetupWebhookRoutes(router, &WebhookController{}) },
+}
+
+func TestOpenAPI_SetupFunctions(t *testing.T) {
+	files, err := filepath.Glob("*_routes.go")
+	assert.NoError(t, err)
+
+	declared := []string{}
+	for _, file := range files {
+		f, err := parser.ParseFile(token.NewFileSet(), file, nil, 0)
+		assert.NoError(t, err)
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if ok && strings.HasPrefix(fn.Name.Name, "Setup") && strings.HasSuffix(fn.Name.Name, "Routes") {
+				declared = append(declared, fn.Name.Name)
+			}
+		}
+	}
+
+	known := []string{}
+	for name := range setupAllRoutes {
+		known = append(known, name)
+	}
+	sort.Strings(declared)
+	sort.Strings(known)
+	assert.Equal(t, declared, known, "setupAllRoutes must call every Setup*Routes function")
+}
+
+func TestOpenAPI_Routes(t *testing.T) {
+	router := mux.NewRouter()
+	for _, setup := range setupAllRoutes {
+		setup(router)
+	}
+
+	registered := map[string]bool{}
+	err := router.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return err
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			return err
+		}
+		for _, method := range methods {
+			registered[method+" "+path] = true
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+
+	doc := newOpenAPIDocument(apiOperations)
+	documented := map[string]bool{}
+	for path, operations := range doc.Paths {
+		for method := range operations {
+			documented[strings.ToUpper(method)+" "+path] = true
+		}
+	}
+
+	for route := range registered {
+		assert.True(t, documented[route], "%s is missing from the OpenAPI document", route)
+	}
+	for route := range documented {
+		assert.True(t, registered[route], "%s is documented but not registered", route)
+	}
+}
+
+func TestOpenAPIController_Document(t *testing.T) {
+	controller := NewOpenAPIController()
+	w, r := test.NewRequest("GET", "/openapi.json", nil)
+	test.MakeRequest("/openapi.json", controller.Document, w, r)
+	assert.Equal(t, StatusOK, w.Code)
+
+	var doc openapi.Document
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&doc))
+	assert.Equal(t, openapi.Version, doc.OpenAPI)
+
+	operation := doc.Paths["/todo_lists/{list_id}/todo_items"]["post"]
+	assert.Equal(t, "list_id", operation.Parameters[0].Name)
+	assert.Equal(t, "path", operation.Parameters[0].In)
+	assert.Equal(t, "#/components/schemas/TodoItem", operation.RequestBody.Content["application/json"].Schema.Ref)
+	assert.Equal(t, "#/components/schemas/TodoItem", operation.Responses["201"].Content["application/json"].Schema.Ref)
+	assert.Equal(t, "#/components/schemas/HTTPError", operation.Responses["400"].Content["application/json"].Schema.Ref)
+	assert.Nil(t, operation.Security)
+
+	// public routes need no token
+	assert.Equal(t, []map[string][]string{{}}, doc.Paths["/users"]["post"].Security)
+
+	todoItem := doc.Components.Schemas["TodoItem"]
+	assert.Equal(t, "integer", todoItem.Properties["ID"].Type)
+	assert.Equal(t, "date-time", todoItem.Properties["Due"].Format)
+	assert.True(t, todoItem.Properties["Due"].Nullable)
+	assert.Equal(t, "#/components/schemas/Tag", todoItem.Properties["Tags"].Items.Ref)
+	assert.Contains(t, doc.Components.Schemas["HTTPError"].Properties, "message")
+	assert.NotContains(t, doc.Components.Schemas["User"].Properties, "PasswordHash")
+}
+
+func TestOpenAPIController_Docs(t *testing.T) {
+	controller := NewOpenAPIController()
+	w, r := test.NewRequest("GET", "/docs", nil)
+	test.MakeRequest("/docs", controller.Docs, w, r)
+	assert.Equal(t, StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `url: "openapi.json"`)
+}
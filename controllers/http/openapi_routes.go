@@ -0,0 +1,9 @@
+package http
+
+import "github.com/gorilla/mux"
+
+// SetupOpenAPIRoutes ...
+func SetupOpenAPIRoutes(router *mux.Router, controller *OpenAPIController) {
+	router.HandleFunc("/openapi.json", controller.Document).Methods("GET")
+	router.HandleFunc("/docs", controller.Docs).Methods("GET")
+}
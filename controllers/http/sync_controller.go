@@ -0,0 +1,85 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/danikg/go-todo-rest-api/models"
+	"github.com/danikg/go-todo-rest-api/services"
+	"github.com/danikg/go-todo-rest-api/utils/auth"
+	"github.com/danikg/go-todo-rest-api/utils/response"
+)
+
+// SyncController ...
+type SyncController struct {
+	syncService services.ISyncService
+}
+
+// NewSyncController ...
+func NewSyncController(syncService services.ISyncService) *SyncController {
+	return &SyncController{syncService: syncService}
+}
+
+// Get returns the records of the authenticated user changed or deleted after the since cursor,
+// without since the full snapshot is returned
+func (c *SyncController) Get(w http.ResponseWriter, r *http.Request) {
+	var (
+		changes models.SyncChanges
+		since   uint64
+		err     error
+	)
+
+	userID, ok := auth.UserID(r.Context())
+	if !ok {
+		response.SendErrorResponse(w, http.StatusUnauthorized, errMissingToken)
+		return
+	}
+
+	if value := r.URL.Query().Get("since"); value != "" {
+		if since, err = strconv.ParseUint(value, 10, 64); err != nil {
+			response.SendErrorResponse(w, http.StatusBadRequest, fmt.Errorf("invalid since cursor %q", value))
+			return
+		}
+	}
+
+	if changes, err = c.syncService.Changes(r.Context(), userID, since); err != nil {
+		response.SendErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.SendResponse(w, changes, 0)
+}
+
+// Post applies the changes pushed by the authenticated user and reports the outcome of each one
+func (c *SyncController) Post(w http.ResponseWriter, r *http.Request) {
+	var (
+		push   models.SyncPush
+		report models.SyncReport
+		err    error
+	)
+
+	userID, ok := auth.UserID(r.Context())
+	if !ok {
+		response.SendErrorResponse(w, http.StatusUnauthorized, errMissingToken)
+		return
+	}
+
+	if err = json.NewDecoder(r.Body).Decode(&push); err != nil {
+		response.SendErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if report, err = c.syncService.Push(r.Context(), userID, &push); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, services.ErrInvalidSyncChange) {
+			status = http.StatusBadRequest
+		}
+		response.SendErrorResponse(w, status, err)
+		return
+	}
+
+	response.SendResponse(w, report, 0)
+}
@@ -0,0 +1,156 @@
+package http
+
+import (
+	"encoding/json"
+	. "net/http"
+	"testing"
+
+	"github.com/danikg/go-todo-rest-api/models"
+	"github.com/danikg/go-todo-rest-api/services/mocks"
+	"github.com/danikg/go-todo-rest-api/utils/auth"
+	"github.com/danikg/go-todo-rest-api/utils/test"
+	"github.com/stretchr/testify/assert"
+)
+
+type syncTest struct {
+	title      string
+	method     string
+	path       string
+	body       []byte
+	userID     uint
+	shouldPass bool
+	statusCode int
+	results    int
+}
+
+func testSyncResult(t *testing.T, tc syncTest, controller func(ResponseWriter, *Request)) {
+	w, r := test.NewRequest(tc.method, tc.path, tc.body)
+	if tc.userID != 0 {
+		r = r.WithContext(auth.NewContext(r.Context(), tc.userID))
+	}
+	test.MakeRequest("/sync", controller, w, r)
+	assert.Equal(t, tc.statusCode, w.Code)
+
+	if tc.shouldPass {
+		if tc.method == "GET" {
+			var result models.SyncChanges
+			json.NewDecoder(w.Body).Decode(&result)
+			assert.Equal(t, uint64(2), result.Cursor)
+			assert.Len(t, result.TodoLists, tc.results)
+		} else {
+			var result models.SyncReport
+			json.NewDecoder(w.Body).Decode(&result)
+			assert.Len(t, result.Results, tc.results)
+		}
+	}
+}
+
+func TestSyncController_Get(t *testing.T) {
+	tests := []syncTest{
+		{
+			title:      "Get full snapshot",
+			method:     "GET",
+			path:       "/sync",
+			userID:     1,
+			shouldPass: true,
+			statusCode: StatusOK,
+			results:    1,
+		},
+		{
+			title:      "Get changes since cursor",
+			method:     "GET",
+			path:       "/sync?since=2",
+			userID:     1,
+			shouldPass: true,
+			statusCode: StatusOK,
+			results:    0,
+		},
+		{
+			title:      "Get changes, wrong cursor",
+			method:     "GET",
+			path:       "/sync?since=a",
+			userID:     1,
+			shouldPass: false,
+			statusCode: StatusBadRequest,
+		},
+		{
+			title:      "Get changes, failing user",
+			method:     "GET",
+			path:       "/sync",
+			userID:     2,
+			shouldPass: false,
+			statusCode: StatusInternalServerError,
+		},
+		{
+			title:      "Get changes, not authenticated",
+			method:     "GET",
+			path:       "/sync",
+			shouldPass: false,
+			statusCode: StatusUnauthorized,
+		},
+	}
+
+	syncController := NewSyncController(&mocks.SyncServiceMock{})
+	for _, tc := range tests {
+		t.Run(tc.title, func(t *testing.T) {
+			testSyncResult(t, tc, syncController.Get)
+		})
+	}
+}
+
+func TestSyncController_Post(t *testing.T) {
+	tests := []syncTest{
+		{
+			title:      "Push changes",
+			method:     "POST",
+			path:       "/sync",
+			body:       []byte(`{"Changes":[{"Op":"create","Type":"todo_list","ClientID":"a","TodoList":{"Name":"list"}},{"Op":"delete","Type":"tag","ID":1,"BaseVersion":3}]}`),
+			userID:     1,
+			shouldPass: true,
+			statusCode: StatusOK,
+			results:    2,
+		},
+		{
+			title:      "Push changes, invalid changes",
+			method:     "POST",
+			path:       "/sync",
+			body:       []byte(`{"Changes":[]}`),
+			userID:     1,
+			shouldPass: false,
+			statusCode: StatusBadRequest,
+		},
+		{
+			title:      "Push changes, wrong body",
+			method:     "POST",
+			path:       "/sync",
+			body:       []byte(`{"Changes":`),
+			userID:     1,
+			shouldPass: false,
+			statusCode: StatusBadRequest,
+		},
+		{
+			title:      "Push changes, failing user",
+			method:     "POST",
+			path:       "/sync",
+			body:       []byte(`{"Changes":[{"Op":"delete","Type":"tag","ID":1,"BaseVersion":1}]}`),
+			userID:     2,
+			shouldPass: false,
+			statusCode: StatusInternalServerError,
+		},
+		{
+			title:      "Push changes, not authenticated",
+			method:     "POST",
+			path:       "/sync",
+			body:       []byte(`{"Changes":[]}`),
+			shouldPass: false,
+			statusCode: StatusUnauthorized,
+		},
+	}
+
+	syncController := NewSyncController(&mocks.SyncServiceMock{})
+	for _, tc := range tests {
+		t.Run(tc.title, func(t *testing.T) {
+			testSyncResult(t, tc, syncController.Post)
+		})
+	}
+}
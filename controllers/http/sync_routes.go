@@ -0,0 +1,9 @@
+package http
+
+import "github.com/gorilla/mux"
+
+// SetupSyncRoutes ...
+func SetupSyncRoutes(router *mux.Router, controller *SyncController) {
+	router.HandleFunc("/sync", controller.Get).Methods("GET")
+	router.HandleFunc("/sync", controller.Post).Methods("POST")
+}
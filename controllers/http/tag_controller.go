@@ -2,14 +2,22 @@ package http
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strings"
 
 	"github.com/danikg/go-todo-rest-api/models"
+	"github.com/danikg/go-todo-rest-api/repositories"
 	"github.com/danikg/go-todo-rest-api/services"
 	"github.com/danikg/go-todo-rest-api/utils/response"
 	"github.com/danikg/go-todo-rest-api/utils/route"
 )
 
+// TagMerge is the body of a tag merge request
+type TagMerge struct {
+	TargetID uint
+}
+
 // TagController ...
 type TagController struct {
 	TagService services.ITagService
@@ -33,8 +41,8 @@ func (c *TagController) GetAll(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if tags, err = c.TagService.GetAll(itemID); err != nil {
-		response.SendErrorResponse(w, http.StatusNotFound, err)
+	if tags, err = c.TagService.GetAll(r.Context(), itemID); err != nil {
+		response.SendErrorResponse(w, accessErrorStatus(err, http.StatusNotFound), err)
 		return
 	}
 
@@ -59,8 +67,55 @@ func (c *TagController) Post(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err = c.TagService.Create(itemID, &tag); err != nil {
-		response.SendErrorResponse(w, http.StatusInternalServerError, err)
+	if err = c.TagService.Create(r.Context(), itemID, &tag); err != nil {
+		response.SendErrorResponse(w, tagErrorStatus(err, http.StatusInternalServerError), err)
+		return
+	}
+
+	response.SendResponse(w, tag, http.StatusCreated)
+}
+
+// GetAllByUser returns the tag vocabulary of the user with usage counts
+func (c *TagController) GetAllByUser(w http.ResponseWriter, r *http.Request) {
+	var (
+		userID uint
+		usages []models.TagUsage
+		err    error
+	)
+
+	if userID, err = route.GetRouteVar(r, "user_id"); err != nil {
+		response.SendErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if usages, err = c.TagService.GetAllByUser(r.Context(), userID); err != nil {
+		response.SendErrorResponse(w, accessErrorStatus(err, http.StatusNotFound), err)
+		return
+	}
+
+	response.SendResponse(w, usages, 0)
+}
+
+// PostForUser adds a tag to the user's vocabulary
+func (c *TagController) PostForUser(w http.ResponseWriter, r *http.Request) {
+	var (
+		tag    models.Tag
+		userID uint
+		err    error
+	)
+
+	if userID, err = route.GetRouteVar(r, "user_id"); err != nil {
+		response.SendErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err = json.NewDecoder(r.Body).Decode(&tag); err != nil {
+		response.SendErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err = c.TagService.CreateForUser(r.Context(), userID, &tag); err != nil {
+		response.SendErrorResponse(w, tagErrorStatus(err, http.StatusNotFound), err)
 		return
 	}
 
@@ -80,15 +135,76 @@ func (c *TagController) GetSingle(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if tag, err = c.TagService.GetSingle(id); err != nil {
-		response.SendErrorResponse(w, http.StatusNotFound, err)
+	if tag, err = c.TagService.GetSingle(r.Context(), id); err != nil {
+		response.SendErrorResponse(w, accessErrorStatus(err, http.StatusNotFound), err)
 		return
 	}
 
 	response.SendResponse(w, tag, 0)
 }
 
-// Put updates the tag by id
+// GetTodoItems returns the todo items the tag is attached to
+func (c *TagController) GetTodoItems(w http.ResponseWriter, r *http.Request) {
+	var (
+		id        uint
+		page      models.Page
+		todoItems []models.TodoItem
+		err       error
+	)
+
+	if id, err = route.GetRouteVar(r, "id"); err != nil {
+		response.SendErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if page, err = route.GetPage(r); err != nil {
+		response.SendErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if todoItems, err = c.TagService.GetTodoItems(r.Context(), id, page); err != nil {
+		response.SendErrorResponse(w, accessErrorStatus(err, http.StatusNotFound), err)
+		return
+	}
+
+	response.SendResponse(w, todoItems, 0)
+}
+
+// GetTodoItemsByUser returns the todo items across all lists of the user,
+// ?tag=a,b keeps items with any of the tags, adding &match=all requires all of them
+func (c *TagController) GetTodoItemsByUser(w http.ResponseWriter, r *http.Request) {
+	var (
+		userID    uint
+		filter    models.TagFilter
+		page      models.Page
+		todoItems []models.TodoItem
+		err       error
+	)
+
+	if userID, err = route.GetRouteVar(r, "user_id"); err != nil {
+		response.SendErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if filter, err = getTagFilter(r); err != nil {
+		response.SendErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if page, err = route.GetPage(r); err != nil {
+		response.SendErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if todoItems, err = c.TagService.GetTodoItemsByUser(r.Context(), userID, filter, page); err != nil {
+		response.SendErrorResponse(w, accessErrorStatus(err, http.StatusNotFound), err)
+		return
+	}
+
+	response.SendResponse(w, todoItems, 0)
+}
+
+// Put replaces the tag by id, the text is required and an omitted color or parent is cleared
 func (c *TagController) Put(w http.ResponseWriter, r *http.Request) {
 	var (
 		id      uint
@@ -107,8 +223,35 @@ func (c *TagController) Put(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if tag, err = c.TagService.Update(id, &tagData); err != nil {
-		response.SendErrorResponse(w, http.StatusNotFound, err)
+	if tag, err = c.TagService.Update(r.Context(), id, &tagData); err != nil {
+		response.SendErrorResponse(w, tagErrorStatus(err, http.StatusNotFound), err)
+		return
+	}
+
+	response.SendResponse(w, tag, 0)
+}
+
+// Merge moves the todo items and child tags of the tag to the target tag and deletes the tag
+func (c *TagController) Merge(w http.ResponseWriter, r *http.Request) {
+	var (
+		id    uint
+		merge TagMerge
+		tag   models.Tag
+		err   error
+	)
+
+	if id, err = route.GetRouteVar(r, "id"); err != nil {
+		response.SendErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err = json.NewDecoder(r.Body).Decode(&merge); err != nil {
+		response.SendErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if tag, err = c.TagService.Merge(r.Context(), id, merge.TargetID); err != nil {
+		response.SendErrorResponse(w, tagErrorStatus(err, http.StatusNotFound), err)
 		return
 	}
 
@@ -133,8 +276,8 @@ func (c *TagController) Remove(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err = c.TagService.Remove(itemID, tagID); err != nil {
-		response.SendErrorResponse(w, http.StatusNotFound, err)
+	if err = c.TagService.Remove(r.Context(), itemID, tagID); err != nil {
+		response.SendErrorResponse(w, accessErrorStatus(err, http.StatusNotFound), err)
 		return
 	}
 
@@ -153,10 +296,49 @@ func (c *TagController) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err = c.TagService.Delete(id); err != nil {
-		response.SendErrorResponse(w, http.StatusNotFound, err)
+	if err = c.TagService.Delete(r.Context(), id); err != nil {
+		response.SendErrorResponse(w, accessErrorStatus(err, http.StatusNotFound), err)
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// getTagFilter reads the comma separated tag query parameters and the match mode
+func getTagFilter(r *http.Request) (models.TagFilter, error) {
+	filter := models.TagFilter{}
+	query := r.URL.Query()
+
+	for _, value := range query["tag"] {
+		for _, tag := range strings.Split(value, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				filter.Tags = append(filter.Tags, tag)
+			}
+		}
+	}
+
+	switch query.Get("match") {
+	case "", "any":
+	case "all":
+		filter.MatchAll = true
+	default:
+		return filter, errors.New("match must be any or all")
+	}
+
+	return filter, nil
+}
+
+// tagErrorStatus maps the tag validation errors, other errors get fallback
+func tagErrorStatus(err error, fallback int) int {
+	switch {
+	case errors.Is(err, services.ErrEmptyTag),
+		errors.Is(err, services.ErrInvalidTagColor),
+		errors.Is(err, repositories.ErrInvalidTagParent),
+		errors.Is(err, repositories.ErrInvalidTagMerge):
+		return http.StatusBadRequest
+	case errors.Is(err, repositories.ErrDuplicateTag):
+		return http.StatusConflict
+	default:
+		return accessErrorStatus(err, fallback)
+	}
+}
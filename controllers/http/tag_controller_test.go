@@ -199,6 +199,24 @@ func TestTagController_Put(t *testing.T) {
 				return tag
 			}(),
 		},
+		{
+			title:      "Put tag, duplicate text",
+			method:     "PUT",
+			path:       "/tags/1",
+			route:      "/tags/{id}",
+			shouldPass: false,
+			statusCode: StatusConflict,
+			body:       []byte(`{"ID": 1, "Text": "tag2"}`),
+		},
+		{
+			title:      "Put tag, invalid color",
+			method:     "PUT",
+			path:       "/tags/1",
+			route:      "/tags/{id}",
+			shouldPass: false,
+			statusCode: StatusBadRequest,
+			body:       []byte(`{"ID": 1, "Text": "tag1", "Color": "red"}`),
+		},
 		{
 			title:      "Put tag, wrong item_id",
 			method:     "PUT",
@@ -236,6 +254,68 @@ func TestTagController_Put(t *testing.T) {
 	}
 }
 
+func TestTagController_Merge(t *testing.T) {
+	tests := []tagTest{
+		{
+			title:      "Merge tag",
+			method:     "POST",
+			path:       "/tags/1/merge",
+			route:      "/tags/{id}/merge",
+			shouldPass: true,
+			statusCode: StatusOK,
+			body:       []byte(`{"TargetID": 2}`),
+			tagResult: func() models.Tag {
+				tag := models.Tag{Text: "tag2"}
+				tag.ID = 2
+				return tag
+			}(),
+		},
+		{
+			title:      "Merge tag into itself",
+			method:     "POST",
+			path:       "/tags/1/merge",
+			route:      "/tags/{id}/merge",
+			shouldPass: false,
+			statusCode: StatusBadRequest,
+			body:       []byte(`{"TargetID": 1}`),
+		},
+		{
+			title:      "Merge tag, wrong id",
+			method:     "POST",
+			path:       "/tags/a/merge",
+			route:      "/tags/{id}/merge",
+			shouldPass: false,
+			statusCode: StatusBadRequest,
+			body:       []byte(`{"TargetID": 2}`),
+		},
+		{
+			title:      "Merge tag, wrong body",
+			method:     "POST",
+			path:       "/tags/1/merge",
+			route:      "/tags/{id}/merge",
+			shouldPass: false,
+			statusCode: StatusBadRequest,
+			body:       []byte{},
+		},
+		{
+			title:      "Merge tag, non-existent id",
+			method:     "POST",
+			path:       "/tags/2/merge",
+			route:      "/tags/{id}/merge",
+			shouldPass: false,
+			statusCode: StatusNotFound,
+			body:       []byte(`{"TargetID": 3}`),
+		},
+	}
+
+	tagController := NewTagController(&mocks.TagServiceMock{})
+	for _, tc := range tests {
+		t.Run(tc.title, func(t *testing.T) {
+			testTagResult(t, tc, tagController.Merge)
+		})
+	}
+}
+
 func TestTagController_Remove(t *testing.T) {
 	tests := []tagTest{
 		{
@@ -315,3 +395,249 @@ func TestTagController_Delete(t *testing.T) {
 		})
 	}
 }
+
+func TestTagController_GetAllByUser(t *testing.T) {
+	tests := []tagTest{
+		{
+			title:      "Get user tags",
+			method:     "GET",
+			path:       "/users/1/tags",
+			route:      "/users/{user_id}/tags",
+			shouldPass: true,
+			statusCode: StatusOK,
+		},
+		{
+			title:      "Get user tags, wrong user_id",
+			method:     "GET",
+			path:       "/users/a/tags",
+			route:      "/users/{user_id}/tags",
+			shouldPass: false,
+			statusCode: StatusBadRequest,
+		},
+		{
+			title:      "Get user tags, non-existent user_id",
+			method:     "GET",
+			path:       "/users/2/tags",
+			route:      "/users/{user_id}/tags",
+			shouldPass: false,
+			statusCode: StatusNotFound,
+		},
+	}
+
+	tagController := NewTagController(&mocks.TagServiceMock{})
+	for _, tc := range tests {
+		t.Run(tc.title, func(t *testing.T) {
+			w, r := test.NewRequest(tc.method, tc.path, tc.body)
+			test.MakeRequest(tc.route, tagController.GetAllByUser, w, r)
+			assert.Equal(t, tc.statusCode, w.Code)
+
+			if tc.shouldPass {
+				var result []models.TagUsage
+				json.NewDecoder(w.Body).Decode(&result)
+				assert.Len(t, result, 1)
+				assert.Equal(t, "tag1", result[0].Text)
+				assert.Equal(t, int64(2), result[0].UsageCount)
+			}
+		})
+	}
+}
+
+func TestTagController_PostForUser(t *testing.T) {
+	tests := []tagTest{
+		{
+			title:      "Post user tag",
+			method:     "POST",
+			path:       "/users/1/tags",
+			route:      "/users/{user_id}/tags",
+			shouldPass: true,
+			statusCode: StatusCreated,
+			body:       []byte(`{"Text": "tag1"}`),
+			tagResult: func() models.Tag {
+				tag := models.Tag{Text: "tag1"}
+				tag.ID = 1
+				return tag
+			}(),
+		},
+		{
+			title:      "Post user tag, empty text",
+			method:     "POST",
+			path:       "/users/1/tags",
+			route:      "/users/{user_id}/tags",
+			shouldPass: false,
+			statusCode: StatusBadRequest,
+			body:       []byte(`{"Text": "  "}`),
+		},
+		{
+			title:      "Post user tag, wrong user_id",
+			method:     "POST",
+			path:       "/users/a/tags",
+			route:      "/users/{user_id}/tags",
+			shouldPass: false,
+			statusCode: StatusBadRequest,
+			body:       []byte(`{"Text": "tag1"}`),
+		},
+		{
+			title:      "Post user tag, non-existent user_id",
+			method:     "POST",
+			path:       "/users/2/tags",
+			route:      "/users/{user_id}/tags",
+			shouldPass: false,
+			statusCode: StatusNotFound,
+			body:       []byte(`{"Text": "tag1"}`),
+		},
+	}
+
+	tagController := NewTagController(&mocks.TagServiceMock{})
+	for _, tc := range tests {
+		t.Run(tc.title, func(t *testing.T) {
+			testTagResult(t, tc, tagController.PostForUser)
+		})
+	}
+}
+
+func tagTodoItems() []models.TodoItem {
+	todoItems := []models.TodoItem{
+		{Title: "item1", Description: ""},
+		{Title: "item2", Description: "desc2"},
+	}
+	todoItems[0].ID = 1
+	todoItems[1].ID = 2
+	return todoItems
+}
+
+func TestTagController_GetTodoItems(t *testing.T) {
+	tests := []todoItemTest{
+		{
+			title:           "Get tagged todo items",
+			method:          "GET",
+			path:            "/tags/1/todo_items",
+			route:           "/tags/{id}/todo_items",
+			shouldPass:      true,
+			statusCode:      StatusOK,
+			todoItemsResult: tagTodoItems(),
+		},
+		{
+			title:           "Get tagged todo items, page",
+			method:          "GET",
+			path:            "/tags/1/todo_items?limit=2&offset=0",
+			route:           "/tags/{id}/todo_items",
+			shouldPass:      true,
+			statusCode:      StatusOK,
+			todoItemsResult: tagTodoItems(),
+		},
+		{
+			title:      "Get tagged todo items, wrong limit",
+			method:     "GET",
+			path:       "/tags/1/todo_items?limit=1000",
+			route:      "/tags/{id}/todo_items",
+			shouldPass: false,
+			statusCode: StatusBadRequest,
+		},
+		{
+			title:      "Get tagged todo items, wrong offset",
+			method:     "GET",
+			path:       "/tags/1/todo_items?offset=-1",
+			route:      "/tags/{id}/todo_items",
+			shouldPass: false,
+			statusCode: StatusBadRequest,
+		},
+		{
+			title:      "Get tagged todo items, wrong id",
+			method:     "GET",
+			path:       "/tags/a/todo_items",
+			route:      "/tags/{id}/todo_items",
+			shouldPass: false,
+			statusCode: StatusBadRequest,
+		},
+		{
+			title:      "Get tagged todo items, non-existent id",
+			method:     "GET",
+			path:       "/tags/2/todo_items",
+			route:      "/tags/{id}/todo_items",
+			shouldPass: false,
+			statusCode: StatusNotFound,
+		},
+	}
+
+	tagController := NewTagController(&mocks.TagServiceMock{})
+	for _, tc := range tests {
+		t.Run(tc.title, func(t *testing.T) {
+			testTodoItemResult(t, tc, tagController.GetTodoItems)
+		})
+	}
+}
+
+func TestTagController_GetTodoItemsByUser(t *testing.T) {
+	tests := []todoItemTest{
+		{
+			title:           "Get user todo items by tags",
+			method:          "GET",
+			path:            "/users/1/todo_items?tag=@phone,@office&match=all",
+			route:           "/users/{user_id}/todo_items",
+			shouldPass:      true,
+			statusCode:      StatusOK,
+			todoItemsResult: tagTodoItems(),
+		},
+		{
+			title:           "Get user todo items without filter",
+			method:          "GET",
+			path:            "/users/1/todo_items",
+			route:           "/users/{user_id}/todo_items",
+			shouldPass:      true,
+			statusCode:      StatusOK,
+			todoItemsResult: tagTodoItems(),
+		},
+		{
+			title:      "Get user todo items, wrong match",
+			method:     "GET",
+			path:       "/users/1/todo_items?tag=@phone&match=some",
+			route:      "/users/{user_id}/todo_items",
+			shouldPass: false,
+			statusCode: StatusBadRequest,
+		},
+		{
+			title:      "Get user todo items, wrong limit",
+			method:     "GET",
+			path:       "/users/1/todo_items?limit=a",
+			route:      "/users/{user_id}/todo_items",
+			shouldPass: false,
+			statusCode: StatusBadRequest,
+		},
+		{
+			title:      "Get user todo items, wrong user_id",
+			method:     "GET",
+			path:       "/users/a/todo_items",
+			route:      "/users/{user_id}/todo_items",
+			shouldPass: false,
+			statusCode: StatusBadRequest,
+		},
+		{
+			title:      "Get user todo items, non-existent user_id",
+			method:     "GET",
+			path:       "/users/2/todo_items?tag=@phone",
+			route:      "/users/{user_id}/todo_items",
+			shouldPass: false,
+			statusCode: StatusNotFound,
+		},
+	}
+
+	tagController := NewTagController(&mocks.TagServiceMock{})
+	for _, tc := range tests {
+		t.Run(tc.title, func(t *testing.T) {
+			testTodoItemResult(t, tc, tagController.GetTodoItemsByUser)
+		})
+	}
+}
+
+func TestGetTagFilter(t *testing.T) {
+	_, r := test.NewRequest("GET", "/users/1/todo_items?tag=@phone,+@office&tag=,errands&match=all", nil)
+	filter, err := getTagFilter(r)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"@phone", "@office", "errands"}, filter.Tags)
+	assert.True(t, filter.MatchAll)
+
+	_, r = test.NewRequest("GET", "/users/1/todo_items?tag=@phone", nil)
+	filter, err = getTagFilter(r)
+	assert.NoError(t, err)
+	assert.False(t, filter.MatchAll)
+}
@@ -7,7 +7,12 @@ func SetupTagRoutes(router *mux.Router, controller *TagController) {
 	router.HandleFunc("/todo_items/{item_id}/tags", controller.GetAll).Methods("GET")
 	router.HandleFunc("/todo_items/{item_id}/tags", controller.Post).Methods("POST")
 	router.HandleFunc("/todo_items/{item_id}/tags/{tag_id}", controller.Remove).Methods("DELETE")
+	router.HandleFunc("/users/{user_id}/tags", controller.GetAllByUser).Methods("GET")
+	router.HandleFunc("/users/{user_id}/tags", controller.PostForUser).Methods("POST")
+	router.HandleFunc("/users/{user_id}/todo_items", controller.GetTodoItemsByUser).Methods("GET")
 	router.HandleFunc("/tags/{id}", controller.GetSingle).Methods("GET")
+	router.HandleFunc("/tags/{id}/todo_items", controller.GetTodoItems).Methods("GET")
+	router.HandleFunc("/tags/{id}/merge", controller.Merge).Methods("POST")
 	router.HandleFunc("/tags/{id}", controller.Put).Methods("PUT")
 	router.HandleFunc("/tags/{id}", controller.Delete).Methods("DELETE")
 }
@@ -2,6 +2,7 @@ package http
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github.com/danikg/go-todo-rest-api/models"
@@ -33,8 +34,8 @@ func (c *TodoItemController) GetAll(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if todoItems, err = c.TodoItemService.GetAll(listID); err != nil {
-		response.SendErrorResponse(w, http.StatusNotFound, err)
+	if todoItems, err = c.TodoItemService.GetAll(r.Context(), listID); err != nil {
+		response.SendErrorResponse(w, accessErrorStatus(err, http.StatusNotFound), err)
 		return
 	}
 
@@ -59,8 +60,12 @@ func (c *TodoItemController) Post(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err = c.TodoItemService.Create(listID, &todoItem); err != nil {
-		response.SendErrorResponse(w, http.StatusInternalServerError, err)
+	if err = c.TodoItemService.Create(r.Context(), listID, &todoItem); err != nil {
+		status := accessErrorStatus(err, http.StatusInternalServerError)
+		if errors.Is(err, services.ErrInvalidRecurrence) {
+			status = http.StatusBadRequest
+		}
+		response.SendErrorResponse(w, status, err)
 		return
 	}
 
@@ -80,8 +85,8 @@ func (c *TodoItemController) GetSingle(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if todoItem, err = c.TodoItemService.GetSingle(id); err != nil {
-		response.SendErrorResponse(w, http.StatusNotFound, err)
+	if todoItem, err = c.TodoItemService.GetSingle(r.Context(), id); err != nil {
+		response.SendErrorResponse(w, accessErrorStatus(err, http.StatusNotFound), err)
 		return
 	}
 
@@ -92,7 +97,7 @@ func (c *TodoItemController) GetSingle(w http.ResponseWriter, r *http.Request) {
 func (c *TodoItemController) Put(w http.ResponseWriter, r *http.Request) {
 	var (
 		id           uint
-		todoItemData models.TodoItem
+		todoItemData models.TodoItemUpdate
 		todoItem     models.TodoItem
 		err          error
 	)
@@ -107,8 +112,12 @@ func (c *TodoItemController) Put(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if todoItem, err = c.TodoItemService.Update(id, &todoItemData); err != nil {
-		response.SendErrorResponse(w, http.StatusNotFound, err)
+	if todoItem, err = c.TodoItemService.Update(r.Context(), id, &todoItemData); err != nil {
+		status := accessErrorStatus(err, http.StatusNotFound)
+		if errors.Is(err, services.ErrInvalidRecurrence) {
+			status = http.StatusBadRequest
+		}
+		response.SendErrorResponse(w, status, err)
 		return
 	}
 
@@ -127,10 +136,40 @@ func (c *TodoItemController) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err = c.TodoItemService.Delete(id); err != nil {
-		response.SendErrorResponse(w, http.StatusNotFound, err)
+	if err = c.TodoItemService.Delete(r.Context(), id); err != nil {
+		response.SendErrorResponse(w, accessErrorStatus(err, http.StatusNotFound), err)
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// Bulk applies a list of operations on todo items in one transaction,
+// responding 422 with the per-item results when an all-or-nothing request was rolled back
+func (c *TodoItemController) Bulk(w http.ResponseWriter, r *http.Request) {
+	var (
+		request models.BulkRequest
+		report  models.BulkReport
+		err     error
+	)
+
+	if err = json.NewDecoder(r.Body).Decode(&request); err != nil {
+		response.SendErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if report, err = c.TodoItemService.Bulk(r.Context(), &request); err != nil {
+		status := accessErrorStatus(err, http.StatusInternalServerError)
+		if errors.Is(err, services.ErrInvalidBulkOperation) {
+			status = http.StatusBadRequest
+		}
+		response.SendErrorResponse(w, status, err)
+		return
+	}
+
+	if !report.Committed {
+		response.SendResponse(w, report, http.StatusUnprocessableEntity)
+		return
+	}
+	response.SendResponse(w, report, 0)
+}
@@ -128,6 +128,15 @@ func TestTodoItemController_Post(t *testing.T) {
 			statusCode: StatusBadRequest,
 			body:       []byte{},
 		},
+		{
+			title:      "Post todo item, recurrence without due date",
+			method:     "POST",
+			path:       "/todo_lists/1/todo_items",
+			route:      "/todo_lists/{list_id}/todo_items",
+			shouldPass: false,
+			statusCode: StatusBadRequest,
+			body:       []byte(`{"Title": "item1", "Recurrence": "FREQ=WEEKLY"}`),
+		},
 		{
 			title:      "Post todo item, internal error",
 			method:     "POST",
@@ -231,6 +240,15 @@ func TestTodoItemController_Put(t *testing.T) {
 			statusCode: StatusNotFound,
 			body:       []byte(`{"ID": 1, "Title": "item1", "Description": ""}`),
 		},
+		{
+			title:      "Put todo item, invalid recurrence",
+			method:     "PUT",
+			path:       "/todo_items/1",
+			route:      "/todo_items/{id}",
+			shouldPass: false,
+			statusCode: StatusBadRequest,
+			body:       []byte(`{"Recurrence": "FREQ=SOMETIMES"}`),
+		},
 	}
 
 	todoItemController := NewTodoItemController(&mocks.TodoItemServiceMock{})
@@ -276,3 +294,63 @@ func TestTodoItemController_Delete(t *testing.T) {
 		})
 	}
 }
+
+func TestTodoItemController_Bulk(t *testing.T) {
+	tests := []struct {
+		title      string
+		body       []byte
+		statusCode int
+		committed  bool
+		results    []bool
+	}{
+		{
+			title:      "Bulk operations",
+			body:       []byte(`{"Operations": [{"Op": "complete", "ID": 1}, {"Op": "delete", "ID": 1}]}`),
+			statusCode: StatusOK,
+			committed:  true,
+			results:    []bool{true, true},
+		},
+		{
+			title:      "Bulk operations, partial failure",
+			body:       []byte(`{"Operations": [{"Op": "complete", "ID": 1}, {"Op": "delete", "ID": 2}]}`),
+			statusCode: StatusOK,
+			committed:  true,
+			results:    []bool{true, false},
+		},
+		{
+			title:      "Bulk operations, all or nothing failure",
+			body:       []byte(`{"AllOrNothing": true, "Operations": [{"Op": "complete", "ID": 1}, {"Op": "delete", "ID": 2}]}`),
+			statusCode: StatusUnprocessableEntity,
+			committed:  false,
+			results:    []bool{true, false},
+		},
+		{
+			title:      "Bulk operations, invalid request",
+			body:       []byte(`{"Operations": []}`),
+			statusCode: StatusBadRequest,
+		},
+		{
+			title:      "Bulk operations, wrong body",
+			body:       []byte{},
+			statusCode: StatusBadRequest,
+		},
+	}
+
+	todoItemController := NewTodoItemController(&mocks.TodoItemServiceMock{})
+	for _, tc := range tests {
+		t.Run(tc.title, func(t *testing.T) {
+			w, r := test.NewRequest("POST", "/todo_items/bulk", tc.body)
+			test.MakeRequest("/todo_items/bulk", todoItemController.Bulk, w, r)
+			assert.Equal(t, tc.statusCode, w.Code)
+
+			if tc.results != nil {
+				var report models.BulkReport
+				json.NewDecoder(w.Body).Decode(&report)
+				assert.Equal(t, tc.committed, report.Committed)
+				for i, ok := range tc.results {
+					assert.Equal(t, ok, report.Results[i].OK)
+				}
+			}
+		})
+	}
+}
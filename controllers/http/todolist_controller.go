@@ -33,8 +33,8 @@ func (c *TodoListController) GetAll(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if todoLists, err = c.todoListService.GetAll(userID); err != nil {
-		response.SendErrorResponse(w, http.StatusNotFound, err)
+	if todoLists, err = c.todoListService.GetAll(r.Context(), userID); err != nil {
+		response.SendErrorResponse(w, accessErrorStatus(err, http.StatusNotFound), err)
 		return
 	}
 
@@ -59,8 +59,8 @@ func (c *TodoListController) Post(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err = c.todoListService.Create(userID, &todoList); err != nil {
-		response.SendErrorResponse(w, http.StatusInternalServerError, err)
+	if err = c.todoListService.Create(r.Context(), userID, &todoList); err != nil {
+		response.SendErrorResponse(w, accessErrorStatus(err, http.StatusInternalServerError), err)
 		return
 	}
 
@@ -80,8 +80,8 @@ func (c *TodoListController) GetSingle(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if todoList, err = c.todoListService.GetSingle(id); err != nil {
-		response.SendErrorResponse(w, http.StatusNotFound, err)
+	if todoList, err = c.todoListService.GetSingle(r.Context(), id); err != nil {
+		response.SendErrorResponse(w, accessErrorStatus(err, http.StatusNotFound), err)
 		return
 	}
 
@@ -107,9 +107,9 @@ func (c *TodoListController) Put(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	todoList, err = c.todoListService.Update(id, &todoListData)
+	todoList, err = c.todoListService.Update(r.Context(), id, &todoListData)
 	if err != nil {
-		response.SendErrorResponse(w, http.StatusNotFound, err)
+		response.SendErrorResponse(w, accessErrorStatus(err, http.StatusNotFound), err)
 		return
 	}
 
@@ -128,8 +128,8 @@ func (c *TodoListController) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err = c.todoListService.Delete(id); err != nil {
-		response.SendErrorResponse(w, http.StatusNotFound, err)
+	if err = c.todoListService.Delete(r.Context(), id); err != nil {
+		response.SendErrorResponse(w, accessErrorStatus(err, http.StatusNotFound), err)
 		return
 	}
 
@@ -22,9 +22,9 @@ func NewUserController(userService services.IUserService) *UserController {
 
 // GetAll returns all users from the db
 func (c *UserController) GetAll(w http.ResponseWriter, r *http.Request) {
-	users, err := c.UserService.GetAll()
+	users, err := c.UserService.GetAll(r.Context())
 	if err != nil {
-		response.SendErrorResponse(w, http.StatusInternalServerError, err)
+		response.SendErrorResponse(w, accessErrorStatus(err, http.StatusInternalServerError), err)
 		return
 	}
 
@@ -43,7 +43,7 @@ func (c *UserController) Post(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err = c.UserService.Create(&user); err != nil {
+	if err = c.UserService.Create(r.Context(), &user); err != nil {
 		response.SendErrorResponse(w, http.StatusInternalServerError, err)
 		return
 	}
@@ -64,8 +64,8 @@ func (c *UserController) GetSingle(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if user, err = c.UserService.GetSingle(id); err != nil {
-		response.SendErrorResponse(w, http.StatusNotFound, err)
+	if user, err = c.UserService.GetSingle(r.Context(), id); err != nil {
+		response.SendErrorResponse(w, accessErrorStatus(err, http.StatusNotFound), err)
 		return
 	}
 
@@ -91,8 +91,8 @@ func (c *UserController) Put(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if user, err = c.UserService.Update(id, &userData); err != nil {
-		response.SendErrorResponse(w, http.StatusNotFound, err)
+	if user, err = c.UserService.Update(r.Context(), id, &userData); err != nil {
+		response.SendErrorResponse(w, accessErrorStatus(err, http.StatusNotFound), err)
 		return
 	}
 
@@ -111,8 +111,8 @@ func (c *UserController) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err = c.UserService.Delete(id); err != nil {
-		response.SendErrorResponse(w, http.StatusNotFound, err)
+	if err = c.UserService.Delete(r.Context(), id); err != nil {
+		response.SendErrorResponse(w, accessErrorStatus(err, http.StatusNotFound), err)
 		return
 	}
 
@@ -0,0 +1,172 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/danikg/go-todo-rest-api/models"
+	"github.com/danikg/go-todo-rest-api/services"
+	"github.com/danikg/go-todo-rest-api/utils/response"
+	"github.com/danikg/go-todo-rest-api/utils/route"
+)
+
+// WebhookController ...
+type WebhookController struct {
+	webhookService services.IWebhookService
+}
+
+// NewWebhookController ...
+func NewWebhookController(webhookService services.IWebhookService) *WebhookController {
+	return &WebhookController{webhookService: webhookService}
+}
+
+// GetAll returns all webhooks by user id
+func (c *WebhookController) GetAll(w http.ResponseWriter, r *http.Request) {
+	var (
+		webhooks []models.Webhook
+		userID   uint
+		err      error
+	)
+
+	if userID, err = route.GetRouteVar(r, "user_id"); err != nil {
+		response.SendErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if webhooks, err = c.webhookService.GetAll(r.Context(), userID); err != nil {
+		response.SendErrorResponse(w, accessErrorStatus(err, http.StatusNotFound), err)
+		return
+	}
+
+	response.SendResponse(w, webhooks, 0)
+}
+
+// Post creates a new webhook, the response carries its secret
+func (c *WebhookController) Post(w http.ResponseWriter, r *http.Request) {
+	var (
+		webhook models.Webhook
+		userID  uint
+		err     error
+	)
+
+	if userID, err = route.GetRouteVar(r, "user_id"); err != nil {
+		response.SendErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err = json.NewDecoder(r.Body).Decode(&webhook); err != nil {
+		response.SendErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err = c.webhookService.Create(r.Context(), userID, &webhook); err != nil {
+		response.SendErrorResponse(w, webhookErrorStatus(err, http.StatusNotFound), err)
+		return
+	}
+
+	response.SendResponse(w, webhook, http.StatusCreated)
+}
+
+// GetSingle returns a single webhook by id
+func (c *WebhookController) GetSingle(w http.ResponseWriter, r *http.Request) {
+	var (
+		id      uint
+		webhook models.Webhook
+		err     error
+	)
+
+	if id, err = route.GetRouteVar(r, "id"); err != nil {
+		response.SendErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if webhook, err = c.webhookService.GetSingle(r.Context(), id); err != nil {
+		response.SendErrorResponse(w, accessErrorStatus(err, http.StatusNotFound), err)
+		return
+	}
+
+	response.SendResponse(w, webhook, 0)
+}
+
+// Put updates the webhook by id
+func (c *WebhookController) Put(w http.ResponseWriter, r *http.Request) {
+	var (
+		id          uint
+		webhookData models.Webhook
+		webhook     models.Webhook
+		err         error
+	)
+
+	if id, err = route.GetRouteVar(r, "id"); err != nil {
+		response.SendErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err = json.NewDecoder(r.Body).Decode(&webhookData); err != nil {
+		response.SendErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if webhook, err = c.webhookService.Update(r.Context(), id, &webhookData); err != nil {
+		response.SendErrorResponse(w, webhookErrorStatus(err, http.StatusNotFound), err)
+		return
+	}
+
+	response.SendResponse(w, webhook, 0)
+}
+
+// Delete removes the webhook by id
+func (c *WebhookController) Delete(w http.ResponseWriter, r *http.Request) {
+	var (
+		id  uint
+		err error
+	)
+
+	if id, err = route.GetRouteVar(r, "id"); err != nil {
+		response.SendErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err = c.webhookService.Delete(r.Context(), id); err != nil {
+		response.SendErrorResponse(w, accessErrorStatus(err, http.StatusNotFound), err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetDeliveries returns the delivery log of the webhook by id, newest first
+func (c *WebhookController) GetDeliveries(w http.ResponseWriter, r *http.Request) {
+	var (
+		id         uint
+		page       models.Page
+		deliveries []models.WebhookDelivery
+		err        error
+	)
+
+	if id, err = route.GetRouteVar(r, "id"); err != nil {
+		response.SendErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if page, err = route.GetPage(r); err != nil {
+		response.SendErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if deliveries, err = c.webhookService.GetDeliveries(r.Context(), id, page); err != nil {
+		response.SendErrorResponse(w, accessErrorStatus(err, http.StatusNotFound), err)
+		return
+	}
+
+	response.SendResponse(w, deliveries, 0)
+}
+
+// webhookErrorStatus maps the webhook validation errors, other errors get fallback
+func webhookErrorStatus(err error, fallback int) int {
+	if errors.Is(err, services.ErrInvalidWebhook) {
+		return http.StatusBadRequest
+	}
+	return accessErrorStatus(err, fallback)
+}
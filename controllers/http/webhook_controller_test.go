@@ -0,0 +1,270 @@
+package http
+
+import (
+	"encoding/json"
+	. "net/http"
+	"testing"
+
+	"github.com/danikg/go-todo-rest-api/models"
+	"github.com/danikg/go-todo-rest-api/services/mocks"
+	"github.com/danikg/go-todo-rest-api/utils/test"
+	"github.com/stretchr/testify/assert"
+)
+
+type webhookTest struct {
+	title      string
+	method     string
+	path       string
+	route      string
+	body       []byte
+	shouldPass bool
+	statusCode int
+	webhookID  uint
+	results    int
+}
+
+func testWebhookResult(t *testing.T, tc webhookTest, controller func(ResponseWriter, *Request)) {
+	w, r := test.NewRequest(tc.method, tc.path, tc.body)
+	test.MakeRequest(tc.route, controller, w, r)
+	assert.Equal(t, tc.statusCode, w.Code)
+
+	if tc.shouldPass {
+		if tc.results != 0 {
+			var result []json.RawMessage
+			json.NewDecoder(w.Body).Decode(&result)
+			assert.Len(t, result, tc.results)
+		} else {
+			var result models.Webhook
+			json.NewDecoder(w.Body).Decode(&result)
+			assert.Equal(t, tc.webhookID, result.ID)
+		}
+	}
+}
+
+func TestWebhookController_GetAll(t *testing.T) {
+	tests := []webhookTest{
+		{
+			title:      "Get all webhooks",
+			method:     "GET",
+			path:       "/users/1/webhooks",
+			route:      "/users/{user_id}/webhooks",
+			shouldPass: true,
+			statusCode: StatusOK,
+			results:    1,
+		},
+		{
+			title:      "Get all webhooks, wrong user_id",
+			method:     "GET",
+			path:       "/users/a/webhooks",
+			route:      "/users/{user_id}/webhooks",
+			shouldPass: false,
+			statusCode: StatusBadRequest,
+		},
+		{
+			title:      "Get all webhooks, non-existent user",
+			method:     "GET",
+			path:       "/users/2/webhooks",
+			route:      "/users/{user_id}/webhooks",
+			shouldPass: false,
+			statusCode: StatusNotFound,
+		},
+	}
+
+	webhookController := NewWebhookController(&mocks.WebhookServiceMock{})
+	for _, tc := range tests {
+		t.Run(tc.title, func(t *testing.T) {
+			testWebhookResult(t, tc, webhookController.GetAll)
+		})
+	}
+}
+
+func TestWebhookController_Post(t *testing.T) {
+	tests := []webhookTest{
+		{
+			title:      "Create webhook",
+			method:     "POST",
+			path:       "/users/1/webhooks",
+			route:      "/users/{user_id}/webhooks",
+			body:       []byte(`{"URL":"https://example.com/hook","Events":["todo_item.created"]}`),
+			shouldPass: true,
+			statusCode: StatusCreated,
+			webhookID:  2,
+		},
+		{
+			title:      "Create webhook, invalid webhook",
+			method:     "POST",
+			path:       "/users/1/webhooks",
+			route:      "/users/{user_id}/webhooks",
+			body:       []byte(`{"Events":["todo_item.created"]}`),
+			shouldPass: false,
+			statusCode: StatusBadRequest,
+		},
+		{
+			title:      "Create webhook, invalid body",
+			method:     "POST",
+			path:       "/users/1/webhooks",
+			route:      "/users/{user_id}/webhooks",
+			body:       []byte(`{"URL":`),
+			shouldPass: false,
+			statusCode: StatusBadRequest,
+		},
+		{
+			title:      "Create webhook, non-existent user",
+			method:     "POST",
+			path:       "/users/2/webhooks",
+			route:      "/users/{user_id}/webhooks",
+			body:       []byte(`{"URL":"https://example.com/hook","Events":["todo_item.created"]}`),
+			shouldPass: false,
+			statusCode: StatusNotFound,
+		},
+	}
+
+	webhookController := NewWebhookController(&mocks.WebhookServiceMock{})
+	for _, tc := range tests {
+		t.Run(tc.title, func(t *testing.T) {
+			testWebhookResult(t, tc, webhookController.Post)
+		})
+	}
+}
+
+func TestWebhookController_GetSingle(t *testing.T) {
+	tests := []webhookTest{
+		{
+			title:      "Get webhook",
+			method:     "GET",
+			path:       "/webhooks/1",
+			route:      "/webhooks/{id}",
+			shouldPass: true,
+			statusCode: StatusOK,
+			webhookID:  1,
+		},
+		{
+			title:      "Get webhook, wrong id",
+			method:     "GET",
+			path:       "/webhooks/a",
+			route:      "/webhooks/{id}",
+			shouldPass: false,
+			statusCode: StatusBadRequest,
+		},
+		{
+			title:      "Get webhook, non-existent id",
+			method:     "GET",
+			path:       "/webhooks/2",
+			route:      "/webhooks/{id}",
+			shouldPass: false,
+			statusCode: StatusNotFound,
+		},
+	}
+
+	webhookController := NewWebhookController(&mocks.WebhookServiceMock{})
+	for _, tc := range tests {
+		t.Run(tc.title, func(t *testing.T) {
+			testWebhookResult(t, tc, webhookController.GetSingle)
+		})
+	}
+}
+
+func TestWebhookController_Put(t *testing.T) {
+	tests := []webhookTest{
+		{
+			title:      "Update webhook",
+			method:     "PUT",
+			path:       "/webhooks/1",
+			route:      "/webhooks/{id}",
+			body:       []byte(`{"Events":["todo_item.completed"]}`),
+			shouldPass: true,
+			statusCode: StatusOK,
+			webhookID:  1,
+		},
+		{
+			title:      "Update webhook, invalid webhook",
+			method:     "PUT",
+			path:       "/webhooks/1",
+			route:      "/webhooks/{id}",
+			body:       []byte(`{"URL":"ftp://example.com"}`),
+			shouldPass: false,
+			statusCode: StatusBadRequest,
+		},
+		{
+			title:      "Update webhook, non-existent id",
+			method:     "PUT",
+			path:       "/webhooks/2",
+			route:      "/webhooks/{id}",
+			body:       []byte(`{}`),
+			shouldPass: false,
+			statusCode: StatusNotFound,
+		},
+	}
+
+	webhookController := NewWebhookController(&mocks.WebhookServiceMock{})
+	for _, tc := range tests {
+		t.Run(tc.title, func(t *testing.T) {
+			testWebhookResult(t, tc, webhookController.Put)
+		})
+	}
+}
+
+func TestWebhookController_Delete(t *testing.T) {
+	tests := []webhookTest{
+		{
+			title:      "Delete webhook",
+			method:     "DELETE",
+			path:       "/webhooks/1",
+			route:      "/webhooks/{id}",
+			shouldPass: false,
+			statusCode: StatusNoContent,
+		},
+		{
+			title:      "Delete webhook, non-existent id",
+			method:     "DELETE",
+			path:       "/webhooks/2",
+			route:      "/webhooks/{id}",
+			shouldPass: false,
+			statusCode: StatusNotFound,
+		},
+	}
+
+	webhookController := NewWebhookController(&mocks.WebhookServiceMock{})
+	for _, tc := range tests {
+		t.Run(tc.title, func(t *testing.T) {
+			testWebhookResult(t, tc, webhookController.Delete)
+		})
+	}
+}
+
+func TestWebhookController_GetDeliveries(t *testing.T) {
+	tests := []webhookTest{
+		{
+			title:      "Get webhook deliveries",
+			method:     "GET",
+			path:       "/webhooks/1/deliveries?limit=10",
+			route:      "/webhooks/{id}/deliveries",
+			shouldPass: true,
+			statusCode: StatusOK,
+			results:    2,
+		},
+		{
+			title:      "Get webhook deliveries, wrong limit",
+			method:     "GET",
+			path:       "/webhooks/1/deliveries?limit=a",
+			route:      "/webhooks/{id}/deliveries",
+			shouldPass: false,
+			statusCode: StatusBadRequest,
+		},
+		{
+			title:      "Get webhook deliveries, non-existent id",
+			method:     "GET",
+			path:       "/webhooks/2/deliveries",
+			route:      "/webhooks/{id}/deliveries",
+			shouldPass: false,
+			statusCode: StatusNotFound,
+		},
+	}
+
+	webhookController := NewWebhookController(&mocks.WebhookServiceMock{})
+	for _, tc := range tests {
+		t.Run(tc.title, func(t *testing.T) {
+			testWebhookResult(t, tc, webhookController.GetDeliveries)
+		})
+	}
+}
@@ -0,0 +1,13 @@
+package http
+
+import "github.com/gorilla/mux"
+
+// SetupWebhookRoutes ...
+func SetupWebhookRoutes(router *mux.Router, controller *WebhookController) {
+	router.HandleFunc("/users/{user_id}/webhooks", controller.GetAll).Methods("GET")
+	router.HandleFunc("/users/{user_id}/webhooks", controller.Post).Methods("POST")
+	router.HandleFunc("/webhooks/{id}", controller.GetSingle).Methods("GET")
+	router.HandleFunc("/webhooks/{id}", controller.Put).Methods("PUT")
+	router.HandleFunc("/webhooks/{id}", controller.Delete).Methods("DELETE")
+	router.HandleFunc("/webhooks/{id}/deliveries", controller.GetDeliveries).Methods("GET")
+}
@@ -0,0 +1,230 @@
+// Package fixtures generates realistic users, todo lists, todo items and tags for local development
+// and tests. The data depends only on the options, so a seed value always yields the same data.
+//
+//	data := fixtures.Generate(fixtures.Options{Seed: 42, Scale: 2})
+//	err := fixtures.Load(ctx, transactor, &data)
+package fixtures
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/danikg/go-todo-rest-api/models"
+	repos "github.com/danikg/go-todo-rest-api/repositories"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Defaults of the options
+const (
+	DefaultSeed     = 1
+	DefaultScale    = 1
+	DefaultPassword = "password"
+)
+
+// DefaultNow anchors the due dates of the seed command, so the same seed and scale give the same data on any day
+var DefaultNow = time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+
+// UsersPerScale is the number of users generated per unit of scale
+const UsersPerScale = 3
+
+// Options ...
+type Options struct {
+	Seed  int64
+	Scale int
+	// Password is given to every user
+	Password string
+	// Now anchors the due dates, the zero value stands for the start of the current day
+	Now time.Time
+}
+
+// Data holds the generated users, the IDs are set by Load
+type Data struct {
+	Users []User
+}
+
+// User is a user with its todo lists, Password keeps the plain text password after Load
+type User struct {
+	models.User
+	TodoLists []TodoList
+}
+
+// TodoList is a todo list with its todo items
+type TodoList struct {
+	models.TodoList
+	TodoItems []models.TodoItem
+}
+
+// Priority tags are named like those of the todo.txt import
+var priorities = []string{"priority:A", "priority:B", "priority:C"}
+
+var usernames = []string{"alice", "bob", "carol", "dave", "erin", "frank", "grace", "heidi", "ivan", "judy"}
+
+// tags is the vocabulary the tags of the users are picked from
+var tags = []models.Tag{
+	{Text: "work", Color: "#1f77b4"},
+	{Text: "home", Color: "#2ca02c"},
+	{Text: "errands", Color: "#ff7f0e"},
+	{Text: "health", Color: "#d62728"},
+	{Text: "finance", Color: "#9467bd"},
+	{Text: "family", Color: "#e377c2"},
+	{Text: "someday"},
+}
+
+// listTemplates pair the todo list names with the titles of their todo items
+var listTemplates = []struct {
+	name   string
+	titles []string
+}{
+	{"Groceries", []string{"Buy milk", "Buy eggs", "Get fresh bread", "Pick up coffee beans", "Buy apples", "Restock olive oil", "Buy rice", "Get cheese for the weekend"}},
+	{"Work", []string{"Prepare the quarterly report", "Review pull requests", "Update the roadmap", "Book the team offsite", "Write the release notes", "Plan the sprint", "Reply to the customer survey", "Fix the flaky build"}},
+	{"Home", []string{"Fix the leaking tap", "Clean the gutters", "Replace the smoke detector battery", "Paint the fence", "Declutter the garage", "Water the plants", "Change the bed sheets"}},
+	{"Errands", []string{"Return the library books", "Pick up the dry cleaning", "Post the parcel", "Renew the passport", "Get the car serviced", "Drop off the recycling"}},
+	{"Health", []string{"Book a dentist appointment", "Refill the prescription", "Go for a run", "Schedule the annual checkup", "Try the new yoga class"}},
+	{"Finance", []string{"Pay the electricity bill", "File the tax return", "Review the budget", "Cancel the unused subscription", "Transfer savings"}},
+	{"Reading list", []string{"Finish the novel", "Read the architecture paper", "Start the history book", "Skim the conference proceedings", "Read the newsletter backlog"}},
+	{"Trip planning", []string{"Book the flights", "Reserve the hotel", "Buy travel insurance", "Make a packing list", "Exchange currency", "Plan the first day"}},
+}
+
+var descriptions = []string{
+	"",
+	"",
+	"Ask for a quote first.",
+	"Check the notes from last time.",
+	"Needs about an hour.",
+	"Do it before the weekend.",
+	"See the shared document for details.",
+}
+
+var recurrences = []string{"FREQ=DAILY", "FREQ=WEEKLY", "FREQ=WEEKLY;BYDAY=MO", "FREQ=MONTHLY"}
+
+// Generate builds the data of the options, scale 1 yields UsersPerScale users
+// with 2 to 4 todo lists of 3 to 8 todo items each
+func Generate(options Options) Data {
+	if options.Scale <= 0 {
+		options.Scale = DefaultScale
+	}
+	if options.Password == "" {
+		options.Password = DefaultPassword
+	}
+	now := options.Now
+	if now.IsZero() {
+		year, month, day := time.Now().Date()
+		now = time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	rnd := rand.New(rand.NewSource(options.Seed))
+	data := Data{Users: make([]User, options.Scale*UsersPerScale)}
+	for i := range data.Users {
+		data.Users[i] = generateUser(rnd, i, options.Password, now)
+	}
+	return data
+}
+
+func generateUser(rnd *rand.Rand, i int, password string, now time.Time) User {
+	username := usernames[i%len(usernames)]
+	if i >= len(usernames) {
+		username = fmt.Sprintf("%s%d", username, i/len(usernames)+1)
+	}
+	user := User{User: models.User{Username: username, Password: password}}
+
+	// every user gets a few tags of the vocabulary
+	userTags := rnd.Perm(len(tags))[:2+rnd.Intn(3)]
+	for _, j := range rnd.Perm(len(listTemplates))[:2+rnd.Intn(3)] {
+		template := listTemplates[j]
+		todoList := TodoList{TodoList: models.TodoList{Name: template.name}}
+
+		titles := rnd.Perm(len(template.titles))
+		for _, k := range titles[:min(len(titles), 3+rnd.Intn(6))] {
+			todoList.TodoItems = append(todoList.TodoItems, generateTodoItem(rnd, template.titles[k], userTags, now))
+		}
+		user.TodoLists = append(user.TodoLists, todoList)
+	}
+	return user
+}
+
+func generateTodoItem(rnd *rand.Rand, title string, userTags []int, now time.Time) models.TodoItem {
+	todoItem := models.TodoItem{
+		Title:       title,
+		Description: descriptions[rnd.Intn(len(descriptions))],
+		Completed:   rnd.Intn(10) < 3,
+		Tags:        []models.Tag{},
+	}
+
+	if rnd.Intn(10) < 6 {
+		// from a week ago to a month ahead, during the day
+		due := now.AddDate(0, 0, rnd.Intn(38)-7).Add(time.Duration(8+rnd.Intn(10)) * time.Hour)
+		todoItem.Due = &due
+		if rnd.Intn(10) == 0 {
+			todoItem.Recurrence = recurrences[rnd.Intn(len(recurrences))]
+		}
+	}
+
+	if rnd.Intn(10) < 4 {
+		todoItem.Tags = append(todoItem.Tags, models.Tag{Text: priorities[rnd.Intn(len(priorities))]})
+	}
+	for _, j := range userTags {
+		if rnd.Intn(3) == 0 {
+			todoItem.Tags = append(todoItem.Tags, models.Tag{Text: tags[j].Text, Color: tags[j].Color})
+		}
+	}
+	return todoItem
+}
+
+// Load creates the data in one transaction and sets the IDs of the created records,
+// the tags of the todo items are created in the vocabulary of their user
+func Load(ctx context.Context, transactor repos.ITransactor, data *Data) error {
+	// the users share the password of the options, hashing it once keeps loading fast
+	hashes := map[string]string{}
+	for i := range data.Users {
+		user := &data.Users[i].User
+		if _, ok := hashes[user.Password]; !ok {
+			hash, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
+			if err != nil {
+				return err
+			}
+			hashes[user.Password] = string(hash)
+		}
+	}
+
+	return transactor.Transaction(ctx, func(r repos.Repositories) error {
+		for i := range data.Users {
+			if err := loadUser(ctx, r, &data.Users[i], hashes); err != nil {
+				return fmt.Errorf("user %s: %w", data.Users[i].Username, err)
+			}
+		}
+		return nil
+	})
+}
+
+func loadUser(ctx context.Context, r repos.Repositories, user *User, hashes map[string]string) error {
+	user.PasswordHash = hashes[user.Password]
+	if err := r.User.Create(ctx, &user.User); err != nil {
+		return err
+	}
+
+	for i := range user.TodoLists {
+		todoList := &user.TodoLists[i]
+		if err := r.TodoList.Create(ctx, user.ID, &todoList.TodoList); err != nil {
+			return err
+		}
+
+		for j := range todoList.TodoItems {
+			todoItem := &todoList.TodoItems[j]
+			// the tags are attached one by one so that they join the vocabulary of the user
+			tags := todoItem.Tags
+			todoItem.Tags = nil
+			if err := r.TodoItem.Create(ctx, todoList.ID, todoItem); err != nil {
+				return err
+			}
+			for k := range tags {
+				if err := r.Tag.Create(ctx, todoItem, &tags[k]); err != nil {
+					return err
+				}
+			}
+			todoItem.Tags = tags
+		}
+	}
+	return nil
+}
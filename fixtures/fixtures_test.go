@@ -0,0 +1,83 @@
+package fixtures
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var now = time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+
+func TestGenerate_Deterministic(t *testing.T) {
+	first := Generate(Options{Seed: 42, Scale: 2, Now: now})
+	assert.Equal(t, first, Generate(Options{Seed: 42, Scale: 2, Now: now}))
+	assert.NotEqual(t, first, Generate(Options{Seed: 43, Scale: 2, Now: now}))
+}
+
+func TestGenerate_Scale(t *testing.T) {
+	tests := []struct {
+		name  string
+		scale int
+		users int
+	}{
+		{"default", 0, UsersPerScale},
+		{"one", 1, UsersPerScale},
+		{"five", 5, 5 * UsersPerScale},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			data := Generate(Options{Seed: 1, Scale: tc.scale, Now: now})
+			assert.Len(t, data.Users, tc.users)
+
+			usernames := map[string]bool{}
+			for _, user := range data.Users {
+				assert.False(t, usernames[user.Username], user.Username)
+				usernames[user.Username] = true
+				assert.Equal(t, DefaultPassword, user.Password)
+				assert.True(t, len(user.TodoLists) >= 2 && len(user.TodoLists) <= 4)
+			}
+		})
+	}
+}
+
+func TestGenerate_TodoItems(t *testing.T) {
+	var items, due, completed, prioritized, tagged int
+	for _, user := range Generate(Options{Seed: 7, Scale: 4, Now: now}).Users {
+		for _, todoList := range user.TodoLists {
+			assert.True(t, len(todoList.TodoItems) >= 3)
+			for _, todoItem := range todoList.TodoItems {
+				items++
+				assert.NotEmpty(t, todoItem.Title)
+				if todoItem.Recurrence != "" {
+					assert.NotNil(t, todoItem.Due, "recurring items need a due date")
+				}
+				if todoItem.Due != nil {
+					due++
+					assert.True(t, !todoItem.Due.Before(now.AddDate(0, 0, -7)) && todoItem.Due.Before(now.AddDate(0, 0, 32)))
+				}
+				if todoItem.Completed {
+					completed++
+				}
+				texts := map[string]bool{}
+				for _, tag := range todoItem.Tags {
+					assert.False(t, texts[tag.Text], "tags are attached once")
+					texts[tag.Text] = true
+					if strings.HasPrefix(tag.Text, "priority:") {
+						prioritized++
+					} else {
+						tagged++
+					}
+				}
+			}
+		}
+	}
+
+	// the mix of a realistic list
+	assert.True(t, due > 0 && due < items)
+	assert.True(t, completed > 0 && completed < items)
+	assert.True(t, prioritized > 0 && prioritized < items)
+	assert.True(t, tagged > 0)
+}
@@ -0,0 +1,97 @@
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/danikg/go-todo-rest-api/models"
+	"github.com/stretchr/testify/assert"
+)
+
+type accessRoute struct {
+	method string
+	path   string
+	body   interface{}
+}
+
+// accessRoutes read or change the data of alice
+func accessRoutes(alice models.User, todoList models.TodoList, milk models.TodoItem, dairy models.Tag) []accessRoute {
+	return []accessRoute{
+		{"GET", fmt.Sprintf("/users/%d", alice.ID), nil},
+		{"PUT", fmt.Sprintf("/users/%d", alice.ID), models.User{Username: "mallory"}},
+		{"DELETE", fmt.Sprintf("/users/%d", alice.ID), nil},
+		{"GET", fmt.Sprintf("/users/%d/todo_lists", alice.ID), nil},
+		{"POST", fmt.Sprintf("/users/%d/todo_lists", alice.ID), models.TodoList{Name: "Planted"}},
+		{"GET", fmt.Sprintf("/todo_lists/%d", todoList.ID), nil},
+		{"PUT", fmt.Sprintf("/todo_lists/%d", todoList.ID), models.TodoList{Name: "Renamed"}},
+		{"DELETE", fmt.Sprintf("/todo_lists/%d", todoList.ID), nil},
+		{"GET", fmt.Sprintf("/todo_lists/%d/todo_items", todoList.ID), nil},
+		{"POST", fmt.Sprintf("/todo_lists/%d/todo_items", todoList.ID), models.TodoItem{Title: "Planted"}},
+		{"GET", fmt.Sprintf("/todo_items/%d", milk.ID), nil},
+		{"PUT", fmt.Sprintf("/todo_items/%d", milk.ID), models.TodoItem{Title: "Renamed"}},
+		{"DELETE", fmt.Sprintf("/todo_items/%d", milk.ID), nil},
+		{"POST", "/todo_items/bulk", models.BulkRequest{Operations: []models.BulkOperation{{Op: models.BulkDelete, ID: milk.ID}}}},
+		{"GET", fmt.Sprintf("/todo_items/%d/tags", milk.ID), nil},
+		{"POST", fmt.Sprintf("/todo_items/%d/tags", milk.ID), models.Tag{Text: "planted"}},
+		{"DELETE", fmt.Sprintf("/todo_items/%d/tags/%d", milk.ID, dairy.ID), nil},
+		{"GET", fmt.Sprintf("/users/%d/tags", alice.ID), nil},
+		{"POST", fmt.Sprintf("/users/%d/tags", alice.ID), models.Tag{Text: "planted"}},
+		{"GET", fmt.Sprintf("/users/%d/todo_items?tag=dairy", alice.ID), nil},
+		{"GET", fmt.Sprintf("/tags/%d", dairy.ID), nil},
+		{"GET", fmt.Sprintf("/tags/%d/todo_items", dairy.ID), nil},
+		{"PUT", fmt.Sprintf("/tags/%d", dairy.ID), models.Tag{Text: "renamed"}},
+		{"DELETE", fmt.Sprintf("/tags/%d", dairy.ID), nil},
+	}
+}
+
+// The data of a user cannot be read or changed with the token of another user
+func TestAccess(t *testing.T) {
+	s := newServer(t)
+	alice := s.signUp("alice")
+	todoList := s.createTodoList(alice.ID, "Groceries")
+	milk := s.createTodoItem(todoList.ID, "Buy milk")
+	dairy := s.tag(milk.ID, "dairy")
+	s.signUp("bob")
+
+	for _, tc := range accessRoutes(alice, todoList, milk, dairy) {
+		t.Run(tc.method+" "+tc.path, func(t *testing.T) {
+			s.with(t).expect(tc.method, tc.path, tc.body, http.StatusForbidden, nil)
+		})
+	}
+
+	// nothing of alice was changed
+	s.signIn("alice")
+	var todoItem models.TodoItem
+	s.expect("GET", fmt.Sprintf("/todo_items/%d", milk.ID), nil, http.StatusOK, &todoItem)
+	assert.Equal(t, "Buy milk", todoItem.Title)
+	var todoLists []models.TodoList
+	s.expect("GET", fmt.Sprintf("/users/%d/todo_lists", alice.ID), nil, http.StatusOK, &todoLists)
+	assert.Len(t, todoLists, 1)
+	assert.Equal(t, int64(1), s.countLinks("todo_item_id = ?", milk.ID))
+}
+
+// Without a token nothing can be read or changed once authentication is enabled
+func TestAccess_WithoutToken(t *testing.T) {
+	s := newServer(t)
+	alice := s.signUp("alice")
+	todoList := s.createTodoList(alice.ID, "Groceries")
+	milk := s.createTodoItem(todoList.ID, "Buy milk")
+	dairy := s.tag(milk.ID, "dairy")
+
+	s.token = ""
+	routes := append(accessRoutes(alice, todoList, milk, dairy),
+		accessRoute{"GET", "/users", nil},
+		accessRoute{"GET", fmt.Sprintf("/users/%d/activity", alice.ID), nil},
+		accessRoute{"GET", fmt.Sprintf("/users/%d/export", alice.ID), nil},
+		accessRoute{"GET", "/sync", nil},
+		accessRoute{"GET", "/events", nil},
+		accessRoute{"POST", "/graphql", map[string]string{"query": "{ users { id } }"}},
+	)
+	for _, tc := range routes {
+		t.Run(tc.method+" "+tc.path, func(t *testing.T) {
+			s.with(t).expect(tc.method, tc.path, tc.body, http.StatusUnauthorized, nil)
+		})
+	}
+	assert.Equal(t, int64(1), s.count(&models.TodoItem{}, "id = ?", milk.ID))
+}
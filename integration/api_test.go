@@ -0,0 +1,248 @@
+package integration
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danikg/go-todo-rest-api/config"
+	"github.com/danikg/go-todo-rest-api/models"
+	"github.com/danikg/go-todo-rest-api/utils/graphql"
+	"github.com/stretchr/testify/assert"
+
+	controllers "github.com/danikg/go-todo-rest-api/controllers/http"
+)
+
+func TestActivity(t *testing.T) {
+	s := newServer(t)
+	alice := s.signUp("alice")
+	todoList := s.createTodoList(alice.ID, "Groceries")
+	milk := s.createTodoItem(todoList.ID, "Buy milk")
+	milk.Title = "Buy oat milk"
+	s.expect("PUT", fmt.Sprintf("/todo_items/%d", milk.ID), milk, http.StatusOK, nil)
+	s.tag(milk.ID, "dairy")
+
+	// the activity of a list outlives it
+	s.expect("DELETE", fmt.Sprintf("/todo_lists/%d", todoList.ID), nil, http.StatusNoContent, nil)
+	var events []models.AuditEvent
+	s.expect("GET", fmt.Sprintf("/todo_lists/%d/activity", todoList.ID), nil, http.StatusOK, &events)
+	if assert.NotEmpty(t, events) {
+		// the newest event comes first
+		assert.Equal(t, models.AuditDelete, events[0].Action)
+		assert.Equal(t, models.AuditTodoList, events[0].ResourceType)
+		assert.Equal(t, models.AuditCreate, events[len(events)-1].Action)
+	}
+	actions := map[string]int{}
+	for _, event := range events {
+		actions[event.ResourceType+" "+event.Action]++
+		assert.Equal(t, alice.ID, *event.ActorID)
+	}
+	assert.Equal(t, 1, actions["todo_item update"])
+
+	// the tag events belong to the user only
+	s.expect("GET", fmt.Sprintf("/users/%d/activity", alice.ID), nil, http.StatusOK, &events)
+	actions = map[string]int{}
+	for _, event := range events {
+		actions[event.ResourceType+" "+event.Action]++
+	}
+	assert.Equal(t, 1, actions["tag attach"])
+	assert.Equal(t, 1, actions["todo_list delete"])
+	s.expect("GET", fmt.Sprintf("/users/%d/activity?limit=2", alice.ID), nil, http.StatusOK, &events)
+	assert.Len(t, events, 2)
+	s.expect("GET", fmt.Sprintf("/users/%d/activity?limit=x", alice.ID), nil, http.StatusBadRequest, nil)
+
+	s.signUp("bob")
+	s.expect("GET", fmt.Sprintf("/todo_lists/%d/activity", todoList.ID), nil, http.StatusForbidden, nil)
+	s.expect("GET", fmt.Sprintf("/users/%d/activity", alice.ID), nil, http.StatusForbidden, nil)
+}
+
+func TestGraphQL(t *testing.T) {
+	s := newServer(t)
+	alice := s.signUp("alice")
+
+	// the data is kept raw, graphql.Map only encodes
+	type response struct {
+		Data   json.RawMessage
+		Errors []graphql.Error
+	}
+	query := func(query string, variables map[string]interface{}, status int) response {
+		var result response
+		s.expect("POST", "/graphql", graphql.Request{Query: query, Variables: variables}, status, &result)
+		return result
+	}
+	data := func(result response) string { return string(result.Data) }
+
+	result := query(`mutation ($userId: Int!) { createTodoList(userId: $userId, name: "Groceries") { id name } }`,
+		map[string]interface{}{"userId": alice.ID}, http.StatusOK)
+	assert.Empty(t, result.Errors)
+	var created struct{ CreateTodoList struct{ ID uint } }
+	json.Unmarshal([]byte(data(result)), &created)
+	listID := created.CreateTodoList.ID
+	assert.NotZero(t, listID)
+
+	result = query(fmt.Sprintf(`mutation {
+		milk: createTodoItem(listId: %d, title: "Buy milk") { id }
+		bread: createTodoItem(listId: %d, title: "Buy bread") { id }
+	}`, listID, listID), nil, http.StatusOK)
+	assert.Empty(t, result.Errors)
+	var items struct{ Milk struct{ ID uint } }
+	json.Unmarshal([]byte(data(result)), &items)
+	result = query(fmt.Sprintf(`mutation { createTag(itemId: %d, text: "dairy") { text } }`, items.Milk.ID), nil, http.StatusOK)
+	assert.Empty(t, result.Errors)
+
+	// the nested fields are loaded from the db
+	result = query(fmt.Sprintf(`{ user(id: %d) { username todoLists { name todoItems { title tags { text } } } } }`, alice.ID), nil, http.StatusOK)
+	assert.Empty(t, result.Errors)
+	assert.JSONEq(t, `{"user": {"username": "alice", "todoLists": [{"name": "Groceries", "todoItems": [
+		{"title": "Buy milk", "tags": [{"text": "dairy"}]},
+		{"title": "Buy bread", "tags": []}
+	]}]}}`, data(result))
+	result = query(fmt.Sprintf(`{ tags(userId: %d) { usageCount tag { text } } }`, alice.ID), nil, http.StatusOK)
+	assert.JSONEq(t, `{"tags": [{"usageCount": 1, "tag": {"text": "dairy"}}]}`, data(result))
+
+	result = query(fmt.Sprintf(`mutation { deleteTodoList(id: %d) }`, listID), nil, http.StatusOK)
+	assert.JSONEq(t, `{"deleteTodoList": true}`, data(result))
+	assert.Zero(t, s.count(&models.TodoItem{}, "todo_list_id = ?", listID))
+
+	// field errors come with the data, invalid queries without
+	result = query(`{ todoList(id: 999) { name } }`, nil, http.StatusOK)
+	assert.NotEmpty(t, result.Errors)
+	result = query(`{ user(id: 1) { password } }`, nil, http.StatusBadRequest)
+	assert.NotEmpty(t, result.Errors)
+
+	resp := s.do("GET", "/graphql/schema", nil, nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	schema, _ := io.ReadAll(resp.Body)
+	assert.Contains(t, string(schema), "type TodoItem")
+}
+
+func TestEvents(t *testing.T) {
+	s := newServer(t)
+	alice := s.signUp("alice")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	r, _ := http.NewRequestWithContext(ctx, "GET", s.url+"/events", nil)
+	r.Header.Set("Authorization", "Bearer "+s.token)
+	resp, err := http.DefaultClient.Do(r)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	// the changes made by another user are not pushed to alice
+	bob := s.signUp("bob")
+	s.createTodoList(bob.ID, "Work")
+	s.signIn("alice")
+	todoList := s.createTodoList(alice.ID, "Groceries")
+
+	var event models.ChangeEvent
+	lines := bufio.NewScanner(resp.Body)
+	for lines.Scan() {
+		if data := strings.TrimPrefix(lines.Text(), "data: "); data != lines.Text() {
+			assert.NoError(t, json.Unmarshal([]byte(data), &event))
+			break
+		}
+	}
+	assert.Equal(t, models.EventTodoListCreated, event.Type)
+	assert.Equal(t, todoList.ID, event.TodoListID)
+
+	s.token = ""
+	s.expect("GET", "/events", nil, http.StatusUnauthorized, nil)
+}
+
+func TestEvents_WithoutAuth(t *testing.T) {
+	// the default config serves the whole API without tokens, the events of every user included
+	s := newServer(t, func(cfg *config.Config) { cfg.Auth.Enabled = false })
+	var alice, bob models.User
+	s.expect("POST", "/users", models.User{Username: "alice", Password: password}, http.StatusCreated, &alice)
+	s.expect("POST", "/users", models.User{Username: "bob", Password: password}, http.StatusCreated, &bob)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	r, _ := http.NewRequestWithContext(ctx, "GET", s.url+"/events", nil)
+	resp, err := http.DefaultClient.Do(r)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	aliceList := s.createTodoList(alice.ID, "Groceries")
+	bobList := s.createTodoList(bob.ID, "Work")
+
+	var todoListIDs []uint
+	lines := bufio.NewScanner(resp.Body)
+	for len(todoListIDs) < 2 && lines.Scan() {
+		if data := strings.TrimPrefix(lines.Text(), "data: "); data != lines.Text() {
+			var event models.ChangeEvent
+			assert.NoError(t, json.Unmarshal([]byte(data), &event))
+			todoListIDs = append(todoListIDs, event.TodoListID)
+		}
+	}
+	assert.Equal(t, []uint{aliceList.ID, bobList.ID}, todoListIDs)
+}
+
+func TestIdempotency(t *testing.T) {
+	s := newServer(t)
+	alice := s.signUp("alice")
+
+	post := func(name string) (*http.Response, models.TodoList) {
+		body, _ := json.Marshal(models.TodoList{Name: name})
+		r, _ := http.NewRequest("POST", fmt.Sprintf("%s/users/%d/todo_lists", s.url, alice.ID), bytes.NewReader(body))
+		r.Header.Set("Authorization", "Bearer "+s.token)
+		r.Header.Set(controllers.IdempotencyKeyHeader, "groceries")
+		resp, err := http.DefaultClient.Do(r)
+		if err != nil {
+			t.Fatalf("failed to post todo list: %v", err)
+		}
+		defer resp.Body.Close()
+		var todoList models.TodoList
+		json.NewDecoder(resp.Body).Decode(&todoList)
+		return resp, todoList
+	}
+
+	resp, created := post("Groceries")
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	resp, replayed := post("Groceries")
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, "true", resp.Header.Get(controllers.IdempotentReplayedHeader))
+	assert.Equal(t, created.ID, replayed.ID)
+	assert.Equal(t, int64(1), s.count(&models.TodoList{}, "user_id = ?", alice.ID))
+
+	// the key cannot be reused for another request
+	resp, _ = post("Work")
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+}
+
+func TestDocs(t *testing.T) {
+	s := newServer(t)
+
+	tests := []struct {
+		path        string
+		contentType string
+		contains    string
+	}{
+		{"/openapi.json", "application/json", `"openapi"`},
+		{"/docs", "text/html; charset=utf-8", "openapi.json"},
+		{"/metrics", "text/plain", "todo_api_"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.path, func(t *testing.T) {
+			resp := s.with(t).do("GET", tc.path, nil, nil)
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Contains(t, resp.Header.Get("Content-Type"), tc.contentType)
+			data, _ := io.ReadAll(resp.Body)
+			assert.Contains(t, string(data), tc.contains)
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package integration
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danikg/go-todo-rest-api/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalendarFeed(t *testing.T) {
+	s := newServer(t)
+	alice := s.signUp("alice")
+	groceries := s.createTodoList(alice.ID, "Groceries")
+	work := s.createTodoList(alice.ID, "Work")
+	due := time.Date(2030, 1, 6, 9, 0, 0, 0, time.UTC)
+	s.expect("POST", fmt.Sprintf("/todo_lists/%d/todo_items", groceries.ID), models.TodoItem{Title: "Buy milk", Due: &due}, http.StatusCreated, nil)
+	s.expect("POST", fmt.Sprintf("/todo_lists/%d/todo_items", work.ID), models.TodoItem{Title: "Write the report", Due: &due}, http.StatusCreated, nil)
+
+	var feed models.Feed
+	s.expect("POST", fmt.Sprintf("/users/%d/feed", alice.ID), nil, http.StatusCreated, &feed)
+	s.expect("POST", "/users/999/feed", nil, http.StatusForbidden, nil)
+
+	// calendar apps send no bearer token
+	s.token = ""
+	resp := s.do("GET", feed.Path, nil, nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, resp.Header.Get("Content-Type"), "text/calendar")
+	data, _ := io.ReadAll(resp.Body)
+	assert.Contains(t, string(data), "SUMMARY:Buy milk")
+	assert.Contains(t, string(data), "SUMMARY:Write the report")
+
+	r, _ := http.NewRequest("GET", s.url+feed.Path, nil)
+	r.Header.Set("If-None-Match", resp.Header.Get("ETag"))
+	notModified, err := http.DefaultClient.Do(r)
+	if assert.NoError(t, err) {
+		notModified.Body.Close()
+		assert.Equal(t, http.StatusNotModified, notModified.StatusCode)
+	}
+	assert.Equal(t, http.StatusOK, s.do("HEAD", feed.Path, nil, nil).StatusCode)
+
+	resp = s.do("GET", strings.Replace(feed.ListPath, "{list_id}", fmt.Sprint(work.ID), 1), nil, nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	data, _ = io.ReadAll(resp.Body)
+	assert.NotContains(t, string(data), "Buy milk")
+	assert.Contains(t, string(data), "Write the report")
+	s.expect("GET", "/feeds/unknown.ics", nil, http.StatusNotFound, nil)
+
+	// another user can neither replace nor revoke the feed
+	s.signUp("bob")
+	s.expect("POST", fmt.Sprintf("/users/%d/feed", alice.ID), nil, http.StatusForbidden, nil)
+	s.expect("DELETE", fmt.Sprintf("/users/%d/feed", alice.ID), nil, http.StatusForbidden, nil)
+	s.expect("GET", feed.Path, nil, http.StatusOK, nil)
+
+	// a new token replaces the previous one, deleting it revokes the feed
+	s.signIn("alice")
+	var renewed models.Feed
+	s.expect("POST", fmt.Sprintf("/users/%d/feed", alice.ID), nil, http.StatusCreated, &renewed)
+	s.expect("GET", feed.Path, nil, http.StatusNotFound, nil)
+	s.expect("GET", renewed.Path, nil, http.StatusOK, nil)
+	s.expect("DELETE", fmt.Sprintf("/users/%d/feed", alice.ID), nil, http.StatusNoContent, nil)
+	s.expect("GET", renewed.Path, nil, http.StatusNotFound, nil)
+}
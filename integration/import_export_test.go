@@ -0,0 +1,154 @@
+package integration
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danikg/go-todo-rest-api/models"
+	"github.com/stretchr/testify/assert"
+)
+
+const todoTxt = `(A) Call the plumber +Home @phone
+x 2025-03-01 Buy milk +Groceries @errands
+Renew the passport @errands due:2030-01-06 office:townhall
+`
+
+func TestImport(t *testing.T) {
+	s := newServer(t)
+	alice := s.signUp("alice")
+	home := s.createTodoList(alice.ID, "Home")
+	s.tag(s.createTodoItem(home.ID, "Water the plants").ID, "errands")
+	path := fmt.Sprintf("/users/%d/import?format=todotxt", alice.ID)
+
+	// a dry run only previews the import
+	var report models.ImportReport
+	s.expect("POST", path+"&dry_run=true", todoTxt, http.StatusOK, &report)
+	assert.False(t, report.Committed)
+	assert.Equal(t, 3, report.Items)
+	assert.ElementsMatch(t, []string{"phone", "priority:A"}, report.NewTags)
+	assert.Equal(t, int64(1), s.count(&models.TodoList{}, "user_id = ?", alice.ID))
+
+	s.expect("POST", path, todoTxt, http.StatusCreated, &report)
+	assert.True(t, report.Committed)
+	lists := map[string]models.ImportList{}
+	for _, todoList := range report.Lists {
+		lists[todoList.Name] = todoList
+	}
+	assert.Equal(t, home.ID, lists["Home"].ID)
+	assert.False(t, lists["Home"].New)
+	assert.True(t, lists["Groceries"].New)
+
+	var todoItems []models.TodoItem
+	s.expect("GET", fmt.Sprintf("/users/%d/todo_items?tag=errands", alice.ID), nil, http.StatusOK, &todoItems)
+	assert.ElementsMatch(t, []string{"Water the plants", "Buy milk", "Renew the passport"}, titles(todoItems))
+	s.expect("GET", fmt.Sprintf("/todo_lists/%d/todo_items", lists["Groceries"].ID), nil, http.StatusOK, &todoItems)
+	if assert.Len(t, todoItems, 1) {
+		assert.True(t, todoItems[0].Completed)
+	}
+	s.expect("GET", fmt.Sprintf("/todo_lists/%d/todo_items", lists["Inbox"].ID), nil, http.StatusOK, &todoItems)
+	if assert.Len(t, todoItems, 1) && assert.NotNil(t, todoItems[0].Due) {
+		assert.True(t, time.Date(2030, 1, 6, 0, 0, 0, 0, time.UTC).Equal(*todoItems[0].Due))
+		assert.Equal(t, "office: townhall", todoItems[0].Description)
+	}
+	// the imported tags join the vocabulary of the user
+	assert.Equal(t, int64(3), s.count(&models.Tag{}, "user_id = ?", alice.ID))
+
+	// nothing is imported from a file with errors
+	s.expect("POST", fmt.Sprintf("/users/%d/import?format=csv", alice.ID), "title,completed\nTile the roof,maybe\n", http.StatusUnprocessableEntity, &report)
+	assert.NotEmpty(t, report.Errors)
+	s.expect("POST", fmt.Sprintf("/users/%d/import?format=xml", alice.ID), "<todo/>", http.StatusBadRequest, nil)
+	s.expect("POST", "/users/999/import?format=todotxt", todoTxt, http.StatusForbidden, nil)
+}
+
+func TestImport_JSONExport(t *testing.T) {
+	s := newServer(t)
+	alice := s.signUp("alice")
+	todoList := s.createTodoList(alice.ID, "Chores")
+	due := time.Date(2030, 1, 6, 9, 0, 0, 0, time.UTC)
+	s.expect("POST", fmt.Sprintf("/todo_lists/%d/todo_items", todoList.ID),
+		models.TodoItem{Title: "Water the plants", Due: &due, Recurrence: "FREQ=WEEKLY"}, http.StatusCreated, nil)
+	resp := s.do("GET", fmt.Sprintf("/users/%d/export", alice.ID), nil, nil)
+	export, _ := io.ReadAll(resp.Body)
+
+	// the export of alice is imported by bob with its due dates and recurrences
+	bob := s.signUp("bob")
+	var report models.ImportReport
+	s.expect("POST", fmt.Sprintf("/users/%d/import?format=json", bob.ID), string(export), http.StatusCreated, &report)
+	var todoItems []models.TodoItem
+	s.expect("GET", fmt.Sprintf("/todo_lists/%d/todo_items", report.Lists[0].ID), nil, http.StatusOK, &todoItems)
+	if assert.Len(t, todoItems, 1) && assert.NotNil(t, todoItems[0].Due) {
+		assert.True(t, due.Equal(*todoItems[0].Due))
+		assert.Equal(t, "FREQ=WEEKLY", todoItems[0].Recurrence)
+	}
+
+	file := `{"Lists":[{"Name":"Chores","Items":[
+		{"Title":"Dust","Due":"2030-01-06","Recurrence":"FREQ=DAILY"},
+		{"Title":"Mop","Due":"soon"},
+		{"Title":"Vacuum","Recurrence":"FREQ=DAILY"},
+		{"Title":"Sweep","Due":"2030-01-06","Recurrence":"FREQ=SOMETIMES"}]}]}`
+	s.expect("POST", fmt.Sprintf("/users/%d/import?format=json", bob.ID), file, http.StatusUnprocessableEntity, &report)
+	lines := []int{}
+	for _, importError := range report.Errors {
+		lines = append(lines, importError.Line)
+	}
+	assert.Equal(t, []int{2, 3, 4}, lines)
+}
+
+func TestExport(t *testing.T) {
+	s := newServer(t)
+	alice := s.signUp("alice")
+	todoList := s.createTodoList(alice.ID, "Groceries")
+	milk := s.createTodoItem(todoList.ID, "Buy milk")
+	s.tag(milk.ID, "dairy")
+	s.createTodoItem(s.createTodoList(alice.ID, "Work").ID, "Write the report")
+
+	resp := s.do("GET", fmt.Sprintf("/users/%d/export", alice.ID), nil, nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	assert.Contains(t, resp.Header.Get("Content-Disposition"), fmt.Sprintf("export-%d.json", alice.ID))
+	var export struct {
+		User      models.User
+		Tags      []models.Tag
+		TodoLists []struct {
+			models.TodoList
+			TodoItems []models.TodoItem
+		}
+	}
+	data, _ := io.ReadAll(resp.Body)
+	if assert.NoError(t, json.Unmarshal(data, &export), string(data)) {
+		assert.Equal(t, "alice", export.User.Username)
+		assert.Len(t, export.Tags, 1)
+		if assert.Len(t, export.TodoLists, 2) {
+			assert.Equal(t, []string{"Buy milk"}, titles(export.TodoLists[0].TodoItems))
+		}
+	}
+
+	tests := []struct {
+		format      string
+		contentType string
+		contains    string
+	}{
+		{"csv", "text/csv; charset=utf-8", "Buy milk"},
+		{"markdown", "text/markdown; charset=utf-8", "# alice"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.format, func(t *testing.T) {
+			resp := s.with(t).do("GET", fmt.Sprintf("/users/%d/export?format=%s", alice.ID, tc.format), nil, nil)
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Equal(t, tc.contentType, resp.Header.Get("Content-Type"))
+			data, _ := io.ReadAll(resp.Body)
+			assert.True(t, strings.Contains(string(data), tc.contains), string(data))
+		})
+	}
+
+	s.expect("GET", fmt.Sprintf("/users/%d/export?format=xml", alice.ID), nil, http.StatusBadRequest, nil)
+	s.expect("GET", "/users/999/export", nil, http.StatusForbidden, nil)
+
+	s.signUp("bob")
+	s.expect("GET", fmt.Sprintf("/users/%d/export", alice.ID), nil, http.StatusForbidden, nil)
+}
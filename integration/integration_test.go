@@ -0,0 +1,179 @@
+// Package integration boots the full router of the app on a real database and drives it over HTTP,
+// so that the repositories run their actual queries instead of the mocks
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/danikg/go-todo-rest-api/app"
+	"github.com/danikg/go-todo-rest-api/app/pg"
+	"github.com/danikg/go-todo-rest-api/config"
+	"github.com/danikg/go-todo-rest-api/models"
+	"github.com/danikg/go-todo-rest-api/utils/events"
+	"gorm.io/gorm"
+
+	controllers "github.com/danikg/go-todo-rest-api/controllers/http"
+)
+
+const password = "password"
+
+// server is the app listening on a test server, requests are sent with the token of the signed in user
+type server struct {
+	t     *testing.T
+	url   string
+	db    *gorm.DB
+	token string
+}
+
+// newServer migrates a new db and serves the router of the app on it with authentication enabled,
+// the options change the config afterwards
+func newServer(t *testing.T, options ...func(cfg *config.Config)) *server {
+	t.Helper()
+	db := openDB(t)
+	if err := pg.Migrate(db); err != nil {
+		t.Fatalf("failed to migrate db: %v", err)
+	}
+
+	cfg := config.Default()
+	cfg.Auth.Enabled = true
+	cfg.Auth.Secret = "integration"
+	// the webhook receivers of the tests listen on loopback
+	cfg.Webhook.AllowPrivateTargets = true
+	for _, option := range options {
+		option(cfg)
+	}
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	ts := httptest.NewServer(app.NewRouter(cfg, log, db, events.NewBus(cfg.Events.History)))
+	t.Cleanup(ts.Close)
+	return &server{t: t, url: ts.URL, db: db}
+}
+
+// with returns a copy of the server reporting to the subtest t
+func (s *server) with(t *testing.T) *server {
+	c := *s
+	c.t = t
+	return &c
+}
+
+// signUp creates the user and signs in as it
+func (s *server) signUp(username string) models.User {
+	s.t.Helper()
+	var user models.User
+	s.expect("POST", "/users", models.User{Username: username, Password: password}, http.StatusCreated, &user)
+	s.signIn(username)
+	return user
+}
+
+// signIn replaces the token of the server by one of the user
+func (s *server) signIn(username string) {
+	s.t.Helper()
+	var token controllers.Token
+	s.expect("POST", "/auth/token", controllers.Credentials{Username: username, Password: password}, http.StatusCreated, &token)
+	s.token = token.Token
+}
+
+// do sends the request, body is encoded as JSON unless it is a string, out is decoded from a JSON response
+func (s *server) do(method, path string, body interface{}, out interface{}) *http.Response {
+	s.t.Helper()
+	var reader io.Reader
+	switch body := body.(type) {
+	case nil:
+	case string:
+		reader = bytes.NewBufferString(body)
+	default:
+		data, err := json.Marshal(body)
+		if err != nil {
+			s.t.Fatalf("failed to encode body: %v", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	r, err := http.NewRequest(method, s.url+path, reader)
+	if err != nil {
+		s.t.Fatalf("failed to create request: %v", err)
+	}
+	if s.token != "" {
+		r.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	resp, err := http.DefaultClient.Do(r)
+	if err != nil {
+		s.t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		s.t.Fatalf("%s %s: %v", method, path, err)
+	}
+	// the body is kept for the assertions on non JSON responses
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+	if out != nil && len(data) > 0 {
+		if err = json.Unmarshal(data, out); err != nil {
+			s.t.Fatalf("%s %s: failed to decode %s: %v", method, path, data, err)
+		}
+	}
+	return resp
+}
+
+// expect sends the request and fails the test unless it is answered with status
+func (s *server) expect(method, path string, body interface{}, status int, out interface{}) {
+	s.t.Helper()
+	resp := s.do(method, path, body, out)
+	if resp.StatusCode != status {
+		data, _ := io.ReadAll(resp.Body)
+		s.t.Fatalf("%s %s: expected %d, got %d: %s", method, path, status, resp.StatusCode, data)
+	}
+}
+
+// createTodoList creates a todo list of the user
+func (s *server) createTodoList(userID uint, name string) models.TodoList {
+	s.t.Helper()
+	var todoList models.TodoList
+	s.expect("POST", fmt.Sprintf("/users/%d/todo_lists", userID), models.TodoList{Name: name}, http.StatusCreated, &todoList)
+	return todoList
+}
+
+// createTodoItem creates a todo item in the todo list
+func (s *server) createTodoItem(listID uint, title string) models.TodoItem {
+	s.t.Helper()
+	var todoItem models.TodoItem
+	s.expect("POST", fmt.Sprintf("/todo_lists/%d/todo_items", listID), models.TodoItem{Title: title}, http.StatusCreated, &todoItem)
+	return todoItem
+}
+
+// tag attaches a tag with the text to the todo item
+func (s *server) tag(itemID uint, text string) models.Tag {
+	s.t.Helper()
+	var tag models.Tag
+	s.expect("POST", fmt.Sprintf("/todo_items/%d/tags", itemID), models.Tag{Text: text}, http.StatusCreated, &tag)
+	return tag
+}
+
+// count returns the number of rows of the model, soft deleted ones included
+func (s *server) count(model interface{}, query string, args ...interface{}) int64 {
+	s.t.Helper()
+	var n int64
+	if err := s.db.Unscoped().Model(model).Where(query, args...).Count(&n).Error; err != nil {
+		s.t.Fatalf("failed to count: %v", err)
+	}
+	return n
+}
+
+// countLinks returns the number of todo item and tag associations matching the query
+func (s *server) countLinks(query string, args ...interface{}) int64 {
+	s.t.Helper()
+	var n int64
+	if err := s.db.Table("todo_item_tags").Where(query, args...).Count(&n).Error; err != nil {
+		s.t.Fatalf("failed to count tag links: %v", err)
+	}
+	return n
+}
@@ -0,0 +1,99 @@
+package integration
+
+import (
+	"testing"
+
+	"github.com/danikg/go-todo-rest-api/app/pg"
+	"github.com/danikg/go-todo-rest-api/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// legacyUser is the users table before usernames were unique
+type legacyUser struct {
+	gorm.Model
+	Username string
+}
+
+func (legacyUser) TableName() string {
+	return "users"
+}
+
+// legacyTag is the tags table before tags became a vocabulary per user
+type legacyTag struct {
+	gorm.Model
+	Text string
+}
+
+func (legacyTag) TableName() string {
+	return "tags"
+}
+
+func TestMigrate_DuplicateUsernames(t *testing.T) {
+	db := openDB(t)
+	assert.NoError(t, db.AutoMigrate(&legacyUser{}))
+	for _, username := range []string{"alice", "bob", "alice", "alice-3", "alice"} {
+		assert.NoError(t, db.Create(&legacyUser{Username: username}).Error)
+	}
+
+	assert.NoError(t, pg.Migrate(db))
+	var users []models.User
+	assert.NoError(t, db.Order("id").Find(&users).Error)
+	usernames := []string{}
+	for _, user := range users {
+		usernames = append(usernames, user.Username)
+	}
+	assert.Equal(t, []string{"alice", "bob", "alice-3-2", "alice-3", "alice-5"}, usernames)
+
+	// the unique index is in place
+	assert.Error(t, db.Create(&models.User{Username: "bob"}).Error)
+}
+
+func TestMigrate_TagVocabulary(t *testing.T) {
+	db := openDB(t)
+	// the tags table as it was before the vocabulary; SQLite cannot add constraints later, so the rest is current
+	assert.NoError(t, db.AutoMigrate(&models.User{}, &models.TodoList{}, &models.TodoItem{}))
+	assert.NoError(t, db.Migrator().DropTable(&models.Tag{}))
+	assert.NoError(t, db.AutoMigrate(&legacyTag{}))
+	alice, bob := models.User{Username: "alice"}, models.User{Username: "bob"}
+	assert.NoError(t, db.Create(&alice).Error)
+	assert.NoError(t, db.Create(&bob).Error)
+	aliceList, bobList := models.TodoList{Name: "Home", UserID: alice.ID}, models.TodoList{Name: "Work", UserID: bob.ID}
+	assert.NoError(t, db.Create(&aliceList).Error)
+	assert.NoError(t, db.Create(&bobList).Error)
+
+	// every todo item got its own tag rows, the texts differ in case and spacing
+	items := []struct {
+		todoItem models.TodoItem
+		tags     []string
+	}{
+		{models.TodoItem{Title: "Milk", TodoListID: aliceList.ID}, []string{"Urgent", "Été"}},
+		{models.TodoItem{Title: "Eggs", TodoListID: aliceList.ID}, []string{"  urgent ", "ÉTÉ", "Home  Office"}},
+		{models.TodoItem{Title: "Review", TodoListID: bobList.ID}, []string{"URGENT"}},
+	}
+	for _, item := range items {
+		assert.NoError(t, db.Create(&item.todoItem).Error)
+		for _, text := range item.tags {
+			tag := legacyTag{Text: text}
+			assert.NoError(t, db.Create(&tag).Error)
+			assert.NoError(t, db.Exec("INSERT INTO todo_item_tags (todo_item_id, tag_id) VALUES (?, ?)", item.todoItem.ID, tag.ID).Error)
+		}
+	}
+
+	assert.NoError(t, pg.Migrate(db))
+
+	var tags []models.Tag
+	assert.NoError(t, db.Order("id").Find(&tags).Error)
+	normalized := map[uint][]string{}
+	for _, tag := range tags {
+		normalized[tag.UserID] = append(normalized[tag.UserID], tag.Normalized)
+	}
+	assert.Equal(t, []string{"urgent", "été", "home office"}, normalized[alice.ID])
+	assert.Equal(t, []string{"urgent"}, normalized[bob.ID])
+
+	// both todo items of alice share her merged tags
+	s := &server{t: t, db: db}
+	assert.Equal(t, int64(2), s.countLinks("tag_id = ?", tags[0].ID))
+	assert.Equal(t, int64(2), s.countLinks("tag_id = ?", tags[1].ID))
+	assert.Equal(t, int64(6), s.countLinks("1 = 1"))
+}
@@ -0,0 +1,15 @@
+//go:build !cgo
+
+package integration
+
+import (
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// openDB skips the suite, the SQLite driver needs cgo
+func openDB(t *testing.T) *gorm.DB {
+	t.Skip("the integration tests need cgo for SQLite")
+	return nil
+}
@@ -0,0 +1,102 @@
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/danikg/go-todo-rest-api/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSync(t *testing.T) {
+	s := newServer(t)
+	bob := s.signUp("bob")
+	bobsList := s.createTodoList(bob.ID, "Work")
+
+	alice := s.signUp("alice")
+	todoList := s.createTodoList(alice.ID, "Groceries")
+	milk := s.createTodoItem(todoList.ID, "Buy milk")
+	s.tag(milk.ID, "dairy")
+
+	// the snapshot only holds the records of the user
+	var changes models.SyncChanges
+	s.expect("GET", "/sync", nil, http.StatusOK, &changes)
+	assert.Len(t, changes.TodoLists, 1)
+	assert.Len(t, changes.TodoItems, 1)
+	assert.Len(t, changes.Tags, 1)
+	assert.Empty(t, changes.Deleted)
+	cursor := changes.Cursor
+
+	var report models.SyncReport
+	s.expect("POST", "/sync", models.SyncPush{Changes: []models.SyncChange{
+		{Op: models.SyncCreate, Type: models.AuditTodoList, ClientID: "list-1", TodoList: &models.TodoList{Name: "Offline"}},
+		{Op: models.SyncUpdate, Type: models.AuditTodoItem, ID: milk.ID, BaseVersion: changes.TodoItems[0].Version,
+			TodoItem: &models.TodoItem{Title: "Buy oat milk"}},
+		// the first update moved the version on
+		{Op: models.SyncUpdate, Type: models.AuditTodoItem, ID: milk.ID, BaseVersion: changes.TodoItems[0].Version,
+			TodoItem: &models.TodoItem{Title: "Buy soy milk"}},
+		{Op: models.SyncDelete, Type: models.AuditTodoList, ID: bobsList.ID, BaseVersion: bobsList.Version},
+	}}, http.StatusOK, &report)
+	if assert.Len(t, report.Results, 4) {
+		assert.Equal(t, models.SyncApplied, report.Results[0].Status, report.Results[0].Error)
+		assert.Equal(t, "list-1", report.Results[0].ClientID)
+		assert.NotZero(t, report.Results[0].ID)
+		assert.Equal(t, models.SyncApplied, report.Results[1].Status, report.Results[1].Error)
+		assert.Equal(t, models.SyncConflict, report.Results[2].Status)
+		// the list of another user is not found
+		assert.NotEqual(t, models.SyncApplied, report.Results[3].Status)
+	}
+	assert.Greater(t, report.Cursor, cursor)
+
+	var todoItem models.TodoItem
+	s.expect("GET", fmt.Sprintf("/todo_items/%d", milk.ID), nil, http.StatusOK, &todoItem)
+	assert.Equal(t, "Buy oat milk", todoItem.Title)
+	assert.Equal(t, int64(1), s.count(&models.TodoList{}, "id = ? AND deleted_at IS NULL", bobsList.ID))
+
+	s.expect("GET", fmt.Sprintf("/sync?since=%d", cursor), nil, http.StatusOK, &changes)
+	if assert.Len(t, changes.TodoLists, 1) {
+		assert.Equal(t, "Offline", changes.TodoLists[0].Name)
+	}
+	assert.Len(t, changes.TodoItems, 1)
+	assert.Empty(t, changes.Tags)
+	cursor = changes.Cursor
+
+	// deletions reach the clients as tombstones
+	s.expect("DELETE", fmt.Sprintf("/todo_items/%d", milk.ID), nil, http.StatusNoContent, nil)
+	s.expect("GET", fmt.Sprintf("/sync?since=%d", cursor), nil, http.StatusOK, &changes)
+	assert.Empty(t, changes.TodoItems)
+	if assert.Len(t, changes.Deleted, 1) {
+		assert.Equal(t, models.AuditTodoItem, changes.Deleted[0].ResourceType)
+		assert.Equal(t, milk.ID, changes.Deleted[0].ResourceID)
+	}
+
+	s.expect("GET", "/sync?since=last", nil, http.StatusBadRequest, nil)
+	s.expect("POST", "/sync", models.SyncPush{}, http.StatusBadRequest, nil)
+	s.token = ""
+	s.expect("GET", "/sync", nil, http.StatusUnauthorized, nil)
+}
+
+func TestSync_CreateRecurringTodoItem(t *testing.T) {
+	s := newServer(t)
+	alice := s.signUp("alice")
+	todoList := s.createTodoList(alice.ID, "Chores")
+
+	due := time.Date(2030, 1, 6, 9, 0, 0, 0, time.UTC)
+	var report models.SyncReport
+	s.expect("POST", "/sync", models.SyncPush{Changes: []models.SyncChange{
+		{Op: models.SyncCreate, Type: models.AuditTodoItem, ClientID: "item-1",
+			TodoItem: &models.TodoItem{Title: "Water plants", TodoListID: todoList.ID, Due: &due, Recurrence: "FREQ=WEEKLY"}},
+	}}, http.StatusOK, &report)
+	if !assert.Len(t, report.Results, 1) || !assert.Equal(t, models.SyncApplied, report.Results[0].Status, report.Results[0].Error) {
+		return
+	}
+
+	var todoItem models.TodoItem
+	s.expect("GET", fmt.Sprintf("/todo_items/%d", report.Results[0].ID), nil, http.StatusOK, &todoItem)
+	if assert.NotNil(t, todoItem.Due) {
+		assert.True(t, due.Equal(*todoItem.Due))
+	}
+	assert.Equal(t, "FREQ=WEEKLY", todoItem.Recurrence)
+}
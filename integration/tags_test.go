@@ -0,0 +1,241 @@
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/danikg/go-todo-rest-api/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// titles returns the titles of the todo items
+func titles(todoItems []models.TodoItem) []string {
+	result := []string{}
+	for _, todoItem := range todoItems {
+		result = append(result, todoItem.Title)
+	}
+	return result
+}
+
+func TestTags_TodoItem(t *testing.T) {
+	s := newServer(t)
+	alice := s.signUp("alice")
+	todoList := s.createTodoList(alice.ID, "Groceries")
+	milk := s.createTodoItem(todoList.ID, "Buy milk")
+	eggs := s.createTodoItem(todoList.ID, "Buy eggs")
+
+	dairy := s.tag(milk.ID, "Dairy")
+	assert.Equal(t, alice.ID, dairy.UserID)
+	s.tag(milk.ID, "urgent")
+	// the same text in another case or spacing reuses the tag of the user
+	assert.Equal(t, dairy.ID, s.tag(eggs.ID, "  dairy ").ID)
+	s.expect("POST", fmt.Sprintf("/todo_items/%d/tags", milk.ID), models.Tag{Text: " "}, http.StatusBadRequest, nil)
+	s.expect("POST", fmt.Sprintf("/todo_items/%d/tags", milk.ID), models.Tag{Text: "red", Color: "red"}, http.StatusBadRequest, nil)
+	s.expect("POST", "/todo_items/999/tags", models.Tag{Text: "lost"}, http.StatusInternalServerError, nil)
+
+	var tags []models.Tag
+	s.expect("GET", fmt.Sprintf("/todo_items/%d/tags", milk.ID), nil, http.StatusOK, &tags)
+	assert.Len(t, tags, 2)
+	s.expect("GET", fmt.Sprintf("/todo_items/%d/tags", eggs.ID), nil, http.StatusOK, &tags)
+	assert.Len(t, tags, 1)
+	s.expect("GET", "/todo_items/999/tags", nil, http.StatusNotFound, nil)
+
+	var todoItem models.TodoItem
+	s.expect("GET", fmt.Sprintf("/todo_items/%d", milk.ID), nil, http.StatusOK, &todoItem)
+	assert.Len(t, todoItem.Tags, 2)
+	assert.Greater(t, todoItem.Version, milk.Version)
+
+	s.expect("DELETE", fmt.Sprintf("/todo_items/%d/tags/%d", milk.ID, dairy.ID), nil, http.StatusNoContent, nil)
+	s.expect("GET", fmt.Sprintf("/todo_items/%d/tags", milk.ID), nil, http.StatusOK, &tags)
+	if assert.Len(t, tags, 1) {
+		assert.Equal(t, "urgent", tags[0].Text)
+	}
+	// only the association is removed
+	s.expect("GET", fmt.Sprintf("/todo_items/%d/tags", eggs.ID), nil, http.StatusOK, &tags)
+	assert.Len(t, tags, 1)
+	s.expect("DELETE", fmt.Sprintf("/todo_items/%d/tags/999", milk.ID), nil, http.StatusNotFound, nil)
+}
+
+func TestTags_Vocabulary(t *testing.T) {
+	s := newServer(t)
+	bob := s.signUp("bob")
+	bobsItem := s.createTodoItem(s.createTodoList(bob.ID, "Work").ID, "Review")
+	bobsDairy := s.tag(bobsItem.ID, "dairy")
+
+	alice := s.signUp("alice")
+	todoList := s.createTodoList(alice.ID, "Groceries")
+	milk := s.createTodoItem(todoList.ID, "Buy milk")
+
+	// every user has a vocabulary of its own
+	dairy := s.tag(milk.ID, "dairy")
+	assert.NotEqual(t, bobsDairy.ID, dairy.ID)
+
+	var tag models.Tag
+	s.expect("POST", fmt.Sprintf("/users/%d/tags", alice.ID), models.Tag{Text: "someday", Color: "#aabbcc"}, http.StatusCreated, &tag)
+	assert.Equal(t, "#aabbcc", tag.Color)
+	s.expect("POST", "/users/999/tags", models.Tag{Text: "lost"}, http.StatusForbidden, nil)
+
+	var usages []models.TagUsage
+	s.expect("GET", fmt.Sprintf("/users/%d/tags", alice.ID), nil, http.StatusOK, &usages)
+	if assert.Len(t, usages, 2) {
+		counts := map[string]int64{}
+		for _, usage := range usages {
+			counts[usage.Text] = usage.UsageCount
+		}
+		assert.Equal(t, map[string]int64{"dairy": 1, "someday": 0}, counts)
+	}
+
+	s.expect("GET", fmt.Sprintf("/tags/%d", tag.ID), nil, http.StatusOK, &tag)
+	assert.Equal(t, "someday", tag.Text)
+	s.expect("GET", "/tags/999", nil, http.StatusNotFound, nil)
+
+	s.expect("PUT", fmt.Sprintf("/tags/%d", tag.ID), models.Tag{Text: "Later", Color: "#000000"}, http.StatusOK, &tag)
+	assert.Equal(t, "Later", tag.Text)
+	s.expect("PUT", fmt.Sprintf("/tags/%d", tag.ID), models.Tag{Text: "DAIRY"}, http.StatusConflict, nil)
+	s.expect("PUT", fmt.Sprintf("/tags/%d", tag.ID), models.Tag{Text: "later", ParentID: &bobsDairy.ID}, http.StatusBadRequest, nil)
+	s.expect("PUT", "/tags/999", models.Tag{Text: "nothing"}, http.StatusNotFound, nil)
+}
+
+// Deleting a tag removes it from every todo item and gives those a new version
+func TestTags_PutReplaces(t *testing.T) {
+	s := newServer(t)
+	alice := s.signUp("alice")
+	path := fmt.Sprintf("/users/%d/tags", alice.ID)
+	var work, tag models.Tag
+	s.expect("POST", path, models.Tag{Text: "work"}, http.StatusCreated, &work)
+	s.expect("POST", path, models.Tag{Text: "clientA", Color: "#aabbcc", ParentID: &work.ID}, http.StatusCreated, &tag)
+
+	// the whole tag is sent, an omitted color or parent is cleared
+	s.expect("PUT", fmt.Sprintf("/tags/%d", tag.ID), models.Tag{Text: "Client A", Color: "#aabbcc", ParentID: &work.ID}, http.StatusOK, &tag)
+	assert.Equal(t, "Client A", tag.Text)
+	assert.Equal(t, "#aabbcc", tag.Color)
+	assert.NotNil(t, tag.ParentID)
+	s.expect("PUT", fmt.Sprintf("/tags/%d", tag.ID), models.Tag{Text: "Client A"}, http.StatusOK, &tag)
+	s.expect("GET", fmt.Sprintf("/tags/%d", tag.ID), nil, http.StatusOK, &tag)
+	assert.Empty(t, tag.Color)
+	assert.Nil(t, tag.ParentID)
+
+	// the text is required
+	s.expect("PUT", fmt.Sprintf("/tags/%d", tag.ID), models.Tag{Color: "#aabbcc"}, http.StatusBadRequest, nil)
+	s.expect("GET", fmt.Sprintf("/tags/%d", tag.ID), nil, http.StatusOK, &tag)
+	assert.Equal(t, "Client A", tag.Text)
+}
+
+func TestTags_DeleteCascades(t *testing.T) {
+	s := newServer(t)
+	alice := s.signUp("alice")
+	todoList := s.createTodoList(alice.ID, "Groceries")
+	milk := s.createTodoItem(todoList.ID, "Buy milk")
+	eggs := s.createTodoItem(todoList.ID, "Buy eggs")
+	dairy := s.tag(milk.ID, "dairy")
+	s.tag(eggs.ID, "dairy")
+	s.tag(eggs.ID, "urgent")
+
+	var before models.TodoItem
+	s.expect("GET", fmt.Sprintf("/todo_items/%d", eggs.ID), nil, http.StatusOK, &before)
+
+	s.expect("DELETE", fmt.Sprintf("/tags/%d", dairy.ID), nil, http.StatusNoContent, nil)
+	s.expect("GET", fmt.Sprintf("/tags/%d", dairy.ID), nil, http.StatusNotFound, nil)
+	s.expect("DELETE", fmt.Sprintf("/tags/%d", dairy.ID), nil, http.StatusNotFound, nil)
+	assert.Zero(t, s.countLinks("tag_id = ?", dairy.ID))
+
+	var todoItem models.TodoItem
+	s.expect("GET", fmt.Sprintf("/todo_items/%d", eggs.ID), nil, http.StatusOK, &todoItem)
+	if assert.Len(t, todoItem.Tags, 1) {
+		assert.Equal(t, "urgent", todoItem.Tags[0].Text)
+	}
+	assert.Greater(t, todoItem.Version, before.Version)
+	s.expect("GET", fmt.Sprintf("/todo_items/%d", milk.ID), nil, http.StatusOK, &todoItem)
+	assert.Empty(t, todoItem.Tags)
+}
+
+func TestTags_TodoItems(t *testing.T) {
+	s := newServer(t)
+	alice := s.signUp("alice")
+	groceries := s.createTodoList(alice.ID, "Groceries")
+	work := s.createTodoList(alice.ID, "Work")
+	milk := s.createTodoItem(groceries.ID, "Buy milk")
+	eggs := s.createTodoItem(groceries.ID, "Buy eggs")
+	report := s.createTodoItem(work.ID, "Write the report")
+	s.createTodoItem(work.ID, "Untagged")
+
+	dairy := s.tag(milk.ID, "dairy")
+	s.tag(eggs.ID, "dairy")
+	s.tag(eggs.ID, "urgent")
+	s.tag(report.ID, "urgent")
+
+	var todoItems []models.TodoItem
+	s.expect("GET", fmt.Sprintf("/tags/%d/todo_items", dairy.ID), nil, http.StatusOK, &todoItems)
+	assert.ElementsMatch(t, []string{"Buy milk", "Buy eggs"}, titles(todoItems))
+	s.expect("GET", fmt.Sprintf("/tags/%d/todo_items?limit=1&offset=1", dairy.ID), nil, http.StatusOK, &todoItems)
+	assert.Len(t, todoItems, 1)
+	s.expect("GET", fmt.Sprintf("/tags/%d/todo_items?limit=0", dairy.ID), nil, http.StatusBadRequest, nil)
+
+	tests := []struct {
+		name   string
+		query  string
+		titles []string
+	}{
+		{"all", "", []string{"Buy milk", "Buy eggs", "Write the report", "Untagged"}},
+		{"any", "?tag=dairy,URGENT", []string{"Buy milk", "Buy eggs", "Write the report"}},
+		{"all tags", "?tag=dairy&tag=urgent&match=all", []string{"Buy eggs"}},
+		{"unknown tag", "?tag=someday", []string{}},
+		{"page", "?limit=2", []string{"Buy milk", "Buy eggs"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var todoItems []models.TodoItem
+			s.with(t).expect("GET", fmt.Sprintf("/users/%d/todo_items%s", alice.ID, tc.query), nil, http.StatusOK, &todoItems)
+			assert.ElementsMatch(t, tc.titles, titles(todoItems))
+		})
+	}
+	s.expect("GET", fmt.Sprintf("/users/%d/todo_items?match=some", alice.ID), nil, http.StatusBadRequest, nil)
+}
+
+// Nested tags match the todo items tagged with their descendants, merging moves items and children
+func TestTags_NestedAndMerge(t *testing.T) {
+	s := newServer(t)
+	alice := s.signUp("alice")
+	todoList := s.createTodoList(alice.ID, "Work")
+	report := s.createTodoItem(todoList.ID, "Write the report")
+	call := s.createTodoItem(todoList.ID, "Call the client")
+
+	var work, job models.Tag
+	s.expect("POST", fmt.Sprintf("/users/%d/tags", alice.ID), models.Tag{Text: "work"}, http.StatusCreated, &work)
+	s.expect("POST", fmt.Sprintf("/users/%d/tags", alice.ID), models.Tag{Text: "job"}, http.StatusCreated, &job)
+	clientA := s.tag(report.ID, "clientA")
+	s.expect("PUT", fmt.Sprintf("/tags/%d", clientA.ID), models.Tag{Text: "clientA", ParentID: &work.ID}, http.StatusOK, nil)
+	s.tag(call.ID, "job")
+
+	// a tag cannot be nested below its own descendant
+	s.expect("PUT", fmt.Sprintf("/tags/%d", work.ID), models.Tag{Text: "work", ParentID: &clientA.ID}, http.StatusBadRequest, nil)
+
+	var todoItems []models.TodoItem
+	s.expect("GET", fmt.Sprintf("/users/%d/todo_items?tag=work", alice.ID), nil, http.StatusOK, &todoItems)
+	assert.Equal(t, []string{"Write the report"}, titles(todoItems))
+
+	s.expect("POST", fmt.Sprintf("/tags/%d/merge", work.ID), map[string]uint{"TargetID": clientA.ID}, http.StatusBadRequest, nil)
+	s.expect("POST", fmt.Sprintf("/tags/%d/merge", job.ID), map[string]uint{"TargetID": 999}, http.StatusNotFound, nil)
+
+	var target models.Tag
+	s.expect("POST", fmt.Sprintf("/tags/%d/merge", job.ID), map[string]uint{"TargetID": work.ID}, http.StatusOK, &target)
+	assert.Equal(t, work.ID, target.ID)
+	s.expect("GET", fmt.Sprintf("/tags/%d", job.ID), nil, http.StatusNotFound, nil)
+	s.expect("GET", fmt.Sprintf("/users/%d/todo_items?tag=work", alice.ID), nil, http.StatusOK, &todoItems)
+	assert.ElementsMatch(t, []string{"Write the report", "Call the client"}, titles(todoItems))
+
+	var tag models.Tag
+	s.expect("GET", fmt.Sprintf("/tags/%d", clientA.ID), nil, http.StatusOK, &tag)
+	if assert.NotNil(t, tag.ParentID) {
+		assert.Equal(t, work.ID, *tag.ParentID)
+	}
+
+	// deleting the parent keeps the child as a root tag
+	s.expect("DELETE", fmt.Sprintf("/tags/%d", work.ID), nil, http.StatusNoContent, nil)
+	s.expect("GET", fmt.Sprintf("/tags/%d", clientA.ID), nil, http.StatusOK, &tag)
+	assert.Nil(t, tag.ParentID)
+	s.expect("GET", fmt.Sprintf("/users/%d/todo_items?tag=clientA", alice.ID), nil, http.StatusOK, &todoItems)
+	assert.Equal(t, []string{"Write the report"}, titles(todoItems))
+}
@@ -0,0 +1,196 @@
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/danikg/go-todo-rest-api/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTodoLists(t *testing.T) {
+	s := newServer(t)
+	alice := s.signUp("alice")
+
+	groceries := s.createTodoList(alice.ID, "Groceries")
+	work := s.createTodoList(alice.ID, "Work")
+	assert.Equal(t, alice.ID, groceries.UserID)
+	assert.Greater(t, work.Version, groceries.Version)
+	s.expect("POST", "/users/999/todo_lists", models.TodoList{Name: "Nobody"}, http.StatusForbidden, nil)
+	assert.Zero(t, s.count(&models.TodoList{}, "user_id = ?", 999))
+
+	var todoLists []models.TodoList
+	s.expect("GET", fmt.Sprintf("/users/%d/todo_lists", alice.ID), nil, http.StatusOK, &todoLists)
+	assert.Len(t, todoLists, 2)
+
+	var user models.User
+	s.expect("GET", fmt.Sprintf("/users/%d", alice.ID), nil, http.StatusOK, &user)
+	assert.Len(t, user.TodoLists, 2)
+
+	var todoList models.TodoList
+	s.expect("PUT", fmt.Sprintf("/todo_lists/%d", groceries.ID), models.TodoList{Name: "Shopping"}, http.StatusOK, &todoList)
+	assert.Equal(t, "Shopping", todoList.Name)
+	assert.Greater(t, todoList.Version, work.Version)
+	s.expect("GET", fmt.Sprintf("/todo_lists/%d", groceries.ID), nil, http.StatusOK, &todoList)
+	assert.Equal(t, "Shopping", todoList.Name)
+	s.expect("PUT", "/todo_lists/999", models.TodoList{Name: "Nothing"}, http.StatusNotFound, nil)
+
+	s.expect("DELETE", fmt.Sprintf("/todo_lists/%d", work.ID), nil, http.StatusNoContent, nil)
+	s.expect("GET", fmt.Sprintf("/todo_lists/%d", work.ID), nil, http.StatusNotFound, nil)
+	s.expect("DELETE", fmt.Sprintf("/todo_lists/%d", work.ID), nil, http.StatusNotFound, nil)
+}
+
+// Deleting a todo list removes its todo items and their tag associations, the tags stay in the vocabulary
+func TestTodoLists_DeleteCascades(t *testing.T) {
+	s := newServer(t)
+	alice := s.signUp("alice")
+	groceries := s.createTodoList(alice.ID, "Groceries")
+	milk := s.createTodoItem(groceries.ID, "Buy milk")
+	dairy := s.tag(milk.ID, "dairy")
+	work := s.createTodoList(alice.ID, "Work")
+	review := s.createTodoItem(work.ID, "Review")
+	s.tag(review.ID, "dairy")
+
+	s.expect("DELETE", fmt.Sprintf("/todo_lists/%d", groceries.ID), nil, http.StatusNoContent, nil)
+	s.expect("GET", fmt.Sprintf("/todo_items/%d", milk.ID), nil, http.StatusNotFound, nil)
+	assert.Zero(t, s.count(&models.TodoItem{}, "todo_list_id = ?", groceries.ID))
+	assert.Zero(t, s.countLinks("todo_item_id = ?", milk.ID))
+	assert.Equal(t, int64(1), s.countLinks("todo_item_id = ?", review.ID))
+
+	var tag models.Tag
+	s.expect("GET", fmt.Sprintf("/tags/%d", dairy.ID), nil, http.StatusOK, &tag)
+
+	// the sync clients learn about the deleted list and item
+	assert.Equal(t, int64(1), s.count(&models.Tombstone{}, "resource_type = ? AND resource_id = ?", models.AuditTodoList, groceries.ID))
+	assert.Equal(t, int64(1), s.count(&models.Tombstone{}, "resource_type = ? AND resource_id = ?", models.AuditTodoItem, milk.ID))
+}
+
+func TestTodoItems(t *testing.T) {
+	s := newServer(t)
+	alice := s.signUp("alice")
+	todoList := s.createTodoList(alice.ID, "Groceries")
+
+	due := time.Date(2030, 1, 6, 9, 0, 0, 0, time.UTC)
+	var todoItem models.TodoItem
+	s.expect("POST", fmt.Sprintf("/todo_lists/%d/todo_items", todoList.ID),
+		models.TodoItem{Title: "Buy milk", Description: "Oat", Due: &due, Recurrence: "FREQ=WEEKLY"}, http.StatusCreated, &todoItem)
+	assert.Equal(t, todoList.ID, todoItem.TodoListID)
+	assert.Equal(t, todoList.ID, todoItem.TodoList.ID)
+	s.expect("POST", fmt.Sprintf("/todo_lists/%d/todo_items", todoList.ID), models.TodoItem{Title: "Forever", Recurrence: "FREQ=DAILY"}, http.StatusBadRequest, nil)
+	s.expect("POST", "/todo_lists/999/todo_items", models.TodoItem{Title: "Lost"}, http.StatusInternalServerError, nil)
+	eggs := s.createTodoItem(todoList.ID, "Buy eggs")
+
+	var todoItems []models.TodoItem
+	s.expect("GET", fmt.Sprintf("/todo_lists/%d/todo_items", todoList.ID), nil, http.StatusOK, &todoItems)
+	if assert.Len(t, todoItems, 2) {
+		assert.Equal(t, "Buy milk", todoItems[0].Title)
+		assert.True(t, due.Equal(*todoItems[0].Due))
+	}
+
+	// a GET response can be sent back as it is
+	s.expect("GET", fmt.Sprintf("/todo_items/%d", todoItem.ID), nil, http.StatusOK, &todoItem)
+	todoItem.Title = "Buy oat milk"
+	todoItem.Completed = true
+	s.expect("PUT", fmt.Sprintf("/todo_items/%d", todoItem.ID), todoItem, http.StatusOK, &todoItem)
+	assert.Equal(t, "Buy oat milk", todoItem.Title)
+	assert.Equal(t, "Oat", todoItem.Description)
+	s.expect("GET", fmt.Sprintf("/todo_items/%d", todoItem.ID), nil, http.StatusOK, &todoItem)
+	assert.True(t, todoItem.Completed)
+
+	// fields left out keep their values, Completed included
+	s.expect("PUT", fmt.Sprintf("/todo_items/%d", todoItem.ID), map[string]string{"Title": "Buy milk"}, http.StatusOK, &todoItem)
+	assert.Equal(t, "Buy milk", todoItem.Title)
+	assert.True(t, todoItem.Completed)
+	s.expect("PUT", fmt.Sprintf("/todo_items/%d", todoItem.ID), map[string]bool{"Completed": false}, http.StatusOK, &todoItem)
+	assert.Equal(t, "Buy milk", todoItem.Title)
+	assert.False(t, todoItem.Completed)
+	s.expect("PUT", "/todo_items/999", models.TodoItemUpdate{Title: "Nothing"}, http.StatusNotFound, nil)
+
+	s.expect("DELETE", fmt.Sprintf("/todo_items/%d", eggs.ID), nil, http.StatusNoContent, nil)
+	s.expect("GET", fmt.Sprintf("/todo_items/%d", eggs.ID), nil, http.StatusNotFound, nil)
+	s.expect("DELETE", fmt.Sprintf("/todo_items/%d", eggs.ID), nil, http.StatusNotFound, nil)
+	s.expect("GET", fmt.Sprintf("/todo_lists/%d/todo_items", todoList.ID), nil, http.StatusOK, &todoItems)
+	assert.Len(t, todoItems, 1)
+}
+
+// Deleting a todo item removes its tag associations but not the tags
+func TestTodoItems_DeleteCascades(t *testing.T) {
+	s := newServer(t)
+	alice := s.signUp("alice")
+	todoList := s.createTodoList(alice.ID, "Groceries")
+	milk := s.createTodoItem(todoList.ID, "Buy milk")
+	eggs := s.createTodoItem(todoList.ID, "Buy eggs")
+	dairy := s.tag(milk.ID, "dairy")
+	s.tag(milk.ID, "urgent")
+	s.tag(eggs.ID, "dairy")
+
+	s.expect("DELETE", fmt.Sprintf("/todo_items/%d", milk.ID), nil, http.StatusNoContent, nil)
+	assert.Zero(t, s.countLinks("todo_item_id = ?", milk.ID))
+	assert.Equal(t, int64(2), s.count(&models.Tag{}, "user_id = ?", alice.ID))
+
+	var usages []models.TagUsage
+	s.expect("GET", fmt.Sprintf("/users/%d/tags", alice.ID), nil, http.StatusOK, &usages)
+	for _, usage := range usages {
+		if usage.ID == dairy.ID {
+			assert.Equal(t, int64(1), usage.UsageCount)
+		} else {
+			assert.Zero(t, usage.UsageCount)
+		}
+	}
+}
+
+func TestTodoItems_Bulk(t *testing.T) {
+	s := newServer(t)
+	alice := s.signUp("alice")
+	groceries := s.createTodoList(alice.ID, "Groceries")
+	work := s.createTodoList(alice.ID, "Work")
+	milk := s.createTodoItem(groceries.ID, "Buy milk")
+	eggs := s.createTodoItem(groceries.ID, "Buy eggs")
+	bread := s.createTodoItem(groceries.ID, "Buy bread")
+	dairy := s.tag(eggs.ID, "dairy")
+
+	completed := true
+	var report models.BulkReport
+	s.expect("POST", "/todo_items/bulk", models.BulkRequest{Operations: []models.BulkOperation{
+		{Op: models.BulkUpdate, ID: milk.ID, Title: "Buy oat milk"},
+		{Op: models.BulkComplete, ID: milk.ID},
+		{Op: models.BulkMove, ID: bread.ID, ListID: work.ID},
+		{Op: models.BulkAddTag, ID: milk.ID, Tag: "Dairy"},
+		{Op: models.BulkRemoveTag, ID: eggs.ID, TagID: dairy.ID},
+		{Op: models.BulkUpdate, ID: eggs.ID, Completed: &completed},
+		{Op: models.BulkDelete, ID: 999},
+	}}, http.StatusOK, &report)
+	assert.True(t, report.Committed)
+	if assert.Len(t, report.Results, 7) {
+		for _, result := range report.Results[:6] {
+			assert.True(t, result.OK, result.Error)
+		}
+		assert.False(t, report.Results[6].OK)
+	}
+
+	var todoItem models.TodoItem
+	s.expect("GET", fmt.Sprintf("/todo_items/%d", milk.ID), nil, http.StatusOK, &todoItem)
+	assert.Equal(t, "Buy oat milk", todoItem.Title)
+	assert.True(t, todoItem.Completed)
+	if assert.Len(t, todoItem.Tags, 1) {
+		// the tag with the same normalized text is reused
+		assert.Equal(t, dairy.ID, todoItem.Tags[0].ID)
+	}
+	s.expect("GET", fmt.Sprintf("/todo_items/%d", bread.ID), nil, http.StatusOK, &todoItem)
+	assert.Equal(t, work.ID, todoItem.TodoListID)
+	s.expect("GET", fmt.Sprintf("/todo_items/%d", eggs.ID), nil, http.StatusOK, &todoItem)
+	assert.Empty(t, todoItem.Tags)
+	assert.True(t, todoItem.Completed)
+
+	// a failing all or nothing request leaves every todo item as it was
+	s.expect("POST", "/todo_items/bulk", models.BulkRequest{AllOrNothing: true, Operations: []models.BulkOperation{
+		{Op: models.BulkDelete, ID: milk.ID},
+		{Op: models.BulkDelete, ID: 999},
+	}}, http.StatusUnprocessableEntity, &report)
+	assert.False(t, report.Committed)
+	s.expect("GET", fmt.Sprintf("/todo_items/%d", milk.ID), nil, http.StatusOK, nil)
+
+	s.expect("POST", "/todo_items/bulk", models.BulkRequest{Operations: []models.BulkOperation{{Op: "archive", ID: milk.ID}}}, http.StatusBadRequest, nil)
+}
@@ -0,0 +1,114 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/danikg/go-todo-rest-api/models"
+	repos "github.com/danikg/go-todo-rest-api/repositories/pg"
+	webservices "github.com/danikg/go-todo-rest-api/services/web"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUsers(t *testing.T) {
+	s := newServer(t)
+
+	// authentication is enabled, only signing up and in are public
+	s.expect("GET", "/users", nil, http.StatusUnauthorized, nil)
+	alice := s.signUp("alice")
+	s.expect("POST", "/users", models.User{Username: "bob", Password: password}, http.StatusCreated, nil)
+	s.expect("POST", "/users", models.User{Username: "alice", Password: password}, http.StatusInternalServerError, nil)
+
+	// only the signed in user is listed
+	var users []models.User
+	s.expect("GET", "/users", nil, http.StatusOK, &users)
+	if assert.Len(t, users, 1) {
+		assert.Equal(t, "alice", users[0].Username)
+		assert.Empty(t, users[0].Password)
+	}
+
+	var user models.User
+	s.expect("GET", fmt.Sprintf("/users/%d", alice.ID), nil, http.StatusOK, &user)
+	assert.Equal(t, "alice", user.Username)
+	s.expect("GET", "/users/999", nil, http.StatusForbidden, nil)
+
+	s.expect("PUT", fmt.Sprintf("/users/%d", alice.ID), models.User{Username: "alice2", Password: "secret"}, http.StatusOK, &user)
+	assert.Equal(t, "alice2", user.Username)
+	s.expect("POST", "/auth/token", map[string]string{"Username": "alice2", "Password": password}, http.StatusUnauthorized, nil)
+	s.expect("POST", "/auth/token", map[string]string{"Username": "alice2", "Password": "secret"}, http.StatusCreated, nil)
+
+	s.expect("DELETE", fmt.Sprintf("/users/%d", alice.ID), nil, http.StatusNoContent, nil)
+	// the token of the deleted user is not accepted anymore
+	s.expect("GET", fmt.Sprintf("/users/%d", alice.ID), nil, http.StatusUnauthorized, nil)
+	s.expect("DELETE", fmt.Sprintf("/users/%d", alice.ID), nil, http.StatusUnauthorized, nil)
+	assert.Zero(t, s.count(&models.User{}, "username = ?", "alice2"))
+}
+
+func TestUsers_InvalidToken(t *testing.T) {
+	s := newServer(t)
+	s.signUp("alice")
+
+	s.token = s.token + "x"
+	s.expect("GET", "/users", nil, http.StatusUnauthorized, nil)
+	s.expect("POST", "/auth/token", map[string]string{"Username": "alice", "Password": "wrong"}, http.StatusUnauthorized, nil)
+	s.expect("POST", "/auth/token", map[string]string{"Username": "nobody", "Password": password}, http.StatusUnauthorized, nil)
+}
+
+// Disabling a user and resetting its password revoke the tokens issued before
+func TestUsers_RevokedToken(t *testing.T) {
+	s := newServer(t)
+	alice := s.signUp("alice")
+	adminService := webservices.NewAdminService(repos.NewUserRepository(s.db), repos.NewTodoListRepository(s.db),
+		repos.NewMaintenanceRepository(s.db), repos.NewAuditRepository(s.db))
+
+	_, err := adminService.ResetPassword(context.Background(), alice.ID, "secret")
+	assert.NoError(t, err)
+	s.expect("GET", "/users", nil, http.StatusUnauthorized, nil)
+	s.expect("POST", "/auth/token", map[string]string{"Username": "alice", "Password": "secret"}, http.StatusCreated, nil)
+
+	_, err = adminService.ResetPassword(context.Background(), alice.ID, password)
+	assert.NoError(t, err)
+	s.signIn("alice")
+	s.expect("GET", "/users", nil, http.StatusOK, nil)
+
+	_, err = adminService.SetDisabled(context.Background(), alice.ID, true)
+	assert.NoError(t, err)
+	s.expect("GET", "/users", nil, http.StatusUnauthorized, nil)
+
+	_, err = adminService.SetDisabled(context.Background(), alice.ID, false)
+	assert.NoError(t, err)
+	s.expect("GET", "/users", nil, http.StatusUnauthorized, nil)
+	s.signIn("alice")
+	s.expect("GET", "/users", nil, http.StatusOK, nil)
+}
+
+// Deleting a user removes its todo lists, their todo items and the tag associations of those
+func TestUsers_DeleteCascades(t *testing.T) {
+	s := newServer(t)
+	alice := s.signUp("alice")
+	todoList := s.createTodoList(alice.ID, "Groceries")
+	milk := s.createTodoItem(todoList.ID, "Buy milk")
+	eggs := s.createTodoItem(todoList.ID, "Buy eggs")
+	s.tag(milk.ID, "dairy")
+	s.tag(eggs.ID, "dairy")
+
+	bob := s.signUp("bob")
+	other := s.createTodoItem(s.createTodoList(bob.ID, "Work").ID, "Review")
+	s.tag(other.ID, "dairy")
+
+	s.signIn("alice")
+	s.expect("DELETE", fmt.Sprintf("/users/%d", alice.ID), nil, http.StatusNoContent, nil)
+	assert.Zero(t, s.count(&models.TodoList{}, "user_id = ?", alice.ID))
+	assert.Zero(t, s.count(&models.TodoItem{}, "todo_list_id = ?", todoList.ID))
+	assert.Zero(t, s.countLinks("todo_item_id IN ?", []uint{milk.ID, eggs.ID}))
+	assert.Zero(t, s.count(&models.Tag{}, "user_id = ?", alice.ID))
+
+	// the data of the other user is untouched
+	assert.Equal(t, int64(1), s.countLinks("todo_item_id = ?", other.ID))
+	s.signIn("bob")
+	var tags []models.Tag
+	s.expect("GET", fmt.Sprintf("/todo_items/%d/tags", other.ID), nil, http.StatusOK, &tags)
+	assert.Len(t, tags, 1)
+}
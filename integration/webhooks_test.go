@@ -0,0 +1,108 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/danikg/go-todo-rest-api/config"
+	"github.com/danikg/go-todo-rest-api/models"
+	"github.com/stretchr/testify/assert"
+
+	repos "github.com/danikg/go-todo-rest-api/repositories/pg"
+	services "github.com/danikg/go-todo-rest-api/services/web"
+)
+
+// receiver records the webhook deliveries it is sent
+type receiver struct {
+	mu         sync.Mutex
+	events     []string
+	signatures []string
+	bodies     [][]byte
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, req.Header.Get(services.WebhookEventHeader))
+	r.signatures = append(r.signatures, req.Header.Get(services.WebhookSignatureHeader))
+	r.bodies = append(r.bodies, body)
+}
+
+func TestWebhooks(t *testing.T) {
+	s := newServer(t)
+	alice := s.signUp("alice")
+	todoList := s.createTodoList(alice.ID, "Groceries")
+
+	received := &receiver{}
+	ts := httptest.NewServer(received)
+	defer ts.Close()
+
+	var webhook models.Webhook
+	s.expect("POST", fmt.Sprintf("/users/%d/webhooks", alice.ID),
+		models.Webhook{URL: ts.URL, Events: models.WebhookEvents{models.WebhookTodoItemCreated}}, http.StatusCreated, &webhook)
+	secret := webhook.Secret
+	assert.NotEmpty(t, secret)
+	s.expect("POST", fmt.Sprintf("/users/%d/webhooks", alice.ID), models.Webhook{URL: "ftp://example.com", Events: models.WebhookEvents{models.WebhookTodoItemCreated}}, http.StatusBadRequest, nil)
+	s.expect("POST", fmt.Sprintf("/users/%d/webhooks", alice.ID), models.Webhook{URL: ts.URL, Events: models.WebhookEvents{"todo_list.created"}}, http.StatusBadRequest, nil)
+	s.expect("POST", "/users/999/webhooks", models.Webhook{URL: ts.URL, Events: models.WebhookEvents{models.WebhookTodoItemCreated}}, http.StatusForbidden, nil)
+
+	var webhooks []models.Webhook
+	s.expect("GET", fmt.Sprintf("/users/%d/webhooks", alice.ID), nil, http.StatusOK, &webhooks)
+	if assert.Len(t, webhooks, 1) {
+		assert.Empty(t, webhooks[0].Secret)
+	}
+	s.expect("PUT", fmt.Sprintf("/webhooks/%d", webhook.ID),
+		models.Webhook{Events: models.WebhookEvents{models.WebhookTodoItemCreated, models.WebhookTodoItemCompleted}}, http.StatusOK, nil)
+	s.expect("GET", fmt.Sprintf("/webhooks/%d", webhook.ID), nil, http.StatusOK, &webhook)
+	assert.Equal(t, models.WebhookEvents{models.WebhookTodoItemCreated, models.WebhookTodoItemCompleted}, webhook.Events)
+	s.expect("GET", "/webhooks/999", nil, http.StatusNotFound, nil)
+
+	// the webhooks of alice are hidden from bob
+	s.signUp("bob")
+	s.expect("GET", fmt.Sprintf("/users/%d/webhooks", alice.ID), nil, http.StatusForbidden, nil)
+	s.expect("GET", fmt.Sprintf("/webhooks/%d", webhook.ID), nil, http.StatusForbidden, nil)
+	s.expect("PUT", fmt.Sprintf("/webhooks/%d", webhook.ID), models.Webhook{URL: "https://example.com/stolen"}, http.StatusForbidden, nil)
+	s.expect("GET", fmt.Sprintf("/webhooks/%d/deliveries", webhook.ID), nil, http.StatusForbidden, nil)
+	s.expect("DELETE", fmt.Sprintf("/webhooks/%d", webhook.ID), nil, http.StatusForbidden, nil)
+	s.signIn("alice")
+
+	// the events are written to the outbox with the changes and delivered by the dispatcher
+	milk := s.createTodoItem(todoList.ID, "Buy milk")
+	milk.Completed = true
+	s.expect("PUT", fmt.Sprintf("/todo_items/%d", milk.ID), milk, http.StatusOK, nil)
+	s.expect("DELETE", fmt.Sprintf("/todo_items/%d", milk.ID), nil, http.StatusNoContent, nil)
+
+	cfg := config.Default().Webhook
+	cfg.AllowPrivateTargets = true
+	dispatcher := services.NewWebhookDispatcher(repos.NewWebhookRepository(s.db), cfg)
+	assert.NoError(t, dispatcher.Dispatch(context.Background(), time.Now()))
+
+	received.mu.Lock()
+	assert.ElementsMatch(t, []string{models.WebhookTodoItemCreated, models.WebhookTodoItemCompleted}, received.events)
+	for i, body := range received.bodies {
+		assert.Equal(t, services.WebhookSignature(secret, body), received.signatures[i])
+	}
+	received.mu.Unlock()
+
+	var deliveries []models.WebhookDelivery
+	s.expect("GET", fmt.Sprintf("/webhooks/%d/deliveries", webhook.ID), nil, http.StatusOK, &deliveries)
+	if assert.Len(t, deliveries, 2) {
+		for _, delivery := range deliveries {
+			assert.Equal(t, models.WebhookDeliverySucceeded, delivery.Status)
+			assert.Equal(t, http.StatusOK, delivery.ResponseStatus)
+		}
+	}
+
+	// deleting the webhook removes its deliveries
+	s.expect("DELETE", fmt.Sprintf("/webhooks/%d", webhook.ID), nil, http.StatusNoContent, nil)
+	s.expect("GET", fmt.Sprintf("/webhooks/%d/deliveries", webhook.ID), nil, http.StatusNotFound, nil)
+	assert.Zero(t, s.count(&models.WebhookDelivery{}, "webhook_id = ?", webhook.ID))
+	s.expect("DELETE", fmt.Sprintf("/webhooks/%d", webhook.ID), nil, http.StatusNotFound, nil)
+}
@@ -1,19 +1,100 @@
 package main
 
 import (
-	"log"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
 
 	"github.com/danikg/go-todo-rest-api/app"
+	"github.com/danikg/go-todo-rest-api/config"
 	"github.com/joho/godotenv"
 )
 
+const usage = `usage: main [command] [flags]
+
+commands:
+  serve                                run the API server (default)
+  config print                         print the effective configuration with secrets redacted
+  admin user list                      list the users
+  admin user create USERNAME           create a user, the password is read from stdin
+  admin user password ID               reset the password of a user, read from stdin
+  admin user disable|enable ID         revoke the tokens of a user or allow new ones
+  admin list transfer LIST_ID USER_ID  give a todo list with its todo items to another user
+  admin purge [AGE]                    remove deleted data older than AGE (default 720h)
+  admin stats                          count the rows of the db
+  seed [SCALE [SEED]]                  load generated users, todo lists and todo items (default 1 1)
+
+run "main -h" to list the flags`
+
 func init() {
-	if err := godotenv.Load(); err != nil {
-		log.Fatal(err.Error())
+	// .env is a convenience for local runs, the environment alone is enough
+	if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
 }
 
 func main() {
-	api := app.NewApp()
-	api.Run()
+	command, args := splitCommand(os.Args[1:])
+	cfg, err := config.Load(args)
+	if cfg == nil {
+		// the flag set already printed the problem
+		os.Exit(2)
+	}
+
+	switch words := strings.Fields(command); {
+	case command == "" || command == "serve":
+		exitOnError(err)
+		app.NewApp(cfg).Run()
+	case command == "config print":
+		if printErr := cfg.Print(os.Stdout); printErr != nil {
+			fmt.Fprintln(os.Stderr, printErr)
+			os.Exit(1)
+		}
+		exitOnError(err)
+	case len(words) > 0 && words[0] == "admin":
+		adminCommand, adminArgs, usageErr := parseAdminCommand(words[1:])
+		if usageErr != nil {
+			fmt.Fprintln(os.Stderr, usage)
+			os.Exit(2)
+		}
+		exitOnError(err)
+		if err = runAdmin(context.Background(), newAdminService(cfg), adminCommand, adminArgs, os.Stdin, os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	case len(words) > 0 && words[0] == "seed":
+		options, usageErr := parseSeedArgs(words[1:])
+		if usageErr != nil {
+			fmt.Fprintf(os.Stderr, "%s\n\n%s\n", usageErr, usage)
+			os.Exit(2)
+		}
+		exitOnError(err)
+		if err = runSeed(context.Background(), cfg, options, os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+// splitCommand separates the leading command words from the flags
+func splitCommand(args []string) (string, []string) {
+	i := 0
+	for i < len(args) && !strings.HasPrefix(args[i], "-") {
+		i++
+	}
+	return strings.Join(args[:i], " "), args[i:]
+}
+
+func exitOnError(err error) {
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid configuration:\n%s\n", err)
+		os.Exit(1)
+	}
 }
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"time"
+)
+
+// Audited actions
+const (
+	AuditCreate = "create"
+	AuditUpdate = "update"
+	AuditDelete = "delete"
+	AuditAttach = "attach"
+	AuditDetach = "detach"
+	AuditMerge  = "merge"
+)
+
+// Audited resource types
+const (
+	AuditUser     = "user"
+	AuditTodoList = "todo_list"
+	AuditTodoItem = "todo_item"
+	AuditTag      = "tag"
+)
+
+// AuditEvent is an append-only record of a mutation,
+// UserID owns the changed resource while ActorID is the authenticated user who changed it
+type AuditEvent struct {
+	ID           uint `gorm:"primarykey"`
+	CreatedAt    time.Time
+	ActorID      *uint
+	Action       string
+	ResourceType string
+	ResourceID   uint
+	UserID       uint  `gorm:"index"`
+	TodoListID   *uint `gorm:"index"`
+	Changes      AuditChanges
+	RequestID    string
+}
+
+// AuditChange holds the values of a field before and after a mutation
+type AuditChange struct {
+	Before interface{} `json:",omitempty"`
+	After  interface{} `json:",omitempty"`
+}
+
+// AuditChanges is the JSON object mapping the changed fields of an audit event to AuditChange values,
+// it is stored as is and embedded unchanged in API responses
+type AuditChanges []byte
+
+// Value ...
+func (c AuditChanges) Value() (driver.Value, error) {
+	if len(c) == 0 {
+		return nil, nil
+	}
+	return []byte(c), nil
+}
+
+// Scan ...
+func (c *AuditChanges) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*c = nil
+	case []byte:
+		*c = append(AuditChanges(nil), v...)
+	case string:
+		*c = AuditChanges(v)
+	default:
+		return fmt.Errorf("cannot scan %T into AuditChanges", value)
+	}
+	return nil
+}
+
+// MarshalJSON ...
+func (c AuditChanges) MarshalJSON() ([]byte, error) {
+	if len(c) == 0 {
+		return []byte("null"), nil
+	}
+	return c, nil
+}
+
+// UnmarshalJSON ...
+func (c *AuditChanges) UnmarshalJSON(data []byte) error {
+	*c = append(AuditChanges(nil), data...)
+	return nil
+}
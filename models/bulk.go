@@ -0,0 +1,48 @@
+package models
+
+// Bulk operation kinds
+const (
+	BulkUpdate    = "update"
+	BulkComplete  = "complete"
+	BulkMove      = "move"
+	BulkAddTag    = "add_tag"
+	BulkRemoveTag = "remove_tag"
+	BulkDelete    = "delete"
+)
+
+// BulkOperation is a single change of a todo item in a bulk request,
+// the fields besides Op and ID are used depending on the kind of the operation
+type BulkOperation struct {
+	Op          string
+	ID          uint
+	Title       string
+	Description string
+	Completed   *bool
+	ListID      uint
+	Tag         string
+	TagID       uint
+}
+
+// BulkRequest is a list of operations applied in one transaction,
+// AllOrNothing rolls every operation back when one of them fails
+type BulkRequest struct {
+	Operations   []BulkOperation
+	AllOrNothing bool
+}
+
+// BulkResult reports the outcome of the operation at Index
+type BulkResult struct {
+	Index int
+	ID    uint
+	OK    bool
+	Error string `json:",omitempty"`
+	// Completed is set when the operation marked a pending todo item as completed
+	Completed bool `json:"-"`
+}
+
+// BulkReport holds the results of a bulk request,
+// Committed is false when nothing was saved
+type BulkReport struct {
+	Committed bool
+	Results   []BulkResult
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// FeedToken grants read access to the calendar feeds of a user without a bearer token,
+// only the sha256 of the token is stored
+type FeedToken struct {
+	ID        uint   `gorm:"primarykey"`
+	UserID    uint   `gorm:"uniqueIndex"`
+	Hash      string `gorm:"uniqueIndex"`
+	CreatedAt time.Time
+}
+
+// Feed is returned once when a feed token is created, the paths of the feeds carry the token
+type Feed struct {
+	Token string
+	Path  string
+	// ListPath is the feed of a single todo list, {list_id} stands for its id
+	ListPath string
+}
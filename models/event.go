@@ -0,0 +1,24 @@
+package models
+
+// Change event types
+const (
+	EventTodoListCreated = "todo_list.created"
+	EventTodoListUpdated = "todo_list.updated"
+	EventTodoListDeleted = "todo_list.deleted"
+	EventTodoItemCreated = "todo_item.created"
+	EventTodoItemUpdated = "todo_item.updated"
+	EventTodoItemDeleted = "todo_item.deleted"
+
+	// EventReset tells a resuming client that events were missed and its data must be reloaded
+	EventReset = "reset"
+)
+
+// ChangeEvent is pushed to the /events stream of the user owning the changed todo list,
+// Data is the list or item after the change, or before it for deletions
+type ChangeEvent struct {
+	ID         uint64
+	Type       string
+	UserID     uint `json:"-"`
+	TodoListID uint
+	Data       interface{}
+}
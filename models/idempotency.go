@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// IdempotencyKey stores the response of a POST request sent with an Idempotency-Key header,
+// StatusCode stays 0 while the first request is still being handled
+type IdempotencyKey struct {
+	ID          uint   `gorm:"primarykey"`
+	UserID      uint   `gorm:"uniqueIndex:idx_idempotency_keys_user_key"`
+	Key         string `gorm:"uniqueIndex:idx_idempotency_keys_user_key"`
+	Method      string
+	Path        string
+	RequestHash string
+	StatusCode  int
+	ContentType string
+	Body        []byte
+	CreatedAt   time.Time
+	ExpiresAt   time.Time `gorm:"index"`
+}
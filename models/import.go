@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// ImportItem is a todo item read from an import file, List is the name of the todo list it goes to
+type ImportItem struct {
+	Line        int `json:"-"`
+	List        string
+	Title       string
+	Description string
+	Completed   bool
+	Due         *time.Time
+	Recurrence  string
+	Tags        []string
+}
+
+// ImportError reports a line of the import file that could not be read,
+// for json files Line is the position of the todo item
+type ImportError struct {
+	Line  int
+	Error string
+}
+
+// ImportList summarizes the todo items imported into a todo list,
+// New lists are created by the import, ID is 0 for them in a dry run
+type ImportList struct {
+	ID    uint
+	Name  string
+	New   bool
+	Items int
+}
+
+// ImportReport previews an import in a dry run or reports what it created,
+// nothing is committed while there are Errors
+type ImportReport struct {
+	DryRun    bool
+	Committed bool
+	Lists     []ImportList
+	Items     int
+	Tags      []string
+	NewTags   []string
+	Errors    []ImportError
+}
@@ -0,0 +1,26 @@
+package models
+
+// PurgeReport counts the rows removed by a purge
+type PurgeReport struct {
+	// SoftDeleted counts the users, todo lists, todo items, tags and webhooks marked as deleted
+	SoftDeleted       int64
+	Tombstones        int64
+	IdempotencyKeys   int64
+	WebhookDeliveries int64
+	WebhookEvents     int64
+}
+
+// Stats counts the rows of the db, ChangeSequence is the last version handed out
+type Stats struct {
+	Users             int64
+	DisabledUsers     int64
+	TodoLists         int64
+	TodoItems         int64
+	CompletedItems    int64
+	Tags              int64
+	Webhooks          int64
+	PendingDeliveries int64
+	AuditEvents       int64
+	Tombstones        int64
+	ChangeSequence    uint64
+}
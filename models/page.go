@@ -0,0 +1,13 @@
+package models
+
+// DefaultPageLimit is the page size used when a request does not ask for one
+const DefaultPageLimit = 50
+
+// MaxPageLimit is the largest page size a request may ask for
+const MaxPageLimit = 100
+
+// Page selects a window of an ordered result set
+type Page struct {
+	Limit  int
+	Offset int
+}
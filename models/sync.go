@@ -0,0 +1,84 @@
+package models
+
+import "time"
+
+// Sync operations
+const (
+	SyncCreate = "create"
+	SyncUpdate = "update"
+	SyncDelete = "delete"
+)
+
+// Sync result statuses
+const (
+	SyncApplied  = "applied"
+	SyncConflict = "conflict"
+	SyncError    = "error"
+)
+
+// ChangeCounter is the single row holding the change sequence,
+// every mutation increments it and stores the value as Version of the records it touched
+type ChangeCounter struct {
+	ID    uint `gorm:"primarykey"`
+	Value uint64
+}
+
+// Tombstone records the deletion of a todo list, todo item or tag,
+// so that clients can drop their copies on the next sync
+type Tombstone struct {
+	ID           uint   `gorm:"primarykey"`
+	Version      uint64 `gorm:"index"`
+	UserID       uint   `gorm:"index"`
+	ResourceType string
+	ResourceID   uint
+	DeletedAt    time.Time
+}
+
+// SyncChanges holds the records of a user changed after a cursor,
+// Cursor is passed as since to the next sync
+type SyncChanges struct {
+	Cursor    uint64
+	TodoLists []TodoList
+	TodoItems []TodoItem
+	Tags      []Tag
+	Deleted   []Tombstone
+}
+
+// SyncChange is a change made by a client while offline, BaseVersion is the version
+// the client last saw of the record and is required for updates and deletes.
+// The record matching Type carries the data of creates and updates
+type SyncChange struct {
+	Op          string
+	Type        string
+	ID          uint
+	ClientID    string
+	BaseVersion uint64
+	TodoList    *TodoList `json:",omitempty"`
+	TodoItem    *TodoItem `json:",omitempty"`
+	Tag         *Tag      `json:",omitempty"`
+}
+
+// SyncPush is a batch of client changes applied in order
+type SyncPush struct {
+	Changes []SyncChange
+}
+
+// SyncResult reports the outcome of the change at Index, Record is the server record
+// after an applied change or the current one on a conflict
+type SyncResult struct {
+	Index    int
+	ClientID string `json:",omitempty"`
+	ID       uint
+	Status   string
+	Version  uint64      `json:",omitempty"`
+	Error    string      `json:",omitempty"`
+	Record   interface{} `json:",omitempty"`
+	// Before is the record before an applied update or delete
+	Before interface{} `json:"-"`
+}
+
+// SyncReport holds the results of a push, Cursor is the sequence value after it
+type SyncReport struct {
+	Cursor  uint64
+	Results []SyncResult
+}
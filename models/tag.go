@@ -1,9 +1,38 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"strings"
 
-// Tag model represents a tag in db
+	"gorm.io/gorm"
+)
+
+// Tag model represents a tag of a user's vocabulary in db,
+// the same tag row is shared by every todo item of the user tagged with it,
+// tags can be nested under a parent tag of the same user (e.g. work/clientA)
 type Tag struct {
 	gorm.Model
-	Text string
+	Text       string
+	Color      string
+	ParentID   *uint  `gorm:"index"`
+	UserID     uint   `gorm:"uniqueIndex:idx_tags_user_normalized"`
+	Normalized string `gorm:"uniqueIndex:idx_tags_user_normalized" json:"-"`
+	Version    uint64 `gorm:"index"`
+}
+
+// TagUsage is a tag with the number of todo items it is attached to
+type TagUsage struct {
+	Tag
+	UsageCount int64
+}
+
+// NormalizeTagText returns the form under which tag texts are compared
+func NormalizeTagText(text string) string {
+	return strings.ToLower(strings.Join(strings.Fields(text), " "))
+}
+
+// TagFilter selects todo items by the texts of their tags or of their descendant tags,
+// MatchAll requires every tag (AND), otherwise any of them is enough (OR)
+type TagFilter struct {
+	Tags     []string
+	MatchAll bool
 }
@@ -1,13 +1,30 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 // TodoItem represents a todo item in db
 type TodoItem struct {
 	gorm.Model
 	Title       string
 	Description string
+	Completed   bool
+	Due         *time.Time
+	Recurrence  string // an RRULE value like FREQ=WEEKLY;BYDAY=MO, recurring items need a Due date
 	TodoListID  uint
 	TodoList    TodoList `gorm:"constraint:OnDelete:CASCADE;"`
 	Tags        []Tag    `gorm:"many2many:todo_item_tags;constraint:OnDelete:CASCADE;"`
+	Version     uint64   `gorm:"index"`
+}
+
+// TodoItemUpdate holds the changes of a todo item, empty strings and nil values keep the current ones
+type TodoItemUpdate struct {
+	Title       string
+	Description string
+	Completed   *bool
+	Due         *time.Time
+	Recurrence  string
 }
@@ -5,6 +5,7 @@ import "gorm.io/gorm"
 // TodoList represents a todo list in db
 type TodoList struct {
 	gorm.Model
-	Name   string
-	UserID uint
+	Name    string
+	UserID  uint
+	Version uint64 `gorm:"index"`
 }
@@ -2,9 +2,14 @@ package models
 
 import "gorm.io/gorm"
 
-// User model represents a user in db
+// User model represents a user in db, disabled users cannot get tokens
+// and bumping TokenGeneration revokes the tokens issued before
 type User struct {
 	gorm.Model
-	Username  string
-	TodoLists []TodoList `gorm:"constraint:OnDelete:CASCADE;"`
+	Username        string     `gorm:"uniqueIndex"`
+	Password        string     `gorm:"-" json:",omitempty"`
+	PasswordHash    string     `json:"-"`
+	Disabled        bool       `json:",omitempty"`
+	TokenGeneration uint       `json:"-"`
+	TodoLists       []TodoList `gorm:"constraint:OnDelete:CASCADE;"`
 }
@@ -0,0 +1,119 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Webhook event types
+const (
+	WebhookTodoItemCreated   = "todo_item.created"
+	WebhookTodoItemUpdated   = "todo_item.updated"
+	WebhookTodoItemCompleted = "todo_item.completed"
+	WebhookTodoItemDeleted   = "todo_item.deleted"
+)
+
+// WebhookEventTypes are the event types a webhook can subscribe to
+var WebhookEventTypes = []string{
+	WebhookTodoItemCreated,
+	WebhookTodoItemUpdated,
+	WebhookTodoItemCompleted,
+	WebhookTodoItemDeleted,
+}
+
+// Webhook delivery statuses
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// Webhook model represents a subscription of a URL to the todo events of a user,
+// the deliveries are signed with the Secret
+type Webhook struct {
+	gorm.Model
+	UserID uint `gorm:"index"`
+	URL    string
+	Secret string `json:",omitempty"`
+	Events WebhookEvents
+}
+
+// WebhookEvents is a set of event types, stored comma separated
+type WebhookEvents []string
+
+// Has reports whether eventType is part of the set
+func (e WebhookEvents) Has(eventType string) bool {
+	for _, event := range e {
+		if event == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Value ...
+func (e WebhookEvents) Value() (driver.Value, error) {
+	return strings.Join(e, ","), nil
+}
+
+// Scan ...
+func (e *WebhookEvents) Scan(value interface{}) error {
+	var s string
+	switch v := value.(type) {
+	case nil:
+	case []byte:
+		s = string(v)
+	case string:
+		s = v
+	default:
+		return fmt.Errorf("cannot scan %T into WebhookEvents", value)
+	}
+
+	*e = WebhookEvents{}
+	if s != "" {
+		*e = strings.Split(s, ",")
+	}
+	return nil
+}
+
+// WebhookOutboxEvent is a todo event written in the transaction of the mutation,
+// it waits in the outbox until the dispatcher creates the deliveries of the subscribed webhooks
+type WebhookOutboxEvent struct {
+	ID           uint `gorm:"primarykey"`
+	CreatedAt    time.Time
+	UserID       uint
+	Type         string
+	Payload      []byte
+	DispatchedAt *time.Time `gorm:"index"`
+}
+
+// WebhookDelivery logs the attempts to deliver an outbox event to a webhook
+type WebhookDelivery struct {
+	ID             uint `gorm:"primarykey"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	WebhookID      uint               `gorm:"index"`
+	Webhook        Webhook            `gorm:"constraint:OnDelete:CASCADE;" json:"-"`
+	EventID        uint               `gorm:"index"`
+	Event          WebhookOutboxEvent `gorm:"constraint:OnDelete:CASCADE;" json:"-"`
+	EventType      string
+	Status         string    `gorm:"index:idx_webhook_deliveries_due"`
+	NextAttemptAt  time.Time `gorm:"index:idx_webhook_deliveries_due"`
+	Attempts       int
+	ResponseStatus int
+	Error          string
+	DeliveredAt    *time.Time
+}
+
+// WebhookPayload is the body posted to a webhook, Data is the todo item of the event
+type WebhookPayload struct {
+	EventID   uint
+	Type      string
+	CreatedAt time.Time
+	Data      json.RawMessage
+}
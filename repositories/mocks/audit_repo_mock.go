@@ -0,0 +1,69 @@
+package mocks
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/danikg/go-todo-rest-api/models"
+)
+
+// AuditRepositoryMock keeps the events in memory
+type AuditRepositoryMock struct {
+	mu     sync.Mutex
+	Events []models.AuditEvent
+}
+
+// Create ...
+func (s *AuditRepositoryMock) Create(ctx context.Context, event *models.AuditEvent) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	event.ID = uint(len(s.Events) + 1)
+	s.Events = append(s.Events, *event)
+	return nil
+}
+
+// GetAllByTodoList ...
+func (s *AuditRepositoryMock) GetAllByTodoList(ctx context.Context, listID uint, page models.Page) ([]models.AuditEvent, error) {
+	if listID == 0 {
+		return []models.AuditEvent{}, errors.New("err")
+	}
+	return s.filter(page, func(event models.AuditEvent) bool {
+		return event.TodoListID != nil && *event.TodoListID == listID
+	}), nil
+}
+
+// GetAllByUser ...
+func (s *AuditRepositoryMock) GetAllByUser(ctx context.Context, userID uint, page models.Page) ([]models.AuditEvent, error) {
+	if userID == 0 {
+		return []models.AuditEvent{}, errors.New("err")
+	}
+	return s.filter(page, func(event models.AuditEvent) bool {
+		return event.UserID == userID || (event.ActorID != nil && *event.ActorID == userID)
+	}), nil
+}
+
+func (s *AuditRepositoryMock) filter(page models.Page, keep func(models.AuditEvent) bool) []models.AuditEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	events := []models.AuditEvent{}
+	for i := len(s.Events) - 1; i >= 0; i-- {
+		if keep(s.Events[i]) {
+			events = append(events, s.Events[i])
+		}
+	}
+	if page.Offset >= len(events) {
+		return []models.AuditEvent{}
+	}
+	events = events[page.Offset:]
+	if len(events) > page.Limit {
+		events = events[:page.Limit]
+	}
+	return events
+}
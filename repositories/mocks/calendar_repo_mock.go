@@ -0,0 +1,82 @@
+package mocks
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/danikg/go-todo-rest-api/models"
+)
+
+// CalendarRepositoryMock keeps the feed token hashes in memory, user 1 has a recurring todo item
+// with tags in todo list 1 and a completed one in todo list 2
+type CalendarRepositoryMock struct {
+	Tokens map[string]uint
+}
+
+// SaveFeedToken ...
+func (s *CalendarRepositoryMock) SaveFeedToken(ctx context.Context, userID uint, hash string) error {
+	s.DeleteFeedToken(ctx, userID)
+	if s.Tokens == nil {
+		s.Tokens = map[string]uint{}
+	}
+	s.Tokens[hash] = userID
+	return nil
+}
+
+// DeleteFeedToken ...
+func (s *CalendarRepositoryMock) DeleteFeedToken(ctx context.Context, userID uint) error {
+	for hash, id := range s.Tokens {
+		if id == userID {
+			delete(s.Tokens, hash)
+			return nil
+		}
+	}
+	return errors.New("record not found")
+}
+
+// GetFeedTokenUser ...
+func (s *CalendarRepositoryMock) GetFeedTokenUser(ctx context.Context, hash string) (uint, error) {
+	userID, ok := s.Tokens[hash]
+	if !ok {
+		return 0, errors.New("record not found")
+	}
+	return userID, nil
+}
+
+// GetTodoItems ...
+func (s *CalendarRepositoryMock) GetTodoItems(ctx context.Context, userID uint, listID uint) ([]models.TodoItem, error) {
+	if userID != 1 {
+		return []models.TodoItem{}, errors.New("err")
+	}
+
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	due := time.Date(2024, 5, 6, 9, 0, 0, 0, time.FixedZone("CEST", 2*60*60))
+	todoItem1 := models.TodoItem{
+		Title:       "Weekly review; plan the sprint, " + strings.Repeat("ünïcödé ", 10),
+		Description: "agenda:\nnotes, actions\\follow-ups",
+		Due:         &due,
+		Recurrence:  "FREQ=WEEKLY;BYDAY=MO",
+		TodoListID:  1,
+		TodoList:    models.TodoList{Name: "list1", UserID: 1},
+		Tags:        []models.Tag{{Text: "work"}, {Text: "a,b"}},
+	}
+	todoItem1.ID, todoItem1.CreatedAt, todoItem1.UpdatedAt = 1, created, created
+	todoItem2 := models.TodoItem{
+		Title:      "Bread",
+		Completed:  true,
+		TodoListID: 2,
+		TodoList:   models.TodoList{Name: "list2", UserID: 1},
+		Tags:       []models.Tag{},
+	}
+	todoItem2.ID, todoItem2.CreatedAt, todoItem2.UpdatedAt = 2, created, created.Add(time.Hour)
+
+	todoItems := []models.TodoItem{}
+	for _, todoItem := range []models.TodoItem{todoItem1, todoItem2} {
+		if listID == 0 || todoItem.TodoListID == listID {
+			todoItems = append(todoItems, todoItem)
+		}
+	}
+	return todoItems, nil
+}
@@ -0,0 +1,51 @@
+package mocks
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/danikg/go-todo-rest-api/models"
+	"github.com/danikg/go-todo-rest-api/utils/export"
+)
+
+// ExportRepositoryMock exports user 1 with tag 1, todo list 1 holding two todo items and the empty todo list 2
+type ExportRepositoryMock struct{}
+
+// Export ...
+func (s *ExportRepositoryMock) Export(ctx context.Context, userID uint, w export.Writer) error {
+	if userID != 1 {
+		return errors.New("record not found")
+	}
+
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	user := models.User{Username: "user1"}
+	user.ID = 1
+	tag := models.Tag{Text: "work", UserID: 1}
+	tag.ID = 1
+	todoList1 := models.TodoList{Name: "Groceries", UserID: 1}
+	todoList1.ID = 1
+	todoList2 := models.TodoList{Name: "Empty", UserID: 1}
+	todoList2.ID = 2
+	todoItem1 := models.TodoItem{Title: "Milk", Description: "2 liters,\nlow fat", TodoListID: 1, Tags: []models.Tag{tag}}
+	todoItem1.ID, todoItem1.CreatedAt, todoItem1.UpdatedAt = 1, created, created
+	todoItem2 := models.TodoItem{Title: "Bread", Completed: true, TodoListID: 1, Tags: []models.Tag{}}
+	todoItem2.ID, todoItem2.CreatedAt, todoItem2.UpdatedAt = 2, created, created
+
+	if err := w.User(user); err != nil {
+		return err
+	}
+	if err := w.Tag(tag); err != nil {
+		return err
+	}
+	if err := w.TodoList(todoList1); err != nil {
+		return err
+	}
+	if err := w.TodoItem(todoItem1); err != nil {
+		return err
+	}
+	if err := w.TodoItem(todoItem2); err != nil {
+		return err
+	}
+	return w.TodoList(todoList2)
+}
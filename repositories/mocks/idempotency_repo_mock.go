@@ -0,0 +1,82 @@
+package mocks
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/danikg/go-todo-rest-api/models"
+	"gorm.io/gorm"
+)
+
+// IdempotencyRepositoryMock keeps the keys in memory
+type IdempotencyRepositoryMock struct {
+	mu   sync.Mutex
+	keys map[string]models.IdempotencyKey
+}
+
+func idempotencyMapKey(userID uint, key string) string {
+	return fmt.Sprintf("%d/%s", userID, key)
+}
+
+// Claim ...
+func (s *IdempotencyRepositoryMock) Claim(ctx context.Context, key *models.IdempotencyKey) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.keys == nil {
+		s.keys = map[string]models.IdempotencyKey{}
+	}
+	mapKey := idempotencyMapKey(key.UserID, key.Key)
+	if stored, ok := s.keys[mapKey]; ok && stored.ExpiresAt.After(key.CreatedAt) {
+		return false, nil
+	}
+	s.keys[mapKey] = *key
+	return true, nil
+}
+
+// Get ...
+func (s *IdempotencyRepositoryMock) Get(ctx context.Context, userID uint, key string) (models.IdempotencyKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.keys[idempotencyMapKey(userID, key)]
+	if !ok {
+		return models.IdempotencyKey{}, gorm.ErrRecordNotFound
+	}
+	return stored, nil
+}
+
+// Complete ...
+func (s *IdempotencyRepositoryMock) Complete(ctx context.Context, key *models.IdempotencyKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.keys[idempotencyMapKey(key.UserID, key.Key)] = *key
+	return nil
+}
+
+// Release ...
+func (s *IdempotencyRepositoryMock) Release(ctx context.Context, key *models.IdempotencyKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.keys, idempotencyMapKey(key.UserID, key.Key))
+	return nil
+}
+
+// DeleteExpired ...
+func (s *IdempotencyRepositoryMock) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var deleted int64
+	for mapKey, stored := range s.keys {
+		if !stored.ExpiresAt.After(now) {
+			delete(s.keys, mapKey)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
@@ -0,0 +1,34 @@
+package mocks
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/danikg/go-todo-rest-api/models"
+)
+
+// MaintenanceRepositoryMock ...
+type MaintenanceRepositoryMock struct {
+	GenerateErr bool
+	// Before is the cutoff of the last purge
+	Before time.Time
+}
+
+// Purge ...
+func (s *MaintenanceRepositoryMock) Purge(ctx context.Context, before time.Time) (models.PurgeReport, error) {
+	if s.GenerateErr {
+		return models.PurgeReport{}, errors.New("err")
+	}
+
+	s.Before = before
+	return models.PurgeReport{SoftDeleted: 1, Tombstones: 2, IdempotencyKeys: 3, WebhookDeliveries: 4, WebhookEvents: 2}, nil
+}
+
+// Stats ...
+func (s *MaintenanceRepositoryMock) Stats(ctx context.Context) (models.Stats, error) {
+	if s.GenerateErr {
+		return models.Stats{}, errors.New("err")
+	}
+	return models.Stats{Users: 2, TodoLists: 2, TodoItems: 2, CompletedItems: 1, Tags: 2, ChangeSequence: 10}, nil
+}
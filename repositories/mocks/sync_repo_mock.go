@@ -0,0 +1,98 @@
+package mocks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/danikg/go-todo-rest-api/models"
+)
+
+// SyncRepositoryMock holds todo list 1 with todo item 1 and tag 1 of user 1, all at version 1,
+// changes with another base version conflict
+type SyncRepositoryMock struct{}
+
+func syncRecords() (models.TodoList, models.TodoItem, models.Tag) {
+	todoList := models.TodoList{Name: "list", UserID: 1, Version: 1}
+	todoList.ID = 1
+	todoItem := models.TodoItem{Title: "item", TodoListID: 1, TodoList: todoList, Version: 1}
+	todoItem.ID = 1
+	tag := models.Tag{Text: "work", UserID: 1, Version: 1}
+	tag.ID = 1
+	return todoList, todoItem, tag
+}
+
+// Changes ...
+func (s *SyncRepositoryMock) Changes(ctx context.Context, userID uint, since uint64) (models.SyncChanges, error) {
+	if userID != 1 {
+		return models.SyncChanges{}, errors.New("err")
+	}
+
+	changes := models.SyncChanges{Cursor: 2, TodoLists: []models.TodoList{}, TodoItems: []models.TodoItem{}, Tags: []models.Tag{}}
+	if since < 1 {
+		todoList, todoItem, tag := syncRecords()
+		changes.TodoLists = append(changes.TodoLists, todoList)
+		changes.TodoItems = append(changes.TodoItems, todoItem)
+		changes.Tags = append(changes.Tags, tag)
+	}
+	if since > 0 && since < 2 {
+		changes.Deleted = []models.Tombstone{{ID: 1, Version: 2, UserID: 1, ResourceType: models.AuditTodoItem, ResourceID: 2}}
+	}
+	return changes, nil
+}
+
+// Push ...
+func (s *SyncRepositoryMock) Push(ctx context.Context, userID uint, changes []models.SyncChange) (models.SyncReport, error) {
+	if userID != 1 {
+		return models.SyncReport{}, errors.New("err")
+	}
+
+	report := models.SyncReport{Cursor: 2, Results: make([]models.SyncResult, len(changes))}
+	for i, change := range changes {
+		result := models.SyncResult{Index: i, ClientID: change.ClientID, ID: change.ID, Status: models.SyncApplied}
+		version := uint64(2 + i)
+
+		todoList, todoItem, tag := syncRecords()
+		var current, updated interface{}
+		switch change.Type {
+		case models.AuditTodoList:
+			current = todoList
+			if change.TodoList != nil {
+				todoList.Name = change.TodoList.Name
+			}
+			todoList.Version = version
+			updated = todoList
+		case models.AuditTodoItem:
+			current = todoItem
+			if change.TodoItem != nil {
+				todoItem.Title = change.TodoItem.Title
+				todoItem.Completed = change.TodoItem.Completed
+			}
+			todoItem.Version = version
+			updated = todoItem
+		case models.AuditTag:
+			current = tag
+			if change.Tag != nil {
+				tag.Text = change.Tag.Text
+			}
+			tag.Version = version
+			updated = tag
+		}
+
+		switch {
+		case change.Op == models.SyncCreate:
+			result.ID, result.Version, result.Record = 1, version, updated
+		case change.ID != 1:
+			result.Status, result.Error = models.SyncError, "record not found"
+		case change.BaseVersion != 1:
+			result.Status, result.Error = models.SyncConflict, fmt.Sprintf("base version %d", change.BaseVersion)
+			result.Version, result.Record = 1, current
+		case change.Op == models.SyncUpdate:
+			result.Version, result.Record, result.Before = version, updated, current
+		default:
+			result.Before = current
+		}
+		report.Results[i] = result
+	}
+	return report, nil
+}
@@ -1,16 +1,18 @@
 package mocks
 
 import (
+	"context"
 	"errors"
 
 	"github.com/danikg/go-todo-rest-api/models"
+	"github.com/danikg/go-todo-rest-api/repositories"
 )
 
 // TagRepositoryMock ...
 type TagRepositoryMock struct{}
 
 // GetAll ...
-func (s *TagRepositoryMock) GetAll(todoItem *models.TodoItem) ([]models.Tag, error) {
+func (s *TagRepositoryMock) GetAll(ctx context.Context, todoItem *models.TodoItem) ([]models.Tag, error) {
 	if todoItem.ID != 1 {
 		return []models.Tag{}, errors.New("err")
 	}
@@ -21,8 +23,19 @@ func (s *TagRepositoryMock) GetAll(todoItem *models.TodoItem) ([]models.Tag, err
 	return tags, nil
 }
 
+// GetAllByUser ...
+func (s *TagRepositoryMock) GetAllByUser(ctx context.Context, userID uint) ([]models.TagUsage, error) {
+	if userID != 1 {
+		return []models.TagUsage{}, errors.New("err")
+	}
+
+	usages := []models.TagUsage{{Tag: models.Tag{Text: "tag1", UserID: 1}, UsageCount: 2}}
+	usages[0].ID = 1
+	return usages, nil
+}
+
 // GetSingle ...
-func (s *TagRepositoryMock) GetSingle(id uint) (models.Tag, error) {
+func (s *TagRepositoryMock) GetSingle(ctx context.Context, id uint) (models.Tag, error) {
 	if id != 1 {
 		return models.Tag{}, errors.New("not found")
 	}
@@ -32,8 +45,36 @@ func (s *TagRepositoryMock) GetSingle(id uint) (models.Tag, error) {
 	return tag, nil
 }
 
+// GetTodoItems ...
+func (s *TagRepositoryMock) GetTodoItems(ctx context.Context, tagID uint, page models.Page) ([]models.TodoItem, error) {
+	if tagID != 1 {
+		return []models.TodoItem{}, errors.New("err")
+	}
+
+	todoItems := []models.TodoItem{
+		{Title: "item1", Description: ""},
+		{Title: "item2", Description: "desc2"},
+	}
+
+	todoItems[0].ID = 1
+	todoItems[1].ID = 2
+	return todoItems, nil
+}
+
+// FindOrCreate ...
+func (s *TagRepositoryMock) FindOrCreate(ctx context.Context, userID uint, tag *models.Tag) error {
+	if userID != 1 {
+		return errors.New("err")
+	}
+
+	tag.ID = 1
+	tag.UserID = userID
+	tag.Normalized = models.NormalizeTagText(tag.Text)
+	return nil
+}
+
 // Create ...
-func (s *TagRepositoryMock) Create(todoItem *models.TodoItem, tag *models.Tag) error {
+func (s *TagRepositoryMock) Create(ctx context.Context, todoItem *models.TodoItem, tag *models.Tag) error {
 	if todoItem.ID != 1 {
 		return errors.New("err")
 	}
@@ -41,7 +82,7 @@ func (s *TagRepositoryMock) Create(todoItem *models.TodoItem, tag *models.Tag) e
 }
 
 // Update ...
-func (s *TagRepositoryMock) Update(id uint, tagData *models.Tag) (models.Tag, error) {
+func (s *TagRepositoryMock) Update(ctx context.Context, id uint, tagData *models.Tag) (models.Tag, error) {
 	if id != 1 {
 		return models.Tag{}, errors.New("err")
 	}
@@ -51,8 +92,22 @@ func (s *TagRepositoryMock) Update(id uint, tagData *models.Tag) (models.Tag, er
 	return tag, nil
 }
 
+// Merge ...
+func (s *TagRepositoryMock) Merge(ctx context.Context, id uint, targetID uint) (models.Tag, error) {
+	if id != 1 {
+		return models.Tag{}, errors.New("not found")
+	}
+	if targetID == id {
+		return models.Tag{}, repositories.ErrInvalidTagMerge
+	}
+
+	tag := models.Tag{Text: "tag2"}
+	tag.ID = targetID
+	return tag, nil
+}
+
 // Remove ...
-func (s *TagRepositoryMock) Remove(todoItem *models.TodoItem, tagID uint) error {
+func (s *TagRepositoryMock) Remove(ctx context.Context, todoItem *models.TodoItem, tagID uint) error {
 	if tagID != 1 {
 		return errors.New("err")
 	}
@@ -60,7 +115,7 @@ func (s *TagRepositoryMock) Remove(todoItem *models.TodoItem, tagID uint) error
 }
 
 // Delete ...
-func (s *TagRepositoryMock) Delete(id uint) error {
+func (s *TagRepositoryMock) Delete(ctx context.Context, id uint) error {
 	if id != 1 {
 		return errors.New("err")
 	}
@@ -1,6 +1,7 @@
 package mocks
 
 import (
+	"context"
 	"errors"
 
 	"github.com/danikg/go-todo-rest-api/models"
@@ -10,7 +11,7 @@ import (
 type TodoItemRepositoryMock struct{}
 
 // GetAll ...
-func (s *TodoItemRepositoryMock) GetAll(listID uint) ([]models.TodoItem, error) {
+func (s *TodoItemRepositoryMock) GetAll(ctx context.Context, listID uint) ([]models.TodoItem, error) {
 	if listID != 1 {
 		return []models.TodoItem{}, errors.New("err")
 	}
@@ -25,27 +26,65 @@ func (s *TodoItemRepositoryMock) GetAll(listID uint) ([]models.TodoItem, error)
 	return todoItems, nil
 }
 
+// GetAllByLists ...
+func (s *TodoItemRepositoryMock) GetAllByLists(ctx context.Context, listIDs []uint) ([]models.TodoItem, error) {
+	todoItems := []models.TodoItem{}
+	for _, listID := range listIDs {
+		if listID == 1 {
+			listItems, _ := s.GetAll(ctx, listID)
+			for i := range listItems {
+				listItems[i].TodoListID = listID
+				listItems[i].TodoList = models.TodoList{Name: "list1", UserID: 1}
+				listItems[i].TodoList.ID = listID
+			}
+			todoItems = append(todoItems, listItems...)
+		}
+	}
+	return todoItems, nil
+}
+
+// GetAllByTags ...
+func (s *TodoItemRepositoryMock) GetAllByTags(ctx context.Context, userID uint, filter models.TagFilter, page models.Page) ([]models.TodoItem, error) {
+	if userID != 1 {
+		return []models.TodoItem{}, errors.New("err")
+	}
+
+	todoItems := []models.TodoItem{
+		{Title: "item1", Description: ""},
+		{Title: "item2", Description: "desc2"},
+	}
+
+	todoItems[0].ID = 1
+	todoItems[1].ID = 2
+	return todoItems, nil
+}
+
 // GetSingle ...
-func (s *TodoItemRepositoryMock) GetSingle(id uint) (models.TodoItem, error) {
+func (s *TodoItemRepositoryMock) GetSingle(ctx context.Context, id uint) (models.TodoItem, error) {
 	if id != 1 {
 		return models.TodoItem{}, errors.New("not found")
 	}
 
-	todoItem := models.TodoItem{Title: "item1", Description: ""}
+	todoItem := models.TodoItem{Title: "item1", Description: "", TodoListID: 1}
 	todoItem.ID = 1
+	todoItem.TodoList.ID = 1
+	todoItem.TodoList.UserID = 1
 	return todoItem, nil
 }
 
 // Create ...
-func (s *TodoItemRepositoryMock) Create(listID uint, todoItem *models.TodoItem) error {
+func (s *TodoItemRepositoryMock) Create(ctx context.Context, listID uint, todoItem *models.TodoItem) error {
 	if listID != 1 {
 		return errors.New("err")
 	}
+	if todoItem.ID == 0 {
+		todoItem.ID = 1
+	}
 	return nil
 }
 
 // Update ...
-func (s *TodoItemRepositoryMock) Update(id uint, todoItemData *models.TodoItem) (models.TodoItem, error) {
+func (s *TodoItemRepositoryMock) Update(ctx context.Context, id uint, todoItemData *models.TodoItemUpdate) (models.TodoItem, error) {
 	if id != 1 {
 		return models.TodoItem{}, errors.New("err")
 	}
@@ -56,9 +95,24 @@ func (s *TodoItemRepositoryMock) Update(id uint, todoItemData *models.TodoItem)
 }
 
 // Delete ...
-func (s *TodoItemRepositoryMock) Delete(id uint) error {
+func (s *TodoItemRepositoryMock) Delete(ctx context.Context, id uint) error {
 	if id != 1 {
 		return errors.New("err")
 	}
 	return nil
 }
+
+// Bulk ...
+func (s *TodoItemRepositoryMock) Bulk(ctx context.Context, operations []models.BulkOperation, allOrNothing bool) (models.BulkReport, error) {
+	report := models.BulkReport{Committed: true}
+	for i, operation := range operations {
+		result := models.BulkResult{Index: i, ID: operation.ID, OK: operation.ID == 1}
+		if !result.OK {
+			result.Error = "not found"
+			report.Committed = report.Committed && !allOrNothing
+		}
+		result.Completed = result.OK && operation.Op == models.BulkComplete
+		report.Results = append(report.Results, result)
+	}
+	return report, nil
+}
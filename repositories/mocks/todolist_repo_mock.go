@@ -1,6 +1,7 @@
 package mocks
 
 import (
+	"context"
 	"errors"
 
 	"github.com/danikg/go-todo-rest-api/models"
@@ -10,7 +11,7 @@ import (
 type TodoListRepositoryMock struct{}
 
 // GetAll ...
-func (s *TodoListRepositoryMock) GetAll(userID uint) ([]models.TodoList, error) {
+func (s *TodoListRepositoryMock) GetAll(ctx context.Context, userID uint) ([]models.TodoList, error) {
 	if userID != 1 {
 		return []models.TodoList{}, errors.New("err")
 	}
@@ -25,8 +26,20 @@ func (s *TodoListRepositoryMock) GetAll(userID uint) ([]models.TodoList, error)
 	return todoLists, nil
 }
 
+// GetAllByUsers ...
+func (s *TodoListRepositoryMock) GetAllByUsers(ctx context.Context, userIDs []uint) ([]models.TodoList, error) {
+	todoLists := []models.TodoList{}
+	for _, userID := range userIDs {
+		if userID == 1 {
+			userLists, _ := s.GetAll(ctx, userID)
+			todoLists = append(todoLists, userLists...)
+		}
+	}
+	return todoLists, nil
+}
+
 // GetSingle ...
-func (s *TodoListRepositoryMock) GetSingle(id uint) (models.TodoList, error) {
+func (s *TodoListRepositoryMock) GetSingle(ctx context.Context, id uint) (models.TodoList, error) {
 	if id != 1 {
 		return models.TodoList{}, errors.New("not found")
 	}
@@ -37,15 +50,19 @@ func (s *TodoListRepositoryMock) GetSingle(id uint) (models.TodoList, error) {
 }
 
 // Create ...
-func (s *TodoListRepositoryMock) Create(userID uint, todoList *models.TodoList) error {
+func (s *TodoListRepositoryMock) Create(ctx context.Context, userID uint, todoList *models.TodoList) error {
 	if userID != 1 {
 		return errors.New("err")
 	}
+	if todoList.ID == 0 {
+		todoList.ID = 1
+	}
+	todoList.UserID = userID
 	return nil
 }
 
 // Update ...
-func (s *TodoListRepositoryMock) Update(id uint, todoListData *models.TodoList) (models.TodoList, error) {
+func (s *TodoListRepositoryMock) Update(ctx context.Context, id uint, todoListData *models.TodoList) (models.TodoList, error) {
 	if id != 1 {
 		return models.TodoList{}, errors.New("err")
 	}
@@ -55,8 +72,19 @@ func (s *TodoListRepositoryMock) Update(id uint, todoListData *models.TodoList)
 	return todoList, nil
 }
 
+// Transfer ...
+func (s *TodoListRepositoryMock) Transfer(ctx context.Context, id uint, userID uint) (models.TodoList, error) {
+	if id != 1 {
+		return models.TodoList{}, errors.New("err")
+	}
+
+	todoList := models.TodoList{Name: "list1", UserID: userID}
+	todoList.ID = 1
+	return todoList, nil
+}
+
 // Delete ...
-func (s *TodoListRepositoryMock) Delete(id uint) error {
+func (s *TodoListRepositoryMock) Delete(ctx context.Context, id uint) error {
 	if id != 1 {
 		return errors.New("err")
 	}
@@ -0,0 +1,17 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/danikg/go-todo-rest-api/repositories"
+)
+
+// TransactorMock hands out Repos, nothing is rolled back
+type TransactorMock struct {
+	Repos repositories.Repositories
+}
+
+// Transaction ...
+func (s *TransactorMock) Transaction(ctx context.Context, fn func(repos repositories.Repositories) error) error {
+	return fn(s.Repos)
+}
@@ -1,6 +1,7 @@
 package mocks
 
 import (
+	"context"
 	"errors"
 
 	"github.com/danikg/go-todo-rest-api/models"
@@ -9,10 +10,11 @@ import (
 // UserRepositoryMock ...
 type UserRepositoryMock struct {
 	GenerateErr bool
+	Revoked     []uint
 }
 
 // GetAll ...
-func (s *UserRepositoryMock) GetAll() ([]models.User, error) {
+func (s *UserRepositoryMock) GetAll(ctx context.Context) ([]models.User, error) {
 	if s.GenerateErr {
 		return []models.User{}, errors.New("err")
 	}
@@ -28,7 +30,7 @@ func (s *UserRepositoryMock) GetAll() ([]models.User, error) {
 }
 
 // GetSingle ...
-func (s *UserRepositoryMock) GetSingle(id uint) (models.User, error) {
+func (s *UserRepositoryMock) GetSingle(ctx context.Context, id uint) (models.User, error) {
 	if id != 1 {
 		return models.User{}, errors.New("err")
 	}
@@ -38,8 +40,23 @@ func (s *UserRepositoryMock) GetSingle(id uint) (models.User, error) {
 	return user, nil
 }
 
+// GetByUsername ...
+func (s *UserRepositoryMock) GetByUsername(ctx context.Context, username string) (models.User, error) {
+	if username != "user1" && username != "disabled" {
+		return models.User{}, errors.New("err")
+	}
+
+	// bcrypt hash of "password"
+	user := models.User{Username: username, PasswordHash: "$2a$04$8mPSKfWgAqFebASK5rCM2eeIB3rat3248EKvXhaJI8ipNIxMDjRfW"}
+	user.ID = 1
+	if username == "disabled" {
+		user.ID, user.Disabled = 3, true
+	}
+	return user, nil
+}
+
 // Create ...
-func (s *UserRepositoryMock) Create(user *models.User) error {
+func (s *UserRepositoryMock) Create(ctx context.Context, user *models.User) error {
 	if s.GenerateErr {
 		return errors.New("err")
 	}
@@ -47,7 +64,7 @@ func (s *UserRepositoryMock) Create(user *models.User) error {
 }
 
 // Update ...
-func (s *UserRepositoryMock) Update(id uint, userData *models.User) (models.User, error) {
+func (s *UserRepositoryMock) Update(ctx context.Context, id uint, userData *models.User) (models.User, error) {
 	if id != 1 {
 		return models.User{}, errors.New("err")
 	}
@@ -57,8 +74,42 @@ func (s *UserRepositoryMock) Update(id uint, userData *models.User) (models.User
 	return user, nil
 }
 
+// SetDisabled ...
+func (s *UserRepositoryMock) SetDisabled(ctx context.Context, id uint, disabled bool) (models.User, error) {
+	if id != 1 {
+		return models.User{}, errors.New("err")
+	}
+
+	user := models.User{Username: "user1", Disabled: disabled}
+	user.ID = 1
+	return user, nil
+}
+
+// GetCredentials ...
+func (s *UserRepositoryMock) GetCredentials(ctx context.Context, id uint) (models.User, error) {
+	if id != 1 && id != 3 {
+		return models.User{}, errors.New("err")
+	}
+
+	user := models.User{Username: "user1"}
+	user.ID = 1
+	if id == 3 {
+		user.ID, user.Disabled = 3, true
+	}
+	return user, nil
+}
+
+// RevokeTokens ...
+func (s *UserRepositoryMock) RevokeTokens(ctx context.Context, id uint) error {
+	if id != 1 {
+		return errors.New("err")
+	}
+	s.Revoked = append(s.Revoked, id)
+	return nil
+}
+
 // Delete ...
-func (s *UserRepositoryMock) Delete(id uint) error {
+func (s *UserRepositoryMock) Delete(ctx context.Context, id uint) error {
 	if id != 1 {
 		return errors.New("err")
 	}
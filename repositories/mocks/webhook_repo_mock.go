@@ -0,0 +1,127 @@
+package mocks
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/danikg/go-todo-rest-api/models"
+)
+
+// WebhookRepositoryMock knows webhook 1 of user 1 and keeps the deliveries in memory
+type WebhookRepositoryMock struct {
+	mu         sync.Mutex
+	Deliveries []models.WebhookDelivery
+}
+
+func webhook1() models.Webhook {
+	webhook := models.Webhook{
+		UserID: 1,
+		URL:    "http://example.com/hook",
+		Secret: "secret",
+		Events: models.WebhookEvents{models.WebhookTodoItemCreated},
+	}
+	webhook.ID = 1
+	return webhook
+}
+
+// GetAll ...
+func (s *WebhookRepositoryMock) GetAll(ctx context.Context, userID uint) ([]models.Webhook, error) {
+	if userID != 1 {
+		return []models.Webhook{}, errors.New("err")
+	}
+	return []models.Webhook{webhook1()}, nil
+}
+
+// GetSingle ...
+func (s *WebhookRepositoryMock) GetSingle(ctx context.Context, id uint) (models.Webhook, error) {
+	if id != 1 {
+		return models.Webhook{}, errors.New("not found")
+	}
+	return webhook1(), nil
+}
+
+// Create ...
+func (s *WebhookRepositoryMock) Create(ctx context.Context, userID uint, webhook *models.Webhook) error {
+	if userID != 1 {
+		return errors.New("err")
+	}
+	webhook.ID = 2
+	webhook.UserID = userID
+	return nil
+}
+
+// Update ...
+func (s *WebhookRepositoryMock) Update(ctx context.Context, id uint, webhookData *models.Webhook) (models.Webhook, error) {
+	if id != 1 {
+		return models.Webhook{}, errors.New("err")
+	}
+
+	webhook := webhook1()
+	if webhookData.URL != "" {
+		webhook.URL = webhookData.URL
+	}
+	if len(webhookData.Events) != 0 {
+		webhook.Events = webhookData.Events
+	}
+	return webhook, nil
+}
+
+// Delete ...
+func (s *WebhookRepositoryMock) Delete(ctx context.Context, id uint) error {
+	if id != 1 {
+		return errors.New("err")
+	}
+	return nil
+}
+
+// GetDeliveries ...
+func (s *WebhookRepositoryMock) GetDeliveries(ctx context.Context, webhookID uint, page models.Page) ([]models.WebhookDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	deliveries := []models.WebhookDelivery{}
+	for i := len(s.Deliveries) - 1; i >= 0; i-- {
+		if s.Deliveries[i].WebhookID == webhookID {
+			deliveries = append(deliveries, s.Deliveries[i])
+		}
+	}
+	return deliveries, nil
+}
+
+// FanOut ...
+func (s *WebhookRepositoryMock) FanOut(ctx context.Context, now time.Time, limit int) (int, error) {
+	return 0, nil
+}
+
+// ClaimDueDeliveries ...
+func (s *WebhookRepositoryMock) ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.WebhookDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	claimed := []models.WebhookDelivery{}
+	for i := range s.Deliveries {
+		delivery := &s.Deliveries[i]
+		if len(claimed) == limit || delivery.Status != models.WebhookDeliveryPending || delivery.NextAttemptAt.After(now) {
+			continue
+		}
+		delivery.NextAttemptAt = now.Add(lease)
+		claimed = append(claimed, *delivery)
+	}
+	return claimed, nil
+}
+
+// UpdateDelivery ...
+func (s *WebhookRepositoryMock) UpdateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.Deliveries {
+		if s.Deliveries[i].ID == delivery.ID {
+			s.Deliveries[i] = *delivery
+			return nil
+		}
+	}
+	return errors.New("not found")
+}
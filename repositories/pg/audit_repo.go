@@ -0,0 +1,47 @@
+package pg
+
+import (
+	"context"
+
+	"github.com/danikg/go-todo-rest-api/models"
+	"gorm.io/gorm"
+)
+
+// AuditRepository ...
+type AuditRepository struct {
+	Conn *gorm.DB
+}
+
+// NewAuditRepository ...
+func NewAuditRepository(conn *gorm.DB) *AuditRepository {
+	return &AuditRepository{Conn: conn}
+}
+
+// Create appends the event
+func (t *AuditRepository) Create(ctx context.Context, event *models.AuditEvent) error {
+	return t.Conn.WithContext(ctx).Create(event).Error
+}
+
+// GetAllByTodoList returns the events of the todo list and its items, newest first
+func (t *AuditRepository) GetAllByTodoList(ctx context.Context, listID uint, page models.Page) ([]models.AuditEvent, error) {
+	events := []models.AuditEvent{}
+	err := t.Conn.WithContext(ctx).
+		Where("todo_list_id = ?", listID).
+		Order("id DESC").
+		Limit(page.Limit).
+		Offset(page.Offset).
+		Find(&events).Error
+	return events, err
+}
+
+// GetAllByUser returns the events on the data of the user and the changes made by the user, newest first
+func (t *AuditRepository) GetAllByUser(ctx context.Context, userID uint, page models.Page) ([]models.AuditEvent, error) {
+	events := []models.AuditEvent{}
+	err := t.Conn.WithContext(ctx).
+		Where("user_id = ? OR actor_id = ?", userID, userID).
+		Order("id DESC").
+		Limit(page.Limit).
+		Offset(page.Offset).
+		Find(&events).Error
+	return events, err
+}
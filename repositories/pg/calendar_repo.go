@@ -0,0 +1,61 @@
+package pg
+
+import (
+	"context"
+
+	"github.com/danikg/go-todo-rest-api/models"
+	"gorm.io/gorm"
+)
+
+// CalendarRepository ...
+type CalendarRepository struct {
+	Conn *gorm.DB
+}
+
+// NewCalendarRepository ...
+func NewCalendarRepository(conn *gorm.DB) *CalendarRepository {
+	return &CalendarRepository{Conn: conn}
+}
+
+// SaveFeedToken stores the hash of the feed token of the user, replacing the previous token
+func (t *CalendarRepository) SaveFeedToken(ctx context.Context, userID uint, hash string) error {
+	return t.Conn.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.FeedToken{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.FeedToken{UserID: userID, Hash: hash}).Error
+	})
+}
+
+// DeleteFeedToken revokes the feed token of the user
+func (t *CalendarRepository) DeleteFeedToken(ctx context.Context, userID uint) error {
+	result := t.Conn.WithContext(ctx).Where("user_id = ?", userID).Delete(&models.FeedToken{})
+	if result.Error == nil && result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return result.Error
+}
+
+// GetFeedTokenUser returns the id of the user owning the feed token with the hash
+func (t *CalendarRepository) GetFeedTokenUser(ctx context.Context, hash string) (uint, error) {
+	feedToken := models.FeedToken{}
+	err := t.Conn.WithContext(ctx).Where("hash = ?", hash).First(&feedToken).Error
+	return feedToken.UserID, err
+}
+
+// GetTodoItems returns the todo items of the user with their tags and todo list,
+// restricted to the todo list listID unless it is 0
+func (t *CalendarRepository) GetTodoItems(ctx context.Context, userID uint, listID uint) ([]models.TodoItem, error) {
+	todoItems := []models.TodoItem{}
+	conn := t.Conn.WithContext(ctx)
+
+	lists := conn.Model(&models.TodoList{}).Select("id").Where("user_id = ?", userID)
+	if listID != 0 {
+		lists = lists.Where("id = ?", listID)
+	}
+	err := conn.Joins("TodoList").Preload("Tags").
+		Where("todo_items.todo_list_id IN (?)", lists).
+		Order("todo_items.id").
+		Find(&todoItems).Error
+	return todoItems, err
+}
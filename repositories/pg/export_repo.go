@@ -0,0 +1,74 @@
+package pg
+
+import (
+	"context"
+
+	"github.com/danikg/go-todo-rest-api/models"
+	"github.com/danikg/go-todo-rest-api/utils/export"
+	"gorm.io/gorm"
+)
+
+// exportBatchSize bounds the rows held in memory while the data of a user is exported
+const exportBatchSize = 500
+
+// ExportRepository ...
+type ExportRepository struct {
+	Conn *gorm.DB
+}
+
+// NewExportRepository ...
+func NewExportRepository(conn *gorm.DB) *ExportRepository {
+	return &ExportRepository{Conn: conn}
+}
+
+// Export streams the user, the tags and the todo lists with their todo items to w in batches,
+// nothing is written when the user does not exist
+func (t *ExportRepository) Export(ctx context.Context, userID uint, w export.Writer) error {
+	conn := t.Conn.WithContext(ctx)
+
+	user := models.User{}
+	if err := conn.First(&user, userID).Error; err != nil {
+		return err
+	}
+	if err := w.User(user); err != nil {
+		return err
+	}
+
+	tags := []models.Tag{}
+	err := conn.Where("user_id = ?", userID).Order("id").FindInBatches(&tags, exportBatchSize, func(*gorm.DB, int) error {
+		for _, tag := range tags {
+			if err := w.Tag(tag); err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Error
+	if err != nil {
+		return err
+	}
+
+	todoLists := []models.TodoList{}
+	return conn.Where("user_id = ?", userID).Order("id").FindInBatches(&todoLists, exportBatchSize, func(*gorm.DB, int) error {
+		for _, todoList := range todoLists {
+			if err := w.TodoList(todoList); err != nil {
+				return err
+			}
+			if err := exportTodoItems(conn, todoList.ID, w); err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Error
+}
+
+func exportTodoItems(conn *gorm.DB, listID uint, w export.Writer) error {
+	todoItems := []models.TodoItem{}
+	return conn.Preload("Tags").Where("todo_list_id = ?", listID).Order("id").FindInBatches(&todoItems, exportBatchSize, func(*gorm.DB, int) error {
+		for _, todoItem := range todoItems {
+			if err := w.TodoItem(todoItem); err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Error
+}
@@ -0,0 +1,64 @@
+package pg
+
+import (
+	"context"
+	"time"
+
+	"github.com/danikg/go-todo-rest-api/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// IdempotencyRepository ...
+type IdempotencyRepository struct {
+	Conn *gorm.DB
+}
+
+// NewIdempotencyRepository ...
+func NewIdempotencyRepository(conn *gorm.DB) *IdempotencyRepository {
+	return &IdempotencyRepository{Conn: conn}
+}
+
+// Claim stores the key unless the user already has an unexpired one with the same value,
+// reporting whether the caller owns the key and should handle the request
+func (t *IdempotencyRepository) Claim(ctx context.Context, key *models.IdempotencyKey) (bool, error) {
+	conn := t.Conn.WithContext(ctx)
+	err := conn.Where("user_id = ? AND key = ? AND expires_at <= ?", key.UserID, key.Key, key.CreatedAt).
+		Delete(&models.IdempotencyKey{}).Error
+	if err != nil {
+		return false, err
+	}
+
+	result := conn.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "key"}},
+		DoNothing: true,
+	}).Create(key)
+	return result.RowsAffected == 1, result.Error
+}
+
+// Get returns the stored key of the user
+func (t *IdempotencyRepository) Get(ctx context.Context, userID uint, key string) (models.IdempotencyKey, error) {
+	idempotencyKey := models.IdempotencyKey{}
+	err := t.Conn.WithContext(ctx).First(&idempotencyKey, "user_id = ? AND key = ?", userID, key).Error
+	return idempotencyKey, err
+}
+
+// Complete stores the response of the request
+func (t *IdempotencyRepository) Complete(ctx context.Context, key *models.IdempotencyKey) error {
+	return t.Conn.WithContext(ctx).Model(key).Updates(map[string]interface{}{
+		"status_code":  key.StatusCode,
+		"content_type": key.ContentType,
+		"body":         key.Body,
+	}).Error
+}
+
+// Release removes the key so that the request can be retried
+func (t *IdempotencyRepository) Release(ctx context.Context, key *models.IdempotencyKey) error {
+	return t.Conn.WithContext(ctx).Delete(key).Error
+}
+
+// DeleteExpired removes the keys that expired before now
+func (t *IdempotencyRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	result := t.Conn.WithContext(ctx).Where("expires_at <= ?", now).Delete(&models.IdempotencyKey{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,91 @@
+package pg
+
+import (
+	"context"
+	"time"
+
+	"github.com/danikg/go-todo-rest-api/models"
+	"gorm.io/gorm"
+)
+
+// MaintenanceRepository ...
+type MaintenanceRepository struct {
+	Conn *gorm.DB
+}
+
+// NewMaintenanceRepository ...
+func NewMaintenanceRepository(conn *gorm.DB) *MaintenanceRepository {
+	return &MaintenanceRepository{Conn: conn}
+}
+
+// Purge removes in one transaction the rows marked as deleted, the tombstones and the finished webhook
+// deliveries older than before, and the expired idempotency keys. Clients that last synced before
+// the purged tombstones miss those deletions until they sync from scratch
+func (t *MaintenanceRepository) Purge(ctx context.Context, before time.Time) (models.PurgeReport, error) {
+	report := models.PurgeReport{}
+	err := t.Conn.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, model := range []interface{}{&models.TodoItem{}, &models.TodoList{}, &models.Tag{}, &models.Webhook{}, &models.User{}} {
+			result := tx.Unscoped().Where("deleted_at < ?", before).Delete(model)
+			if result.Error != nil {
+				return result.Error
+			}
+			report.SoftDeleted += result.RowsAffected
+		}
+
+		for _, purge := range []struct {
+			count *int64
+			model interface{}
+			query string
+			args  []interface{}
+		}{
+			{&report.Tombstones, &models.Tombstone{}, "deleted_at < ?", []interface{}{before}},
+			{&report.IdempotencyKeys, &models.IdempotencyKey{}, "expires_at <= ?", []interface{}{time.Now()}},
+			{&report.WebhookDeliveries, &models.WebhookDelivery{}, "status <> ? AND updated_at < ?", []interface{}{models.WebhookDeliveryPending, before}},
+			// events whose deliveries are all purged, deliveries of a dispatched event are created with it
+			{&report.WebhookEvents, &models.WebhookOutboxEvent{}, "dispatched_at < ? AND NOT EXISTS (SELECT 1 FROM webhook_deliveries WHERE webhook_deliveries.event_id = webhook_outbox_events.id)", []interface{}{before}},
+		} {
+			result := tx.Where(purge.query, purge.args...).Delete(purge.model)
+			if result.Error != nil {
+				return result.Error
+			}
+			*purge.count = result.RowsAffected
+		}
+		return nil
+	})
+	return report, err
+}
+
+// Stats counts the rows of the db
+func (t *MaintenanceRepository) Stats(ctx context.Context) (models.Stats, error) {
+	stats := models.Stats{}
+	conn := t.Conn.WithContext(ctx)
+	for _, count := range []struct {
+		count *int64
+		model interface{}
+		query string
+		args  []interface{}
+	}{
+		{&stats.Users, &models.User{}, "", nil},
+		{&stats.DisabledUsers, &models.User{}, "disabled", nil},
+		{&stats.TodoLists, &models.TodoList{}, "", nil},
+		{&stats.TodoItems, &models.TodoItem{}, "", nil},
+		{&stats.CompletedItems, &models.TodoItem{}, "completed", nil},
+		{&stats.Tags, &models.Tag{}, "", nil},
+		{&stats.Webhooks, &models.Webhook{}, "", nil},
+		{&stats.PendingDeliveries, &models.WebhookDelivery{}, "status = ?", []interface{}{models.WebhookDeliveryPending}},
+		{&stats.AuditEvents, &models.AuditEvent{}, "", nil},
+		{&stats.Tombstones, &models.Tombstone{}, "", nil},
+	} {
+		query := conn.Model(count.model)
+		if count.query != "" {
+			query = query.Where(count.query, count.args...)
+		}
+		if err := query.Count(count.count).Error; err != nil {
+			return stats, err
+		}
+	}
+
+	var err error
+	stats.ChangeSequence, err = currentVersion(conn)
+	return stats, err
+}
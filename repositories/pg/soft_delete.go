@@ -0,0 +1,9 @@
+package pg
+
+import "gorm.io/gorm"
+
+// undelete clears the deletion time of a record about to be created,
+// gorm decodes the zero DeletedAt of a JSON round trip as a soft deletion
+func undelete(model *gorm.Model) {
+	model.DeletedAt = gorm.DeletedAt{}
+}
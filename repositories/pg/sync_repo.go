@@ -0,0 +1,257 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/danikg/go-todo-rest-api/models"
+	"gorm.io/gorm"
+)
+
+// errSyncConflict rolls back a client change whose base version is not the version of the server record
+var errSyncConflict = errors.New("the record was changed by another client")
+
+// errSyncDeleted rolls back a client update of a record deleted on the server
+var errSyncDeleted = errors.New("the record was deleted by another client")
+
+// SyncRepository ...
+type SyncRepository struct {
+	Conn *gorm.DB
+}
+
+// NewSyncRepository ...
+func NewSyncRepository(conn *gorm.DB) *SyncRepository {
+	return &SyncRepository{Conn: conn}
+}
+
+// Changes returns the todo lists, todo items and tags of the user changed after since
+// and the tombstones of those deleted after it, since 0 returns everything without tombstones
+func (t *SyncRepository) Changes(ctx context.Context, userID uint, since uint64) (models.SyncChanges, error) {
+	changes := models.SyncChanges{
+		TodoLists: []models.TodoList{},
+		TodoItems: []models.TodoItem{},
+		Tags:      []models.Tag{},
+		Deleted:   []models.Tombstone{},
+	}
+	conn := t.Conn.WithContext(ctx)
+
+	// the cursor is read first, records changed meanwhile are returned again by the next sync
+	cursor, err := currentVersion(conn)
+	if err != nil {
+		return changes, err
+	}
+	changes.Cursor = cursor
+
+	err = changedSince(conn, since).Where("user_id = ?", userID).Order("version").Find(&changes.TodoLists).Error
+	if err != nil {
+		return changes, err
+	}
+
+	lists := conn.Model(&models.TodoList{}).Select("id").Where("user_id = ?", userID)
+	err = changedSince(conn, since).Preload("Tags").Where("todo_list_id IN (?)", lists).Order("version").Find(&changes.TodoItems).Error
+	if err != nil {
+		return changes, err
+	}
+
+	err = changedSince(conn, since).Where("user_id = ?", userID).Order("version").Find(&changes.Tags).Error
+	if err != nil || since == 0 {
+		return changes, err
+	}
+
+	err = conn.Where("user_id = ? AND version > ?", userID, since).Order("version").Find(&changes.Deleted).Error
+	return changes, err
+}
+
+// Push applies the client changes in order, each one in its own savepoint,
+// so that a conflict or a failure only discards that change
+func (t *SyncRepository) Push(ctx context.Context, userID uint, changes []models.SyncChange) (models.SyncReport, error) {
+	report := models.SyncReport{Results: make([]models.SyncResult, len(changes))}
+
+	err := t.Conn.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i, change := range changes {
+			result := &report.Results[i]
+			*result = models.SyncResult{Index: i, ClientID: change.ClientID, ID: change.ID}
+
+			err := tx.Transaction(func(savepoint *gorm.DB) error {
+				return applySyncChange(ctx, savepoint, userID, change, result)
+			})
+			switch {
+			case err == nil:
+				result.Status = models.SyncApplied
+			case errors.Is(err, errSyncConflict) || errors.Is(err, errSyncDeleted):
+				result.Status = models.SyncConflict
+				result.Error = err.Error()
+			default:
+				result.Status = models.SyncError
+				result.Error = err.Error()
+				result.Record = nil
+			}
+			if result.Status != models.SyncApplied {
+				result.Before = nil
+			}
+		}
+
+		var err error
+		report.Cursor, err = currentVersion(tx)
+		return err
+	})
+	return report, err
+}
+
+// applySyncChange applies a single client change to a record of the user
+func applySyncChange(ctx context.Context, tx *gorm.DB, userID uint, change models.SyncChange, result *models.SyncResult) error {
+	switch change.Type {
+	case models.AuditTodoList:
+		return applyTodoListChange(ctx, tx, userID, change, result)
+	case models.AuditTodoItem:
+		return applyTodoItemChange(ctx, tx, userID, change, result)
+	case models.AuditTag:
+		return applyTagChange(ctx, tx, userID, change, result)
+	default:
+		return fmt.Errorf("unknown type %q", change.Type)
+	}
+}
+
+func applyTodoListChange(ctx context.Context, tx *gorm.DB, userID uint, change models.SyncChange, result *models.SyncResult) error {
+	repo := NewTodoListRepository(tx)
+	if change.Op == models.SyncCreate {
+		todoList := models.TodoList{Name: change.TodoList.Name}
+		if err := repo.Create(ctx, userID, &todoList); err != nil {
+			return err
+		}
+		result.ID, result.Version, result.Record = todoList.ID, todoList.Version, todoList
+		return nil
+	}
+
+	current, err := repo.GetSingle(ctx, change.ID)
+	proceed, err := checkSyncBase(tx, userID, change, err, current.UserID, current.Version, current, result)
+	if !proceed || err != nil {
+		return err
+	}
+
+	switch change.Op {
+	case models.SyncUpdate:
+		todoList, err := repo.Update(ctx, change.ID, change.TodoList)
+		result.Version, result.Record = todoList.Version, todoList
+		return err
+	case models.SyncDelete:
+		return repo.Delete(ctx, change.ID)
+	default:
+		return fmt.Errorf("unknown op %q", change.Op)
+	}
+}
+
+func applyTodoItemChange(ctx context.Context, tx *gorm.DB, userID uint, change models.SyncChange, result *models.SyncResult) error {
+	repo := NewTodoItemRepository(tx)
+	if change.Op == models.SyncCreate {
+		todoList := models.TodoList{}
+		err := tx.First(&todoList, "id = ? AND user_id = ?", change.TodoItem.TodoListID, userID).Error
+		if err != nil {
+			return fmt.Errorf("todo list %d: %w", change.TodoItem.TodoListID, err)
+		}
+
+		todoItem := models.TodoItem{
+			Title:       change.TodoItem.Title,
+			Description: change.TodoItem.Description,
+			Completed:   change.TodoItem.Completed,
+			Due:         change.TodoItem.Due,
+			Recurrence:  change.TodoItem.Recurrence,
+		}
+		if err = repo.Create(ctx, todoList.ID, &todoItem); err != nil {
+			return err
+		}
+		todoItem.TodoList = todoList
+		result.ID, result.Version, result.Record = todoItem.ID, todoItem.Version, todoItem
+		return nil
+	}
+
+	current, err := repo.GetSingle(ctx, change.ID)
+	proceed, err := checkSyncBase(tx, userID, change, err, current.TodoList.UserID, current.Version, current, result)
+	if !proceed || err != nil {
+		return err
+	}
+
+	switch change.Op {
+	case models.SyncUpdate:
+		// the client sends the whole record, its completion state is always applied
+		todoItem, err := repo.Update(ctx, change.ID, &models.TodoItemUpdate{
+			Title:       change.TodoItem.Title,
+			Description: change.TodoItem.Description,
+			Completed:   &change.TodoItem.Completed,
+			Due:         change.TodoItem.Due,
+			Recurrence:  change.TodoItem.Recurrence,
+		})
+		result.Version, result.Record = todoItem.Version, todoItem
+		return err
+	case models.SyncDelete:
+		return repo.Delete(ctx, change.ID)
+	default:
+		return fmt.Errorf("unknown op %q", change.Op)
+	}
+}
+
+func applyTagChange(ctx context.Context, tx *gorm.DB, userID uint, change models.SyncChange, result *models.SyncResult) error {
+	repo := NewTagRepository(tx)
+	if change.Op == models.SyncCreate {
+		tag := models.Tag{Text: change.Tag.Text, Color: change.Tag.Color, ParentID: change.Tag.ParentID}
+		if err := repo.FindOrCreate(ctx, userID, &tag); err != nil {
+			return err
+		}
+		result.ID, result.Version, result.Record = tag.ID, tag.Version, tag
+		return nil
+	}
+
+	current, err := repo.GetSingle(ctx, change.ID)
+	proceed, err := checkSyncBase(tx, userID, change, err, current.UserID, current.Version, current, result)
+	if !proceed || err != nil {
+		return err
+	}
+
+	switch change.Op {
+	case models.SyncUpdate:
+		tag, err := repo.Update(ctx, change.ID, change.Tag)
+		result.Version, result.Record = tag.Version, tag
+		return err
+	case models.SyncDelete:
+		return repo.Delete(ctx, change.ID)
+	default:
+		return fmt.Errorf("unknown op %q", change.Op)
+	}
+}
+
+// checkSyncBase verifies that the record loaded with loadErr belongs to the user and is still at the base version
+// of the change, a conflict reports the current record. Deleting a record that is already gone is a no-op
+// and does not proceed, updating it is a conflict
+func checkSyncBase(tx *gorm.DB, userID uint, change models.SyncChange, loadErr error, ownerID uint, version uint64,
+	current interface{}, result *models.SyncResult) (bool, error) {
+	if loadErr == nil && ownerID != userID {
+		loadErr = gorm.ErrRecordNotFound
+	}
+	if errors.Is(loadErr, gorm.ErrRecordNotFound) {
+		var buried int64
+		err := tx.Model(&models.Tombstone{}).
+			Where("user_id = ? AND resource_type = ? AND resource_id = ?", userID, change.Type, change.ID).
+			Count(&buried).Error
+		switch {
+		case err != nil:
+			return false, err
+		case buried == 0:
+			return false, loadErr
+		case change.Op == models.SyncDelete:
+			return false, nil
+		default:
+			return false, errSyncDeleted
+		}
+	}
+	if loadErr != nil {
+		return false, loadErr
+	}
+
+	if version != change.BaseVersion {
+		result.Version, result.Record = version, current
+		return false, errSyncConflict
+	}
+	result.Before = current
+	return true, nil
+}
@@ -1,8 +1,13 @@
 package pg
 
 import (
+	"context"
+	"errors"
+
 	"github.com/danikg/go-todo-rest-api/models"
+	"github.com/danikg/go-todo-rest-api/repositories"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // TagRepository ...
@@ -16,49 +21,252 @@ func NewTagRepository(conn *gorm.DB) *TagRepository {
 }
 
 // GetAll returns all tags by todo item id
-func (t *TagRepository) GetAll(todoItem *models.TodoItem) ([]models.Tag, error) {
+func (t *TagRepository) GetAll(ctx context.Context, todoItem *models.TodoItem) ([]models.Tag, error) {
 	tags := []models.Tag{}
-	err := t.Conn.Model(&todoItem).Association("Tags").Find(&tags)
+	err := t.Conn.WithContext(ctx).Model(todoItem).Association("Tags").Find(&tags)
 	return tags, err
 }
 
+// GetAllByUser returns the tags of the user with the number of todo items using them
+func (t *TagRepository) GetAllByUser(ctx context.Context, userID uint) ([]models.TagUsage, error) {
+	usages := []models.TagUsage{}
+	err := t.Conn.WithContext(ctx).Model(&models.Tag{}).
+		Select("tags.*, COUNT(todo_item_tags.todo_item_id) AS usage_count").
+		Joins("LEFT JOIN todo_item_tags ON todo_item_tags.tag_id = tags.id").
+		Where("tags.user_id = ?", userID).
+		Group("tags.id").
+		Order("tags.normalized").
+		Find(&usages).Error
+	return usages, err
+}
+
 // GetSingle returns a tag by id
-func (t *TagRepository) GetSingle(id uint) (models.Tag, error) {
+func (t *TagRepository) GetSingle(ctx context.Context, id uint) (models.Tag, error) {
 	tag := models.Tag{}
-	err := t.Conn.First(&tag, id).Error
+	err := t.Conn.WithContext(ctx).First(&tag, id).Error
 	return tag, err
 }
 
-// Create creates a new tag
-func (t *TagRepository) Create(todoItem *models.TodoItem, tag *models.Tag) error {
-	return t.Conn.Model(todoItem).Association("Tags").Append(tag)
+// GetTodoItems returns the todo items the tag or one of its descendants is attached to
+func (t *TagRepository) GetTodoItems(ctx context.Context, tagID uint, page models.Page) ([]models.TodoItem, error) {
+	todoItems := []models.TodoItem{}
+	query := t.Conn.WithContext(ctx).Joins("TodoList").Preload("Tags")
+	err := whereTagged(query, 1, "id = ?", tagID).
+		Order("todo_items.id").
+		Limit(page.Limit).
+		Offset(page.Offset).
+		Find(&todoItems).Error
+	return todoItems, err
+}
+
+// FindOrCreate loads the user's tag with the same normalized text into tag, creating it if missing
+func (t *TagRepository) FindOrCreate(ctx context.Context, userID uint, tag *models.Tag) error {
+	tag.ID = 0
+	tag.UserID = userID
+	tag.Normalized = models.NormalizeTagText(tag.Text)
+	undelete(&tag.Model)
+
+	return t.Conn.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := checkParent(tx, tag, tag.ParentID); err != nil {
+			return err
+		}
+
+		version, err := nextVersion(tx)
+		if err != nil {
+			return err
+		}
+		tag.Version = version
+
+		err = tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "normalized"}},
+			DoNothing: true,
+		}).Create(tag).Error
+		if err != nil || tag.ID != 0 {
+			return err
+		}
+		return tx.First(tag, "user_id = ? AND normalized = ?", userID, tag.Normalized).Error
+	})
 }
 
-// Update updates the tag
-func (t *TagRepository) Update(id uint, tagData *models.Tag) (models.Tag, error) {
-	tag, err := t.GetSingle(id)
+// Create attaches the tag to the todo item, reusing the tag of the list owner with the same text
+func (t *TagRepository) Create(ctx context.Context, todoItem *models.TodoItem, tag *models.Tag) error {
+	return t.Conn.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := NewTagRepository(tx).FindOrCreate(ctx, todoItem.TodoList.UserID, tag); err != nil {
+			return err
+		}
+		if err := tx.Model(todoItem).Association("Tags").Append(tag); err != nil {
+			return err
+		}
+		version, err := bumpVersion(tx, &models.TodoItem{}, "id = ?", todoItem.ID)
+		todoItem.Version = version
+		return err
+	})
+}
+
+// Update replaces the text, color and parent of the tag for every todo item using it
+func (t *TagRepository) Update(ctx context.Context, id uint, tagData *models.Tag) (models.Tag, error) {
+	tag, err := t.GetSingle(ctx, id)
 	if err != nil {
 		return tag, err
 	}
 
-	err = t.Conn.Model(&tag).Update("text", tagData.Text).Error
+	var duplicates int64
+	normalized := models.NormalizeTagText(tagData.Text)
+	err = t.Conn.WithContext(ctx).Model(&models.Tag{}).
+		Where("user_id = ? AND normalized = ? AND id <> ?", tag.UserID, normalized, tag.ID).
+		Count(&duplicates).Error
+	if err != nil {
+		return tag, err
+	}
+	if duplicates != 0 {
+		return tag, repositories.ErrDuplicateTag
+	}
+	if err = checkParent(t.Conn.WithContext(ctx), &tag, tagData.ParentID); err != nil {
+		return tag, err
+	}
+
+	err = t.Conn.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		version, err := nextVersion(tx)
+		if err != nil {
+			return err
+		}
+		return tx.Model(&tag).Updates(map[string]interface{}{
+			"text":       tagData.Text,
+			"normalized": normalized,
+			"color":      tagData.Color,
+			"parent_id":  tagData.ParentID,
+			"version":    version,
+		}).Error
+	})
 	return tag, err
 }
 
+// Merge moves every todo item and child tag of the tag to the target tag and deletes the tag
+func (t *TagRepository) Merge(ctx context.Context, id uint, targetID uint) (models.Tag, error) {
+	target := models.Tag{}
+	err := t.Conn.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		source := models.Tag{}
+		if err := tx.First(&source, id).Error; err != nil {
+			return err
+		}
+		if err := tx.First(&target, targetID).Error; err != nil {
+			return err
+		}
+		if source.UserID != target.UserID {
+			return repositories.ErrInvalidTagMerge
+		}
+
+		subtree, err := subtreeIDs(tx, source.ID)
+		if err != nil {
+			return err
+		}
+		for _, tagID := range subtree {
+			if tagID == target.ID {
+				return repositories.ErrInvalidTagMerge
+			}
+		}
+
+		tagged := tx.Table("todo_item_tags").Select("todo_item_id").Where("tag_id = ?", source.ID)
+		if _, err = bumpVersion(tx, &models.TodoItem{}, "id IN (?)", tagged); err != nil {
+			return err
+		}
+
+		err = tx.Exec(`INSERT INTO todo_item_tags (todo_item_id, tag_id)
+			SELECT todo_item_id, ? FROM todo_item_tags
+			WHERE tag_id = ? AND todo_item_id NOT IN (SELECT todo_item_id FROM todo_item_tags WHERE tag_id = ?)`,
+			target.ID, source.ID, target.ID).Error
+		if err != nil {
+			return err
+		}
+		if err = tx.Exec("DELETE FROM todo_item_tags WHERE tag_id = ?", source.ID).Error; err != nil {
+			return err
+		}
+		version, err := nextVersion(tx)
+		if err != nil {
+			return err
+		}
+		err = tx.Model(&models.Tag{}).Where("parent_id = ?", source.ID).
+			Updates(map[string]interface{}{"parent_id": target.ID, "version": version}).Error
+		if err != nil {
+			return err
+		}
+		if err = tx.Unscoped().Delete(&source).Error; err != nil {
+			return err
+		}
+		return bury(tx, source.UserID, models.AuditTag, source.ID)
+	})
+	return target, err
+}
+
 // Remove removes the tag from the todo item
-func (t *TagRepository) Remove(todoItem *models.TodoItem, tagID uint) error {
-	tag, err := t.GetSingle(tagID)
+func (t *TagRepository) Remove(ctx context.Context, todoItem *models.TodoItem, tagID uint) error {
+	tag, err := t.GetSingle(ctx, tagID)
+	if err != nil {
+		return err
+	}
+	return t.Conn.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(todoItem).Association("Tags").Delete(&tag); err != nil {
+			return err
+		}
+		version, err := bumpVersion(tx, &models.TodoItem{}, "id = ?", todoItem.ID)
+		todoItem.Version = version
+		return err
+	})
+}
+
+// Delete removes the tag from the db, the todo items it was attached to and its child tags get a new version
+func (t *TagRepository) Delete(ctx context.Context, id uint) error {
+	tag, err := t.GetSingle(ctx, id)
 	if err != nil {
 		return err
 	}
-	return t.Conn.Model(&todoItem).Association("Tags").Delete(&tag)
+	return t.Conn.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		tagged := tx.Table("todo_item_tags").Select("todo_item_id").Where("tag_id = ?", tag.ID)
+		if _, err := bumpVersion(tx, &models.TodoItem{}, "id IN (?)", tagged); err != nil {
+			return err
+		}
+		// the child tags move up to the parent of the tag
+		if _, err := bumpVersion(tx, &models.Tag{}, "parent_id = ?", tag.ID); err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Tag{}).Where("parent_id = ?", tag.ID).Update("parent_id", tag.ParentID).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Delete(&tag).Error; err != nil {
+			return err
+		}
+		return bury(tx, tag.UserID, models.AuditTag, tag.ID)
+	})
 }
 
-// Delete removes the tag from the db
-func (t *TagRepository) Delete(id uint) error {
-	tag, err := t.GetSingle(id)
+// checkParent verifies that parentID may become the parent of the tag
+func checkParent(conn *gorm.DB, tag *models.Tag, parentID *uint) error {
+	if parentID == nil {
+		return nil
+	}
+
+	parent := models.Tag{}
+	if err := conn.First(&parent, *parentID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return repositories.ErrInvalidTagParent
+		}
+		return err
+	}
+	if parent.UserID != tag.UserID {
+		return repositories.ErrInvalidTagParent
+	}
+	if tag.ID == 0 {
+		return nil
+	}
+
+	subtree, err := subtreeIDs(conn, tag.ID)
 	if err != nil {
 		return err
 	}
-	return t.Conn.Unscoped().Delete(&tag).Error
+	for _, id := range subtree {
+		if id == parent.ID {
+			return repositories.ErrInvalidTagParent
+		}
+	}
+	return nil
 }
@@ -0,0 +1,32 @@
+package pg
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// tagTreeCTE lists the tags picked by the root condition together with all their descendants,
+// every row keeps the id of the root it was reached from
+const tagTreeCTE = `WITH RECURSIVE tag_tree (root_id, id) AS (
+	SELECT id, id FROM tags WHERE (%s) AND deleted_at IS NULL
+	UNION
+	SELECT tag_tree.root_id, tags.id FROM tags JOIN tag_tree ON tags.parent_id = tag_tree.id WHERE tags.deleted_at IS NULL
+) `
+
+// subtreeIDs returns the id of the tag and the ids of all tags nested below it
+func subtreeIDs(conn *gorm.DB, tagID uint) ([]uint, error) {
+	ids := []uint{}
+	err := conn.Raw(fmt.Sprintf(tagTreeCTE, "id = ?")+"SELECT id FROM tag_tree", tagID).Scan(&ids).Error
+	return ids, err
+}
+
+// whereTagged keeps the todo items carrying at least required of the root tags,
+// a root counts as carried when the item has the root itself or one of its descendants
+func whereTagged(query *gorm.DB, required int, roots string, args ...interface{}) *gorm.DB {
+	items := fmt.Sprintf(tagTreeCTE, roots) + `SELECT todo_item_tags.todo_item_id FROM todo_item_tags
+	JOIN tag_tree ON tag_tree.id = todo_item_tags.tag_id
+	GROUP BY todo_item_tags.todo_item_id
+	HAVING COUNT(DISTINCT tag_tree.root_id) >= ?`
+	return query.Where("todo_items.id IN ("+items+")", append(args, required)...)
+}
@@ -0,0 +1,149 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/danikg/go-todo-rest-api/models"
+	"github.com/danikg/go-todo-rest-api/repositories"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// errBulkFailed rolls back the transaction of an all-or-nothing bulk request
+var errBulkFailed = errors.New("bulk operation failed")
+
+// Bulk applies the operations in one transaction, in all-or-nothing mode the first failure
+// rolls everything back, otherwise a failed operation is only rolled back to its savepoint
+func (t *TodoItemRepository) Bulk(ctx context.Context, operations []models.BulkOperation, allOrNothing bool) (models.BulkReport, error) {
+	report := models.BulkReport{Results: make([]models.BulkResult, len(operations))}
+	for i, operation := range operations {
+		report.Results[i] = models.BulkResult{Index: i, ID: operation.ID}
+	}
+
+	err := t.Conn.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i, operation := range operations {
+			result := &report.Results[i]
+
+			var err error
+			if allOrNothing {
+				result.Completed, err = applyBulkOperation(ctx, tx, operation)
+			} else {
+				err = tx.Transaction(func(savepoint *gorm.DB) error {
+					var err error
+					result.Completed, err = applyBulkOperation(ctx, savepoint, operation)
+					return err
+				})
+			}
+
+			if err != nil {
+				result.Completed = false
+				result.Error = err.Error()
+				if allOrNothing {
+					for j := i + 1; j < len(report.Results); j++ {
+						report.Results[j].Error = "skipped after a failed operation"
+					}
+					return errBulkFailed
+				}
+				continue
+			}
+			result.OK = true
+		}
+		return nil
+	})
+
+	if errors.Is(err, errBulkFailed) {
+		for i := range report.Results {
+			if report.Results[i].OK {
+				report.Results[i] = models.BulkResult{Index: i, ID: report.Results[i].ID, Error: "rolled back"}
+			}
+		}
+		return report, nil
+	}
+	if err != nil {
+		return report, err
+	}
+
+	report.Committed = true
+	return report, nil
+}
+
+// applyBulkOperation applies a single bulk operation, reporting whether it completed a pending todo item
+func applyBulkOperation(ctx context.Context, tx *gorm.DB, operation models.BulkOperation) (bool, error) {
+	todoItem := models.TodoItem{}
+	if err := tx.Joins("TodoList").First(&todoItem, operation.ID).Error; err != nil {
+		return false, err
+	}
+	version, err := nextVersion(tx)
+	if err != nil {
+		return false, err
+	}
+	// the loaded list must not be saved back, it would reset todo_list_id
+	update := tx.Model(&todoItem).Omit(clause.Associations)
+	pending := !todoItem.Completed
+
+	switch operation.Op {
+	case models.BulkUpdate:
+		fields := map[string]interface{}{}
+		if operation.Title != "" {
+			fields["title"] = operation.Title
+		}
+		if operation.Description != "" {
+			fields["description"] = operation.Description
+		}
+		if operation.Completed != nil {
+			fields["completed"] = *operation.Completed
+		}
+		fields["version"] = version
+		if err := update.Updates(fields).Error; err != nil {
+			return false, err
+		}
+		return pending && todoItem.Completed, enqueueTodoItemUpdate(tx, &todoItem, pending)
+
+	case models.BulkComplete:
+		if err := update.Updates(map[string]interface{}{"completed": true, "version": version}).Error; err != nil {
+			return false, err
+		}
+		return pending, enqueueTodoItemUpdate(tx, &todoItem, pending)
+
+	case models.BulkMove:
+		todoList := models.TodoList{}
+		if err := tx.First(&todoList, operation.ListID).Error; err != nil {
+			return false, err
+		}
+		if todoList.UserID != todoItem.TodoList.UserID {
+			return false, repositories.ErrForeignList
+		}
+		if err := update.Updates(map[string]interface{}{"todo_list_id": todoList.ID, "version": version}).Error; err != nil {
+			return false, err
+		}
+		todoItem.TodoList = todoList
+		return false, enqueueTodoItemUpdate(tx, &todoItem, false)
+
+	case models.BulkAddTag:
+		return false, NewTagRepository(tx).Create(ctx, &todoItem, &models.Tag{Text: operation.Tag})
+
+	case models.BulkRemoveTag:
+		result := tx.Exec("DELETE FROM todo_item_tags WHERE todo_item_id = ? AND tag_id = ?", todoItem.ID, operation.TagID)
+		if result.Error == nil && result.RowsAffected == 0 {
+			return false, gorm.ErrRecordNotFound
+		}
+		if result.Error != nil {
+			return false, result.Error
+		}
+		return false, update.UpdateColumn("version", version).Error
+
+	case models.BulkDelete:
+		if err := tx.Unscoped().Delete(&todoItem).Error; err != nil {
+			return false, err
+		}
+		if err := bury(tx, todoItem.TodoList.UserID, models.AuditTodoItem, todoItem.ID); err != nil {
+			return false, err
+		}
+		return false, enqueueWebhookEvent(tx, todoItem.TodoList.UserID, models.WebhookTodoItemDeleted, todoItem)
+
+	default:
+		return false, fmt.Errorf("unknown operation %q", operation.Op)
+	}
+}
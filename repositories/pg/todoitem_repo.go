@@ -1,8 +1,11 @@
 package pg
 
 import (
+	"context"
+
 	"github.com/danikg/go-todo-rest-api/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // TodoItemRepository ...
@@ -16,43 +19,140 @@ func NewTodoItemRepository(conn *gorm.DB) *TodoItemRepository {
 }
 
 // GetAll returns all todo items by todo list id
-func (t *TodoItemRepository) GetAll(listID uint) ([]models.TodoItem, error) {
+func (t *TodoItemRepository) GetAll(ctx context.Context, listID uint) ([]models.TodoItem, error) {
+	todoItems := []models.TodoItem{}
+	err := t.Conn.WithContext(ctx).Joins("TodoList").Preload("Tags").Find(&todoItems, "todo_list_id = ?", listID).Error
+	return todoItems, err
+}
+
+// GetAllByLists returns the todo items of all the todo lists in one query, ordered by id
+func (t *TodoItemRepository) GetAllByLists(ctx context.Context, listIDs []uint) ([]models.TodoItem, error) {
+	todoItems := []models.TodoItem{}
+	if len(listIDs) == 0 {
+		return todoItems, nil
+	}
+	err := t.Conn.WithContext(ctx).Joins("TodoList").Preload("Tags").
+		Order("todo_items.id").Find(&todoItems, "todo_items.todo_list_id IN (?)", listIDs).Error
+	return todoItems, err
+}
+
+// GetAllByTags returns the todo items of all lists of the user carrying the filter's tags or their descendants
+func (t *TodoItemRepository) GetAllByTags(ctx context.Context, userID uint, filter models.TagFilter, page models.Page) ([]models.TodoItem, error) {
 	todoItems := []models.TodoItem{}
-	err := t.Conn.Joins("TodoList").Preload("Tags").Find(&todoItems, "todo_list_id = ?", listID).Error
+	conn := t.Conn.WithContext(ctx)
+
+	lists := conn.Model(&models.TodoList{}).Select("id").Where("user_id = ?", userID)
+	query := conn.Joins("TodoList").Preload("Tags").Where("todo_items.todo_list_id IN (?)", lists)
+
+	if tags := normalizeTags(filter.Tags); len(tags) != 0 {
+		required := 1
+		if filter.MatchAll {
+			required = len(tags)
+		}
+		query = whereTagged(query, required, "user_id = ? AND normalized IN (?)", userID, tags)
+	}
+
+	err := query.Order("todo_items.id").Limit(page.Limit).Offset(page.Offset).Find(&todoItems).Error
 	return todoItems, err
 }
 
 // GetSingle returns a todo item by id
-func (t *TodoItemRepository) GetSingle(id uint) (models.TodoItem, error) {
+func (t *TodoItemRepository) GetSingle(ctx context.Context, id uint) (models.TodoItem, error) {
 	todoItem := models.TodoItem{}
-	err := t.Conn.Joins("TodoList").Preload("Tags").First(&todoItem, id).Error
+	err := t.Conn.WithContext(ctx).Joins("TodoList").Preload("Tags").First(&todoItem, id).Error
 	return todoItem, err
 }
 
 // Create creates a new todo item
-func (t *TodoItemRepository) Create(listID uint, todoItem *models.TodoItem) error {
+func (t *TodoItemRepository) Create(ctx context.Context, listID uint, todoItem *models.TodoItem) error {
 	todoItem.TodoListID = listID
-	return t.Conn.Create(todoItem).Error
+	undelete(&todoItem.Model)
+	return t.Conn.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		todoList := models.TodoList{}
+		if err := tx.First(&todoList, listID).Error; err != nil {
+			return err
+		}
+		version, err := nextVersion(tx)
+		if err != nil {
+			return err
+		}
+		todoItem.Version = version
+		// tags are attached by the tag repository, which keeps them in the vocabulary of the user
+		if err := tx.Omit(clause.Associations).Create(todoItem).Error; err != nil {
+			return err
+		}
+		todoItem.TodoList = todoList
+		return enqueueWebhookEvent(tx, todoList.UserID, models.WebhookTodoItemCreated, todoItem)
+	})
 }
 
 // Update updates the todo item
-func (t *TodoItemRepository) Update(id uint, todoItemData *models.TodoItem) (models.TodoItem, error) {
-	todoItem, err := t.GetSingle(id)
+func (t *TodoItemRepository) Update(ctx context.Context, id uint, todoItemData *models.TodoItemUpdate) (models.TodoItem, error) {
+	todoItem, err := t.GetSingle(ctx, id)
 	if err != nil {
 		return todoItem, err
 	}
-	err = t.Conn.Model(&todoItem).
-		Updates(models.TodoItem{
-			Title:       todoItemData.Title,
-			Description: todoItemData.Description}).Error
+
+	// empty strings and nil values keep the current values
+	fields := map[string]interface{}{}
+	if todoItemData.Title != "" {
+		fields["title"] = todoItemData.Title
+	}
+	if todoItemData.Description != "" {
+		fields["description"] = todoItemData.Description
+	}
+	if todoItemData.Completed != nil {
+		fields["completed"] = *todoItemData.Completed
+	}
+	if todoItemData.Due != nil {
+		fields["due"] = todoItemData.Due
+	}
+	if todoItemData.Recurrence != "" {
+		fields["recurrence"] = todoItemData.Recurrence
+	}
+
+	pending := !todoItem.Completed
+	err = t.Conn.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		version, err := nextVersion(tx)
+		if err != nil {
+			return err
+		}
+		fields["version"] = version
+		if err := tx.Model(&todoItem).Updates(fields).Error; err != nil {
+			return err
+		}
+		return enqueueTodoItemUpdate(tx, &todoItem, pending)
+	})
 	return todoItem, err
 }
 
 // Delete removes the todo item
-func (t *TodoItemRepository) Delete(id uint) error {
-	todoItem, err := t.GetSingle(id)
+func (t *TodoItemRepository) Delete(ctx context.Context, id uint) error {
+	todoItem, err := t.GetSingle(ctx, id)
 	if err != nil {
 		return err
 	}
-	return t.Conn.Unscoped().Delete(&todoItem).Error
+	return t.Conn.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Delete(&todoItem).Error; err != nil {
+			return err
+		}
+		if err := bury(tx, todoItem.TodoList.UserID, models.AuditTodoItem, todoItem.ID); err != nil {
+			return err
+		}
+		return enqueueWebhookEvent(tx, todoItem.TodoList.UserID, models.WebhookTodoItemDeleted, todoItem)
+	})
+}
+
+// normalizeTags returns the distinct normalized forms of the tag texts, skipping empty ones
+func normalizeTags(texts []string) []string {
+	seen := map[string]bool{}
+	tags := []string{}
+	for _, text := range texts {
+		tag := models.NormalizeTagText(text)
+		if tag != "" && !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	return tags
 }
@@ -1,6 +1,8 @@
 package pg
 
 import (
+	"context"
+
 	"github.com/danikg/go-todo-rest-api/models"
 	"gorm.io/gorm"
 )
@@ -16,41 +18,146 @@ func NewTodoListRepository(conn *gorm.DB) *TodoListRepository {
 }
 
 // GetAll returns all todo lists by user id
-func (t *TodoListRepository) GetAll(userID uint) ([]models.TodoList, error) {
+func (t *TodoListRepository) GetAll(ctx context.Context, userID uint) ([]models.TodoList, error) {
+	todoLists := []models.TodoList{}
+	err := t.Conn.WithContext(ctx).Find(&todoLists, "user_id = ?", userID).Error
+	return todoLists, err
+}
+
+// GetAllByUsers returns the todo lists of all the users in one query, ordered by id
+func (t *TodoListRepository) GetAllByUsers(ctx context.Context, userIDs []uint) ([]models.TodoList, error) {
 	todoLists := []models.TodoList{}
-	err := t.Conn.Find(&todoLists, "user_id = ?", userID).Error
+	if len(userIDs) == 0 {
+		return todoLists, nil
+	}
+	err := t.Conn.WithContext(ctx).Order("id").Find(&todoLists, "user_id IN (?)", userIDs).Error
 	return todoLists, err
 }
 
 // GetSingle returns a todo list by id
-func (t *TodoListRepository) GetSingle(id uint) (models.TodoList, error) {
+func (t *TodoListRepository) GetSingle(ctx context.Context, id uint) (models.TodoList, error) {
 	todoList := models.TodoList{}
-	err := t.Conn.First(&todoList, id).Error
+	err := t.Conn.WithContext(ctx).First(&todoList, id).Error
 	return todoList, err
 }
 
 // Create creates a new todo list
-func (t *TodoListRepository) Create(userID uint, todoList *models.TodoList) error {
+func (t *TodoListRepository) Create(ctx context.Context, userID uint, todoList *models.TodoList) error {
 	todoList.UserID = userID
-	return t.Conn.Create(todoList).Error
+	undelete(&todoList.Model)
+	return t.Conn.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		version, err := nextVersion(tx)
+		if err != nil {
+			return err
+		}
+		todoList.Version = version
+		return tx.Create(todoList).Error
+	})
 }
 
 // Update updates the todo list
-func (t *TodoListRepository) Update(id uint, todoListData *models.TodoList) (models.TodoList, error) {
-	todoList, err := t.GetSingle(id)
+func (t *TodoListRepository) Update(ctx context.Context, id uint, todoListData *models.TodoList) (models.TodoList, error) {
+	todoList, err := t.GetSingle(ctx, id)
 	if err != nil {
 		return todoList, err
 	}
 
-	err = t.Conn.Model(&todoList).Update("Name", todoListData.Name).Error
+	err = t.Conn.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		version, err := nextVersion(tx)
+		if err != nil {
+			return err
+		}
+		return tx.Model(&todoList).Updates(map[string]interface{}{"name": todoListData.Name, "version": version}).Error
+	})
+	return todoList, err
+}
+
+// Transfer gives the todo list with its todo items to another user. The tags of the items are replaced
+// by the tags of the new owner with the same text, created without parent when missing,
+// and the previous owner gets tombstones of the list and its items
+func (t *TodoListRepository) Transfer(ctx context.Context, id uint, userID uint) (models.TodoList, error) {
+	todoList, err := t.GetSingle(ctx, id)
+	if err != nil || todoList.UserID == userID {
+		return todoList, err
+	}
+
+	previousOwner := todoList.UserID
+	err = t.Conn.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		itemIDs := []uint{}
+		if err := tx.Model(&models.TodoItem{}).Where("todo_list_id = ?", todoList.ID).Pluck("id", &itemIDs).Error; err != nil {
+			return err
+		}
+		if err := transferTags(ctx, tx, itemIDs, userID); err != nil {
+			return err
+		}
+
+		version, err := nextVersion(tx)
+		if err != nil {
+			return err
+		}
+		if len(itemIDs) != 0 {
+			if err = tx.Model(&models.TodoItem{}).Where("id IN (?)", itemIDs).UpdateColumn("version", version).Error; err != nil {
+				return err
+			}
+		}
+		if err = tx.Model(&todoList).Updates(map[string]interface{}{"user_id": userID, "version": version}).Error; err != nil {
+			return err
+		}
+
+		if err = bury(tx, previousOwner, models.AuditTodoItem, itemIDs...); err != nil {
+			return err
+		}
+		return bury(tx, previousOwner, models.AuditTodoList, todoList.ID)
+	})
 	return todoList, err
 }
 
-// Delete removes the todo list
-func (t *TodoListRepository) Delete(id uint) error {
-	todoList, err := t.GetSingle(id)
+// transferTags moves the tag links of the todo items to the tags of the user with the same text
+func transferTags(ctx context.Context, tx *gorm.DB, itemIDs []uint, userID uint) error {
+	if len(itemIDs) == 0 {
+		return nil
+	}
+
+	tagIDs := []uint{}
+	if err := tx.Table("todo_item_tags").Where("todo_item_id IN (?)", itemIDs).Pluck("tag_id", &tagIDs).Error; err != nil || len(tagIDs) == 0 {
+		return err
+	}
+	tags := []models.Tag{}
+	if err := tx.Find(&tags, "user_id <> ? AND id IN (?)", userID, tagIDs).Error; err != nil {
+		return err
+	}
+
+	tagRepo := NewTagRepository(tx)
+	for _, tag := range tags {
+		target := models.Tag{Text: tag.Text, Color: tag.Color}
+		if err := tagRepo.FindOrCreate(ctx, userID, &target); err != nil {
+			return err
+		}
+		err := tx.Exec("UPDATE todo_item_tags SET tag_id = ? WHERE tag_id = ? AND todo_item_id IN (?)", target.ID, tag.ID, itemIDs).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Delete removes the todo list with its todo items, leaving tombstones of all of them
+func (t *TodoListRepository) Delete(ctx context.Context, id uint) error {
+	todoList, err := t.GetSingle(ctx, id)
 	if err != nil {
 		return err
 	}
-	return t.Conn.Unscoped().Delete(&todoList).Error
+	return t.Conn.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		itemIDs := []uint{}
+		if err := tx.Model(&models.TodoItem{}).Where("todo_list_id = ?", todoList.ID).Pluck("id", &itemIDs).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Delete(&todoList).Error; err != nil {
+			return err
+		}
+		if err := bury(tx, todoList.UserID, models.AuditTodoItem, itemIDs...); err != nil {
+			return err
+		}
+		return bury(tx, todoList.UserID, models.AuditTodoList, todoList.ID)
+	})
 }
@@ -0,0 +1,31 @@
+package pg
+
+import (
+	"context"
+
+	"github.com/danikg/go-todo-rest-api/repositories"
+	"gorm.io/gorm"
+)
+
+// Transactor ...
+type Transactor struct {
+	Conn *gorm.DB
+}
+
+// NewTransactor ...
+func NewTransactor(conn *gorm.DB) *Transactor {
+	return &Transactor{Conn: conn}
+}
+
+// Transaction hands fn the repositories bound to a new transaction
+func (t *Transactor) Transaction(ctx context.Context, fn func(repos repositories.Repositories) error) error {
+	return t.Conn.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(repositories.Repositories{
+			User:     NewUserRepository(tx),
+			TodoList: NewTodoListRepository(tx),
+			TodoItem: NewTodoItemRepository(tx),
+			Tag:      NewTagRepository(tx),
+			Audit:    NewAuditRepository(tx),
+		})
+	})
+}
@@ -1,6 +1,8 @@
 package pg
 
 import (
+	"context"
+
 	"github.com/danikg/go-todo-rest-api/models"
 	"gorm.io/gorm"
 )
@@ -16,40 +18,89 @@ func NewUserRepository(conn *gorm.DB) *UserRepository {
 }
 
 // GetAll returns all users from the db
-func (u *UserRepository) GetAll() ([]models.User, error) {
+func (u *UserRepository) GetAll(ctx context.Context) ([]models.User, error) {
 	users := []models.User{}
-	err := u.Conn.Preload("TodoLists").Find(&users).Error
+	err := u.Conn.WithContext(ctx).Preload("TodoLists").Find(&users).Error
 	return users, err
 }
 
 // GetSingle returns a user by id
-func (u *UserRepository) GetSingle(id uint) (models.User, error) {
+func (u *UserRepository) GetSingle(ctx context.Context, id uint) (models.User, error) {
+	user := models.User{}
+	err := u.Conn.WithContext(ctx).Preload("TodoLists").First(&user, id).Error
+	return user, err
+}
+
+// GetByUsername returns a user by username
+func (u *UserRepository) GetByUsername(ctx context.Context, username string) (models.User, error) {
 	user := models.User{}
-	err := u.Conn.Preload("TodoLists").First(&user, id).Error
+	err := u.Conn.WithContext(ctx).First(&user, "username = ?", username).Error
 	return user, err
 }
 
 // Create creates a new user
-func (u *UserRepository) Create(user *models.User) error {
-	return u.Conn.Create(user).Error
+func (u *UserRepository) Create(ctx context.Context, user *models.User) error {
+	undelete(&user.Model)
+	return u.Conn.WithContext(ctx).Create(user).Error
 }
 
 // Update updates the user
-func (u *UserRepository) Update(id uint, userData *models.User) (models.User, error) {
-	user, err := u.GetSingle(id)
+func (u *UserRepository) Update(ctx context.Context, id uint, userData *models.User) (models.User, error) {
+	user, err := u.GetSingle(ctx, id)
 	if err != nil {
 		return user, err
 	}
 
-	err = u.Conn.Model(&user).Update("Username", userData.Username).Error
+	fields := map[string]interface{}{"username": userData.Username}
+	if userData.PasswordHash != "" {
+		fields["password_hash"] = userData.PasswordHash
+	}
+
+	err = u.Conn.WithContext(ctx).Model(&user).Updates(fields).Error
 	return user, err
 }
 
-// Delete removes the user
-func (u *UserRepository) Delete(id uint) error {
-	user, err := u.GetSingle(id)
+// SetDisabled disables or enables the user
+func (u *UserRepository) SetDisabled(ctx context.Context, id uint, disabled bool) (models.User, error) {
+	user, err := u.GetSingle(ctx, id)
+	if err != nil {
+		return user, err
+	}
+
+	err = u.Conn.WithContext(ctx).Model(&user).Update("disabled", disabled).Error
+	return user, err
+}
+
+// GetCredentials returns a user by id without its todo lists, it is read on every authenticated request
+func (u *UserRepository) GetCredentials(ctx context.Context, id uint) (models.User, error) {
+	user := models.User{}
+	err := u.Conn.WithContext(ctx).Select("id", "disabled", "token_generation").First(&user, id).Error
+	return user, err
+}
+
+// RevokeTokens bumps the token generation of the user, the tokens issued before are rejected
+func (u *UserRepository) RevokeTokens(ctx context.Context, id uint) error {
+	result := u.Conn.WithContext(ctx).Model(&models.User{}).Where("id = ?", id).
+		UpdateColumn("token_generation", gorm.Expr("token_generation + 1"))
+	if result.Error == nil && result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return result.Error
+}
+
+// Delete removes the user, the db cascades to its todo lists and todo items
+// while the records only holding a user id are removed here
+func (u *UserRepository) Delete(ctx context.Context, id uint) error {
+	user, err := u.GetSingle(ctx, id)
 	if err != nil {
 		return err
 	}
-	return u.Conn.Unscoped().Delete(&user).Error
+	return u.Conn.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, model := range []interface{}{&models.Tag{}, &models.Webhook{}, &models.FeedToken{}} {
+			if err := tx.Unscoped().Where("user_id = ?", user.ID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Unscoped().Delete(&user).Error
+	})
 }
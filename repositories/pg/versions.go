@@ -0,0 +1,77 @@
+package pg
+
+import (
+	"time"
+
+	"github.com/danikg/go-todo-rest-api/models"
+	"gorm.io/gorm"
+)
+
+// nextVersion increments the change sequence in the transaction of a mutation and returns the new value,
+// concurrent writers wait on the counter row until commit, so versions become visible in increasing order
+func nextVersion(tx *gorm.DB) (uint64, error) {
+	result := tx.Exec("UPDATE change_counters SET value = value + 1 WHERE id = 1")
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	if result.RowsAffected == 0 {
+		counter := models.ChangeCounter{ID: 1, Value: 1}
+		return counter.Value, tx.Create(&counter).Error
+	}
+
+	counter := models.ChangeCounter{}
+	err := tx.First(&counter, 1).Error
+	return counter.Value, err
+}
+
+// currentVersion returns the last value of the change sequence
+func currentVersion(conn *gorm.DB) (uint64, error) {
+	counters := []models.ChangeCounter{}
+	if err := conn.Limit(1).Find(&counters, 1).Error; err != nil || len(counters) == 0 {
+		return 0, err
+	}
+	return counters[0].Value, nil
+}
+
+// bumpVersion stores a new version on the rows of model matching the query
+func bumpVersion(tx *gorm.DB, model interface{}, query string, args ...interface{}) (uint64, error) {
+	version, err := nextVersion(tx)
+	if err != nil {
+		return 0, err
+	}
+	err = tx.Model(model).Where(query, args...).UpdateColumn("version", version).Error
+	return version, err
+}
+
+// bury writes the tombstones of the deleted records of the user
+func bury(tx *gorm.DB, userID uint, resourceType string, ids ...uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	version, err := nextVersion(tx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	tombstones := make([]models.Tombstone, len(ids))
+	for i, id := range ids {
+		tombstones[i] = models.Tombstone{
+			Version:      version,
+			UserID:       userID,
+			ResourceType: resourceType,
+			ResourceID:   id,
+			DeletedAt:    now,
+		}
+	}
+	return tx.Create(&tombstones).Error
+}
+
+// changedSince selects the rows with a version after since, since 0 selects all rows
+func changedSince(conn *gorm.DB, since uint64) *gorm.DB {
+	if since == 0 {
+		return conn
+	}
+	return conn.Where("version > ?", since)
+}
@@ -0,0 +1,39 @@
+package pg
+
+import (
+	"encoding/json"
+
+	"github.com/danikg/go-todo-rest-api/models"
+	"gorm.io/gorm"
+)
+
+// enqueueWebhookEvent writes a todo event to the outbox in the transaction of the mutation,
+// nothing is written when none of the user's webhooks is subscribed to its type
+func enqueueWebhookEvent(tx *gorm.DB, userID uint, eventType string, data interface{}) error {
+	var subscribed int64
+	err := tx.Model(&models.Webhook{}).
+		Where("user_id = ? AND ',' || events || ',' LIKE ?", userID, "%,"+eventType+",%").
+		Count(&subscribed).Error
+	if err != nil || subscribed == 0 {
+		return err
+	}
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return tx.Create(&models.WebhookOutboxEvent{UserID: userID, Type: eventType, Payload: payload}).Error
+}
+
+// enqueueTodoItemUpdate writes the update of a todo item with its list loaded to the outbox,
+// followed by a completion when the item was pending before
+func enqueueTodoItemUpdate(tx *gorm.DB, todoItem *models.TodoItem, pending bool) error {
+	userID := todoItem.TodoList.UserID
+	if err := enqueueWebhookEvent(tx, userID, models.WebhookTodoItemUpdated, todoItem); err != nil {
+		return err
+	}
+	if pending && todoItem.Completed {
+		return enqueueWebhookEvent(tx, userID, models.WebhookTodoItemCompleted, todoItem)
+	}
+	return nil
+}
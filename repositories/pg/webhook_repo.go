@@ -0,0 +1,190 @@
+package pg
+
+import (
+	"context"
+	"time"
+
+	"github.com/danikg/go-todo-rest-api/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WebhookRepository ...
+type WebhookRepository struct {
+	Conn *gorm.DB
+}
+
+// NewWebhookRepository ...
+func NewWebhookRepository(conn *gorm.DB) *WebhookRepository {
+	return &WebhookRepository{Conn: conn}
+}
+
+// GetAll returns all webhooks of the user
+func (t *WebhookRepository) GetAll(ctx context.Context, userID uint) ([]models.Webhook, error) {
+	webhooks := []models.Webhook{}
+	err := t.Conn.WithContext(ctx).Order("id").Find(&webhooks, "user_id = ?", userID).Error
+	return webhooks, err
+}
+
+// GetSingle returns a webhook by id
+func (t *WebhookRepository) GetSingle(ctx context.Context, id uint) (models.Webhook, error) {
+	webhook := models.Webhook{}
+	err := t.Conn.WithContext(ctx).First(&webhook, id).Error
+	return webhook, err
+}
+
+// Create creates a new webhook
+func (t *WebhookRepository) Create(ctx context.Context, userID uint, webhook *models.Webhook) error {
+	webhook.UserID = userID
+	undelete(&webhook.Model)
+	return t.Conn.WithContext(ctx).Create(webhook).Error
+}
+
+// Update updates the webhook, empty values keep the current ones
+func (t *WebhookRepository) Update(ctx context.Context, id uint, webhookData *models.Webhook) (models.Webhook, error) {
+	webhook, err := t.GetSingle(ctx, id)
+	if err != nil {
+		return webhook, err
+	}
+
+	fields := map[string]interface{}{}
+	if webhookData.URL != "" {
+		fields["url"] = webhookData.URL
+	}
+	if webhookData.Secret != "" {
+		fields["secret"] = webhookData.Secret
+	}
+	if len(webhookData.Events) != 0 {
+		fields["events"] = webhookData.Events
+	}
+	if len(fields) == 0 {
+		return webhook, nil
+	}
+
+	err = t.Conn.WithContext(ctx).Model(&webhook).Updates(fields).Error
+	return webhook, err
+}
+
+// Delete removes the webhook with its delivery log
+func (t *WebhookRepository) Delete(ctx context.Context, id uint) error {
+	webhook, err := t.GetSingle(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return t.Conn.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("webhook_id = ?", webhook.ID).Delete(&models.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&webhook).Error
+	})
+}
+
+// GetDeliveries returns the delivery log of the webhook, newest first
+func (t *WebhookRepository) GetDeliveries(ctx context.Context, webhookID uint, page models.Page) ([]models.WebhookDelivery, error) {
+	deliveries := []models.WebhookDelivery{}
+	err := t.Conn.WithContext(ctx).
+		Where("webhook_id = ?", webhookID).
+		Order("id DESC").
+		Limit(page.Limit).
+		Offset(page.Offset).
+		Find(&deliveries).Error
+	return deliveries, err
+}
+
+// FanOut creates the deliveries of up to limit outbox events for the webhooks subscribed to them,
+// each event is marked dispatched in the transaction creating its deliveries so that it is fanned out once
+func (t *WebhookRepository) FanOut(ctx context.Context, now time.Time, limit int) (int, error) {
+	conn := t.Conn.WithContext(ctx)
+	events := []models.WebhookOutboxEvent{}
+	if err := conn.Where("dispatched_at IS NULL").Order("id").Limit(limit).Find(&events).Error; err != nil {
+		return 0, err
+	}
+
+	created := 0
+	for _, event := range events {
+		count := 0
+		err := conn.Transaction(func(tx *gorm.DB) error {
+			// another dispatcher may have taken the event in the meantime
+			result := tx.Model(&models.WebhookOutboxEvent{}).
+				Where("id = ? AND dispatched_at IS NULL", event.ID).
+				Update("dispatched_at", now)
+			if result.Error != nil || result.RowsAffected == 0 {
+				return result.Error
+			}
+
+			webhooks := []models.Webhook{}
+			if err := tx.Order("id").Find(&webhooks, "user_id = ?", event.UserID).Error; err != nil {
+				return err
+			}
+			for _, webhook := range webhooks {
+				if !webhook.Events.Has(event.Type) {
+					continue
+				}
+				delivery := models.WebhookDelivery{
+					WebhookID:     webhook.ID,
+					EventID:       event.ID,
+					EventType:     event.Type,
+					Status:        models.WebhookDeliveryPending,
+					NextAttemptAt: now,
+				}
+				if err := tx.Omit(clause.Associations).Create(&delivery).Error; err != nil {
+					return err
+				}
+				count++
+			}
+			return nil
+		})
+		if err != nil {
+			return created, err
+		}
+		created += count
+	}
+	return created, nil
+}
+
+// ClaimDueDeliveries returns up to limit pending deliveries due at now with their webhook and event,
+// their next attempt is pushed back by lease so that no other dispatcher attempts them meanwhile
+func (t *WebhookRepository) ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.WebhookDelivery, error) {
+	conn := t.Conn.WithContext(ctx)
+	due := []models.WebhookDelivery{}
+	err := conn.Where("status = ? AND next_attempt_at <= ?", models.WebhookDeliveryPending, now).
+		Order("next_attempt_at, id").
+		Limit(limit).
+		Find(&due).Error
+	if err != nil {
+		return due, err
+	}
+
+	ids := []uint{}
+	for _, delivery := range due {
+		result := conn.Model(&models.WebhookDelivery{}).
+			Where("id = ? AND status = ? AND next_attempt_at <= ?", delivery.ID, models.WebhookDeliveryPending, now).
+			Update("next_attempt_at", now.Add(lease))
+		if result.Error != nil {
+			return []models.WebhookDelivery{}, result.Error
+		}
+		if result.RowsAffected == 1 {
+			ids = append(ids, delivery.ID)
+		}
+	}
+
+	claimed := []models.WebhookDelivery{}
+	if len(ids) == 0 {
+		return claimed, nil
+	}
+	err = conn.Preload("Webhook").Preload("Event").Order("id").Find(&claimed, ids).Error
+	return claimed, err
+}
+
+// UpdateDelivery stores the outcome of a delivery attempt
+func (t *WebhookRepository) UpdateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	return t.Conn.WithContext(ctx).Model(delivery).Omit(clause.Associations).Updates(map[string]interface{}{
+		"status":          delivery.Status,
+		"next_attempt_at": delivery.NextAttemptAt,
+		"attempts":        delivery.Attempts,
+		"response_status": delivery.ResponseStatus,
+		"error":           delivery.Error,
+		"delivered_at":    delivery.DeliveredAt,
+	}).Error
+}
@@ -0,0 +1,16 @@
+package repositories
+
+import "context"
+
+type replicaKey struct{}
+
+// WithReadReplica marks ctx so that its reads may be served by a read replica
+func WithReadReplica(ctx context.Context) context.Context {
+	return context.WithValue(ctx, replicaKey{}, true)
+}
+
+// UseReadReplica reports whether the reads of ctx may be served by a read replica
+func UseReadReplica(ctx context.Context) bool {
+	use, _ := ctx.Value(replicaKey{}).(bool)
+	return use
+}
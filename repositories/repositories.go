@@ -1,40 +1,140 @@
 package repositories
 
-import "github.com/danikg/go-todo-rest-api/models"
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/danikg/go-todo-rest-api/models"
+	"github.com/danikg/go-todo-rest-api/utils/export"
+)
+
+// ErrDuplicateTag is returned when a tag would get the text of another tag of the same user
+var ErrDuplicateTag = errors.New("the user already has a tag with this text")
+
+// ErrInvalidTagParent is returned when the parent tag is missing, belongs to another user or is nested below the tag
+var ErrInvalidTagParent = errors.New("the parent must be a tag of the same user that is not nested below this tag")
+
+// ErrInvalidTagMerge is returned when a tag would be merged into itself, a tag of another user or one of its descendants
+var ErrInvalidTagMerge = errors.New("a tag can only be merged into another tag of the same user that is not nested below it")
+
+// ErrForeignList is returned when a todo item would be moved to a list of another user
+var ErrForeignList = errors.New("todo items can only be moved between lists of the same user")
 
 // IUserRepository ...
 type IUserRepository interface {
-	GetAll() ([]models.User, error)
-	GetSingle(id uint) (models.User, error)
-	Create(user *models.User) error
-	Update(id uint, userData *models.User) (models.User, error)
-	Delete(id uint) error
+	GetAll(ctx context.Context) ([]models.User, error)
+	GetSingle(ctx context.Context, id uint) (models.User, error)
+	GetByUsername(ctx context.Context, username string) (models.User, error)
+	Create(ctx context.Context, user *models.User) error
+	Update(ctx context.Context, id uint, userData *models.User) (models.User, error)
+	SetDisabled(ctx context.Context, id uint, disabled bool) (models.User, error)
+	GetCredentials(ctx context.Context, id uint) (models.User, error)
+	RevokeTokens(ctx context.Context, id uint) error
+	Delete(ctx context.Context, id uint) error
 }
 
 // ITodoListRepository ...
 type ITodoListRepository interface {
-	GetAll(userID uint) ([]models.TodoList, error)
-	GetSingle(id uint) (models.TodoList, error)
-	Create(userID uint, todoList *models.TodoList) error
-	Update(id uint, todoListData *models.TodoList) (models.TodoList, error)
-	Delete(id uint) error
+	GetAll(ctx context.Context, userID uint) ([]models.TodoList, error)
+	GetAllByUsers(ctx context.Context, userIDs []uint) ([]models.TodoList, error)
+	GetSingle(ctx context.Context, id uint) (models.TodoList, error)
+	Create(ctx context.Context, userID uint, todoList *models.TodoList) error
+	Update(ctx context.Context, id uint, todoListData *models.TodoList) (models.TodoList, error)
+	Transfer(ctx context.Context, id uint, userID uint) (models.TodoList, error)
+	Delete(ctx context.Context, id uint) error
 }
 
 // ITodoItemRepository ...
 type ITodoItemRepository interface {
-	GetAll(listID uint) ([]models.TodoItem, error)
-	GetSingle(id uint) (models.TodoItem, error)
-	Create(listID uint, todoItem *models.TodoItem) error
-	Update(id uint, todoItemData *models.TodoItem) (models.TodoItem, error)
-	Delete(id uint) error
+	GetAll(ctx context.Context, listID uint) ([]models.TodoItem, error)
+	GetAllByLists(ctx context.Context, listIDs []uint) ([]models.TodoItem, error)
+	GetAllByTags(ctx context.Context, userID uint, filter models.TagFilter, page models.Page) ([]models.TodoItem, error)
+	GetSingle(ctx context.Context, id uint) (models.TodoItem, error)
+	Create(ctx context.Context, listID uint, todoItem *models.TodoItem) error
+	Update(ctx context.Context, id uint, todoItemData *models.TodoItemUpdate) (models.TodoItem, error)
+	Delete(ctx context.Context, id uint) error
+	Bulk(ctx context.Context, operations []models.BulkOperation, allOrNothing bool) (models.BulkReport, error)
 }
 
 // ITagRepository ...
 type ITagRepository interface {
-	GetAll(todoItem *models.TodoItem) ([]models.Tag, error)
-	GetSingle(id uint) (models.Tag, error)
-	Create(todoItem *models.TodoItem, tag *models.Tag) error
-	Update(id uint, tagData *models.Tag) (models.Tag, error)
-	Remove(todoItem *models.TodoItem, tagID uint) error
-	Delete(id uint) error
+	GetAll(ctx context.Context, todoItem *models.TodoItem) ([]models.Tag, error)
+	GetAllByUser(ctx context.Context, userID uint) ([]models.TagUsage, error)
+	GetSingle(ctx context.Context, id uint) (models.Tag, error)
+	GetTodoItems(ctx context.Context, tagID uint, page models.Page) ([]models.TodoItem, error)
+	FindOrCreate(ctx context.Context, userID uint, tag *models.Tag) error
+	Create(ctx context.Context, todoItem *models.TodoItem, tag *models.Tag) error
+	Update(ctx context.Context, id uint, tagData *models.Tag) (models.Tag, error)
+	Merge(ctx context.Context, id uint, targetID uint) (models.Tag, error)
+	Remove(ctx context.Context, todoItem *models.TodoItem, tagID uint) error
+	Delete(ctx context.Context, id uint) error
+}
+
+// IIdempotencyRepository ...
+type IIdempotencyRepository interface {
+	Claim(ctx context.Context, key *models.IdempotencyKey) (bool, error)
+	Get(ctx context.Context, userID uint, key string) (models.IdempotencyKey, error)
+	Complete(ctx context.Context, key *models.IdempotencyKey) error
+	Release(ctx context.Context, key *models.IdempotencyKey) error
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+// IAuditRepository is append-only, events are never updated or deleted
+type IAuditRepository interface {
+	Create(ctx context.Context, event *models.AuditEvent) error
+	GetAllByTodoList(ctx context.Context, listID uint, page models.Page) ([]models.AuditEvent, error)
+	GetAllByUser(ctx context.Context, userID uint, page models.Page) ([]models.AuditEvent, error)
+}
+
+// IWebhookRepository ...
+type IWebhookRepository interface {
+	GetAll(ctx context.Context, userID uint) ([]models.Webhook, error)
+	GetSingle(ctx context.Context, id uint) (models.Webhook, error)
+	Create(ctx context.Context, userID uint, webhook *models.Webhook) error
+	Update(ctx context.Context, id uint, webhookData *models.Webhook) (models.Webhook, error)
+	Delete(ctx context.Context, id uint) error
+	GetDeliveries(ctx context.Context, webhookID uint, page models.Page) ([]models.WebhookDelivery, error)
+	FanOut(ctx context.Context, now time.Time, limit int) (int, error)
+	ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.WebhookDelivery, error)
+	UpdateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error
+}
+
+// ISyncRepository ...
+type ISyncRepository interface {
+	Changes(ctx context.Context, userID uint, since uint64) (models.SyncChanges, error)
+	Push(ctx context.Context, userID uint, changes []models.SyncChange) (models.SyncReport, error)
+}
+
+// IExportRepository ...
+type IExportRepository interface {
+	Export(ctx context.Context, userID uint, w export.Writer) error
+}
+
+// ICalendarRepository ...
+type ICalendarRepository interface {
+	SaveFeedToken(ctx context.Context, userID uint, hash string) error
+	DeleteFeedToken(ctx context.Context, userID uint) error
+	GetFeedTokenUser(ctx context.Context, hash string) (uint, error)
+	GetTodoItems(ctx context.Context, userID uint, listID uint) ([]models.TodoItem, error)
+}
+
+// IMaintenanceRepository ...
+type IMaintenanceRepository interface {
+	Purge(ctx context.Context, before time.Time) (models.PurgeReport, error)
+	Stats(ctx context.Context) (models.Stats, error)
+}
+
+// Repositories bundles the repositories bound to one transaction
+type Repositories struct {
+	User     IUserRepository
+	TodoList ITodoListRepository
+	TodoItem ITodoItemRepository
+	Tag      ITagRepository
+	Audit    IAuditRepository
+}
+
+// ITransactor runs fn in a transaction, which is rolled back when fn fails
+type ITransactor interface {
+	Transaction(ctx context.Context, fn func(repos Repositories) error) error
 }
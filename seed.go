@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/danikg/go-todo-rest-api/app/pg"
+	"github.com/danikg/go-todo-rest-api/config"
+	"github.com/danikg/go-todo-rest-api/fixtures"
+	"gorm.io/gorm"
+
+	repos "github.com/danikg/go-todo-rest-api/repositories/pg"
+)
+
+// parseSeedArgs reads the optional scale and seed of the seed command
+func parseSeedArgs(args []string) (fixtures.Options, error) {
+	options := fixtures.Options{Seed: fixtures.DefaultSeed, Scale: fixtures.DefaultScale, Now: fixtures.DefaultNow}
+	if len(args) > 2 {
+		return options, errors.New("expected at most a scale and a seed")
+	}
+
+	if len(args) > 0 {
+		scale, err := strconv.Atoi(args[0])
+		if err != nil || scale <= 0 {
+			return options, fmt.Errorf("invalid scale %q, expected a positive number", args[0])
+		}
+		options.Scale = scale
+	}
+	if len(args) > 1 {
+		seed, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return options, fmt.Errorf("invalid seed %q", args[1])
+		}
+		options.Seed = seed
+	}
+	return options, nil
+}
+
+// runSeed loads the fixtures of the options into the db of the configuration,
+// a db already holding the first user of the fixtures is left as it is
+func runSeed(ctx context.Context, cfg *config.Config, options fixtures.Options, stdout io.Writer) error {
+	db := pg.GetDB(cfg)
+	data := fixtures.Generate(options)
+
+	_, err := repos.NewUserRepository(db).GetByUsername(ctx, data.Users[0].Username)
+	if err == nil {
+		fmt.Fprintf(stdout, "user %s exists, the db is already seeded\n", data.Users[0].Username)
+		return nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	if err = fixtures.Load(ctx, repos.NewTransactor(db), &data); err != nil {
+		return err
+	}
+
+	lists, items := 0, 0
+	for _, user := range data.Users {
+		lists += len(user.TodoLists)
+		for _, todoList := range user.TodoLists {
+			items += len(todoList.TodoItems)
+		}
+	}
+	fmt.Fprintf(stdout, "seeded %d users with %d todo lists and %d todo items, their password is %q\n",
+		len(data.Users), lists, items, data.Users[0].Password)
+	return nil
+}
@@ -0,0 +1,37 @@
+package mocks
+
+import (
+	"context"
+	"errors"
+
+	"github.com/danikg/go-todo-rest-api/models"
+)
+
+// ActivityServiceMock ...
+type ActivityServiceMock struct{}
+
+// GetByTodoList ...
+func (s *ActivityServiceMock) GetByTodoList(ctx context.Context, listID uint, page models.Page) ([]models.AuditEvent, error) {
+	if listID != 1 {
+		return []models.AuditEvent{}, errors.New("err")
+	}
+
+	listID = 1
+	events := []models.AuditEvent{
+		{ID: 2, Action: models.AuditDelete, ResourceType: models.AuditTodoItem, ResourceID: 1, UserID: 1, TodoListID: &listID},
+		{ID: 1, Action: models.AuditCreate, ResourceType: models.AuditTodoList, ResourceID: 1, UserID: 1, TodoListID: &listID},
+	}
+	return events, nil
+}
+
+// GetByUser ...
+func (s *ActivityServiceMock) GetByUser(ctx context.Context, userID uint, page models.Page) ([]models.AuditEvent, error) {
+	if userID != 1 {
+		return []models.AuditEvent{}, errors.New("err")
+	}
+
+	events := []models.AuditEvent{
+		{ID: 1, Action: models.AuditCreate, ResourceType: models.AuditUser, ResourceID: 1, UserID: 1},
+	}
+	return events, nil
+}
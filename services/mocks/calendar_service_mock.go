@@ -0,0 +1,39 @@
+package mocks
+
+import (
+	"context"
+	"errors"
+
+	"github.com/danikg/go-todo-rest-api/models"
+	"github.com/danikg/go-todo-rest-api/services"
+)
+
+// CalendarServiceMock serves the feed of the token "token1" for user 1
+type CalendarServiceMock struct{}
+
+// CreateFeed ...
+func (s *CalendarServiceMock) CreateFeed(ctx context.Context, userID uint) (models.Feed, error) {
+	if userID != 1 {
+		return models.Feed{}, errors.New("record not found")
+	}
+	return models.Feed{Token: "token1", Path: "/feeds/token1.ics", ListPath: "/feeds/token1/todo_lists/{list_id}.ics"}, nil
+}
+
+// DeleteFeed ...
+func (s *CalendarServiceMock) DeleteFeed(ctx context.Context, userID uint) error {
+	if userID != 1 {
+		return errors.New("record not found")
+	}
+	return nil
+}
+
+// Feed ...
+func (s *CalendarServiceMock) Feed(ctx context.Context, token string, listID uint) ([]byte, error) {
+	if token != "token1" {
+		return nil, services.ErrInvalidFeedToken
+	}
+	if listID > 1 {
+		return nil, errors.New("todo list not found")
+	}
+	return []byte("BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n"), nil
+}
@@ -0,0 +1,30 @@
+package mocks
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/danikg/go-todo-rest-api/services"
+	"github.com/danikg/go-todo-rest-api/utils/export"
+)
+
+// ExportServiceMock ...
+type ExportServiceMock struct{}
+
+// Export ...
+func (s *ExportServiceMock) Export(ctx context.Context, userID uint, format string, w io.Writer) error {
+	if format != export.JSON && format != export.CSV && format != export.Markdown {
+		return services.ErrInvalidExportFormat
+	}
+	switch userID {
+	case 1:
+		_, err := io.WriteString(w, "export of user1")
+		return err
+	case 3:
+		io.WriteString(w, "partial")
+		return errors.New("connection lost")
+	default:
+		return errors.New("record not found")
+	}
+}
@@ -0,0 +1,34 @@
+package mocks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/danikg/go-todo-rest-api/models"
+	"github.com/danikg/go-todo-rest-api/services"
+	"github.com/danikg/go-todo-rest-api/utils/importer"
+)
+
+// ImportServiceMock parses the file for user1, nothing is stored
+type ImportServiceMock struct{}
+
+// Import ...
+func (s *ImportServiceMock) Import(ctx context.Context, userID uint, format string, r io.Reader, options importer.Options, dryRun bool) (models.ImportReport, error) {
+	if userID != 1 {
+		return models.ImportReport{}, errors.New("record not found")
+	}
+
+	items, errs, err := importer.Parse(format, r, options)
+	if err != nil {
+		return models.ImportReport{}, fmt.Errorf("%w: %w", services.ErrInvalidImport, err)
+	}
+
+	report := models.ImportReport{DryRun: dryRun, Items: len(items), Errors: errs}
+	if !dryRun && len(errs) != 0 {
+		return report, services.ErrInvalidImport
+	}
+	report.Committed = !dryRun
+	return report, nil
+}
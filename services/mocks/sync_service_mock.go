@@ -0,0 +1,46 @@
+package mocks
+
+import (
+	"context"
+	"errors"
+
+	"github.com/danikg/go-todo-rest-api/models"
+	"github.com/danikg/go-todo-rest-api/services"
+)
+
+// SyncServiceMock ...
+type SyncServiceMock struct{}
+
+// Changes ...
+func (s *SyncServiceMock) Changes(ctx context.Context, userID uint, since uint64) (models.SyncChanges, error) {
+	if userID != 1 {
+		return models.SyncChanges{}, errors.New("err")
+	}
+
+	changes := models.SyncChanges{Cursor: 2, TodoLists: []models.TodoList{}, TodoItems: []models.TodoItem{}, Tags: []models.Tag{}}
+	if since == 0 {
+		todoList := models.TodoList{Name: "list", UserID: 1, Version: 1}
+		todoList.ID = 1
+		changes.TodoLists = append(changes.TodoLists, todoList)
+	}
+	return changes, nil
+}
+
+// Push ...
+func (s *SyncServiceMock) Push(ctx context.Context, userID uint, push *models.SyncPush) (models.SyncReport, error) {
+	if len(push.Changes) == 0 {
+		return models.SyncReport{}, services.ErrInvalidSyncChange
+	}
+	if userID != 1 {
+		return models.SyncReport{}, errors.New("err")
+	}
+
+	report := models.SyncReport{Cursor: 2, Results: make([]models.SyncResult, len(push.Changes))}
+	for i, change := range push.Changes {
+		report.Results[i] = models.SyncResult{Index: i, ClientID: change.ClientID, ID: change.ID, Status: models.SyncApplied}
+		if change.Op != models.SyncCreate && change.BaseVersion != 1 {
+			report.Results[i].Status = models.SyncConflict
+		}
+	}
+	return report, nil
+}
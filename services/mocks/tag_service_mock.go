@@ -1,16 +1,19 @@
 package mocks
 
 import (
+	"context"
 	"errors"
 
 	"github.com/danikg/go-todo-rest-api/models"
+	"github.com/danikg/go-todo-rest-api/repositories"
+	"github.com/danikg/go-todo-rest-api/services"
 )
 
 // TagServiceMock ...
 type TagServiceMock struct{}
 
 // GetAll ...
-func (s *TagServiceMock) GetAll(itemID uint) ([]models.Tag, error) {
+func (s *TagServiceMock) GetAll(ctx context.Context, itemID uint) ([]models.Tag, error) {
 	if itemID != 1 {
 		return []models.Tag{}, errors.New("err")
 	}
@@ -21,8 +24,19 @@ func (s *TagServiceMock) GetAll(itemID uint) ([]models.Tag, error) {
 	return tags, nil
 }
 
+// GetAllByUser ...
+func (s *TagServiceMock) GetAllByUser(ctx context.Context, userID uint) ([]models.TagUsage, error) {
+	if userID != 1 {
+		return []models.TagUsage{}, errors.New("err")
+	}
+
+	usages := []models.TagUsage{{Tag: models.Tag{Text: "tag1", UserID: 1}, UsageCount: 2}}
+	usages[0].ID = 1
+	return usages, nil
+}
+
 // GetSingle ...
-func (s *TagServiceMock) GetSingle(id uint) (models.Tag, error) {
+func (s *TagServiceMock) GetSingle(ctx context.Context, id uint) (models.Tag, error) {
 	if id != 1 {
 		return models.Tag{}, errors.New("not found")
 	}
@@ -32,27 +46,93 @@ func (s *TagServiceMock) GetSingle(id uint) (models.Tag, error) {
 	return tag, nil
 }
 
+// GetTodoItems ...
+func (s *TagServiceMock) GetTodoItems(ctx context.Context, tagID uint, page models.Page) ([]models.TodoItem, error) {
+	if tagID != 1 {
+		return []models.TodoItem{}, errors.New("err")
+	}
+
+	todoItems := []models.TodoItem{
+		{Title: "item1", Description: ""},
+		{Title: "item2", Description: "desc2"},
+	}
+
+	todoItems[0].ID = 1
+	todoItems[1].ID = 2
+	return todoItems, nil
+}
+
+// GetTodoItemsByUser ...
+func (s *TagServiceMock) GetTodoItemsByUser(ctx context.Context, userID uint, filter models.TagFilter, page models.Page) ([]models.TodoItem, error) {
+	if userID != 1 {
+		return []models.TodoItem{}, errors.New("err")
+	}
+
+	todoItems := []models.TodoItem{
+		{Title: "item1", Description: ""},
+		{Title: "item2", Description: "desc2"},
+	}
+
+	todoItems[0].ID = 1
+	todoItems[1].ID = 2
+	return todoItems, nil
+}
+
 // Create ...
-func (s *TagServiceMock) Create(itemID uint, tag *models.Tag) error {
+func (s *TagServiceMock) Create(ctx context.Context, itemID uint, tag *models.Tag) error {
 	if itemID != 1 {
 		return errors.New("err")
 	}
 	return nil
 }
 
+// CreateForUser ...
+func (s *TagServiceMock) CreateForUser(ctx context.Context, userID uint, tag *models.Tag) error {
+	if models.NormalizeTagText(tag.Text) == "" {
+		return services.ErrEmptyTag
+	}
+	if userID != 1 {
+		return errors.New("err")
+	}
+
+	tag.ID = 1
+	tag.UserID = userID
+	return nil
+}
+
 // Update ...
-func (s *TagServiceMock) Update(id uint, tagData *models.Tag) (models.Tag, error) {
+func (s *TagServiceMock) Update(ctx context.Context, id uint, tagData *models.Tag) (models.Tag, error) {
 	if id != 1 {
 		return models.Tag{}, errors.New("err")
 	}
+	if tagData.Text == "tag2" {
+		return models.Tag{}, repositories.ErrDuplicateTag
+	}
+	if tagData.Color == "red" {
+		return models.Tag{}, services.ErrInvalidTagColor
+	}
 
 	tag := models.Tag{Text: "tag1"}
 	tag.ID = 1
 	return tag, nil
 }
 
+// Merge ...
+func (s *TagServiceMock) Merge(ctx context.Context, id uint, targetID uint) (models.Tag, error) {
+	if id != 1 {
+		return models.Tag{}, errors.New("not found")
+	}
+	if targetID == id {
+		return models.Tag{}, repositories.ErrInvalidTagMerge
+	}
+
+	tag := models.Tag{Text: "tag2"}
+	tag.ID = targetID
+	return tag, nil
+}
+
 // Remove ...
-func (s *TagServiceMock) Remove(itemID uint, tagID uint) error {
+func (s *TagServiceMock) Remove(ctx context.Context, itemID uint, tagID uint) error {
 	if itemID != 1 {
 		return errors.New("err")
 	}
@@ -60,7 +140,7 @@ func (s *TagServiceMock) Remove(itemID uint, tagID uint) error {
 }
 
 // Delete ...
-func (s *TagServiceMock) Delete(id uint) error {
+func (s *TagServiceMock) Delete(ctx context.Context, id uint) error {
 	if id != 1 {
 		return errors.New("err")
 	}
@@ -1,16 +1,21 @@
 package mocks
 
 import (
+	"context"
 	"errors"
 
 	"github.com/danikg/go-todo-rest-api/models"
+	"github.com/danikg/go-todo-rest-api/services"
 )
 
 // TodoItemServiceMock ...
-type TodoItemServiceMock struct{}
+type TodoItemServiceMock struct {
+	// BatchCalls counts the calls of GetAllByLists
+	BatchCalls int
+}
 
 // GetAll ...
-func (s *TodoItemServiceMock) GetAll(listID uint) ([]models.TodoItem, error) {
+func (s *TodoItemServiceMock) GetAll(ctx context.Context, listID uint) ([]models.TodoItem, error) {
 	if listID != 1 {
 		return []models.TodoItem{}, errors.New("err")
 	}
@@ -25,8 +30,25 @@ func (s *TodoItemServiceMock) GetAll(listID uint) ([]models.TodoItem, error) {
 	return todoItems, nil
 }
 
+// GetAllByLists ...
+func (s *TodoItemServiceMock) GetAllByLists(ctx context.Context, listIDs []uint) ([]models.TodoItem, error) {
+	s.BatchCalls++
+
+	todoItems := []models.TodoItem{}
+	for _, listID := range listIDs {
+		if listID == 1 {
+			listItems, _ := s.GetAll(ctx, listID)
+			for _, todoItem := range listItems {
+				todoItem.TodoListID = listID
+				todoItems = append(todoItems, todoItem)
+			}
+		}
+	}
+	return todoItems, nil
+}
+
 // GetSingle ...
-func (s *TodoItemServiceMock) GetSingle(id uint) (models.TodoItem, error) {
+func (s *TodoItemServiceMock) GetSingle(ctx context.Context, id uint) (models.TodoItem, error) {
 	if id != 1 {
 		return models.TodoItem{}, errors.New("not found")
 	}
@@ -37,18 +59,24 @@ func (s *TodoItemServiceMock) GetSingle(id uint) (models.TodoItem, error) {
 }
 
 // Create ...
-func (s *TodoItemServiceMock) Create(listID uint, todoItem *models.TodoItem) error {
+func (s *TodoItemServiceMock) Create(ctx context.Context, listID uint, todoItem *models.TodoItem) error {
 	if listID != 1 {
 		return errors.New("err")
 	}
+	if todoItem.Recurrence != "" && todoItem.Due == nil {
+		return services.ErrInvalidRecurrence
+	}
 	return nil
 }
 
 // Update ...
-func (s *TodoItemServiceMock) Update(id uint, todoItemData *models.TodoItem) (models.TodoItem, error) {
+func (s *TodoItemServiceMock) Update(ctx context.Context, id uint, todoItemData *models.TodoItemUpdate) (models.TodoItem, error) {
 	if id != 1 {
 		return models.TodoItem{}, errors.New("err")
 	}
+	if todoItemData.Recurrence == "FREQ=SOMETIMES" {
+		return models.TodoItem{}, services.ErrInvalidRecurrence
+	}
 
 	todoItem := models.TodoItem{Title: "item1", Description: ""}
 	todoItem.ID = 1
@@ -56,9 +84,27 @@ func (s *TodoItemServiceMock) Update(id uint, todoItemData *models.TodoItem) (mo
 }
 
 // Delete ...
-func (s *TodoItemServiceMock) Delete(id uint) error {
+func (s *TodoItemServiceMock) Delete(ctx context.Context, id uint) error {
 	if id != 1 {
 		return errors.New("err")
 	}
 	return nil
 }
+
+// Bulk ...
+func (s *TodoItemServiceMock) Bulk(ctx context.Context, request *models.BulkRequest) (models.BulkReport, error) {
+	if len(request.Operations) == 0 {
+		return models.BulkReport{}, services.ErrInvalidBulkOperation
+	}
+
+	report := models.BulkReport{Committed: true}
+	for i, operation := range request.Operations {
+		result := models.BulkResult{Index: i, ID: operation.ID, OK: operation.ID == 1}
+		if !result.OK {
+			result.Error = "not found"
+			report.Committed = report.Committed && !request.AllOrNothing
+		}
+		report.Results = append(report.Results, result)
+	}
+	return report, nil
+}
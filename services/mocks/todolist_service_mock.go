@@ -1,16 +1,20 @@
 package mocks
 
 import (
+	"context"
 	"errors"
 
 	"github.com/danikg/go-todo-rest-api/models"
 )
 
 // TodoListServiceMock ...
-type TodoListServiceMock struct{}
+type TodoListServiceMock struct {
+	// BatchCalls counts the calls of GetAllByUsers
+	BatchCalls int
+}
 
 // GetAll ...
-func (s *TodoListServiceMock) GetAll(userID uint) ([]models.TodoList, error) {
+func (s *TodoListServiceMock) GetAll(ctx context.Context, userID uint) ([]models.TodoList, error) {
 	if userID != 1 {
 		return []models.TodoList{}, errors.New("err")
 	}
@@ -25,8 +29,22 @@ func (s *TodoListServiceMock) GetAll(userID uint) ([]models.TodoList, error) {
 	return todoLists, nil
 }
 
+// GetAllByUsers ...
+func (s *TodoListServiceMock) GetAllByUsers(ctx context.Context, userIDs []uint) ([]models.TodoList, error) {
+	s.BatchCalls++
+
+	todoLists := []models.TodoList{}
+	for _, userID := range userIDs {
+		if userID == 1 {
+			userLists, _ := s.GetAll(ctx, userID)
+			todoLists = append(todoLists, userLists...)
+		}
+	}
+	return todoLists, nil
+}
+
 // GetSingle ...
-func (s *TodoListServiceMock) GetSingle(id uint) (models.TodoList, error) {
+func (s *TodoListServiceMock) GetSingle(ctx context.Context, id uint) (models.TodoList, error) {
 	if id != 1 {
 		return models.TodoList{}, errors.New("not found")
 	}
@@ -37,7 +55,7 @@ func (s *TodoListServiceMock) GetSingle(id uint) (models.TodoList, error) {
 }
 
 // Create ...
-func (s *TodoListServiceMock) Create(userID uint, todoList *models.TodoList) error {
+func (s *TodoListServiceMock) Create(ctx context.Context, userID uint, todoList *models.TodoList) error {
 	if userID != 1 {
 		return errors.New("err")
 	}
@@ -45,7 +63,7 @@ func (s *TodoListServiceMock) Create(userID uint, todoList *models.TodoList) err
 }
 
 // Update ...
-func (s *TodoListServiceMock) Update(id uint, todoListData *models.TodoList) (models.TodoList, error) {
+func (s *TodoListServiceMock) Update(ctx context.Context, id uint, todoListData *models.TodoList) (models.TodoList, error) {
 	if id != 1 {
 		return models.TodoList{}, errors.New("err")
 	}
@@ -56,7 +74,7 @@ func (s *TodoListServiceMock) Update(id uint, todoListData *models.TodoList) (mo
 }
 
 // Delete ...
-func (s *TodoListServiceMock) Delete(id uint) error {
+func (s *TodoListServiceMock) Delete(ctx context.Context, id uint) error {
 	if id != 1 {
 		return errors.New("err")
 	}
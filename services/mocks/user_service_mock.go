@@ -1,9 +1,11 @@
 package mocks
 
 import (
+	"context"
 	"errors"
 
 	"github.com/danikg/go-todo-rest-api/models"
+	"github.com/danikg/go-todo-rest-api/services"
 )
 
 // UserServiceMock ...
@@ -12,7 +14,7 @@ type UserServiceMock struct {
 }
 
 // GetAll ...
-func (s *UserServiceMock) GetAll() ([]models.User, error) {
+func (s *UserServiceMock) GetAll(ctx context.Context) ([]models.User, error) {
 	if s.GenerateErr {
 		return []models.User{}, errors.New("err")
 	}
@@ -28,7 +30,7 @@ func (s *UserServiceMock) GetAll() ([]models.User, error) {
 }
 
 // GetSingle ...
-func (s *UserServiceMock) GetSingle(id uint) (models.User, error) {
+func (s *UserServiceMock) GetSingle(ctx context.Context, id uint) (models.User, error) {
 	if id != 1 {
 		return models.User{}, errors.New("err")
 	}
@@ -39,7 +41,7 @@ func (s *UserServiceMock) GetSingle(id uint) (models.User, error) {
 }
 
 // Create ...
-func (s *UserServiceMock) Create(user *models.User) error {
+func (s *UserServiceMock) Create(ctx context.Context, user *models.User) error {
 	if s.GenerateErr {
 		return errors.New("err")
 	}
@@ -47,7 +49,7 @@ func (s *UserServiceMock) Create(user *models.User) error {
 }
 
 // Update ...
-func (s *UserServiceMock) Update(id uint, userData *models.User) (models.User, error) {
+func (s *UserServiceMock) Update(ctx context.Context, id uint, userData *models.User) (models.User, error) {
 	if id != 1 {
 		return models.User{}, errors.New("err")
 	}
@@ -58,9 +60,28 @@ func (s *UserServiceMock) Update(id uint, userData *models.User) (models.User, e
 }
 
 // Delete ...
-func (s *UserServiceMock) Delete(id uint) error {
+func (s *UserServiceMock) Delete(ctx context.Context, id uint) error {
 	if id != 1 {
 		return errors.New("err")
 	}
 	return nil
 }
+
+// Authenticate ...
+func (s *UserServiceMock) Authenticate(ctx context.Context, username, password string) (models.User, error) {
+	if username != "user1" || password != "password" {
+		return models.User{}, errors.New("invalid credentials")
+	}
+
+	user := models.User{Username: "user1"}
+	user.ID = 1
+	return user, nil
+}
+
+// CheckToken ...
+func (s *UserServiceMock) CheckToken(ctx context.Context, userID, generation uint) error {
+	if generation != 0 {
+		return services.ErrTokenRevoked
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package mocks
+
+import (
+	"context"
+	"errors"
+
+	"github.com/danikg/go-todo-rest-api/models"
+	"github.com/danikg/go-todo-rest-api/services"
+)
+
+// WebhookServiceMock ...
+type WebhookServiceMock struct{}
+
+func webhook1() models.Webhook {
+	webhook := models.Webhook{
+		UserID: 1,
+		URL:    "http://example.com/hook",
+		Events: models.WebhookEvents{models.WebhookTodoItemCreated},
+	}
+	webhook.ID = 1
+	return webhook
+}
+
+// GetAll ...
+func (s *WebhookServiceMock) GetAll(ctx context.Context, userID uint) ([]models.Webhook, error) {
+	if userID != 1 {
+		return []models.Webhook{}, errors.New("err")
+	}
+	return []models.Webhook{webhook1()}, nil
+}
+
+// GetSingle ...
+func (s *WebhookServiceMock) GetSingle(ctx context.Context, id uint) (models.Webhook, error) {
+	if id != 1 {
+		return models.Webhook{}, errors.New("not found")
+	}
+	return webhook1(), nil
+}
+
+// Create ...
+func (s *WebhookServiceMock) Create(ctx context.Context, userID uint, webhook *models.Webhook) error {
+	if webhook.URL == "" {
+		return services.ErrInvalidWebhook
+	}
+	if userID != 1 {
+		return errors.New("err")
+	}
+	webhook.ID = 2
+	webhook.Secret = "secret"
+	return nil
+}
+
+// Update ...
+func (s *WebhookServiceMock) Update(ctx context.Context, id uint, webhookData *models.Webhook) (models.Webhook, error) {
+	if webhookData.URL == "ftp://example.com" {
+		return models.Webhook{}, services.ErrInvalidWebhook
+	}
+	if id != 1 {
+		return models.Webhook{}, errors.New("not found")
+	}
+	return webhook1(), nil
+}
+
+// Delete ...
+func (s *WebhookServiceMock) Delete(ctx context.Context, id uint) error {
+	if id != 1 {
+		return errors.New("not found")
+	}
+	return nil
+}
+
+// GetDeliveries ...
+func (s *WebhookServiceMock) GetDeliveries(ctx context.Context, id uint, page models.Page) ([]models.WebhookDelivery, error) {
+	if id != 1 {
+		return []models.WebhookDelivery{}, errors.New("not found")
+	}
+
+	deliveries := []models.WebhookDelivery{
+		{ID: 2, WebhookID: 1, EventID: 2, EventType: models.WebhookTodoItemCreated, Status: models.WebhookDeliveryPending, Attempts: 1},
+		{ID: 1, WebhookID: 1, EventID: 1, EventType: models.WebhookTodoItemCreated, Status: models.WebhookDeliverySucceeded, Attempts: 1},
+	}
+	return deliveries, nil
+}
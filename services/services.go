@@ -1,40 +1,155 @@
 package services
 
-import "github.com/danikg/go-todo-rest-api/models"
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/danikg/go-todo-rest-api/models"
+	"github.com/danikg/go-todo-rest-api/utils/importer"
+)
+
+// ErrEmptyTag is returned for tags without text
+var ErrEmptyTag = errors.New("tag text is empty")
+
+// ErrInvalidTagColor is returned for tag colors that are not #rrggbb hex codes
+var ErrInvalidTagColor = errors.New("tag color must be a #rrggbb hex code")
+
+// ErrInvalidBulkOperation is returned for malformed bulk requests before anything is applied
+var ErrInvalidBulkOperation = errors.New("invalid bulk operation")
+
+// ErrInvalidWebhook is returned for webhooks without a valid http(s) URL or with unknown event types
+var ErrInvalidWebhook = errors.New("invalid webhook")
+
+// ErrInvalidSyncChange is returned for malformed sync pushes before anything is applied
+var ErrInvalidSyncChange = errors.New("invalid sync change")
+
+// ErrInvalidExportFormat is returned for export formats other than json, csv and markdown
+var ErrInvalidExportFormat = errors.New("export format must be json, csv or markdown")
+
+// ErrInvalidRecurrence is returned for recurrence rules that are not RRULE values or lack a due date
+var ErrInvalidRecurrence = errors.New("recurrence must be an RRULE value like FREQ=WEEKLY on a todo item with a due date")
+
+// ErrInvalidFeedToken is returned for calendar feed tokens that do not exist or were revoked
+var ErrInvalidFeedToken = errors.New("unknown feed token")
+
+// ErrInvalidImport is returned for import files that cannot be read, have unreadable lines or too many todo items,
+// nothing is imported
+var ErrInvalidImport = errors.New("invalid import")
+
+// ErrMissingCredentials is returned when an admin creates a user without username or password,
+// or resets a password to an empty one
+var ErrMissingCredentials = errors.New("username and password are required")
+
+// ErrTokenRevoked is returned for tokens of users that were deleted, disabled or had their password reset
+var ErrTokenRevoked = errors.New("the token was revoked")
+
+// ErrForbidden is returned when the authenticated user reads or changes the data of another user
+var ErrForbidden = errors.New("the resource belongs to another user")
+
+// MaxBulkOperations is the largest number of operations accepted in one bulk request
+const MaxBulkOperations = 100
+
+// MaxSyncChanges is the largest number of changes accepted in one sync push
+const MaxSyncChanges = 500
+
+// MaxImportItems is the largest number of todo items accepted in one import
+const MaxImportItems = 5000
 
 // IUserService ...
 type IUserService interface {
-	GetAll() ([]models.User, error)
-	GetSingle(id uint) (models.User, error)
-	Create(user *models.User) error
-	Update(id uint, userData *models.User) (models.User, error)
-	Delete(id uint) error
+	GetAll(ctx context.Context) ([]models.User, error)
+	GetSingle(ctx context.Context, id uint) (models.User, error)
+	Create(ctx context.Context, user *models.User) error
+	Update(ctx context.Context, id uint, userData *models.User) (models.User, error)
+	Delete(ctx context.Context, id uint) error
+	Authenticate(ctx context.Context, username, password string) (models.User, error)
+	CheckToken(ctx context.Context, userID, generation uint) error
 }
 
 // ITodoListService ...
 type ITodoListService interface {
-	GetAll(userID uint) ([]models.TodoList, error)
-	GetSingle(id uint) (models.TodoList, error)
-	Create(userID uint, todoList *models.TodoList) error
-	Update(id uint, todoListData *models.TodoList) (models.TodoList, error)
-	Delete(id uint) error
+	GetAll(ctx context.Context, userID uint) ([]models.TodoList, error)
+	GetAllByUsers(ctx context.Context, userIDs []uint) ([]models.TodoList, error)
+	GetSingle(ctx context.Context, id uint) (models.TodoList, error)
+	Create(ctx context.Context, userID uint, todoList *models.TodoList) error
+	Update(ctx context.Context, id uint, todoListData *models.TodoList) (models.TodoList, error)
+	Delete(ctx context.Context, id uint) error
 }
 
 // ITodoItemService ...
 type ITodoItemService interface {
-	GetAll(listID uint) ([]models.TodoItem, error)
-	GetSingle(id uint) (models.TodoItem, error)
-	Create(listID uint, todoItem *models.TodoItem) error
-	Update(id uint, todoItemData *models.TodoItem) (models.TodoItem, error)
-	Delete(id uint) error
+	GetAll(ctx context.Context, listID uint) ([]models.TodoItem, error)
+	GetAllByLists(ctx context.Context, listIDs []uint) ([]models.TodoItem, error)
+	GetSingle(ctx context.Context, id uint) (models.TodoItem, error)
+	Create(ctx context.Context, listID uint, todoItem *models.TodoItem) error
+	Update(ctx context.Context, id uint, todoItemData *models.TodoItemUpdate) (models.TodoItem, error)
+	Delete(ctx context.Context, id uint) error
+	Bulk(ctx context.Context, request *models.BulkRequest) (models.BulkReport, error)
 }
 
 // ITagService ...
 type ITagService interface {
-	GetAll(itemID uint) ([]models.Tag, error)
-	GetSingle(id uint) (models.Tag, error)
-	Create(itemID uint, tag *models.Tag) error
-	Update(id uint, tagData *models.Tag) (models.Tag, error)
-	Remove(itemID uint, tagID uint) error
-	Delete(id uint) error
+	GetAll(ctx context.Context, itemID uint) ([]models.Tag, error)
+	GetAllByUser(ctx context.Context, userID uint) ([]models.TagUsage, error)
+	GetSingle(ctx context.Context, id uint) (models.Tag, error)
+	GetTodoItems(ctx context.Context, tagID uint, page models.Page) ([]models.TodoItem, error)
+	GetTodoItemsByUser(ctx context.Context, userID uint, filter models.TagFilter, page models.Page) ([]models.TodoItem, error)
+	Create(ctx context.Context, itemID uint, tag *models.Tag) error
+	CreateForUser(ctx context.Context, userID uint, tag *models.Tag) error
+	Update(ctx context.Context, id uint, tagData *models.Tag) (models.Tag, error)
+	Merge(ctx context.Context, id uint, targetID uint) (models.Tag, error)
+	Remove(ctx context.Context, itemID uint, tagID uint) error
+	Delete(ctx context.Context, id uint) error
+}
+
+// IActivityService ...
+type IActivityService interface {
+	GetByTodoList(ctx context.Context, listID uint, page models.Page) ([]models.AuditEvent, error)
+	GetByUser(ctx context.Context, userID uint, page models.Page) ([]models.AuditEvent, error)
+}
+
+// IWebhookService ...
+type IWebhookService interface {
+	GetAll(ctx context.Context, userID uint) ([]models.Webhook, error)
+	GetSingle(ctx context.Context, id uint) (models.Webhook, error)
+	Create(ctx context.Context, userID uint, webhook *models.Webhook) error
+	Update(ctx context.Context, id uint, webhookData *models.Webhook) (models.Webhook, error)
+	Delete(ctx context.Context, id uint) error
+	GetDeliveries(ctx context.Context, id uint, page models.Page) ([]models.WebhookDelivery, error)
+}
+
+// ISyncService ...
+type ISyncService interface {
+	Changes(ctx context.Context, userID uint, since uint64) (models.SyncChanges, error)
+	Push(ctx context.Context, userID uint, push *models.SyncPush) (models.SyncReport, error)
+}
+
+// IExportService ...
+type IExportService interface {
+	Export(ctx context.Context, userID uint, format string, w io.Writer) error
+}
+
+// IImportService ...
+type IImportService interface {
+	Import(ctx context.Context, userID uint, format string, r io.Reader, options importer.Options, dryRun bool) (models.ImportReport, error)
+}
+
+// ICalendarService ...
+type ICalendarService interface {
+	CreateFeed(ctx context.Context, userID uint) (models.Feed, error)
+	DeleteFeed(ctx context.Context, userID uint) error
+	Feed(ctx context.Context, token string, listID uint) ([]byte, error)
+}
+
+// IAdminService ...
+type IAdminService interface {
+	ListUsers(ctx context.Context) ([]models.User, error)
+	CreateUser(ctx context.Context, user *models.User) error
+	ResetPassword(ctx context.Context, id uint, password string) (models.User, error)
+	SetDisabled(ctx context.Context, id uint, disabled bool) (models.User, error)
+	TransferTodoList(ctx context.Context, id uint, userID uint) (models.TodoList, error)
+	Purge(ctx context.Context, before time.Time) (models.PurgeReport, error)
+	Stats(ctx context.Context) (models.Stats, error)
 }
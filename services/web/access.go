@@ -0,0 +1,19 @@
+package webservices
+
+import (
+	"context"
+
+	"github.com/danikg/go-todo-rest-api/services"
+	"github.com/danikg/go-todo-rest-api/utils/auth"
+)
+
+// authorize returns services.ErrForbidden unless the authenticated user is the owner userID.
+// Calls without an authenticated user come from the admin commands or from an API
+// running without authentication and are let through, unless the API requires authentication
+func authorize(ctx context.Context, ownerID uint) error {
+	userID, ok := auth.UserID(ctx)
+	if ok && userID != ownerID || !ok && auth.Required(ctx) {
+		return services.ErrForbidden
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package webservices
+
+import (
+	"context"
+
+	"github.com/danikg/go-todo-rest-api/models"
+	repos "github.com/danikg/go-todo-rest-api/repositories"
+	"github.com/danikg/go-todo-rest-api/utils/tracing"
+)
+
+// ActivityService reads the audit log, the events outlive the resources they describe
+type ActivityService struct {
+	AuditRepo repos.IAuditRepository
+}
+
+// NewActivityService ...
+func NewActivityService(auditRepo repos.IAuditRepository) *ActivityService {
+	return &ActivityService{AuditRepo: auditRepo}
+}
+
+// GetByTodoList returns the events of the todo list and its items, newest first
+func (a *ActivityService) GetByTodoList(ctx context.Context, listID uint, page models.Page) ([]models.AuditEvent, error) {
+	ctx, span := tracing.Start(ctx, "ActivityService.GetByTodoList")
+	defer span.End()
+
+	// the newest event names the current owner, also once the list is deleted or transferred
+	latest, err := a.AuditRepo.GetAllByTodoList(ctx, listID, models.Page{Limit: 1})
+	if err != nil {
+		return []models.AuditEvent{}, err
+	}
+	if len(latest) != 0 {
+		if err = authorize(ctx, latest[0].UserID); err != nil {
+			return []models.AuditEvent{}, err
+		}
+	}
+
+	return a.AuditRepo.GetAllByTodoList(ctx, listID, page)
+}
+
+// GetByUser returns the events on the data of the user and the changes made by the user, newest first
+func (a *ActivityService) GetByUser(ctx context.Context, userID uint, page models.Page) ([]models.AuditEvent, error) {
+	ctx, span := tracing.Start(ctx, "ActivityService.GetByUser")
+	defer span.End()
+
+	if err := authorize(ctx, userID); err != nil {
+		return []models.AuditEvent{}, err
+	}
+	return a.AuditRepo.GetAllByUser(ctx, userID, page)
+}
@@ -0,0 +1,114 @@
+package webservices
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/danikg/go-todo-rest-api/models"
+	"github.com/danikg/go-todo-rest-api/repositories/mocks"
+	"github.com/danikg/go-todo-rest-api/services"
+	"github.com/danikg/go-todo-rest-api/utils/auth"
+	"github.com/danikg/go-todo-rest-api/utils/events"
+	"github.com/danikg/go-todo-rest-api/utils/requestid"
+)
+
+func decodeChanges(t *testing.T, event models.AuditEvent) map[string]models.AuditChange {
+	changes := map[string]models.AuditChange{}
+	assert.NoError(t, json.Unmarshal(event.Changes, &changes))
+	return changes
+}
+
+func TestAuditDiff(t *testing.T) {
+	before := models.TodoItem{Title: "item", Description: "desc"}
+	after := models.TodoItem{Title: "item", Description: "new", Completed: true}
+
+	raw, err := auditDiff(before, after)
+	assert.NoError(t, err)
+	changes := map[string]models.AuditChange{}
+	assert.NoError(t, json.Unmarshal(raw, &changes))
+	assert.Equal(t, map[string]models.AuditChange{
+		"Description": {Before: "desc", After: "new"},
+		"Completed":   {Before: false, After: true},
+	}, changes)
+
+	raw, err = auditDiff(nil, models.User{Username: "user1", Password: "secret"})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"Username": {"After": "user1"}}`, string(raw))
+}
+
+func TestActivityService_Audit(t *testing.T) {
+	auditRepo := &mocks.AuditRepositoryMock{}
+	todoListService := NewTodoListService(&mocks.UserRepositoryMock{}, &mocks.TodoListRepositoryMock{}, auditRepo, events.NewBus(0))
+	activityService := NewActivityService(auditRepo)
+
+	ctx := requestid.NewContext(auth.NewContext(context.Background(), 1), "request-1")
+	_, err := todoListService.Update(ctx, 1, &models.TodoList{Name: "renamed"})
+	assert.NoError(t, err)
+	assert.NoError(t, todoListService.Delete(ctx, 1))
+	assert.Error(t, todoListService.Delete(ctx, 2))
+
+	events, err := activityService.GetByTodoList(ctx, 1, models.Page{Limit: models.DefaultPageLimit})
+	assert.NoError(t, err)
+	assert.Len(t, events, 2)
+
+	deleted := events[0]
+	assert.Equal(t, models.AuditDelete, deleted.Action)
+	assert.Equal(t, models.AuditTodoList, deleted.ResourceType)
+	assert.Equal(t, uint(1), deleted.ResourceID)
+	assert.Equal(t, uint(1), deleted.UserID)
+	assert.Equal(t, uint(1), *deleted.ActorID)
+	assert.Equal(t, "request-1", deleted.RequestID)
+	assert.Equal(t, models.AuditChange{Before: "list1"}, decodeChanges(t, deleted)["Name"])
+	assert.Equal(t, models.AuditUpdate, events[1].Action)
+
+	events, err = activityService.GetByUser(ctx, 1, models.Page{Limit: 1})
+	assert.NoError(t, err)
+	assert.Len(t, events, 1)
+	assert.Equal(t, models.AuditDelete, events[0].Action)
+
+	events, err = activityService.GetByUser(ctx, 1, models.Page{Limit: 1, Offset: 2})
+	assert.NoError(t, err)
+	assert.Empty(t, events)
+
+	// the activity of user 1 is hidden from other users
+	other := auth.NewContext(context.Background(), 2)
+	_, err = activityService.GetByTodoList(other, 1, models.Page{Limit: models.DefaultPageLimit})
+	assert.ErrorIs(t, err, services.ErrForbidden)
+	_, err = activityService.GetByUser(other, 1, models.Page{Limit: models.DefaultPageLimit})
+	assert.ErrorIs(t, err, services.ErrForbidden)
+}
+
+func TestActivityService_AuditCanceled(t *testing.T) {
+	auditRepo := &mocks.AuditRepositoryMock{}
+	todoListService := NewTodoListService(&mocks.UserRepositoryMock{}, &mocks.TodoListRepositoryMock{}, auditRepo, events.NewBus(0))
+
+	// the client went away after the change was committed
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := todoListService.Update(ctx, 1, &models.TodoList{Name: "renamed"})
+	assert.NoError(t, err)
+	if assert.Len(t, auditRepo.Events, 1) {
+		assert.Equal(t, models.AuditUpdate, auditRepo.Events[0].Action)
+	}
+}
+
+func TestActivityService_AuditBulk(t *testing.T) {
+	auditRepo := &mocks.AuditRepositoryMock{}
+	todoItemService := NewTodoItemService(&mocks.TodoItemRepositoryMock{}, &mocks.TodoListRepositoryMock{}, auditRepo, events.NewBus(0))
+
+	_, err := todoItemService.Bulk(context.Background(), &models.BulkRequest{
+		Operations: []models.BulkOperation{
+			{Op: models.BulkComplete, ID: 1},
+			{Op: models.BulkAddTag, ID: 1, Tag: "@phone"},
+			{Op: models.BulkDelete, ID: 2},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, auditRepo.Events, 1)
+	assert.Equal(t, models.AuditTodoItem, auditRepo.Events[0].ResourceType)
+	assert.Equal(t, uint(1), auditRepo.Events[0].ResourceID)
+	assert.Nil(t, auditRepo.Events[0].ActorID)
+}
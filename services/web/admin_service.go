@@ -0,0 +1,135 @@
+package webservices
+
+import (
+	"context"
+	"time"
+
+	"github.com/danikg/go-todo-rest-api/models"
+	repos "github.com/danikg/go-todo-rest-api/repositories"
+	"github.com/danikg/go-todo-rest-api/services"
+	"github.com/danikg/go-todo-rest-api/utils/tracing"
+)
+
+// AdminService runs the maintenance tasks of the admin commands, bypassing the authorization of the API
+type AdminService struct {
+	UserRepo        repos.IUserRepository
+	TodoListRepo    repos.ITodoListRepository
+	MaintenanceRepo repos.IMaintenanceRepository
+	AuditRepo       repos.IAuditRepository
+}
+
+// NewAdminService ...
+func NewAdminService(userRepo repos.IUserRepository, todoListRepo repos.ITodoListRepository, maintenanceRepo repos.IMaintenanceRepository, auditRepo repos.IAuditRepository) *AdminService {
+	return &AdminService{
+		UserRepo:        userRepo,
+		TodoListRepo:    todoListRepo,
+		MaintenanceRepo: maintenanceRepo,
+		AuditRepo:       auditRepo,
+	}
+}
+
+// ListUsers returns all users from the db
+func (a *AdminService) ListUsers(ctx context.Context) ([]models.User, error) {
+	ctx, span := tracing.Start(ctx, "AdminService.ListUsers")
+	defer span.End()
+
+	return a.UserRepo.GetAll(ctx)
+}
+
+// CreateUser creates a user with a password
+func (a *AdminService) CreateUser(ctx context.Context, user *models.User) error {
+	ctx, span := tracing.Start(ctx, "AdminService.CreateUser")
+	defer span.End()
+
+	if user.Username == "" || user.Password == "" {
+		return services.ErrMissingCredentials
+	}
+	return NewUserService(a.UserRepo, a.AuditRepo).Create(ctx, user)
+}
+
+// ResetPassword replaces the password of the user and revokes the tokens issued before
+func (a *AdminService) ResetPassword(ctx context.Context, id uint, password string) (models.User, error) {
+	ctx, span := tracing.Start(ctx, "AdminService.ResetPassword")
+	defer span.End()
+
+	if password == "" {
+		return models.User{}, services.ErrMissingCredentials
+	}
+
+	user, err := a.UserRepo.GetSingle(ctx, id)
+	if err != nil {
+		return user, err
+	}
+
+	if user, err = NewUserService(a.UserRepo, a.AuditRepo).Update(ctx, id, &models.User{Username: user.Username, Password: password}); err != nil {
+		return user, err
+	}
+	return user, a.UserRepo.RevokeTokens(ctx, id)
+}
+
+// SetDisabled disables or enables the user, disabling revokes the tokens issued before
+func (a *AdminService) SetDisabled(ctx context.Context, id uint, disabled bool) (models.User, error) {
+	ctx, span := tracing.Start(ctx, "AdminService.SetDisabled")
+	defer span.End()
+
+	before, err := a.UserRepo.GetSingle(ctx, id)
+	if err != nil {
+		return before, err
+	}
+
+	user, err := a.UserRepo.SetDisabled(ctx, id, disabled)
+	if err != nil {
+		return user, err
+	}
+	if disabled {
+		if err = a.UserRepo.RevokeTokens(ctx, id); err != nil {
+			return user, err
+		}
+	}
+
+	recordAudit(ctx, a.AuditRepo, userAuditEvent(models.AuditUpdate, id), before, user)
+	return user, nil
+}
+
+// TransferTodoList gives the todo list with its todo items to another user
+func (a *AdminService) TransferTodoList(ctx context.Context, id uint, userID uint) (models.TodoList, error) {
+	ctx, span := tracing.Start(ctx, "AdminService.TransferTodoList")
+	defer span.End()
+
+	if _, err := a.UserRepo.GetSingle(ctx, userID); err != nil {
+		return models.TodoList{}, err
+	}
+
+	before, err := a.TodoListRepo.GetSingle(ctx, id)
+	if err != nil {
+		return before, err
+	}
+
+	todoList, err := a.TodoListRepo.Transfer(ctx, id, userID)
+	if err != nil {
+		return todoList, err
+	}
+
+	// both owners see the transfer in their activity
+	recordAudit(ctx, a.AuditRepo, todoListAuditEvent(models.AuditUpdate, &before), before, todoList)
+	if before.UserID != todoList.UserID {
+		recordAudit(ctx, a.AuditRepo, todoListAuditEvent(models.AuditUpdate, &todoList), before, todoList)
+	}
+	return todoList, nil
+}
+
+// Purge removes the deleted data and the bookkeeping rows older than before
+func (a *AdminService) Purge(ctx context.Context, before time.Time) (models.PurgeReport, error) {
+	ctx, span := tracing.Start(ctx, "AdminService.Purge")
+	defer span.End()
+
+	return a.MaintenanceRepo.Purge(ctx, before)
+}
+
+// Stats counts the rows of the db
+func (a *AdminService) Stats(ctx context.Context) (models.Stats, error) {
+	ctx, span := tracing.Start(ctx, "AdminService.Stats")
+	defer span.End()
+
+	return a.MaintenanceRepo.Stats(ctx)
+}
@@ -0,0 +1,138 @@
+package webservices
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/danikg/go-todo-rest-api/models"
+	"github.com/danikg/go-todo-rest-api/repositories/mocks"
+	"github.com/danikg/go-todo-rest-api/services"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestAdminService(maintenanceRepo *mocks.MaintenanceRepositoryMock, auditRepo *mocks.AuditRepositoryMock) *AdminService {
+	return NewAdminService(&mocks.UserRepositoryMock{}, &mocks.TodoListRepositoryMock{}, maintenanceRepo, auditRepo)
+}
+
+func TestAdminService_CreateUser(t *testing.T) {
+	adminService := newTestAdminService(&mocks.MaintenanceRepositoryMock{}, &mocks.AuditRepositoryMock{})
+
+	tests := []struct {
+		name string
+		user models.User
+		err  error
+	}{
+		{"created", models.User{Username: "user3", Password: "password"}, nil},
+		{"missing username", models.User{Password: "password"}, services.ErrMissingCredentials},
+		{"missing password", models.User{Username: "user3"}, services.ErrMissingCredentials},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			user := tc.user
+			err := adminService.CreateUser(context.Background(), &user)
+			assert.Equal(t, tc.err, err)
+			if err == nil {
+				assert.Empty(t, user.Password)
+				assert.NotEmpty(t, user.PasswordHash)
+			}
+		})
+	}
+}
+
+func TestAdminService_ResetPassword(t *testing.T) {
+	auditRepo := &mocks.AuditRepositoryMock{}
+	adminService := newTestAdminService(&mocks.MaintenanceRepositoryMock{}, auditRepo)
+
+	user, err := adminService.ResetPassword(context.Background(), 1, "secret")
+	assert.NoError(t, err)
+	assert.Equal(t, "user1", user.Username)
+	assert.Len(t, auditRepo.Events, 1)
+	assert.Equal(t, []uint{1}, adminService.UserRepo.(*mocks.UserRepositoryMock).Revoked)
+
+	_, err = adminService.ResetPassword(context.Background(), 1, "")
+	assert.Equal(t, services.ErrMissingCredentials, err)
+
+	_, err = adminService.ResetPassword(context.Background(), 2, "secret")
+	assert.Error(t, err)
+}
+
+func TestAdminService_SetDisabled(t *testing.T) {
+	auditRepo := &mocks.AuditRepositoryMock{}
+	adminService := newTestAdminService(&mocks.MaintenanceRepositoryMock{}, auditRepo)
+
+	user, err := adminService.SetDisabled(context.Background(), 1, true)
+	assert.NoError(t, err)
+	assert.True(t, user.Disabled)
+	if assert.Len(t, auditRepo.Events, 1) {
+		assert.Equal(t, models.AuditUser, auditRepo.Events[0].ResourceType)
+		assert.Contains(t, string(auditRepo.Events[0].Changes), "Disabled")
+	}
+	assert.Equal(t, []uint{1}, adminService.UserRepo.(*mocks.UserRepositoryMock).Revoked)
+
+	// enabling leaves the tokens alone, the user had none left
+	_, err = adminService.SetDisabled(context.Background(), 1, false)
+	assert.NoError(t, err)
+	assert.Len(t, adminService.UserRepo.(*mocks.UserRepositoryMock).Revoked, 1)
+
+	_, err = adminService.SetDisabled(context.Background(), 2, true)
+	assert.Error(t, err)
+}
+
+func TestAdminService_TransferTodoList(t *testing.T) {
+	tests := []struct {
+		name   string
+		listID uint
+		userID uint
+		err    bool
+	}{
+		{"transferred", 1, 1, false},
+		{"unknown list", 2, 1, true},
+		{"unknown user", 1, 2, true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			auditRepo := &mocks.AuditRepositoryMock{}
+			adminService := newTestAdminService(&mocks.MaintenanceRepositoryMock{}, auditRepo)
+
+			todoList, err := adminService.TransferTodoList(context.Background(), tc.listID, tc.userID)
+			if tc.err {
+				assert.Error(t, err)
+				assert.Empty(t, auditRepo.Events)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.userID, todoList.UserID)
+			assert.Len(t, auditRepo.Events, 1)
+		})
+	}
+}
+
+func TestAdminService_Purge(t *testing.T) {
+	maintenanceRepo := &mocks.MaintenanceRepositoryMock{}
+	adminService := newTestAdminService(maintenanceRepo, &mocks.AuditRepositoryMock{})
+	before := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	report, err := adminService.Purge(context.Background(), before)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), report.Tombstones)
+	assert.Equal(t, before, maintenanceRepo.Before)
+
+	adminService = newTestAdminService(&mocks.MaintenanceRepositoryMock{GenerateErr: true}, &mocks.AuditRepositoryMock{})
+	_, err = adminService.Purge(context.Background(), before)
+	assert.Error(t, err)
+}
+
+func TestAdminService_Stats(t *testing.T) {
+	adminService := newTestAdminService(&mocks.MaintenanceRepositoryMock{}, &mocks.AuditRepositoryMock{})
+	stats, err := adminService.Stats(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), stats.Users)
+
+	adminService = newTestAdminService(&mocks.MaintenanceRepositoryMock{GenerateErr: true}, &mocks.AuditRepositoryMock{})
+	_, err = adminService.Stats(context.Background())
+	assert.Error(t, err)
+}
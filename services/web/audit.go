@@ -0,0 +1,100 @@
+package webservices
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+
+	"github.com/danikg/go-todo-rest-api/models"
+	repos "github.com/danikg/go-todo-rest-api/repositories"
+	"github.com/danikg/go-todo-rest-api/utils/auth"
+	"github.com/danikg/go-todo-rest-api/utils/logger"
+	"github.com/danikg/go-todo-rest-api/utils/requestid"
+)
+
+// auditIgnoredFields are left out of the recorded changes,
+// they are either bookkeeping, nested resources audited on their own or secrets
+var auditIgnoredFields = map[string]bool{
+	"ID":        true,
+	"CreatedAt": true,
+	"UpdatedAt": true,
+	"DeletedAt": true,
+	"Version":   true,
+	"TodoList":  true,
+	"TodoLists": true,
+	"Tags":      true,
+	"Password":  true,
+}
+
+// recordAudit appends an event for a mutation that already succeeded,
+// a failure to record it is logged rather than failing the request.
+// The mutation is committed, so the event is written even when the request was canceled meanwhile
+func recordAudit(ctx context.Context, repo repos.IAuditRepository, event models.AuditEvent, before, after interface{}) {
+	ctx = context.WithoutCancel(ctx)
+	if actorID, ok := auth.UserID(ctx); ok {
+		event.ActorID = &actorID
+	}
+	event.RequestID = requestid.FromContext(ctx)
+
+	changes, err := auditDiff(before, after)
+	if err == nil {
+		event.Changes = changes
+		err = repo.Create(ctx, &event)
+	}
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to record audit event", "error", err,
+			"action", event.Action, "resource_type", event.ResourceType, "resource_id", event.ResourceID)
+	}
+}
+
+// auditDiff returns the fields that differ between the JSON forms of before and after,
+// nil stands for a resource that does not exist yet or anymore
+func auditDiff(before, after interface{}) (models.AuditChanges, error) {
+	beforeFields, err := auditFields(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := auditFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := map[string]models.AuditChange{}
+	for name, value := range beforeFields {
+		if !reflect.DeepEqual(value, afterFields[name]) {
+			changes[name] = models.AuditChange{Before: value, After: afterFields[name]}
+		}
+	}
+	for name, value := range afterFields {
+		if _, ok := beforeFields[name]; !ok {
+			changes[name] = models.AuditChange{After: value}
+		}
+	}
+	return json.Marshal(changes)
+}
+
+func auditFields(resource interface{}) (map[string]interface{}, error) {
+	fields := map[string]interface{}{}
+	if resource == nil {
+		return fields, nil
+	}
+
+	raw, err := json.Marshal(resource)
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+	for name := range fields {
+		if auditIgnoredFields[name] {
+			delete(fields, name)
+		}
+	}
+	return fields, nil
+}
+
+// auditListID returns a pointer to the todo list id stored on an event
+func auditListID(listID uint) *uint {
+	return &listID
+}
@@ -0,0 +1,124 @@
+package webservices
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/danikg/go-todo-rest-api/models"
+	repos "github.com/danikg/go-todo-rest-api/repositories"
+	"github.com/danikg/go-todo-rest-api/services"
+	"github.com/danikg/go-todo-rest-api/utils/ical"
+	"github.com/danikg/go-todo-rest-api/utils/tracing"
+)
+
+var errFeedListNotFound = errors.New("todo list not found")
+
+// CalendarService ...
+type CalendarService struct {
+	CalendarRepo repos.ICalendarRepository
+	UserRepo     repos.IUserRepository
+	TodoListRepo repos.ITodoListRepository
+}
+
+// NewCalendarService ...
+func NewCalendarService(calendarRepo repos.ICalendarRepository, userRepo repos.IUserRepository, todoListRepo repos.ITodoListRepository) *CalendarService {
+	return &CalendarService{
+		CalendarRepo: calendarRepo,
+		UserRepo:     userRepo,
+		TodoListRepo: todoListRepo,
+	}
+}
+
+// CreateFeed creates a new feed token for the user, the previous token stops working
+func (t *CalendarService) CreateFeed(ctx context.Context, userID uint) (models.Feed, error) {
+	ctx, span := tracing.Start(ctx, "CalendarService.CreateFeed")
+	defer span.End()
+
+	if err := authorize(ctx, userID); err != nil {
+		return models.Feed{}, err
+	}
+	if _, err := t.UserRepo.GetSingle(ctx, userID); err != nil {
+		return models.Feed{}, err
+	}
+
+	token, err := newFeedToken()
+	if err != nil {
+		return models.Feed{}, err
+	}
+	if err = t.CalendarRepo.SaveFeedToken(ctx, userID, hashFeedToken(token)); err != nil {
+		return models.Feed{}, err
+	}
+
+	return models.Feed{
+		Token:    token,
+		Path:     fmt.Sprintf("/feeds/%s.ics", token),
+		ListPath: fmt.Sprintf("/feeds/%s/todo_lists/{list_id}.ics", token),
+	}, nil
+}
+
+// DeleteFeed revokes the feed token of the user
+func (t *CalendarService) DeleteFeed(ctx context.Context, userID uint) error {
+	ctx, span := tracing.Start(ctx, "CalendarService.DeleteFeed")
+	defer span.End()
+
+	if err := authorize(ctx, userID); err != nil {
+		return err
+	}
+	return t.CalendarRepo.DeleteFeedToken(ctx, userID)
+}
+
+// Feed renders the todo items of the token's user as an iCalendar feed,
+// restricted to the todo list listID of the user unless it is 0
+func (t *CalendarService) Feed(ctx context.Context, token string, listID uint) ([]byte, error) {
+	ctx, span := tracing.Start(ctx, "CalendarService.Feed")
+	defer span.End()
+
+	userID, err := t.CalendarRepo.GetFeedTokenUser(ctx, hashFeedToken(token))
+	if err != nil {
+		return nil, services.ErrInvalidFeedToken
+	}
+
+	user, err := t.UserRepo.GetSingle(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	name := user.Username
+	if listID != 0 {
+		todoList, err := t.TodoListRepo.GetSingle(ctx, listID)
+		if err != nil || todoList.UserID != userID {
+			return nil, errFeedListNotFound
+		}
+		name = todoList.Name
+	}
+
+	todoItems, err := t.CalendarRepo.GetTodoItems(ctx, userID, listID)
+	if err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	if err = ical.Write(&out, name, todoItems); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// newFeedToken returns 256 random bits, url safe so the token can be part of the feed path
+func newFeedToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashFeedToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,162 @@
+package webservices
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/danikg/go-todo-rest-api/repositories/mocks"
+	"github.com/danikg/go-todo-rest-api/services"
+	"github.com/danikg/go-todo-rest-api/utils/auth"
+	"github.com/stretchr/testify/assert"
+)
+
+// icsComponent holds the unescaped values of the properties of a component
+type icsComponent map[string][]string
+
+// parseICS unfolds the content lines of a feed and returns the calendar properties and the VTODO components
+func parseICS(t *testing.T, feed []byte) (icsComponent, []icsComponent) {
+	text := string(feed)
+	assert.True(t, strings.HasSuffix(text, "\r\n"))
+
+	lines := []string{}
+	for _, line := range strings.Split(strings.TrimSuffix(text, "\r\n"), "\r\n") {
+		assert.LessOrEqual(t, len(line), 75)
+		assert.True(t, utf8.ValidString(line), line)
+		assert.NotContains(t, line, "\n")
+		if strings.HasPrefix(line, " ") {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	calendar := icsComponent{}
+	todos := []icsComponent{}
+	var todo icsComponent
+	for _, line := range lines {
+		name, value, _ := strings.Cut(line, ":")
+		switch {
+		case line == "BEGIN:VTODO":
+			todo = icsComponent{}
+		case line == "END:VTODO":
+			todos = append(todos, todo)
+			todo = nil
+		case todo != nil:
+			todo[name] = splitICSValue(value)
+		default:
+			calendar[name] = splitICSValue(value)
+		}
+	}
+	return calendar, todos
+}
+
+// splitICSValue splits a value on the unescaped commas and unescapes the parts
+func splitICSValue(value string) []string {
+	parts := []string{}
+	var part strings.Builder
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; {
+		case c == '\\' && i+1 < len(value):
+			i++
+			if value[i] == 'n' || value[i] == 'N' {
+				part.WriteByte('\n')
+			} else {
+				part.WriteByte(value[i])
+			}
+		case c == ',':
+			parts = append(parts, part.String())
+			part.Reset()
+		default:
+			part.WriteByte(c)
+		}
+	}
+	return append(parts, part.String())
+}
+
+func TestCalendarService_Feed(t *testing.T) {
+	calendarService := NewCalendarService(&mocks.CalendarRepositoryMock{}, &mocks.UserRepositoryMock{}, &mocks.TodoListRepositoryMock{})
+
+	feed, err := calendarService.CreateFeed(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Len(t, feed.Token, 43)
+	assert.Equal(t, "/feeds/"+feed.Token+".ics", feed.Path)
+
+	out, err := calendarService.Feed(context.Background(), feed.Token, 0)
+	assert.NoError(t, err)
+	calendar, todos := parseICS(t, out)
+	assert.Equal(t, []string{"2.0"}, calendar["VERSION"])
+	assert.Equal(t, []string{"user1"}, calendar["X-WR-CALNAME"])
+	assert.Len(t, todos, 2)
+
+	todo := todos[0]
+	assert.Equal(t, []string{"todo-item-1@go-todo-rest-api"}, todo["UID"])
+	assert.Equal(t, []string{"Weekly review; plan the sprint, " + strings.Repeat("ünïcödé ", 10)}, todo["SUMMARY"])
+	assert.Equal(t, []string{"agenda:\nnotes, actions\\follow-ups"}, todo["DESCRIPTION"])
+	assert.Equal(t, []string{"20240506T070000Z"}, todo["DUE"])
+	assert.Equal(t, []string{"20240506T070000Z"}, todo["DTSTART"])
+	assert.Equal(t, []string{"FREQ=WEEKLY;BYDAY=MO"}, todo["RRULE"])
+	assert.Equal(t, []string{"NEEDS-ACTION"}, todo["STATUS"])
+	assert.Equal(t, []string{"work", "a,b"}, todo["CATEGORIES"])
+	assert.Equal(t, []string{"20240102T030405Z"}, todo["DTSTAMP"])
+
+	todo = todos[1]
+	assert.Equal(t, []string{"COMPLETED"}, todo["STATUS"])
+	assert.Equal(t, []string{"20240102T040405Z"}, todo["COMPLETED"])
+	assert.NotContains(t, todo, "DUE")
+	assert.NotContains(t, todo, "RRULE")
+	assert.NotContains(t, todo, "CATEGORIES")
+	assert.NotContains(t, todo, "DESCRIPTION")
+
+	out, err = calendarService.Feed(context.Background(), feed.Token, 1)
+	assert.NoError(t, err)
+	calendar, todos = parseICS(t, out)
+	assert.Equal(t, []string{"list1"}, calendar["X-WR-CALNAME"])
+	assert.Len(t, todos, 1)
+
+	_, err = calendarService.Feed(context.Background(), feed.Token, 2)
+	assert.Error(t, err)
+}
+
+func TestCalendarService_Tokens(t *testing.T) {
+	calendarService := NewCalendarService(&mocks.CalendarRepositoryMock{}, &mocks.UserRepositoryMock{}, &mocks.TodoListRepositoryMock{})
+
+	_, err := calendarService.Feed(context.Background(), "guess", 0)
+	assert.ErrorIs(t, err, services.ErrInvalidFeedToken)
+
+	first, err := calendarService.CreateFeed(context.Background(), 1)
+	assert.NoError(t, err)
+	second, err := calendarService.CreateFeed(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.NotEqual(t, first.Token, second.Token)
+
+	// a new token replaces the previous one
+	_, err = calendarService.Feed(context.Background(), first.Token, 0)
+	assert.ErrorIs(t, err, services.ErrInvalidFeedToken)
+	_, err = calendarService.Feed(context.Background(), second.Token, 0)
+	assert.NoError(t, err)
+
+	assert.NoError(t, calendarService.DeleteFeed(context.Background(), 1))
+	_, err = calendarService.Feed(context.Background(), second.Token, 0)
+	assert.ErrorIs(t, err, services.ErrInvalidFeedToken)
+	assert.Error(t, calendarService.DeleteFeed(context.Background(), 1))
+
+	_, err = calendarService.CreateFeed(context.Background(), 2)
+	assert.Error(t, err)
+}
+
+func TestCalendarService_Forbidden(t *testing.T) {
+	calendarService := NewCalendarService(&mocks.CalendarRepositoryMock{}, &mocks.UserRepositoryMock{}, &mocks.TodoListRepositoryMock{})
+	feed, err := calendarService.CreateFeed(context.Background(), 1)
+	assert.NoError(t, err)
+
+	ctx := auth.NewContext(context.Background(), 2)
+	_, err = calendarService.CreateFeed(ctx, 1)
+	assert.ErrorIs(t, err, services.ErrForbidden)
+	assert.ErrorIs(t, calendarService.DeleteFeed(ctx, 1), services.ErrForbidden)
+
+	// the feed of the user keeps working
+	_, err = calendarService.Feed(context.Background(), feed.Token, 0)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,11 @@
+package webservices
+
+import (
+	"github.com/danikg/go-todo-rest-api/models"
+	"github.com/danikg/go-todo-rest-api/utils/events"
+)
+
+// publishChange hands a mutation that already succeeded to the event bus
+func publishChange(publisher events.Publisher, eventType string, userID uint, listID uint, data interface{}) {
+	publisher.Publish(models.ChangeEvent{Type: eventType, UserID: userID, TodoListID: listID, Data: data})
+}
@@ -0,0 +1,52 @@
+package webservices
+
+import (
+	"context"
+	"testing"
+
+	"github.com/danikg/go-todo-rest-api/models"
+	"github.com/danikg/go-todo-rest-api/repositories/mocks"
+	"github.com/danikg/go-todo-rest-api/utils/events"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServices_PublishChanges(t *testing.T) {
+	bus := events.NewBus(10)
+	sub, _, _ := bus.Subscribe(1, 0)
+	defer sub.Close()
+
+	todoListService := NewTodoListService(&mocks.UserRepositoryMock{}, &mocks.TodoListRepositoryMock{}, &mocks.AuditRepositoryMock{}, bus)
+	todoItemService := NewTodoItemService(&mocks.TodoItemRepositoryMock{}, &mocks.TodoListRepositoryMock{}, &mocks.AuditRepositoryMock{}, bus)
+	tagService := NewTagService(&mocks.TagRepositoryMock{}, &mocks.TodoItemRepositoryMock{}, &mocks.UserRepositoryMock{}, &mocks.AuditRepositoryMock{}, bus)
+
+	ctx := context.Background()
+	completed := true
+	_, err := todoListService.Update(ctx, 1, &models.TodoList{Name: "renamed"})
+	assert.NoError(t, err)
+	assert.NoError(t, todoItemService.Create(ctx, 1, &models.TodoItem{Title: "item"}))
+	_, err = todoItemService.Update(ctx, 1, &models.TodoItemUpdate{Completed: &completed})
+	assert.NoError(t, err)
+	assert.NoError(t, tagService.Create(ctx, 1, &models.Tag{Text: "tag"}))
+	assert.NoError(t, todoItemService.Delete(ctx, 1))
+	assert.Error(t, todoItemService.Delete(ctx, 2))
+	assert.NoError(t, todoListService.Delete(ctx, 1))
+
+	expected := []string{
+		models.EventTodoListUpdated,
+		models.EventTodoItemCreated,
+		models.EventTodoItemUpdated,
+		models.EventTodoItemUpdated,
+		models.EventTodoItemDeleted,
+		models.EventTodoListDeleted,
+	}
+	var lastID uint64
+	for _, eventType := range expected {
+		event := <-sub.C
+		assert.Equal(t, eventType, event.Type)
+		assert.Equal(t, uint(1), event.UserID)
+		assert.Equal(t, uint(1), event.TodoListID)
+		assert.Greater(t, event.ID, lastID)
+		lastID = event.ID
+	}
+	assert.Empty(t, sub.C)
+}
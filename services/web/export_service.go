@@ -0,0 +1,43 @@
+package webservices
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	repos "github.com/danikg/go-todo-rest-api/repositories"
+	"github.com/danikg/go-todo-rest-api/services"
+	"github.com/danikg/go-todo-rest-api/utils/export"
+	"github.com/danikg/go-todo-rest-api/utils/tracing"
+)
+
+// ExportService ...
+type ExportService struct {
+	ExportRepo repos.IExportRepository
+}
+
+// NewExportService ...
+func NewExportService(exportRepo repos.IExportRepository) *ExportService {
+	return &ExportService{ExportRepo: exportRepo}
+}
+
+// Export streams all data of the user to w in the format,
+// unknown formats, unknown users and other users fail before anything is written
+func (t *ExportService) Export(ctx context.Context, userID uint, format string, w io.Writer) error {
+	ctx, span := tracing.Start(ctx, "ExportService.Export")
+	defer span.End()
+
+	if err := authorize(ctx, userID); err != nil {
+		return err
+	}
+
+	writer := export.NewWriter(format, w)
+	if writer == nil {
+		return fmt.Errorf("%w, got %q", services.ErrInvalidExportFormat, format)
+	}
+
+	if err := t.ExportRepo.Export(ctx, userID, writer); err != nil {
+		return err
+	}
+	return writer.Close()
+}
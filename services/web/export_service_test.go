@@ -0,0 +1,70 @@
+package webservices
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/danikg/go-todo-rest-api/repositories/mocks"
+	"github.com/danikg/go-todo-rest-api/services"
+	"github.com/danikg/go-todo-rest-api/utils/auth"
+	"github.com/danikg/go-todo-rest-api/utils/export"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportService_JSON(t *testing.T) {
+	exportService := NewExportService(&mocks.ExportRepositoryMock{})
+
+	var out bytes.Buffer
+	assert.NoError(t, exportService.Export(context.Background(), 1, export.JSON, &out))
+
+	var result struct {
+		User      map[string]interface{}
+		Tags      []map[string]interface{}
+		TodoLists []struct {
+			ID        uint
+			Name      string
+			TodoItems []map[string]interface{}
+		}
+	}
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &result))
+	assert.Equal(t, "user1", result.User["Username"])
+	assert.NotContains(t, result.User, "PasswordHash")
+	assert.Len(t, result.Tags, 1)
+	assert.Len(t, result.TodoLists, 2)
+	assert.Equal(t, "Groceries", result.TodoLists[0].Name)
+	assert.Len(t, result.TodoLists[0].TodoItems, 2)
+	assert.Equal(t, "Milk", result.TodoLists[0].TodoItems[0]["Title"])
+	assert.NotContains(t, result.TodoLists[0].TodoItems[0], "TodoList")
+	assert.Len(t, result.TodoLists[0].TodoItems[0]["Tags"], 1)
+	assert.Empty(t, result.TodoLists[1].TodoItems)
+}
+
+func TestExportService_CSV(t *testing.T) {
+	exportService := NewExportService(&mocks.ExportRepositoryMock{})
+
+	var out bytes.Buffer
+	assert.NoError(t, exportService.Export(context.Background(), 1, export.CSV, &out))
+	assert.Equal(t, "list_id,list,item_id,title,description,completed,tags,created_at,updated_at\n"+
+		"1,Groceries,1,Milk,\"2 liters,\nlow fat\",false,work,2024-01-02T03:04:05Z,2024-01-02T03:04:05Z\n"+
+		"1,Groceries,2,Bread,,true,,2024-01-02T03:04:05Z,2024-01-02T03:04:05Z\n", out.String())
+}
+
+func TestExportService_Markdown(t *testing.T) {
+	exportService := NewExportService(&mocks.ExportRepositoryMock{})
+
+	var out bytes.Buffer
+	assert.NoError(t, exportService.Export(context.Background(), 1, export.Markdown, &out))
+	assert.Equal(t, "# user1\n\n## Groceries\n\n- [ ] Milk `work`\n  2 liters, low fat\n- [x] Bread\n\n## Empty\n\n", out.String())
+}
+
+func TestExportService_Errors(t *testing.T) {
+	exportService := NewExportService(&mocks.ExportRepositoryMock{})
+
+	var out bytes.Buffer
+	assert.ErrorIs(t, exportService.Export(context.Background(), 1, "xml", &out), services.ErrInvalidExportFormat)
+	assert.Error(t, exportService.Export(context.Background(), 2, export.JSON, &out))
+	assert.ErrorIs(t, exportService.Export(auth.NewContext(context.Background(), 2), 1, export.JSON, &out), services.ErrForbidden)
+	assert.Empty(t, out.String())
+}
@@ -0,0 +1,187 @@
+package webservices
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/danikg/go-todo-rest-api/models"
+	repos "github.com/danikg/go-todo-rest-api/repositories"
+	"github.com/danikg/go-todo-rest-api/services"
+	"github.com/danikg/go-todo-rest-api/utils/events"
+	"github.com/danikg/go-todo-rest-api/utils/importer"
+	"github.com/danikg/go-todo-rest-api/utils/metrics"
+	"github.com/danikg/go-todo-rest-api/utils/tracing"
+)
+
+// ImportService ...
+type ImportService struct {
+	Transactor   repos.ITransactor
+	UserRepo     repos.IUserRepository
+	TodoListRepo repos.ITodoListRepository
+	TagRepo      repos.ITagRepository
+	Publisher    events.Publisher
+}
+
+// NewImportService ...
+func NewImportService(transactor repos.ITransactor, userRepo repos.IUserRepository, todoListRepo repos.ITodoListRepository, tagRepo repos.ITagRepository, publisher events.Publisher) *ImportService {
+	return &ImportService{
+		Transactor:   transactor,
+		UserRepo:     userRepo,
+		TodoListRepo: todoListRepo,
+		TagRepo:      tagRepo,
+		Publisher:    publisher,
+	}
+}
+
+// Import reads the file and reports the todo lists, todo items and tags it brings to the user.
+// Unless dryRun, they are created through the todo list, todo item and tag services in one transaction,
+// items go to the existing list of the same name. Files with unreadable lines are never committed
+func (t *ImportService) Import(ctx context.Context, userID uint, format string, r io.Reader, options importer.Options, dryRun bool) (models.ImportReport, error) {
+	ctx, span := tracing.Start(ctx, "ImportService.Import")
+	defer span.End()
+
+	if err := authorize(ctx, userID); err != nil {
+		return models.ImportReport{}, err
+	}
+	user, err := t.UserRepo.GetSingle(ctx, userID)
+	if err != nil {
+		return models.ImportReport{}, err
+	}
+
+	items, errs, err := importer.Parse(format, r, options)
+	if err != nil {
+		return models.ImportReport{}, fmt.Errorf("%w: %w", services.ErrInvalidImport, err)
+	}
+	if len(items) > services.MaxImportItems {
+		return models.ImportReport{}, fmt.Errorf("%w: more than %d todo items", services.ErrInvalidImport, services.MaxImportItems)
+	}
+
+	report, err := t.preview(ctx, user.ID, items, errs)
+	if err != nil || dryRun {
+		report.DryRun = dryRun
+		return report, err
+	}
+	if len(report.Errors) != 0 {
+		return report, fmt.Errorf("%w: %d lines cannot be read", services.ErrInvalidImport, len(report.Errors))
+	}
+
+	// the events and metrics of a rolled back import must not reach the subscribers and counters
+	buffer, counters := &events.Buffer{}, &metrics.Buffer{}
+	err = t.Transactor.Transaction(ctx, func(r repos.Repositories) error {
+		return commitImport(ctx, user.ID, r, buffer, counters, items, &report)
+	})
+	if err != nil {
+		for i := range report.Lists {
+			if report.Lists[i].New {
+				report.Lists[i].ID = 0
+			}
+		}
+		return report, err
+	}
+
+	buffer.Flush(t.Publisher)
+	counters.Flush()
+	report.Committed = true
+	return report, nil
+}
+
+// preview matches the todo lists and tags of the items with those of the user
+func (t *ImportService) preview(ctx context.Context, userID uint, items []models.ImportItem, errs []models.ImportError) (models.ImportReport, error) {
+	report := models.ImportReport{
+		Lists:   []models.ImportList{},
+		Items:   len(items),
+		Tags:    []string{},
+		NewTags: []string{},
+		Errors:  errs,
+	}
+
+	todoLists, err := t.TodoListRepo.GetAll(ctx, userID)
+	if err != nil {
+		return report, err
+	}
+	existing := map[string]uint{}
+	for _, todoList := range todoLists {
+		if _, ok := existing[todoList.Name]; !ok {
+			existing[todoList.Name] = todoList.ID
+		}
+	}
+
+	lists := map[string]int{}
+	for _, item := range items {
+		i, ok := lists[item.List]
+		if !ok {
+			id, found := existing[item.List]
+			report.Lists = append(report.Lists, models.ImportList{ID: id, Name: item.List, New: !found})
+			i = len(report.Lists) - 1
+			lists[item.List] = i
+		}
+		report.Lists[i].Items++
+	}
+
+	usages, err := t.TagRepo.GetAllByUser(ctx, userID)
+	if err != nil {
+		return report, err
+	}
+	known := map[string]bool{}
+	for _, usage := range usages {
+		known[models.NormalizeTagText(usage.Text)] = true
+	}
+
+	seen := map[string]bool{}
+	for _, item := range items {
+		for _, text := range item.Tags {
+			normalized := models.NormalizeTagText(text)
+			if normalized == "" || seen[normalized] {
+				continue
+			}
+			seen[normalized] = true
+			report.Tags = append(report.Tags, text)
+			if !known[normalized] {
+				report.NewTags = append(report.NewTags, text)
+			}
+		}
+	}
+	return report, nil
+}
+
+// commitImport creates the new todo lists, the todo items and their tags with the services bound to the transaction
+func commitImport(ctx context.Context, userID uint, r repos.Repositories, publisher events.Publisher, counters *metrics.Buffer, items []models.ImportItem, report *models.ImportReport) error {
+	todoListService := NewTodoListService(r.User, r.TodoList, r.Audit, publisher)
+	todoItemService := NewTodoItemService(r.TodoItem, r.TodoList, r.Audit, publisher)
+	todoItemService.Counters = counters
+	tagService := NewTagService(r.Tag, r.TodoItem, r.User, r.Audit, publisher)
+
+	listIDs := map[string]uint{}
+	for i := range report.Lists {
+		list := &report.Lists[i]
+		if list.New {
+			todoList := models.TodoList{Name: list.Name}
+			if err := todoListService.Create(ctx, userID, &todoList); err != nil {
+				return fmt.Errorf("todo list %q: %w", list.Name, err)
+			}
+			list.ID = todoList.ID
+		}
+		listIDs[list.Name] = list.ID
+	}
+
+	for _, item := range items {
+		todoItem := models.TodoItem{Title: item.Title, Description: item.Description, Completed: item.Completed, Due: item.Due, Recurrence: item.Recurrence}
+		if err := todoItemService.Create(ctx, listIDs[item.List], &todoItem); err != nil {
+			return fmt.Errorf("line %d: %w", item.Line, err)
+		}
+
+		attached := map[string]bool{}
+		for _, text := range item.Tags {
+			normalized := models.NormalizeTagText(text)
+			if attached[normalized] {
+				continue
+			}
+			attached[normalized] = true
+			if err := tagService.Create(ctx, todoItem.ID, &models.Tag{Text: text}); err != nil {
+				return fmt.Errorf("line %d: tag %q: %w", item.Line, text, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package webservices
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/danikg/go-todo-rest-api/models"
+	repos "github.com/danikg/go-todo-rest-api/repositories"
+	"github.com/danikg/go-todo-rest-api/repositories/mocks"
+	"github.com/danikg/go-todo-rest-api/services"
+	"github.com/danikg/go-todo-rest-api/utils/auth"
+	"github.com/danikg/go-todo-rest-api/utils/events"
+	"github.com/danikg/go-todo-rest-api/utils/importer"
+	"github.com/danikg/go-todo-rest-api/utils/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func newImportService(auditRepo *mocks.AuditRepositoryMock, publisher events.Publisher) *ImportService {
+	transactor := &mocks.TransactorMock{Repos: repos.Repositories{
+		User:     &mocks.UserRepositoryMock{},
+		TodoList: &mocks.TodoListRepositoryMock{},
+		TodoItem: &mocks.TodoItemRepositoryMock{},
+		Tag:      &mocks.TagRepositoryMock{},
+		Audit:    auditRepo,
+	}}
+	return NewImportService(transactor, &mocks.UserRepositoryMock{}, &mocks.TodoListRepositoryMock{}, &mocks.TagRepositoryMock{}, publisher)
+}
+
+func TestImportService_DryRun(t *testing.T) {
+	auditRepo := &mocks.AuditRepositoryMock{}
+	importService := newImportService(auditRepo, events.NewBus(0))
+
+	file := "(A) Call mom +list1 @phone due:2024-05-01\n" +
+		"x 2024-04-02 2024-04-01 Pay rent +list1 @Tag1\n" +
+		"Buy milk @errands\n" +
+		"+list1 @phone\n"
+	report, err := importService.Import(context.Background(), 1, importer.TodoTxt, strings.NewReader(file), importer.Options{DefaultList: "Inbox"}, true)
+	assert.NoError(t, err)
+	assert.True(t, report.DryRun)
+	assert.False(t, report.Committed)
+	assert.Equal(t, 3, report.Items)
+	assert.Equal(t, []models.ImportList{
+		{ID: 1, Name: "list1", Items: 2},
+		{Name: "Inbox", New: true, Items: 1},
+	}, report.Lists)
+	assert.Equal(t, []string{"priority:A", "phone", "Tag1", "errands"}, report.Tags)
+	assert.Equal(t, []string{"priority:A", "phone", "errands"}, report.NewTags)
+	assert.Equal(t, []models.ImportError{{Line: 4, Error: "missing title"}}, report.Errors)
+	assert.Empty(t, auditRepo.Events)
+}
+
+func TestImportService_Commit(t *testing.T) {
+	auditRepo := &mocks.AuditRepositoryMock{}
+	bus := events.NewBus(10)
+	sub, _, _ := bus.Subscribe(1, 0)
+	defer sub.Close()
+	importService := newImportService(auditRepo, bus)
+
+	file := "list,title,done,labels\n" +
+		"list1,Milk,no,work;Work\n" +
+		",Bread,yes,\n"
+	options := importer.Options{DefaultList: "Inbox", Columns: map[string]string{"completed": "done", "tags": "labels"}}
+	created := testutil.ToFloat64(metrics.TodosCreated)
+	report, err := importService.Import(context.Background(), 1, importer.CSV, strings.NewReader(file), options, false)
+	assert.NoError(t, err)
+	assert.True(t, report.Committed)
+	assert.Equal(t, created+2, testutil.ToFloat64(metrics.TodosCreated))
+	assert.Equal(t, []models.ImportList{
+		{ID: 1, Name: "list1", Items: 1},
+		{ID: 1, Name: "Inbox", New: true, Items: 1},
+	}, report.Lists)
+	assert.Equal(t, []string{"work"}, report.NewTags)
+
+	actions := []string{}
+	for _, event := range auditRepo.Events {
+		actions = append(actions, event.ResourceType+" "+event.Action)
+	}
+	assert.Equal(t, []string{"todo_list create", "todo_item create", "tag attach", "todo_item create"}, actions)
+
+	// the events are published once the transaction is committed
+	eventTypes := []string{}
+	for len(sub.C) > 0 {
+		eventTypes = append(eventTypes, (<-sub.C).Type)
+	}
+	assert.Equal(t, []string{models.EventTodoListCreated, models.EventTodoItemCreated, models.EventTodoItemUpdated, models.EventTodoItemCreated}, eventTypes)
+}
+
+func TestImportService_Errors(t *testing.T) {
+	auditRepo := &mocks.AuditRepositoryMock{}
+	importService := newImportService(auditRepo, events.NewBus(0))
+	options := importer.Options{DefaultList: "Inbox"}
+
+	report, err := importService.Import(context.Background(), 1, importer.JSON, strings.NewReader(`{"Lists":[{"Name":"list1","Items":[{"Title":"ok"},{"Title":" "}]}]}`), options, false)
+	assert.ErrorIs(t, err, services.ErrInvalidImport)
+	assert.False(t, report.Committed)
+	assert.Equal(t, []models.ImportError{{Line: 2, Error: "missing title"}}, report.Errors)
+	assert.Empty(t, auditRepo.Events)
+
+	_, err = importService.Import(context.Background(), 1, importer.TodoTxt, strings.NewReader(strings.Repeat("item\n", services.MaxImportItems+1)), options, true)
+	assert.ErrorIs(t, err, services.ErrInvalidImport)
+
+	_, err = importService.Import(context.Background(), 1, "xml", strings.NewReader(""), options, true)
+	assert.ErrorIs(t, err, importer.ErrUnknownFormat)
+
+	_, err = importService.Import(context.Background(), 1, importer.CSV, strings.NewReader("name\nmilk\n"), options, true)
+	assert.ErrorIs(t, err, services.ErrInvalidImport)
+
+	_, err = importService.Import(context.Background(), 2, importer.TodoTxt, strings.NewReader("item\n"), options, true)
+	assert.Error(t, err)
+	_, err = importService.Import(auth.NewContext(context.Background(), 2), 1, importer.TodoTxt, strings.NewReader("item\n"), options, false)
+	assert.ErrorIs(t, err, services.ErrForbidden)
+
+	// a failing commit is rolled back
+	report, err = importService.Import(context.Background(), 1, importer.TodoTxt, strings.NewReader("item +list2\n"), options, false)
+	assert.Error(t, err)
+	assert.False(t, report.Committed)
+}
+
+// failingCommitTransactor runs the transaction and fails to commit it
+type failingCommitTransactor struct {
+	mocks.TransactorMock
+}
+
+func (s *failingCommitTransactor) Transaction(ctx context.Context, fn func(repos repos.Repositories) error) error {
+	if err := s.TransactorMock.Transaction(ctx, fn); err != nil {
+		return err
+	}
+	return errors.New("commit failed")
+}
+
+func TestImportService_FailedCommit(t *testing.T) {
+	bus := events.NewBus(10)
+	sub, _, _ := bus.Subscribe(1, 0)
+	defer sub.Close()
+	importService := newImportService(&mocks.AuditRepositoryMock{}, bus)
+	importService.Transactor = &failingCommitTransactor{*importService.Transactor.(*mocks.TransactorMock)}
+
+	// neither the events nor the metrics of the rolled back changes are applied
+	created := testutil.ToFloat64(metrics.TodosCreated)
+	report, err := importService.Import(context.Background(), 1, importer.TodoTxt, strings.NewReader("Milk +list1\nBread +list1\n"), importer.Options{DefaultList: "Inbox"}, false)
+	assert.Error(t, err)
+	assert.False(t, report.Committed)
+	assert.Equal(t, created, testutil.ToFloat64(metrics.TodosCreated))
+	assert.Empty(t, sub.C)
+}
+
+func TestImportService_TodoTxtDue(t *testing.T) {
+	auditRepo := &mocks.AuditRepositoryMock{}
+	importService := newImportService(auditRepo, events.NewBus(0))
+
+	file := "Call mom +list1 due:2024-05-01 due:soon\n"
+	_, err := importService.Import(context.Background(), 1, importer.TodoTxt, strings.NewReader(file), importer.Options{DefaultList: "Inbox"}, false)
+	assert.NoError(t, err)
+	if assert.Len(t, auditRepo.Events, 1) {
+		changes := decodeChanges(t, auditRepo.Events[0])
+		assert.Equal(t, models.AuditChange{After: "Call mom"}, changes["Title"])
+		assert.Equal(t, models.AuditChange{After: "2024-05-01T00:00:00Z"}, changes["Due"])
+		// values that are no date stay in the description
+		assert.Equal(t, models.AuditChange{After: "due: soon"}, changes["Description"])
+	}
+}
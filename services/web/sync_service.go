@@ -0,0 +1,182 @@
+package webservices
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/danikg/go-todo-rest-api/models"
+	repos "github.com/danikg/go-todo-rest-api/repositories"
+	"github.com/danikg/go-todo-rest-api/services"
+	"github.com/danikg/go-todo-rest-api/utils/events"
+	"github.com/danikg/go-todo-rest-api/utils/ical"
+	"github.com/danikg/go-todo-rest-api/utils/metrics"
+	"github.com/danikg/go-todo-rest-api/utils/tracing"
+)
+
+// syncEventTypes maps the type and op of an applied sync change to its change event,
+// tag changes are not streamed
+var syncEventTypes = map[string]map[string]string{
+	models.AuditTodoList: {
+		models.SyncCreate: models.EventTodoListCreated,
+		models.SyncUpdate: models.EventTodoListUpdated,
+		models.SyncDelete: models.EventTodoListDeleted,
+	},
+	models.AuditTodoItem: {
+		models.SyncCreate: models.EventTodoItemCreated,
+		models.SyncUpdate: models.EventTodoItemUpdated,
+		models.SyncDelete: models.EventTodoItemDeleted,
+	},
+}
+
+// SyncService ...
+type SyncService struct {
+	SyncRepo  repos.ISyncRepository
+	AuditRepo repos.IAuditRepository
+	Publisher events.Publisher
+}
+
+// NewSyncService ...
+func NewSyncService(syncRepo repos.ISyncRepository, auditRepo repos.IAuditRepository, publisher events.Publisher) *SyncService {
+	return &SyncService{
+		SyncRepo:  syncRepo,
+		AuditRepo: auditRepo,
+		Publisher: publisher,
+	}
+}
+
+// Changes returns the records of the user changed or deleted after the cursor since
+func (t *SyncService) Changes(ctx context.Context, userID uint, since uint64) (models.SyncChanges, error) {
+	ctx, span := tracing.Start(ctx, "SyncService.Changes")
+	defer span.End()
+
+	return t.SyncRepo.Changes(ctx, userID, since)
+}
+
+// Push validates the client changes and applies them, every applied change is audited and published
+func (t *SyncService) Push(ctx context.Context, userID uint, push *models.SyncPush) (models.SyncReport, error) {
+	ctx, span := tracing.Start(ctx, "SyncService.Push")
+	defer span.End()
+
+	if err := validateSyncPush(push); err != nil {
+		return models.SyncReport{}, err
+	}
+
+	report, err := t.SyncRepo.Push(ctx, userID, push.Changes)
+	if err != nil {
+		return report, err
+	}
+
+	for i, result := range report.Results {
+		if result.Status == models.SyncApplied {
+			t.auditSync(ctx, userID, push.Changes[i], result)
+		}
+	}
+	return report, nil
+}
+
+// auditSync records and publishes an applied change, deletions of records that were already gone are skipped
+func (t *SyncService) auditSync(ctx context.Context, userID uint, change models.SyncChange, result models.SyncResult) {
+	before, after := result.Before, result.Record
+	if change.Op == models.SyncDelete {
+		after = nil
+	}
+	if before == nil && after == nil {
+		return
+	}
+
+	// the sync ops are named like the audit actions
+	action := change.Op
+	switch record := syncRecord(before, after).(type) {
+	case models.TodoList:
+		recordAudit(ctx, t.AuditRepo, todoListAuditEvent(action, &record), before, after)
+		publishChange(t.Publisher, syncEventTypes[change.Type][change.Op], userID, record.ID, record)
+
+	case models.TodoItem:
+		if change.Op == models.SyncCreate {
+			metrics.TodosCreated.Inc()
+		}
+		if previous, ok := before.(models.TodoItem); ok && !previous.Completed && record.Completed && after != nil {
+			metrics.TodosCompleted.Inc()
+		}
+		recordAudit(ctx, t.AuditRepo, todoItemAuditEvent(action, record.ID, record.TodoList), before, after)
+		publishChange(t.Publisher, syncEventTypes[change.Type][change.Op], userID, record.TodoListID, record)
+
+	case models.Tag:
+		recordAudit(ctx, t.AuditRepo, tagAuditEvent(action, record.ID, userID), before, after)
+	}
+}
+
+// syncRecord returns the record after the change, or before it for deletions
+func syncRecord(before, after interface{}) interface{} {
+	if after != nil {
+		return after
+	}
+	return before
+}
+
+// validateSyncPush checks that every change carries the fields its type and op need
+func validateSyncPush(push *models.SyncPush) error {
+	if len(push.Changes) == 0 {
+		return fmt.Errorf("%w: no changes", services.ErrInvalidSyncChange)
+	}
+	if len(push.Changes) > services.MaxSyncChanges {
+		return fmt.Errorf("%w: more than %d changes", services.ErrInvalidSyncChange, services.MaxSyncChanges)
+	}
+
+	for i, change := range push.Changes {
+		if problem := syncChangeProblem(change); problem != "" {
+			return fmt.Errorf("%w: change %d: %s", services.ErrInvalidSyncChange, i, problem)
+		}
+	}
+	return nil
+}
+
+func syncChangeProblem(change models.SyncChange) string {
+	switch change.Op {
+	case models.SyncCreate:
+	case models.SyncUpdate, models.SyncDelete:
+		if change.ID == 0 {
+			return "missing ID"
+		}
+	default:
+		return fmt.Sprintf("unknown op %q", change.Op)
+	}
+
+	if _, ok := syncEventTypes[change.Type]; !ok && change.Type != models.AuditTag {
+		return fmt.Sprintf("unknown type %q", change.Type)
+	}
+	if change.Op == models.SyncDelete {
+		return ""
+	}
+
+	switch change.Type {
+	case models.AuditTodoList:
+		if change.TodoList == nil {
+			return "missing TodoList"
+		}
+	case models.AuditTodoItem:
+		if change.TodoItem == nil {
+			return "missing TodoItem"
+		}
+		if change.Op == models.SyncCreate && change.TodoItem.TodoListID == 0 {
+			return "missing TodoItem.TodoListID"
+		}
+		// updates may rely on the stored due date
+		if rule := change.TodoItem.Recurrence; rule != "" {
+			if change.Op == models.SyncCreate && change.TodoItem.Due == nil {
+				return "Recurrence without Due"
+			}
+			if err := ical.ValidateRecurrence(rule); err != nil {
+				return "invalid Recurrence: " + err.Error()
+			}
+		}
+	case models.AuditTag:
+		if change.Tag == nil {
+			return "missing Tag"
+		}
+		if err := validateTag(change.Tag); err != nil {
+			return err.Error()
+		}
+	}
+	return ""
+}
@@ -0,0 +1,96 @@
+package webservices
+
+import (
+	"context"
+	"testing"
+
+	"github.com/danikg/go-todo-rest-api/models"
+	"github.com/danikg/go-todo-rest-api/repositories/mocks"
+	"github.com/danikg/go-todo-rest-api/services"
+	"github.com/danikg/go-todo-rest-api/utils/events"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyncService_Changes(t *testing.T) {
+	syncService := NewSyncService(&mocks.SyncRepositoryMock{}, &mocks.AuditRepositoryMock{}, events.NewBus(0))
+
+	changes, err := syncService.Changes(context.Background(), 1, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), changes.Cursor)
+	assert.Len(t, changes.TodoLists, 1)
+	assert.Len(t, changes.TodoItems, 1)
+	assert.Len(t, changes.Tags, 1)
+	assert.Empty(t, changes.Deleted)
+
+	changes, err = syncService.Changes(context.Background(), 1, 1)
+	assert.NoError(t, err)
+	assert.Empty(t, changes.TodoItems)
+	assert.Len(t, changes.Deleted, 1)
+
+	_, err = syncService.Changes(context.Background(), 2, 0)
+	assert.Error(t, err)
+}
+
+func TestSyncService_Push(t *testing.T) {
+	auditRepo := &mocks.AuditRepositoryMock{}
+	bus := events.NewBus(10)
+	sub, _, _ := bus.Subscribe(1, 0)
+	defer sub.Close()
+	syncService := NewSyncService(&mocks.SyncRepositoryMock{}, auditRepo, bus)
+
+	report, err := syncService.Push(context.Background(), 1, &models.SyncPush{Changes: []models.SyncChange{
+		{Op: models.SyncCreate, Type: models.AuditTodoItem, ClientID: "a", TodoItem: &models.TodoItem{Title: "new", TodoListID: 1}},
+		{Op: models.SyncUpdate, Type: models.AuditTodoList, ID: 1, BaseVersion: 1, TodoList: &models.TodoList{Name: "renamed"}},
+		{Op: models.SyncUpdate, Type: models.AuditTodoItem, ID: 1, BaseVersion: 3, TodoItem: &models.TodoItem{Title: "stale"}},
+		{Op: models.SyncDelete, Type: models.AuditTag, ID: 1, BaseVersion: 1},
+		{Op: models.SyncDelete, Type: models.AuditTodoItem, ID: 2, BaseVersion: 1},
+	}})
+	assert.NoError(t, err)
+
+	statuses := []string{}
+	for _, result := range report.Results {
+		statuses = append(statuses, result.Status)
+	}
+	assert.Equal(t, []string{models.SyncApplied, models.SyncApplied, models.SyncConflict, models.SyncApplied, models.SyncError}, statuses)
+	assert.Equal(t, "a", report.Results[0].ClientID)
+	assert.NotNil(t, report.Results[2].Record)
+
+	actions := []string{}
+	for _, event := range auditRepo.Events {
+		actions = append(actions, event.ResourceType+" "+event.Action)
+	}
+	assert.Equal(t, []string{"todo_item create", "todo_list update", "tag delete"}, actions)
+	assert.JSONEq(t, `{"Name":{"Before":"list","After":"renamed"}}`, string(auditRepo.Events[1].Changes))
+
+	for _, eventType := range []string{models.EventTodoItemCreated, models.EventTodoListUpdated} {
+		event := <-sub.C
+		assert.Equal(t, eventType, event.Type)
+		assert.Equal(t, uint(1), event.TodoListID)
+	}
+	assert.Empty(t, sub.C)
+}
+
+func TestSyncService_PushValidation(t *testing.T) {
+	syncService := NewSyncService(&mocks.SyncRepositoryMock{}, &mocks.AuditRepositoryMock{}, events.NewBus(0))
+
+	invalid := [][]models.SyncChange{
+		{},
+		make([]models.SyncChange, services.MaxSyncChanges+1),
+		{{Op: "archive", Type: models.AuditTodoList, ID: 1}},
+		{{Op: models.SyncDelete, Type: "user", ID: 1}},
+		{{Op: models.SyncUpdate, Type: models.AuditTodoList, TodoList: &models.TodoList{Name: "list"}}},
+		{{Op: models.SyncCreate, Type: models.AuditTodoList}},
+		{{Op: models.SyncCreate, Type: models.AuditTodoItem, TodoItem: &models.TodoItem{Title: "item"}}},
+		{{Op: models.SyncCreate, Type: models.AuditTag, Tag: &models.Tag{Text: " "}}},
+		{{Op: models.SyncUpdate, Type: models.AuditTag, ID: 1, Tag: &models.Tag{Text: "tag", Color: "red"}}},
+	}
+	for _, changes := range invalid {
+		_, err := syncService.Push(context.Background(), 1, &models.SyncPush{Changes: changes})
+		assert.ErrorIs(t, err, services.ErrInvalidSyncChange)
+	}
+
+	_, err := syncService.Push(context.Background(), 1, &models.SyncPush{Changes: []models.SyncChange{
+		{Op: models.SyncDelete, Type: models.AuditTodoList, ID: 1, BaseVersion: 1},
+	}})
+	assert.NoError(t, err)
+}
@@ -1,62 +1,273 @@
 package webservices
 
 import (
+	"context"
+	"regexp"
+
 	"github.com/danikg/go-todo-rest-api/models"
 	repos "github.com/danikg/go-todo-rest-api/repositories"
+	"github.com/danikg/go-todo-rest-api/services"
+	"github.com/danikg/go-todo-rest-api/utils/events"
+	"github.com/danikg/go-todo-rest-api/utils/tracing"
 )
 
 // TagService ...
 type TagService struct {
 	TagRepo      repos.ITagRepository
 	TodoItemRepo repos.ITodoItemRepository
+	UserRepo     repos.IUserRepository
+	AuditRepo    repos.IAuditRepository
+	Publisher    events.Publisher
 }
 
 // NewTagService ...
-func NewTagService(tagRepo repos.ITagRepository, todoItemRepo repos.ITodoItemRepository) *TagService {
+func NewTagService(tagRepo repos.ITagRepository, todoItemRepo repos.ITodoItemRepository, userRepo repos.IUserRepository, auditRepo repos.IAuditRepository, publisher events.Publisher) *TagService {
 	return &TagService{
 		TagRepo:      tagRepo,
 		TodoItemRepo: todoItemRepo,
+		UserRepo:     userRepo,
+		AuditRepo:    auditRepo,
+		Publisher:    publisher,
 	}
 }
 
 // GetAll returns all tags by todo item id
-func (t *TagService) GetAll(itemID uint) ([]models.Tag, error) {
-	todoItem, err := t.TodoItemRepo.GetSingle(itemID)
+func (t *TagService) GetAll(ctx context.Context, itemID uint) ([]models.Tag, error) {
+	ctx, span := tracing.Start(ctx, "TagService.GetAll")
+	defer span.End()
+
+	todoItem, err := t.TodoItemRepo.GetSingle(ctx, itemID)
 	if err != nil {
 		return []models.Tag{}, err
 	}
-	return t.TagRepo.GetAll(&todoItem)
+	if err = authorize(ctx, todoItem.TodoList.UserID); err != nil {
+		return []models.Tag{}, err
+	}
+	return t.TagRepo.GetAll(ctx, &todoItem)
+}
+
+// GetAllByUser returns the tag vocabulary of the user with usage counts
+func (t *TagService) GetAllByUser(ctx context.Context, userID uint) ([]models.TagUsage, error) {
+	ctx, span := tracing.Start(ctx, "TagService.GetAllByUser")
+	defer span.End()
+
+	if err := authorize(ctx, userID); err != nil {
+		return []models.TagUsage{}, err
+	}
+	user, err := t.UserRepo.GetSingle(ctx, userID)
+	if err != nil {
+		return []models.TagUsage{}, err
+	}
+	return t.TagRepo.GetAllByUser(ctx, user.ID)
 }
 
 // GetSingle returns a tag by id
-func (t *TagService) GetSingle(id uint) (models.Tag, error) {
-	return t.TagRepo.GetSingle(id)
+func (t *TagService) GetSingle(ctx context.Context, id uint) (models.Tag, error) {
+	ctx, span := tracing.Start(ctx, "TagService.GetSingle")
+	defer span.End()
+
+	return t.getOwned(ctx, id)
+}
+
+// GetTodoItems returns the todo items the tag is attached to
+func (t *TagService) GetTodoItems(ctx context.Context, tagID uint, page models.Page) ([]models.TodoItem, error) {
+	ctx, span := tracing.Start(ctx, "TagService.GetTodoItems")
+	defer span.End()
+
+	tag, err := t.getOwned(ctx, tagID)
+	if err != nil {
+		return []models.TodoItem{}, err
+	}
+	return t.TagRepo.GetTodoItems(ctx, tag.ID, page)
+}
+
+// GetTodoItemsByUser returns the todo items across all lists of the user matching the tag filter
+func (t *TagService) GetTodoItemsByUser(ctx context.Context, userID uint, filter models.TagFilter, page models.Page) ([]models.TodoItem, error) {
+	ctx, span := tracing.Start(ctx, "TagService.GetTodoItemsByUser")
+	defer span.End()
+
+	if err := authorize(ctx, userID); err != nil {
+		return []models.TodoItem{}, err
+	}
+	user, err := t.UserRepo.GetSingle(ctx, userID)
+	if err != nil {
+		return []models.TodoItem{}, err
+	}
+	return t.TodoItemRepo.GetAllByTags(ctx, user.ID, filter, page)
 }
 
-// Create creates a new tag
-func (t *TagService) Create(itemID uint, tag *models.Tag) error {
-	todoItem, err := t.TodoItemRepo.GetSingle(itemID)
+// Create attaches a tag to the todo item, reusing the owner's tag with the same text
+func (t *TagService) Create(ctx context.Context, itemID uint, tag *models.Tag) error {
+	ctx, span := tracing.Start(ctx, "TagService.Create")
+	defer span.End()
+
+	if err := validateTag(tag); err != nil {
+		return err
+	}
+
+	todoItem, err := t.TodoItemRepo.GetSingle(ctx, itemID)
 	if err != nil {
 		return err
 	}
-	return t.TagRepo.Create(&todoItem, tag)
+	if err = authorize(ctx, todoItem.TodoList.UserID); err != nil {
+		return err
+	}
+
+	if err = t.TagRepo.Create(ctx, &todoItem, tag); err != nil {
+		return err
+	}
+
+	event := tagAuditEvent(models.AuditAttach, tag.ID, todoItem.TodoList.UserID)
+	event.TodoListID = auditListID(todoItem.TodoListID)
+	recordAudit(ctx, t.AuditRepo, event, nil, map[string]interface{}{"TodoItemID": todoItem.ID, "Text": tag.Text})
+	t.publishTagsChanged(ctx, todoItem.ID)
+	return nil
 }
 
-// Update updates the tag
-func (t *TagService) Update(id uint, tagData *models.Tag) (models.Tag, error) {
-	return t.TagRepo.Update(id, tagData)
+// CreateForUser adds a tag to the user's vocabulary, returning the existing one with the same text
+func (t *TagService) CreateForUser(ctx context.Context, userID uint, tag *models.Tag) error {
+	ctx, span := tracing.Start(ctx, "TagService.CreateForUser")
+	defer span.End()
+
+	if err := validateTag(tag); err != nil {
+		return err
+	}
+	if err := authorize(ctx, userID); err != nil {
+		return err
+	}
+
+	user, err := t.UserRepo.GetSingle(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err = t.TagRepo.FindOrCreate(ctx, user.ID, tag); err != nil {
+		return err
+	}
+
+	recordAudit(ctx, t.AuditRepo, tagAuditEvent(models.AuditCreate, tag.ID, user.ID), nil, tag)
+	return nil
+}
+
+// Update replaces the text, color and parent of the tag for every todo item using it,
+// an empty color or a nil parent clear them
+func (t *TagService) Update(ctx context.Context, id uint, tagData *models.Tag) (models.Tag, error) {
+	ctx, span := tracing.Start(ctx, "TagService.Update")
+	defer span.End()
+
+	if err := validateTag(tagData); err != nil {
+		return models.Tag{}, err
+	}
+
+	before, err := t.getOwned(ctx, id)
+	if err != nil {
+		return before, err
+	}
+
+	tag, err := t.TagRepo.Update(ctx, id, tagData)
+	if err != nil {
+		return tag, err
+	}
+
+	recordAudit(ctx, t.AuditRepo, tagAuditEvent(models.AuditUpdate, id, before.UserID), before, tag)
+	return tag, nil
+}
+
+// Merge moves every todo item and child tag of the tag to the target tag and deletes the tag
+func (t *TagService) Merge(ctx context.Context, id uint, targetID uint) (models.Tag, error) {
+	ctx, span := tracing.Start(ctx, "TagService.Merge")
+	defer span.End()
+
+	before, err := t.getOwned(ctx, id)
+	if err != nil {
+		return before, err
+	}
+
+	// the repository refuses targets of another user
+	target, err := t.TagRepo.Merge(ctx, id, targetID)
+	if err != nil {
+		return target, err
+	}
+
+	recordAudit(ctx, t.AuditRepo, tagAuditEvent(models.AuditMerge, id, before.UserID), before, map[string]interface{}{"MergedInto": target.ID})
+	return target, nil
 }
 
 // Remove removes the tag from the todo item
-func (t *TagService) Remove(itemID uint, tagID uint) error {
-	todoItem, err := t.TodoItemRepo.GetSingle(itemID)
+func (t *TagService) Remove(ctx context.Context, itemID uint, tagID uint) error {
+	ctx, span := tracing.Start(ctx, "TagService.Remove")
+	defer span.End()
+
+	todoItem, err := t.TodoItemRepo.GetSingle(ctx, itemID)
 	if err != nil {
 		return err
 	}
-	return t.TagRepo.Remove(&todoItem, tagID)
+	if err = authorize(ctx, todoItem.TodoList.UserID); err != nil {
+		return err
+	}
+
+	if err = t.TagRepo.Remove(ctx, &todoItem, tagID); err != nil {
+		return err
+	}
+
+	event := tagAuditEvent(models.AuditDetach, tagID, todoItem.TodoList.UserID)
+	event.TodoListID = auditListID(todoItem.TodoListID)
+	recordAudit(ctx, t.AuditRepo, event, map[string]interface{}{"TodoItemID": todoItem.ID}, nil)
+	t.publishTagsChanged(ctx, todoItem.ID)
+	return nil
 }
 
 // Delete removes the tag from the db
-func (t *TagService) Delete(id uint) error {
-	return t.TagRepo.Delete(id)
+func (t *TagService) Delete(ctx context.Context, id uint) error {
+	ctx, span := tracing.Start(ctx, "TagService.Delete")
+	defer span.End()
+
+	before, err := t.getOwned(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err = t.TagRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	recordAudit(ctx, t.AuditRepo, tagAuditEvent(models.AuditDelete, id, before.UserID), before, nil)
+	return nil
+}
+
+// getOwned returns the tag when it belongs to the authenticated user
+func (t *TagService) getOwned(ctx context.Context, id uint) (models.Tag, error) {
+	tag, err := t.TagRepo.GetSingle(ctx, id)
+	if err != nil {
+		return tag, err
+	}
+	if err = authorize(ctx, tag.UserID); err != nil {
+		return models.Tag{}, err
+	}
+	return tag, nil
+}
+
+// publishTagsChanged publishes the todo item with its new tags
+func (t *TagService) publishTagsChanged(ctx context.Context, itemID uint) {
+	if todoItem, err := t.TodoItemRepo.GetSingle(ctx, itemID); err == nil {
+		publishChange(t.Publisher, models.EventTodoItemUpdated, todoItem.TodoList.UserID, todoItem.TodoListID, todoItem)
+	}
+}
+
+func tagAuditEvent(action string, id uint, userID uint) models.AuditEvent {
+	return models.AuditEvent{Action: action, ResourceType: models.AuditTag, ResourceID: id, UserID: userID}
+}
+
+// tagColor matches the accepted tag colors
+var tagColor = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// validateTag checks the user supplied fields of the tag
+func validateTag(tag *models.Tag) error {
+	if models.NormalizeTagText(tag.Text) == "" {
+		return services.ErrEmptyTag
+	}
+	if tag.Color != "" && !tagColor.MatchString(tag.Color) {
+		return services.ErrInvalidTagColor
+	}
+	return nil
 }
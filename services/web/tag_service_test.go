@@ -1,71 +1,174 @@
 package webservices
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 
 	"github.com/danikg/go-todo-rest-api/models"
+	"github.com/danikg/go-todo-rest-api/repositories"
 	"github.com/danikg/go-todo-rest-api/repositories/mocks"
+	"github.com/danikg/go-todo-rest-api/services"
+	"github.com/danikg/go-todo-rest-api/utils/auth"
+	"github.com/danikg/go-todo-rest-api/utils/events"
 )
 
 func TestTagService_GetAll(t *testing.T) {
-	tagService := NewTagService(&mocks.TagRepositoryMock{}, &mocks.TodoItemRepositoryMock{})
-	tags, err := tagService.GetAll(1)
+	tagService := NewTagService(&mocks.TagRepositoryMock{}, &mocks.TodoItemRepositoryMock{}, &mocks.UserRepositoryMock{}, &mocks.AuditRepositoryMock{}, events.NewBus(0))
+	tags, err := tagService.GetAll(context.Background(), 1)
 	assert.NoError(t, err)
 	assert.NotEmpty(t, tags)
 
-	tags, err = tagService.GetAll(2)
+	tags, err = tagService.GetAll(context.Background(), 2)
 	assert.Error(t, err)
 	assert.Empty(t, tags)
 }
 
 func TestTagService_GetSingle(t *testing.T) {
-	tagService := NewTagService(&mocks.TagRepositoryMock{}, &mocks.TodoItemRepositoryMock{})
-	tag, err := tagService.GetSingle(1)
+	tagService := NewTagService(&mocks.TagRepositoryMock{}, &mocks.TodoItemRepositoryMock{}, &mocks.UserRepositoryMock{}, &mocks.AuditRepositoryMock{}, events.NewBus(0))
+	tag, err := tagService.GetSingle(context.Background(), 1)
 	assert.NoError(t, err)
 	assert.NotEmpty(t, tag)
 
-	tag, err = tagService.GetSingle(2)
+	tag, err = tagService.GetSingle(context.Background(), 2)
 	assert.Error(t, err)
 	assert.Empty(t, tag)
 }
 
 func TestTagService_Create(t *testing.T) {
-	tagService := NewTagService(&mocks.TagRepositoryMock{}, &mocks.TodoItemRepositoryMock{})
+	tagService := NewTagService(&mocks.TagRepositoryMock{}, &mocks.TodoItemRepositoryMock{}, &mocks.UserRepositoryMock{}, &mocks.AuditRepositoryMock{}, events.NewBus(0))
 	tag := models.Tag{Text: "tag"}
 	tag.ID = 1
 
-	err := tagService.Create(1, &tag)
+	err := tagService.Create(context.Background(), 1, &tag)
 	assert.NoError(t, err)
 
-	err = tagService.Create(2, &tag)
+	err = tagService.Create(context.Background(), 2, &tag)
 	assert.Error(t, err)
 }
 
 func TestTagService_Update(t *testing.T) {
-	tagService := NewTagService(&mocks.TagRepositoryMock{}, &mocks.TodoItemRepositoryMock{})
+	tagService := NewTagService(&mocks.TagRepositoryMock{}, &mocks.TodoItemRepositoryMock{}, &mocks.UserRepositoryMock{}, &mocks.AuditRepositoryMock{}, events.NewBus(0))
 	tag := models.Tag{Text: "tag"}
 	tag.ID = 1
 
-	resultTag, err := tagService.Update(1, &tag)
+	resultTag, err := tagService.Update(context.Background(), 1, &tag)
 	assert.NoError(t, err)
 	assert.NotEmpty(t, resultTag)
 
-	resultTag, err = tagService.Update(2, &tag)
+	resultTag, err = tagService.Update(context.Background(), 2, &tag)
 	assert.Error(t, err)
 	assert.Empty(t, &resultTag)
 }
 
 func TestTagService_Remove(t *testing.T) {
-	tagService := NewTagService(&mocks.TagRepositoryMock{}, &mocks.TodoItemRepositoryMock{})
-	assert.NoError(t, tagService.Remove(1, 1))
-	assert.Error(t, tagService.Remove(2, 1))
-	assert.Error(t, tagService.Remove(1, 2))
+	tagService := NewTagService(&mocks.TagRepositoryMock{}, &mocks.TodoItemRepositoryMock{}, &mocks.UserRepositoryMock{}, &mocks.AuditRepositoryMock{}, events.NewBus(0))
+	assert.NoError(t, tagService.Remove(context.Background(), 1, 1))
+	assert.Error(t, tagService.Remove(context.Background(), 2, 1))
+	assert.Error(t, tagService.Remove(context.Background(), 1, 2))
 }
 
 func TestTagService_Delete(t *testing.T) {
-	tagService := NewTagService(&mocks.TagRepositoryMock{}, &mocks.TodoItemRepositoryMock{})
-	assert.NoError(t, tagService.Delete(1))
-	assert.Error(t, tagService.Delete(2))
+	tagService := NewTagService(&mocks.TagRepositoryMock{}, &mocks.TodoItemRepositoryMock{}, &mocks.UserRepositoryMock{}, &mocks.AuditRepositoryMock{}, events.NewBus(0))
+	assert.NoError(t, tagService.Delete(context.Background(), 1))
+	assert.Error(t, tagService.Delete(context.Background(), 2))
+}
+
+func TestTagService_GetAllByUser(t *testing.T) {
+	tagService := NewTagService(&mocks.TagRepositoryMock{}, &mocks.TodoItemRepositoryMock{}, &mocks.UserRepositoryMock{}, &mocks.AuditRepositoryMock{}, events.NewBus(0))
+	usages, err := tagService.GetAllByUser(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, usages)
+
+	usages, err = tagService.GetAllByUser(context.Background(), 2)
+	assert.Error(t, err)
+	assert.Empty(t, usages)
+}
+
+func TestTagService_CreateForUser(t *testing.T) {
+	tagService := NewTagService(&mocks.TagRepositoryMock{}, &mocks.TodoItemRepositoryMock{}, &mocks.UserRepositoryMock{}, &mocks.AuditRepositoryMock{}, events.NewBus(0))
+	tag := models.Tag{Text: " Urgent  Now "}
+
+	assert.NoError(t, tagService.CreateForUser(context.Background(), 1, &tag))
+	assert.Equal(t, "urgent now", tag.Normalized)
+	assert.Equal(t, uint(1), tag.UserID)
+
+	assert.Error(t, tagService.CreateForUser(context.Background(), 2, &models.Tag{Text: "tag"}))
+	assert.Equal(t, services.ErrEmptyTag, tagService.CreateForUser(context.Background(), 1, &models.Tag{Text: " "}))
+	assert.Equal(t, services.ErrEmptyTag, tagService.Create(context.Background(), 1, &models.Tag{}))
+}
+
+func TestTagService_GetTodoItems(t *testing.T) {
+	tagService := NewTagService(&mocks.TagRepositoryMock{}, &mocks.TodoItemRepositoryMock{}, &mocks.UserRepositoryMock{}, &mocks.AuditRepositoryMock{}, events.NewBus(0))
+	page := models.Page{Limit: models.DefaultPageLimit}
+
+	todoItems, err := tagService.GetTodoItems(context.Background(), 1, page)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, todoItems)
+
+	todoItems, err = tagService.GetTodoItems(context.Background(), 2, page)
+	assert.Error(t, err)
+	assert.Empty(t, todoItems)
+}
+
+func TestTagService_GetTodoItemsByUser(t *testing.T) {
+	tagService := NewTagService(&mocks.TagRepositoryMock{}, &mocks.TodoItemRepositoryMock{}, &mocks.UserRepositoryMock{}, &mocks.AuditRepositoryMock{}, events.NewBus(0))
+	filter := models.TagFilter{Tags: []string{"@phone", "@office"}, MatchAll: true}
+	page := models.Page{Limit: models.DefaultPageLimit}
+
+	todoItems, err := tagService.GetTodoItemsByUser(context.Background(), 1, filter, page)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, todoItems)
+
+	todoItems, err = tagService.GetTodoItemsByUser(context.Background(), 2, filter, page)
+	assert.Error(t, err)
+	assert.Empty(t, todoItems)
+}
+
+func TestTagService_Color(t *testing.T) {
+	tagService := NewTagService(&mocks.TagRepositoryMock{}, &mocks.TodoItemRepositoryMock{}, &mocks.UserRepositoryMock{}, &mocks.AuditRepositoryMock{}, events.NewBus(0))
+
+	assert.NoError(t, tagService.CreateForUser(context.Background(), 1, &models.Tag{Text: "work", Color: "#1E90ff"}))
+	assert.NoError(t, tagService.CreateForUser(context.Background(), 1, &models.Tag{Text: "home"}))
+	assert.Equal(t, services.ErrInvalidTagColor, tagService.CreateForUser(context.Background(), 1, &models.Tag{Text: "work", Color: "blue"}))
+	assert.Equal(t, services.ErrInvalidTagColor, tagService.Create(context.Background(), 1, &models.Tag{Text: "work", Color: "#12345"}))
+
+	_, err := tagService.Update(context.Background(), 1, &models.Tag{Text: "work", Color: "#12345g"})
+	assert.Equal(t, services.ErrInvalidTagColor, err)
+}
+
+func TestTagService_Merge(t *testing.T) {
+	tagService := NewTagService(&mocks.TagRepositoryMock{}, &mocks.TodoItemRepositoryMock{}, &mocks.UserRepositoryMock{}, &mocks.AuditRepositoryMock{}, events.NewBus(0))
+	tag, err := tagService.Merge(context.Background(), 1, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(2), tag.ID)
+
+	_, err = tagService.Merge(context.Background(), 1, 1)
+	assert.Equal(t, repositories.ErrInvalidTagMerge, err)
+
+	_, err = tagService.Merge(context.Background(), 2, 1)
+	assert.Error(t, err)
+}
+
+func TestTagService_Forbidden(t *testing.T) {
+	tagService := NewTagService(&mocks.TagRepositoryMock{}, &mocks.TodoItemRepositoryMock{}, &mocks.UserRepositoryMock{}, &mocks.AuditRepositoryMock{}, events.NewBus(0))
+	ctx := auth.NewContext(context.Background(), 2)
+
+	_, err := tagService.GetAll(ctx, 1)
+	assert.Equal(t, services.ErrForbidden, err)
+	assert.Equal(t, services.ErrForbidden, tagService.Create(ctx, 1, &models.Tag{Text: "tag2"}))
+	assert.Equal(t, services.ErrForbidden, tagService.Remove(ctx, 1, 1))
+	_, err = tagService.GetAllByUser(ctx, 1)
+	assert.Equal(t, services.ErrForbidden, err)
+	assert.Equal(t, services.ErrForbidden, tagService.CreateForUser(ctx, 1, &models.Tag{Text: "tag2"}))
+	_, err = tagService.GetTodoItemsByUser(ctx, 1, models.TagFilter{}, models.Page{Limit: 10})
+	assert.Equal(t, services.ErrForbidden, err)
+
+	// the tag of the mock belongs to no user
+	_, err = tagService.GetSingle(ctx, 1)
+	assert.Equal(t, services.ErrForbidden, err)
+	_, err = tagService.Merge(ctx, 1, 2)
+	assert.Equal(t, services.ErrForbidden, err)
+	assert.Equal(t, services.ErrForbidden, tagService.Delete(ctx, 1))
 }
@@ -1,49 +1,300 @@
 package webservices
 
 import (
+	"context"
+	"fmt"
+	"time"
+
 	"github.com/danikg/go-todo-rest-api/models"
 	repos "github.com/danikg/go-todo-rest-api/repositories"
+	"github.com/danikg/go-todo-rest-api/services"
+	"github.com/danikg/go-todo-rest-api/utils/events"
+	"github.com/danikg/go-todo-rest-api/utils/ical"
+	"github.com/danikg/go-todo-rest-api/utils/metrics"
+	"github.com/danikg/go-todo-rest-api/utils/tracing"
 )
 
 // TodoItemService ...
 type TodoItemService struct {
 	TodoItemRepo repos.ITodoItemRepository
 	TodoListRepo repos.ITodoListRepository
+	AuditRepo    repos.IAuditRepository
+	Publisher    events.Publisher
+	// Counters holds the metrics of changes made in a transaction, they are counted right away when nil
+	Counters *metrics.Buffer
 }
 
 // NewTodoItemService ...
-func NewTodoItemService(todoItemRepo repos.ITodoItemRepository, todoListRepo repos.ITodoListRepository) *TodoItemService {
+func NewTodoItemService(todoItemRepo repos.ITodoItemRepository, todoListRepo repos.ITodoListRepository, auditRepo repos.IAuditRepository, publisher events.Publisher) *TodoItemService {
 	return &TodoItemService{
 		TodoItemRepo: todoItemRepo,
 		TodoListRepo: todoListRepo,
+		AuditRepo:    auditRepo,
+		Publisher:    publisher,
 	}
 }
 
 // GetAll returns all todo items by todo list id
-func (t *TodoItemService) GetAll(listID uint) ([]models.TodoItem, error) {
-	todoList, err := t.TodoListRepo.GetSingle(listID)
+func (t *TodoItemService) GetAll(ctx context.Context, listID uint) ([]models.TodoItem, error) {
+	ctx, span := tracing.Start(ctx, "TodoItemService.GetAll")
+	defer span.End()
+
+	todoList, err := t.TodoListRepo.GetSingle(ctx, listID)
+	if err != nil {
+		return []models.TodoItem{}, err
+	}
+	if err = authorize(ctx, todoList.UserID); err != nil {
+		return []models.TodoItem{}, err
+	}
+	return t.TodoItemRepo.GetAll(ctx, todoList.ID)
+}
+
+// GetAllByLists returns the todo items of all the todo lists, unknown lists have none
+func (t *TodoItemService) GetAllByLists(ctx context.Context, listIDs []uint) ([]models.TodoItem, error) {
+	ctx, span := tracing.Start(ctx, "TodoItemService.GetAllByLists")
+	defer span.End()
+
+	todoItems, err := t.TodoItemRepo.GetAllByLists(ctx, listIDs)
 	if err != nil {
 		return []models.TodoItem{}, err
 	}
-	return t.TodoItemRepo.GetAll(todoList.ID)
+	// the owners are only known from the lists the items belong to
+	for _, todoItem := range todoItems {
+		if err = authorize(ctx, todoItem.TodoList.UserID); err != nil {
+			return []models.TodoItem{}, err
+		}
+	}
+	return todoItems, nil
 }
 
 // GetSingle returns a todo item by id
-func (t *TodoItemService) GetSingle(id uint) (models.TodoItem, error) {
-	return t.TodoItemRepo.GetSingle(id)
+func (t *TodoItemService) GetSingle(ctx context.Context, id uint) (models.TodoItem, error) {
+	ctx, span := tracing.Start(ctx, "TodoItemService.GetSingle")
+	defer span.End()
+
+	todoItem, err := t.TodoItemRepo.GetSingle(ctx, id)
+	if err != nil {
+		return todoItem, err
+	}
+	if err = authorize(ctx, todoItem.TodoList.UserID); err != nil {
+		return models.TodoItem{}, err
+	}
+	return todoItem, nil
 }
 
 // Create creates a new todo item
-func (t *TodoItemService) Create(listID uint, todoItem *models.TodoItem) error {
-	return t.TodoItemRepo.Create(listID, todoItem)
+func (t *TodoItemService) Create(ctx context.Context, listID uint, todoItem *models.TodoItem) error {
+	ctx, span := tracing.Start(ctx, "TodoItemService.Create")
+	defer span.End()
+
+	if err := validateRecurrence(todoItem.Recurrence, todoItem.Due); err != nil {
+		return err
+	}
+
+	todoList, err := t.TodoListRepo.GetSingle(ctx, listID)
+	if err != nil {
+		return err
+	}
+	if err = authorize(ctx, todoList.UserID); err != nil {
+		return err
+	}
+
+	if err = t.TodoItemRepo.Create(ctx, todoList.ID, todoItem); err != nil {
+		return err
+	}
+
+	t.Counters.Inc(metrics.TodosCreated)
+	recordAudit(ctx, t.AuditRepo, todoItemAuditEvent(models.AuditCreate, todoItem.ID, todoList), nil, todoItem)
+	publishChange(t.Publisher, models.EventTodoItemCreated, todoList.UserID, todoList.ID, todoItem)
+	return nil
 }
 
 // Update updates the todo item
-func (t *TodoItemService) Update(id uint, todoItemData *models.TodoItem) (models.TodoItem, error) {
-	return t.TodoItemRepo.Update(id, todoItemData)
+func (t *TodoItemService) Update(ctx context.Context, id uint, todoItemData *models.TodoItemUpdate) (models.TodoItem, error) {
+	ctx, span := tracing.Start(ctx, "TodoItemService.Update")
+	defer span.End()
+
+	before, err := t.TodoItemRepo.GetSingle(ctx, id)
+	if err != nil {
+		return before, err
+	}
+	if err = authorize(ctx, before.TodoList.UserID); err != nil {
+		return models.TodoItem{}, err
+	}
+
+	due := todoItemData.Due
+	if due == nil {
+		due = before.Due
+	}
+	if err = validateRecurrence(todoItemData.Recurrence, due); err != nil {
+		return before, err
+	}
+
+	todoItem, err := t.TodoItemRepo.Update(ctx, id, todoItemData)
+	if err != nil {
+		return todoItem, err
+	}
+
+	if !before.Completed && todoItem.Completed {
+		t.Counters.Inc(metrics.TodosCompleted)
+	}
+	recordAudit(ctx, t.AuditRepo, todoItemAuditEvent(models.AuditUpdate, id, before.TodoList), before, todoItem)
+	publishChange(t.Publisher, models.EventTodoItemUpdated, before.TodoList.UserID, before.TodoListID, todoItem)
+	return todoItem, nil
 }
 
 // Delete removes the todo item
-func (t *TodoItemService) Delete(id uint) error {
-	return t.TodoItemRepo.Delete(id)
+func (t *TodoItemService) Delete(ctx context.Context, id uint) error {
+	ctx, span := tracing.Start(ctx, "TodoItemService.Delete")
+	defer span.End()
+
+	before, err := t.TodoItemRepo.GetSingle(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err = authorize(ctx, before.TodoList.UserID); err != nil {
+		return err
+	}
+
+	if err = t.TodoItemRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	recordAudit(ctx, t.AuditRepo, todoItemAuditEvent(models.AuditDelete, id, before.TodoList), before, nil)
+	publishChange(t.Publisher, models.EventTodoItemDeleted, before.TodoList.UserID, before.TodoListID, before)
+	return nil
+}
+
+// Bulk validates the operations and applies them in one transaction
+func (t *TodoItemService) Bulk(ctx context.Context, request *models.BulkRequest) (models.BulkReport, error) {
+	ctx, span := tracing.Start(ctx, "TodoItemService.Bulk")
+	defer span.End()
+
+	if err := validateBulkRequest(request); err != nil {
+		return models.BulkReport{}, err
+	}
+
+	// nothing is applied when one of the todo items or target lists belongs to another user
+	before := map[uint]models.TodoItem{}
+	for _, operation := range request.Operations {
+		if _, ok := before[operation.ID]; !ok {
+			if todoItem, err := t.TodoItemRepo.GetSingle(ctx, operation.ID); err == nil {
+				if err = authorize(ctx, todoItem.TodoList.UserID); err != nil {
+					return models.BulkReport{}, err
+				}
+				before[operation.ID] = todoItem
+			}
+		}
+		if operation.Op == models.BulkMove {
+			if todoList, err := t.TodoListRepo.GetSingle(ctx, operation.ListID); err == nil {
+				if err = authorize(ctx, todoList.UserID); err != nil {
+					return models.BulkReport{}, err
+				}
+			}
+		}
+	}
+
+	report, err := t.TodoItemRepo.Bulk(ctx, request.Operations, request.AllOrNothing)
+	if err != nil || !report.Committed {
+		return report, err
+	}
+
+	changed := map[uint]bool{}
+	for _, result := range report.Results {
+		if result.Completed {
+			t.Counters.Inc(metrics.TodosCompleted)
+		}
+		if result.OK {
+			changed[result.ID] = true
+		}
+	}
+	t.auditBulk(ctx, request.Operations, before, changed)
+	return report, nil
+}
+
+// auditBulk records and publishes one event per todo item changed by a bulk request, in the order of the operations
+func (t *TodoItemService) auditBulk(ctx context.Context, operations []models.BulkOperation, before map[uint]models.TodoItem, changed map[uint]bool) {
+	for _, operation := range operations {
+		if !changed[operation.ID] {
+			continue
+		}
+		changed[operation.ID] = false
+
+		previous := before[operation.ID]
+		if todoItem, err := t.TodoItemRepo.GetSingle(ctx, operation.ID); err == nil {
+			event := todoItemAuditEvent(models.AuditUpdate, operation.ID, todoItem.TodoList)
+			recordAudit(ctx, t.AuditRepo, event, previous, todoItem)
+			publishChange(t.Publisher, models.EventTodoItemUpdated, todoItem.TodoList.UserID, todoItem.TodoListID, todoItem)
+		} else {
+			event := todoItemAuditEvent(models.AuditDelete, operation.ID, previous.TodoList)
+			recordAudit(ctx, t.AuditRepo, event, previous, nil)
+			publishChange(t.Publisher, models.EventTodoItemDeleted, previous.TodoList.UserID, previous.TodoListID, previous)
+		}
+	}
+}
+
+// validateBulkRequest checks that every operation carries the fields its kind needs
+func validateBulkRequest(request *models.BulkRequest) error {
+	if len(request.Operations) == 0 {
+		return fmt.Errorf("%w: no operations", services.ErrInvalidBulkOperation)
+	}
+	if len(request.Operations) > services.MaxBulkOperations {
+		return fmt.Errorf("%w: more than %d operations", services.ErrInvalidBulkOperation, services.MaxBulkOperations)
+	}
+
+	for i, operation := range request.Operations {
+		var problem string
+		switch {
+		case operation.ID == 0:
+			problem = "missing ID"
+		case operation.Op == models.BulkUpdate:
+			if operation.Title == "" && operation.Description == "" && operation.Completed == nil {
+				problem = "nothing to update"
+			}
+		case operation.Op == models.BulkMove:
+			if operation.ListID == 0 {
+				problem = "missing ListID"
+			}
+		case operation.Op == models.BulkAddTag:
+			if models.NormalizeTagText(operation.Tag) == "" {
+				problem = "missing Tag"
+			}
+		case operation.Op == models.BulkRemoveTag:
+			if operation.TagID == 0 {
+				problem = "missing TagID"
+			}
+		case operation.Op == models.BulkComplete, operation.Op == models.BulkDelete:
+		default:
+			problem = fmt.Sprintf("unknown operation %q", operation.Op)
+		}
+
+		if problem != "" {
+			return fmt.Errorf("%w: operation %d: %s", services.ErrInvalidBulkOperation, i, problem)
+		}
+	}
+	return nil
+}
+
+func todoItemAuditEvent(action string, id uint, todoList models.TodoList) models.AuditEvent {
+	return models.AuditEvent{
+		Action:       action,
+		ResourceType: models.AuditTodoItem,
+		ResourceID:   id,
+		UserID:       todoList.UserID,
+		TodoListID:   auditListID(todoList.ID),
+	}
+}
+
+// validateRecurrence checks the RRULE value of a todo item, recurrences start at the due date
+func validateRecurrence(recurrence string, due *time.Time) error {
+	if recurrence == "" {
+		return nil
+	}
+	if due == nil {
+		return fmt.Errorf("%w: missing due date", services.ErrInvalidRecurrence)
+	}
+	if err := ical.ValidateRecurrence(recurrence); err != nil {
+		return fmt.Errorf("%w: %v", services.ErrInvalidRecurrence, err)
+	}
+	return nil
 }
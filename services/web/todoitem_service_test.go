@@ -1,65 +1,208 @@
 package webservices
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/danikg/go-todo-rest-api/models"
+	"github.com/danikg/go-todo-rest-api/utils/events"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/danikg/go-todo-rest-api/repositories/mocks"
+	"github.com/danikg/go-todo-rest-api/services"
+	"github.com/danikg/go-todo-rest-api/utils/auth"
+	"github.com/danikg/go-todo-rest-api/utils/metrics"
+	"github.com/danikg/go-todo-rest-api/utils/test"
+	"github.com/danikg/go-todo-rest-api/utils/tracing"
 )
 
 func TestTodoItemService_GetAll(t *testing.T) {
-	todoItemService := NewTodoItemService(&mocks.TodoItemRepositoryMock{}, &mocks.TodoListRepositoryMock{})
-	todoItems, err := todoItemService.GetAll(1)
+	todoItemService := NewTodoItemService(&mocks.TodoItemRepositoryMock{}, &mocks.TodoListRepositoryMock{}, &mocks.AuditRepositoryMock{}, events.NewBus(0))
+	todoItems, err := todoItemService.GetAll(context.Background(), 1)
 	assert.NoError(t, err)
 	assert.NotEmpty(t, todoItems)
 
-	todoItems, err = todoItemService.GetAll(2)
+	todoItems, err = todoItemService.GetAll(context.Background(), 2)
 	assert.Error(t, err)
 	assert.Empty(t, todoItems)
 }
 
+func TestTodoItemService_GetAllByLists(t *testing.T) {
+	todoItemService := NewTodoItemService(&mocks.TodoItemRepositoryMock{}, &mocks.TodoListRepositoryMock{}, &mocks.AuditRepositoryMock{}, events.NewBus(0))
+	todoItems, err := todoItemService.GetAllByLists(context.Background(), []uint{1, 2})
+	assert.NoError(t, err)
+	assert.Len(t, todoItems, 2)
+
+	todoItems, err = todoItemService.GetAllByLists(context.Background(), nil)
+	assert.NoError(t, err)
+	assert.Empty(t, todoItems)
+
+	// the batch is refused when one of the lists belongs to another user
+	todoItems, err = todoItemService.GetAllByLists(auth.NewContext(context.Background(), 1), []uint{1})
+	assert.NoError(t, err)
+	assert.Len(t, todoItems, 2)
+	todoItems, err = todoItemService.GetAllByLists(auth.NewContext(context.Background(), 2), []uint{1, 2})
+	assert.ErrorIs(t, err, services.ErrForbidden)
+	assert.Empty(t, todoItems)
+}
+
 func TestTodoItemService_GetSingle(t *testing.T) {
-	todoItemService := NewTodoItemService(&mocks.TodoItemRepositoryMock{}, &mocks.TodoListRepositoryMock{})
-	todoItem, err := todoItemService.GetSingle(1)
+	todoItemService := NewTodoItemService(&mocks.TodoItemRepositoryMock{}, &mocks.TodoListRepositoryMock{}, &mocks.AuditRepositoryMock{}, events.NewBus(0))
+	todoItem, err := todoItemService.GetSingle(context.Background(), 1)
 	assert.NoError(t, err)
 	assert.NotEmpty(t, todoItem)
 
-	todoItem, err = todoItemService.GetSingle(2)
+	todoItem, err = todoItemService.GetSingle(context.Background(), 2)
 	assert.Error(t, err)
 	assert.Empty(t, todoItem)
 }
 
 func TestTodoItemService_Create(t *testing.T) {
-	todoItemService := NewTodoItemService(&mocks.TodoItemRepositoryMock{}, &mocks.TodoListRepositoryMock{})
+	todoItemService := NewTodoItemService(&mocks.TodoItemRepositoryMock{}, &mocks.TodoListRepositoryMock{}, &mocks.AuditRepositoryMock{}, events.NewBus(0))
 	todoItem := models.TodoItem{Title: "item"}
 	todoItem.ID = 1
 
-	err := todoItemService.Create(1, &todoItem)
+	err := todoItemService.Create(context.Background(), 1, &todoItem)
 	assert.NoError(t, err)
 
-	err = todoItemService.Create(2, &todoItem)
+	err = todoItemService.Create(context.Background(), 2, &todoItem)
 	assert.Error(t, err)
 }
 
 func TestTodoItemService_Update(t *testing.T) {
-	todoItemService := NewTodoItemService(&mocks.TodoItemRepositoryMock{}, &mocks.TodoListRepositoryMock{})
-	todoItem := models.TodoItem{Title: "item"}
-	todoItem.ID = 1
+	todoItemService := NewTodoItemService(&mocks.TodoItemRepositoryMock{}, &mocks.TodoListRepositoryMock{}, &mocks.AuditRepositoryMock{}, events.NewBus(0))
+	todoItem := models.TodoItemUpdate{Title: "item"}
 
-	resultTodoItem, err := todoItemService.Update(1, &todoItem)
+	resultTodoItem, err := todoItemService.Update(context.Background(), 1, &todoItem)
 	assert.NoError(t, err)
 	assert.NotEmpty(t, resultTodoItem)
 
-	resultTodoItem, err = todoItemService.Update(2, &todoItem)
+	resultTodoItem, err = todoItemService.Update(context.Background(), 2, &todoItem)
 	assert.Error(t, err)
 	assert.Empty(t, &resultTodoItem)
 }
 
+func TestTodoItemService_Recurrence(t *testing.T) {
+	todoItemService := NewTodoItemService(&mocks.TodoItemRepositoryMock{}, &mocks.TodoListRepositoryMock{}, &mocks.AuditRepositoryMock{}, events.NewBus(0))
+	due := time.Date(2024, 5, 6, 9, 0, 0, 0, time.UTC)
+
+	todoItem := models.TodoItem{Title: "standup", Due: &due, Recurrence: "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=10"}
+	assert.NoError(t, todoItemService.Create(context.Background(), 1, &todoItem))
+
+	invalid := []models.TodoItem{
+		{Title: "no due date", Recurrence: "FREQ=DAILY"},
+		{Title: "no freq", Due: &due, Recurrence: "INTERVAL=2"},
+		{Title: "unknown freq", Due: &due, Recurrence: "FREQ=SOMETIMES"},
+		{Title: "unknown part", Due: &due, Recurrence: "FREQ=DAILY;X-NAME=1"},
+		{Title: "count and until", Due: &due, Recurrence: "FREQ=DAILY;COUNT=2;UNTIL=20240601T000000Z"},
+		{Title: "line break", Due: &due, Recurrence: "FREQ=DAILY\r\nSUMMARY:x"},
+	}
+	for _, todoItem := range invalid {
+		assert.ErrorIs(t, todoItemService.Create(context.Background(), 1, &todoItem), services.ErrInvalidRecurrence, todoItem.Title)
+	}
+
+	// the stored item has no due date
+	_, err := todoItemService.Update(context.Background(), 1, &models.TodoItemUpdate{Recurrence: "FREQ=DAILY"})
+	assert.ErrorIs(t, err, services.ErrInvalidRecurrence)
+	_, err = todoItemService.Update(context.Background(), 1, &models.TodoItemUpdate{Due: &due, Recurrence: "FREQ=DAILY"})
+	assert.NoError(t, err)
+}
+
 func TestTodoItemService_Delete(t *testing.T) {
-	todoItemService := NewTodoItemService(&mocks.TodoItemRepositoryMock{}, &mocks.TodoListRepositoryMock{})
-	assert.NoError(t, todoItemService.Delete(1))
-	assert.Error(t, todoItemService.Delete(2))
+	todoItemService := NewTodoItemService(&mocks.TodoItemRepositoryMock{}, &mocks.TodoListRepositoryMock{}, &mocks.AuditRepositoryMock{}, events.NewBus(0))
+	assert.NoError(t, todoItemService.Delete(context.Background(), 1))
+	assert.Error(t, todoItemService.Delete(context.Background(), 2))
+}
+
+func TestTodoItemService_Metrics(t *testing.T) {
+	todoItemService := NewTodoItemService(&mocks.TodoItemRepositoryMock{}, &mocks.TodoListRepositoryMock{}, &mocks.AuditRepositoryMock{}, events.NewBus(0))
+	created := testutil.ToFloat64(metrics.TodosCreated)
+
+	assert.NoError(t, todoItemService.Create(context.Background(), 1, &models.TodoItem{Title: "item"}))
+	assert.Equal(t, created+1, testutil.ToFloat64(metrics.TodosCreated))
+
+	assert.Error(t, todoItemService.Create(context.Background(), 2, &models.TodoItem{Title: "item"}))
+	assert.Equal(t, created+1, testutil.ToFloat64(metrics.TodosCreated))
+}
+
+func TestTodoItemService_Tracing(t *testing.T) {
+	exporter := test.NewSpanExporter()
+	todoItemService := NewTodoItemService(&mocks.TodoItemRepositoryMock{}, &mocks.TodoListRepositoryMock{}, &mocks.AuditRepositoryMock{}, events.NewBus(0))
+
+	ctx, parent := tracing.Start(context.Background(), "parent")
+	_, err := todoItemService.GetAll(ctx, 1)
+	parent.End()
+	assert.NoError(t, err)
+
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 2)
+	assert.Equal(t, "TodoItemService.GetAll", spans[0].Name)
+	assert.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent.SpanID())
+}
+
+func TestTodoItemService_Bulk(t *testing.T) {
+	todoItemService := NewTodoItemService(&mocks.TodoItemRepositoryMock{}, &mocks.TodoListRepositoryMock{}, &mocks.AuditRepositoryMock{}, events.NewBus(0))
+	completed := testutil.ToFloat64(metrics.TodosCompleted)
+
+	report, err := todoItemService.Bulk(context.Background(), &models.BulkRequest{
+		Operations: []models.BulkOperation{
+			{Op: models.BulkComplete, ID: 1},
+			{Op: models.BulkAddTag, ID: 2, Tag: "@phone"},
+		},
+	})
+	assert.NoError(t, err)
+	assert.True(t, report.Committed)
+	assert.True(t, report.Results[0].OK)
+	assert.False(t, report.Results[1].OK)
+	assert.Equal(t, completed+1, testutil.ToFloat64(metrics.TodosCompleted))
+
+	report, err = todoItemService.Bulk(context.Background(), &models.BulkRequest{
+		Operations: []models.BulkOperation{
+			{Op: models.BulkComplete, ID: 1},
+			{Op: models.BulkDelete, ID: 2},
+		},
+		AllOrNothing: true,
+	})
+	assert.NoError(t, err)
+	assert.False(t, report.Committed)
+	assert.Equal(t, completed+1, testutil.ToFloat64(metrics.TodosCompleted))
+}
+
+func TestTodoItemService_BulkValidation(t *testing.T) {
+	todoItemService := NewTodoItemService(&mocks.TodoItemRepositoryMock{}, &mocks.TodoListRepositoryMock{}, &mocks.AuditRepositoryMock{}, events.NewBus(0))
+	invalid := [][]models.BulkOperation{
+		{},
+		make([]models.BulkOperation, services.MaxBulkOperations+1),
+		{{Op: models.BulkComplete}},
+		{{Op: "archive", ID: 1}},
+		{{Op: models.BulkUpdate, ID: 1}},
+		{{Op: models.BulkMove, ID: 1}},
+		{{Op: models.BulkAddTag, ID: 1, Tag: " "}},
+		{{Op: models.BulkRemoveTag, ID: 1}},
+	}
+
+	for _, operations := range invalid {
+		_, err := todoItemService.Bulk(context.Background(), &models.BulkRequest{Operations: operations})
+		assert.ErrorIs(t, err, services.ErrInvalidBulkOperation)
+	}
+}
+
+func TestTodoItemService_Forbidden(t *testing.T) {
+	todoItemService := NewTodoItemService(&mocks.TodoItemRepositoryMock{}, &mocks.TodoListRepositoryMock{}, &mocks.AuditRepositoryMock{}, events.NewBus(0))
+	ctx := auth.NewContext(context.Background(), 2)
+
+	_, err := todoItemService.GetAll(ctx, 1)
+	assert.Equal(t, services.ErrForbidden, err)
+	_, err = todoItemService.GetSingle(ctx, 1)
+	assert.Equal(t, services.ErrForbidden, err)
+	assert.Equal(t, services.ErrForbidden, todoItemService.Create(ctx, 1, &models.TodoItem{Title: "item3"}))
+	_, err = todoItemService.Update(ctx, 1, &models.TodoItemUpdate{Title: "renamed"})
+	assert.Equal(t, services.ErrForbidden, err)
+	assert.Equal(t, services.ErrForbidden, todoItemService.Delete(ctx, 1))
+
+	_, err = todoItemService.Bulk(ctx, &models.BulkRequest{Operations: []models.BulkOperation{{Op: models.BulkComplete, ID: 1}}})
+	assert.Equal(t, services.ErrForbidden, err)
 }
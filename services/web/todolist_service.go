@@ -1,50 +1,145 @@
 package webservices
 
 import (
+	"context"
+
 	"github.com/danikg/go-todo-rest-api/models"
 	repos "github.com/danikg/go-todo-rest-api/repositories"
+	"github.com/danikg/go-todo-rest-api/utils/events"
+	"github.com/danikg/go-todo-rest-api/utils/tracing"
 )
 
 // TodoListService ...
 type TodoListService struct {
 	UserRepo     repos.IUserRepository
 	TodoListRepo repos.ITodoListRepository
+	AuditRepo    repos.IAuditRepository
+	Publisher    events.Publisher
 }
 
 // NewTodoListService ...
-func NewTodoListService(userRepo repos.IUserRepository, todoListRepo repos.ITodoListRepository) *TodoListService {
+func NewTodoListService(userRepo repos.IUserRepository, todoListRepo repos.ITodoListRepository, auditRepo repos.IAuditRepository, publisher events.Publisher) *TodoListService {
 	return &TodoListService{
 		UserRepo:     userRepo,
 		TodoListRepo: todoListRepo,
+		AuditRepo:    auditRepo,
+		Publisher:    publisher,
 	}
 }
 
 // GetAll returns all todo lists by user id
-func (t *TodoListService) GetAll(userID uint) ([]models.TodoList, error) {
-	user, err := t.UserRepo.GetSingle(userID)
+func (t *TodoListService) GetAll(ctx context.Context, userID uint) ([]models.TodoList, error) {
+	ctx, span := tracing.Start(ctx, "TodoListService.GetAll")
+	defer span.End()
+
+	if err := authorize(ctx, userID); err != nil {
+		return []models.TodoList{}, err
+	}
+	user, err := t.UserRepo.GetSingle(ctx, userID)
 	if err != nil {
 		return []models.TodoList{}, err
 	}
 
-	return t.TodoListRepo.GetAll(user.ID)
+	return t.TodoListRepo.GetAll(ctx, user.ID)
+}
+
+// GetAllByUsers returns the todo lists of all the users, unknown users have none
+func (t *TodoListService) GetAllByUsers(ctx context.Context, userIDs []uint) ([]models.TodoList, error) {
+	ctx, span := tracing.Start(ctx, "TodoListService.GetAllByUsers")
+	defer span.End()
+
+	for _, userID := range userIDs {
+		if err := authorize(ctx, userID); err != nil {
+			return []models.TodoList{}, err
+		}
+	}
+
+	return t.TodoListRepo.GetAllByUsers(ctx, userIDs)
 }
 
 // GetSingle returns a todo list by id
-func (t *TodoListService) GetSingle(id uint) (models.TodoList, error) {
-	return t.TodoListRepo.GetSingle(id)
+func (t *TodoListService) GetSingle(ctx context.Context, id uint) (models.TodoList, error) {
+	ctx, span := tracing.Start(ctx, "TodoListService.GetSingle")
+	defer span.End()
+
+	todoList, err := t.TodoListRepo.GetSingle(ctx, id)
+	if err != nil {
+		return todoList, err
+	}
+	if err = authorize(ctx, todoList.UserID); err != nil {
+		return models.TodoList{}, err
+	}
+	return todoList, nil
 }
 
 // Create creates a new todo list
-func (t *TodoListService) Create(userID uint, todoList *models.TodoList) error {
-	return t.TodoListRepo.Create(userID, todoList)
+func (t *TodoListService) Create(ctx context.Context, userID uint, todoList *models.TodoList) error {
+	ctx, span := tracing.Start(ctx, "TodoListService.Create")
+	defer span.End()
+
+	if err := authorize(ctx, userID); err != nil {
+		return err
+	}
+	if err := t.TodoListRepo.Create(ctx, userID, todoList); err != nil {
+		return err
+	}
+
+	recordAudit(ctx, t.AuditRepo, todoListAuditEvent(models.AuditCreate, todoList), nil, todoList)
+	publishChange(t.Publisher, models.EventTodoListCreated, todoList.UserID, todoList.ID, todoList)
+	return nil
 }
 
 // Update updates the todo list
-func (t *TodoListService) Update(id uint, todoListData *models.TodoList) (models.TodoList, error) {
-	return t.TodoListRepo.Update(id, todoListData)
+func (t *TodoListService) Update(ctx context.Context, id uint, todoListData *models.TodoList) (models.TodoList, error) {
+	ctx, span := tracing.Start(ctx, "TodoListService.Update")
+	defer span.End()
+
+	before, err := t.TodoListRepo.GetSingle(ctx, id)
+	if err != nil {
+		return before, err
+	}
+	if err = authorize(ctx, before.UserID); err != nil {
+		return models.TodoList{}, err
+	}
+
+	todoList, err := t.TodoListRepo.Update(ctx, id, todoListData)
+	if err != nil {
+		return todoList, err
+	}
+
+	recordAudit(ctx, t.AuditRepo, todoListAuditEvent(models.AuditUpdate, &before), before, todoList)
+	publishChange(t.Publisher, models.EventTodoListUpdated, before.UserID, id, todoList)
+	return todoList, nil
 }
 
 // Delete removes the todo list
-func (t *TodoListService) Delete(id uint) error {
-	return t.TodoListRepo.Delete(id)
+func (t *TodoListService) Delete(ctx context.Context, id uint) error {
+	ctx, span := tracing.Start(ctx, "TodoListService.Delete")
+	defer span.End()
+
+	before, err := t.TodoListRepo.GetSingle(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err = authorize(ctx, before.UserID); err != nil {
+		return err
+	}
+
+	if err = t.TodoListRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	recordAudit(ctx, t.AuditRepo, todoListAuditEvent(models.AuditDelete, &before), before, nil)
+	publishChange(t.Publisher, models.EventTodoListDeleted, before.UserID, id, before)
+	return nil
+}
+
+func todoListAuditEvent(action string, todoList *models.TodoList) models.AuditEvent {
+	return models.AuditEvent{
+		Action:       action,
+		ResourceType: models.AuditTodoList,
+		ResourceID:   todoList.ID,
+		UserID:       todoList.UserID,
+		TodoListID:   auditListID(todoList.ID),
+	}
 }
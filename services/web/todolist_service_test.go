@@ -1,64 +1,104 @@
 package webservices
 
 import (
+	"context"
 	"testing"
 
 	"github.com/danikg/go-todo-rest-api/models"
+	"github.com/danikg/go-todo-rest-api/utils/events"
 
 	"github.com/danikg/go-todo-rest-api/repositories/mocks"
+	"github.com/danikg/go-todo-rest-api/services"
+	"github.com/danikg/go-todo-rest-api/utils/auth"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestTodoListService_GetAll(t *testing.T) {
-	todoListService := NewTodoListService(&mocks.UserRepositoryMock{}, &mocks.TodoListRepositoryMock{})
-	todoLists, err := todoListService.GetAll(1)
+	todoListService := NewTodoListService(&mocks.UserRepositoryMock{}, &mocks.TodoListRepositoryMock{}, &mocks.AuditRepositoryMock{}, events.NewBus(0))
+	todoLists, err := todoListService.GetAll(context.Background(), 1)
 	assert.NoError(t, err)
 	assert.NotEmpty(t, todoLists)
 
-	todoLists, err = todoListService.GetAll(2)
+	todoLists, err = todoListService.GetAll(context.Background(), 2)
 	assert.Error(t, err)
 	assert.Empty(t, todoLists)
 }
 
+func TestTodoListService_GetAllByUsers(t *testing.T) {
+	todoListService := NewTodoListService(&mocks.UserRepositoryMock{}, &mocks.TodoListRepositoryMock{}, &mocks.AuditRepositoryMock{}, events.NewBus(0))
+	todoLists, err := todoListService.GetAllByUsers(context.Background(), []uint{1, 2})
+	assert.NoError(t, err)
+	assert.Len(t, todoLists, 2)
+
+	todoLists, err = todoListService.GetAllByUsers(context.Background(), []uint{2})
+	assert.NoError(t, err)
+	assert.Empty(t, todoLists)
+
+	// the batch is refused when one of the users is not the authenticated one
+	todoLists, err = todoListService.GetAllByUsers(auth.NewContext(context.Background(), 1), []uint{1})
+	assert.NoError(t, err)
+	assert.Len(t, todoLists, 2)
+	todoLists, err = todoListService.GetAllByUsers(auth.NewContext(context.Background(), 1), []uint{1, 2})
+	assert.ErrorIs(t, err, services.ErrForbidden)
+	assert.Empty(t, todoLists)
+}
+
 func TestTodoListService_GetSingle(t *testing.T) {
-	todoListService := NewTodoListService(&mocks.UserRepositoryMock{}, &mocks.TodoListRepositoryMock{})
-	todoList, err := todoListService.GetSingle(1)
+	todoListService := NewTodoListService(&mocks.UserRepositoryMock{}, &mocks.TodoListRepositoryMock{}, &mocks.AuditRepositoryMock{}, events.NewBus(0))
+	todoList, err := todoListService.GetSingle(context.Background(), 1)
 	assert.NoError(t, err)
 	assert.NotEmpty(t, todoList)
 
-	todoList, err = todoListService.GetSingle(2)
+	todoList, err = todoListService.GetSingle(context.Background(), 2)
 	assert.Error(t, err)
 	assert.Empty(t, todoList)
 }
 
 func TestTodoListService_Create(t *testing.T) {
-	todoListService := NewTodoListService(&mocks.UserRepositoryMock{}, &mocks.TodoListRepositoryMock{})
+	todoListService := NewTodoListService(&mocks.UserRepositoryMock{}, &mocks.TodoListRepositoryMock{}, &mocks.AuditRepositoryMock{}, events.NewBus(0))
 	todoList := models.TodoList{Name: "list"}
 	todoList.ID = 1
 
-	err := todoListService.Create(1, &todoList)
+	err := todoListService.Create(context.Background(), 1, &todoList)
 	assert.NoError(t, err)
 
-	err = todoListService.Create(2, &todoList)
+	err = todoListService.Create(context.Background(), 2, &todoList)
 	assert.Error(t, err)
 }
 
 func TestTodoListService_Update(t *testing.T) {
-	todoListService := NewTodoListService(&mocks.UserRepositoryMock{}, &mocks.TodoListRepositoryMock{})
+	todoListService := NewTodoListService(&mocks.UserRepositoryMock{}, &mocks.TodoListRepositoryMock{}, &mocks.AuditRepositoryMock{}, events.NewBus(0))
 	todoList := models.TodoList{Name: "list"}
 	todoList.ID = 1
 
-	resultTodoList, err := todoListService.Update(1, &todoList)
+	resultTodoList, err := todoListService.Update(context.Background(), 1, &todoList)
 	assert.NoError(t, err)
 	assert.NotEmpty(t, resultTodoList)
 
-	resultTodoList, err = todoListService.Update(2, &todoList)
+	resultTodoList, err = todoListService.Update(context.Background(), 2, &todoList)
 	assert.Error(t, err)
 	assert.Empty(t, &resultTodoList)
 }
 
 func TestTodoListService_Delete(t *testing.T) {
-	todoListService := NewTodoListService(&mocks.UserRepositoryMock{}, &mocks.TodoListRepositoryMock{})
-	assert.NoError(t, todoListService.Delete(1))
-	assert.Error(t, todoListService.Delete(2))
+	todoListService := NewTodoListService(&mocks.UserRepositoryMock{}, &mocks.TodoListRepositoryMock{}, &mocks.AuditRepositoryMock{}, events.NewBus(0))
+	assert.NoError(t, todoListService.Delete(context.Background(), 1))
+	assert.Error(t, todoListService.Delete(context.Background(), 2))
+}
+
+func TestTodoListService_Forbidden(t *testing.T) {
+	todoListService := NewTodoListService(&mocks.UserRepositoryMock{}, &mocks.TodoListRepositoryMock{}, &mocks.AuditRepositoryMock{}, events.NewBus(0))
+	ctx := auth.NewContext(context.Background(), 2)
+
+	_, err := todoListService.GetAll(ctx, 1)
+	assert.Equal(t, services.ErrForbidden, err)
+	_, err = todoListService.GetSingle(ctx, 1)
+	assert.Equal(t, services.ErrForbidden, err)
+	assert.Equal(t, services.ErrForbidden, todoListService.Create(ctx, 1, &models.TodoList{Name: "list3"}))
+	_, err = todoListService.Update(ctx, 1, &models.TodoList{Name: "renamed"})
+	assert.Equal(t, services.ErrForbidden, err)
+	assert.Equal(t, services.ErrForbidden, todoListService.Delete(ctx, 1))
+
+	_, err = todoListService.GetSingle(auth.NewContext(context.Background(), 1), 1)
+	assert.NoError(t, err)
 }
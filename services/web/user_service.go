@@ -1,41 +1,179 @@
 package webservices
 
 import (
+	"context"
+	"errors"
+
 	"github.com/danikg/go-todo-rest-api/models"
 	repos "github.com/danikg/go-todo-rest-api/repositories"
+	"github.com/danikg/go-todo-rest-api/services"
+	"github.com/danikg/go-todo-rest-api/utils/auth"
+	"github.com/danikg/go-todo-rest-api/utils/metrics"
+	"github.com/danikg/go-todo-rest-api/utils/tracing"
+	"golang.org/x/crypto/bcrypt"
 )
 
+// ErrInvalidCredentials is returned when the username or the password does not match
+var ErrInvalidCredentials = errors.New("invalid username or password")
+
+// ErrUserDisabled is returned when the credentials match a user disabled by an admin
+var ErrUserDisabled = errors.New("the user is disabled")
+
 // UserService ...
 type UserService struct {
-	UserRepo repos.IUserRepository
+	UserRepo  repos.IUserRepository
+	AuditRepo repos.IAuditRepository
 }
 
 // NewUserService ...
-func NewUserService(userRepo repos.IUserRepository) *UserService {
-	return &UserService{UserRepo: userRepo}
+func NewUserService(userRepo repos.IUserRepository, auditRepo repos.IAuditRepository) *UserService {
+	return &UserService{
+		UserRepo:  userRepo,
+		AuditRepo: auditRepo,
+	}
 }
 
-// GetAll returns all users from the db
-func (u *UserService) GetAll() ([]models.User, error) {
-	return u.UserRepo.GetAll()
+// GetAll returns all users from the db, only the authenticated user when there is one
+func (u *UserService) GetAll(ctx context.Context) ([]models.User, error) {
+	ctx, span := tracing.Start(ctx, "UserService.GetAll")
+	defer span.End()
+
+	if userID, ok := auth.UserID(ctx); ok {
+		user, err := u.UserRepo.GetSingle(ctx, userID)
+		if err != nil {
+			return []models.User{}, err
+		}
+		return []models.User{user}, nil
+	}
+	if auth.Required(ctx) {
+		return []models.User{}, services.ErrForbidden
+	}
+	return u.UserRepo.GetAll(ctx)
 }
 
 // GetSingle returns a user by id
-func (u *UserService) GetSingle(id uint) (models.User, error) {
-	return u.UserRepo.GetSingle(id)
+func (u *UserService) GetSingle(ctx context.Context, id uint) (models.User, error) {
+	ctx, span := tracing.Start(ctx, "UserService.GetSingle")
+	defer span.End()
+
+	if err := authorize(ctx, id); err != nil {
+		return models.User{}, err
+	}
+	return u.UserRepo.GetSingle(ctx, id)
 }
 
 // Create creates a new user
-func (u *UserService) Create(user *models.User) error {
-	return u.UserRepo.Create(user)
+func (u *UserService) Create(ctx context.Context, user *models.User) error {
+	ctx, span := tracing.Start(ctx, "UserService.Create")
+	defer span.End()
+
+	if err := hashPassword(user); err != nil {
+		return err
+	}
+
+	if err := u.UserRepo.Create(ctx, user); err != nil {
+		return err
+	}
+
+	metrics.UsersRegistered.Inc()
+	recordAudit(ctx, u.AuditRepo, userAuditEvent(models.AuditCreate, user.ID), nil, user)
+	return nil
 }
 
 // Update updates the user
-func (u *UserService) Update(id uint, userData *models.User) (models.User, error) {
-	return u.UserRepo.Update(id, userData)
+func (u *UserService) Update(ctx context.Context, id uint, userData *models.User) (models.User, error) {
+	ctx, span := tracing.Start(ctx, "UserService.Update")
+	defer span.End()
+
+	if err := authorize(ctx, id); err != nil {
+		return models.User{}, err
+	}
+	if err := hashPassword(userData); err != nil {
+		return models.User{}, err
+	}
+
+	before, err := u.UserRepo.GetSingle(ctx, id)
+	if err != nil {
+		return before, err
+	}
+
+	user, err := u.UserRepo.Update(ctx, id, userData)
+	if err != nil {
+		return user, err
+	}
+
+	recordAudit(ctx, u.AuditRepo, userAuditEvent(models.AuditUpdate, id), before, user)
+	return user, nil
 }
 
 // Delete removes the user
-func (u *UserService) Delete(id uint) error {
-	return u.UserRepo.Delete(id)
+func (u *UserService) Delete(ctx context.Context, id uint) error {
+	ctx, span := tracing.Start(ctx, "UserService.Delete")
+	defer span.End()
+
+	if err := authorize(ctx, id); err != nil {
+		return err
+	}
+	before, err := u.UserRepo.GetSingle(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err = u.UserRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	recordAudit(ctx, u.AuditRepo, userAuditEvent(models.AuditDelete, id), before, nil)
+	return nil
+}
+
+// Authenticate returns the user matching the credentials
+func (u *UserService) Authenticate(ctx context.Context, username, password string) (models.User, error) {
+	ctx, span := tracing.Start(ctx, "UserService.Authenticate")
+	defer span.End()
+
+	user, err := u.UserRepo.GetByUsername(ctx, username)
+	if err != nil || user.PasswordHash == "" {
+		return models.User{}, ErrInvalidCredentials
+	}
+
+	if err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return models.User{}, ErrInvalidCredentials
+	}
+	if user.Disabled {
+		return models.User{}, ErrUserDisabled
+	}
+	return user, nil
+}
+
+// CheckToken rejects the tokens of deleted and disabled users and those of an older token generation
+func (u *UserService) CheckToken(ctx context.Context, userID, generation uint) error {
+	ctx, span := tracing.Start(ctx, "UserService.CheckToken")
+	defer span.End()
+
+	user, err := u.UserRepo.GetCredentials(ctx, userID)
+	if err != nil || user.Disabled || user.TokenGeneration != generation {
+		return services.ErrTokenRevoked
+	}
+	return nil
+}
+
+func userAuditEvent(action string, id uint) models.AuditEvent {
+	return models.AuditEvent{Action: action, ResourceType: models.AuditUser, ResourceID: id, UserID: id}
+}
+
+// hashPassword replaces the plain text password with its hash
+func hashPassword(user *models.User) error {
+	if user.Password == "" {
+		return nil
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	user.PasswordHash = string(hash)
+	user.Password = ""
+	return nil
 }
@@ -1,66 +1,136 @@
 package webservices
 
 import (
+	"context"
 	"testing"
 
 	"github.com/danikg/go-todo-rest-api/models"
 	"github.com/danikg/go-todo-rest-api/repositories/mocks"
+	"github.com/danikg/go-todo-rest-api/services"
+	"github.com/danikg/go-todo-rest-api/utils/auth"
 
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
 )
 
 func TestUserService_GetAll(t *testing.T) {
-	userService := NewUserService(&mocks.UserRepositoryMock{})
-	users, err := userService.GetAll()
+	userService := NewUserService(&mocks.UserRepositoryMock{}, &mocks.AuditRepositoryMock{})
+	users, err := userService.GetAll(context.Background())
 	assert.NoError(t, err)
 	assert.NotEmpty(t, users)
 
-	userService = NewUserService(&mocks.UserRepositoryMock{GenerateErr: true})
-	users, err = userService.GetAll()
+	userService = NewUserService(&mocks.UserRepositoryMock{GenerateErr: true}, &mocks.AuditRepositoryMock{})
+	users, err = userService.GetAll(context.Background())
 	assert.Error(t, err)
 	assert.Empty(t, users)
 }
 
 func TestUserService_GetSingle(t *testing.T) {
-	userService := NewUserService(&mocks.UserRepositoryMock{})
-	user, err := userService.GetSingle(1)
+	userService := NewUserService(&mocks.UserRepositoryMock{}, &mocks.AuditRepositoryMock{})
+	user, err := userService.GetSingle(context.Background(), 1)
 	assert.NoError(t, err)
 	assert.NotEmpty(t, user)
 
-	user, err = userService.GetSingle(2)
+	user, err = userService.GetSingle(context.Background(), 2)
 	assert.Error(t, err)
 	assert.Empty(t, user)
 }
 
 func TestUserService_Create(t *testing.T) {
-	userService := NewUserService(&mocks.UserRepositoryMock{})
+	userService := NewUserService(&mocks.UserRepositoryMock{}, &mocks.AuditRepositoryMock{})
 	user := models.User{Username: "user1"}
 	user.ID = 1
 
-	err := userService.Create(&user)
+	err := userService.Create(context.Background(), &user)
 	assert.NoError(t, err)
 
-	userService = NewUserService(&mocks.UserRepositoryMock{GenerateErr: true})
-	err = userService.Create(&user)
+	userService = NewUserService(&mocks.UserRepositoryMock{GenerateErr: true}, &mocks.AuditRepositoryMock{})
+	err = userService.Create(context.Background(), &user)
 	assert.Error(t, err)
 }
 
 func TestUserService_Update(t *testing.T) {
-	userService := NewUserService(&mocks.UserRepositoryMock{})
+	userService := NewUserService(&mocks.UserRepositoryMock{}, &mocks.AuditRepositoryMock{})
 	user := models.User{Username: "user1"}
 	user.ID = 1
 
-	resultUser, err := userService.Update(1, &user)
+	resultUser, err := userService.Update(context.Background(), 1, &user)
 	assert.NoError(t, err)
 	assert.NotEmpty(t, resultUser)
 
-	resultUser, err = userService.Update(2, &user)
+	resultUser, err = userService.Update(context.Background(), 2, &user)
 	assert.Error(t, err)
 	assert.Empty(t, &resultUser)
 }
 
 func TestUserService_Delete(t *testing.T) {
-	userService := NewUserService(&mocks.UserRepositoryMock{})
-	assert.NoError(t, userService.Delete(1))
-	assert.Error(t, userService.Delete(2))
+	userService := NewUserService(&mocks.UserRepositoryMock{}, &mocks.AuditRepositoryMock{})
+	assert.NoError(t, userService.Delete(context.Background(), 1))
+	assert.Error(t, userService.Delete(context.Background(), 2))
+}
+
+func TestUserService_CreateHashesPassword(t *testing.T) {
+	userService := NewUserService(&mocks.UserRepositoryMock{}, &mocks.AuditRepositoryMock{})
+	user := models.User{Username: "user1", Password: "password"}
+
+	assert.NoError(t, userService.Create(context.Background(), &user))
+	assert.Empty(t, user.Password)
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte("password")))
+}
+
+func TestUserService_Authenticate(t *testing.T) {
+	userService := NewUserService(&mocks.UserRepositoryMock{}, &mocks.AuditRepositoryMock{})
+	user, err := userService.Authenticate(context.Background(), "user1", "password")
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), user.ID)
+
+	user, err = userService.Authenticate(context.Background(), "user1", "wrong")
+	assert.Equal(t, ErrInvalidCredentials, err)
+	assert.Empty(t, user)
+
+	user, err = userService.Authenticate(context.Background(), "user2", "password")
+	assert.Equal(t, ErrInvalidCredentials, err)
+	assert.Empty(t, user)
+
+	user, err = userService.Authenticate(context.Background(), "disabled", "wrong")
+	assert.Equal(t, ErrInvalidCredentials, err)
+	assert.Empty(t, user)
+
+	user, err = userService.Authenticate(context.Background(), "disabled", "password")
+	assert.Equal(t, ErrUserDisabled, err)
+	assert.Empty(t, user)
+}
+
+func TestUserService_CheckToken(t *testing.T) {
+	userService := NewUserService(&mocks.UserRepositoryMock{}, &mocks.AuditRepositoryMock{})
+	assert.NoError(t, userService.CheckToken(context.Background(), 1, 0))
+	assert.Equal(t, services.ErrTokenRevoked, userService.CheckToken(context.Background(), 1, 1))
+	assert.Equal(t, services.ErrTokenRevoked, userService.CheckToken(context.Background(), 2, 0))
+	assert.Equal(t, services.ErrTokenRevoked, userService.CheckToken(context.Background(), 3, 0))
+}
+
+func TestUserService_Forbidden(t *testing.T) {
+	userService := NewUserService(&mocks.UserRepositoryMock{}, &mocks.AuditRepositoryMock{})
+	ctx := auth.NewContext(context.Background(), 1)
+
+	users, err := userService.GetAll(ctx)
+	assert.NoError(t, err)
+	if assert.Len(t, users, 1) {
+		assert.Equal(t, uint(1), users[0].ID)
+	}
+
+	_, err = userService.GetSingle(ctx, 2)
+	assert.Equal(t, services.ErrForbidden, err)
+	_, err = userService.Update(ctx, 2, &models.User{Username: "user2"})
+	assert.Equal(t, services.ErrForbidden, err)
+	assert.Equal(t, services.ErrForbidden, userService.Delete(ctx, 2))
+
+	// without a user the checks fail closed when the API requires authentication
+	ctx = auth.RequireUser(context.Background())
+	_, err = userService.GetAll(ctx)
+	assert.Equal(t, services.ErrForbidden, err)
+	_, err = userService.GetSingle(ctx, 1)
+	assert.Equal(t, services.ErrForbidden, err)
+	_, err = userService.GetSingle(context.Background(), 1)
+	assert.NoError(t, err)
 }
@@ -0,0 +1,209 @@
+package webservices
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/danikg/go-todo-rest-api/config"
+	"github.com/danikg/go-todo-rest-api/models"
+	repos "github.com/danikg/go-todo-rest-api/repositories"
+	"github.com/danikg/go-todo-rest-api/utils/logger"
+	"github.com/danikg/go-todo-rest-api/utils/metrics"
+	"github.com/danikg/go-todo-rest-api/utils/tracing"
+)
+
+// Headers sent with every webhook delivery
+const (
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookDeliveryHeader  = "X-Webhook-Delivery"
+	WebhookSignatureHeader = "X-Webhook-Signature"
+)
+
+// webhookBatchSize bounds the outbox events and the deliveries handled by one dispatcher run
+const webhookBatchSize = 100
+
+// WebhookDispatcher fans the outbox out to the subscribed webhooks and delivers the events,
+// failed attempts are retried with exponential backoff until MaxAttempts is reached
+type WebhookDispatcher struct {
+	WebhookRepo repos.IWebhookRepository
+	Client      *http.Client
+	Config      config.WebhookConfig
+}
+
+// NewWebhookDispatcher ...
+func NewWebhookDispatcher(webhookRepo repos.IWebhookRepository, cfg config.WebhookConfig) *WebhookDispatcher {
+	client := &http.Client{Timeout: cfg.Timeout}
+	if !cfg.AllowPrivateTargets {
+		// the addresses are checked on every connection, so that a host resolving
+		// to another address after the webhook was created is still refused
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.Proxy = nil
+		transport.DialContext = (&net.Dialer{Timeout: cfg.Timeout, Control: refusePrivateAddress}).DialContext
+		client.Transport = transport
+	}
+
+	return &WebhookDispatcher{
+		WebhookRepo: webhookRepo,
+		Client:      client,
+		Config:      cfg,
+	}
+}
+
+// refusePrivateAddress is the net.Dialer Control refusing the addresses webhooks may not target
+func refusePrivateAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !publicAddress(ip) {
+		return fmt.Errorf("webhook target %s is a loopback, private or link-local address", host)
+	}
+	return nil
+}
+
+// publicAddress reports whether webhooks may target ip, loopback, private, link-local,
+// multicast and unspecified addresses would let a webhook reach the internal network
+func publicAddress(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified())
+}
+
+// WebhookSignature returns the X-Webhook-Signature value of body,
+// receivers compute it with their secret to check that a delivery is genuine
+func WebhookSignature(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Run dispatches every PollInterval until ctx is done
+func (d *WebhookDispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.Config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := d.Dispatch(ctx, now); err != nil {
+				logger.FromContext(ctx).Error("failed to dispatch webhooks", "error", err)
+			}
+		}
+	}
+}
+
+// Dispatch fans the outbox events out and attempts the deliveries due at now concurrently
+func (d *WebhookDispatcher) Dispatch(ctx context.Context, now time.Time) error {
+	ctx, span := tracing.Start(ctx, "WebhookDispatcher.Dispatch")
+	defer span.End()
+
+	if _, err := d.WebhookRepo.FanOut(ctx, now, webhookBatchSize); err != nil {
+		return err
+	}
+
+	// the lease outlasts the attempts, which are bounded by the client timeout
+	deliveries, err := d.WebhookRepo.ClaimDueDeliveries(ctx, now, 2*d.Config.Timeout, webhookBatchSize)
+	if err != nil {
+		return err
+	}
+
+	var wg sync.WaitGroup
+	for i := range deliveries {
+		wg.Add(1)
+		go func(delivery *models.WebhookDelivery) {
+			defer wg.Done()
+			d.deliver(ctx, delivery, now)
+		}(&deliveries[i])
+	}
+	wg.Wait()
+	return nil
+}
+
+// deliver attempts the delivery once and stores the outcome
+func (d *WebhookDispatcher) deliver(ctx context.Context, delivery *models.WebhookDelivery, now time.Time) {
+	delivery.Attempts++
+	status, err := d.send(ctx, delivery)
+	delivery.ResponseStatus = status
+	delivery.Error = ""
+
+	var outcome string
+	switch {
+	case err == nil:
+		outcome = models.WebhookDeliverySucceeded
+		delivery.DeliveredAt = &now
+	case delivery.Attempts >= d.Config.MaxAttempts:
+		outcome = models.WebhookDeliveryFailed
+		delivery.Error = err.Error()
+	default:
+		outcome = "retried"
+		delivery.Error = err.Error()
+		delivery.NextAttemptAt = now.Add(d.retryBackoff(delivery.Attempts))
+	}
+	if outcome != "retried" {
+		delivery.Status = outcome
+	}
+	metrics.WebhookDeliveries.WithLabelValues(outcome).Inc()
+
+	if err = d.WebhookRepo.UpdateDelivery(ctx, delivery); err != nil {
+		logger.FromContext(ctx).Error("failed to store webhook delivery", "error", err, "delivery_id", delivery.ID)
+	}
+}
+
+// send posts the signed event to the webhook, any status outside 2xx is a failure
+func (d *WebhookDispatcher) send(ctx context.Context, delivery *models.WebhookDelivery) (int, error) {
+	body, err := json.Marshal(models.WebhookPayload{
+		EventID:   delivery.EventID,
+		Type:      delivery.EventType,
+		CreatedAt: delivery.Event.CreatedAt,
+		Data:      delivery.Event.Payload,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, delivery.EventType)
+	req.Header.Set(WebhookDeliveryHeader, strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set(WebhookSignatureHeader, WebhookSignature(delivery.Webhook.Secret, body))
+
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// retryBackoff returns the wait after the given number of failed attempts
+func (d *WebhookDispatcher) retryBackoff(attempts int) time.Duration {
+	backoff := d.Config.RetryBackoff
+	for i := 1; i < attempts && backoff < d.Config.MaxRetryBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > d.Config.MaxRetryBackoff {
+		return d.Config.MaxRetryBackoff
+	}
+	return backoff
+}
@@ -0,0 +1,162 @@
+package webservices
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/danikg/go-todo-rest-api/config"
+	"github.com/danikg/go-todo-rest-api/models"
+	"github.com/danikg/go-todo-rest-api/repositories/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+// webhookReceiver is a local webhook answering with the queued statuses, then 200
+type webhookReceiver struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (h *webhookReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	body, _ := io.ReadAll(r.Body)
+	h.requests = append(h.requests, r)
+	h.bodies = append(h.bodies, body)
+
+	status := http.StatusOK
+	if len(h.statuses) != 0 {
+		status, h.statuses = h.statuses[0], h.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+func newTestDispatcher(url string, now time.Time) (*WebhookDispatcher, *mocks.WebhookRepositoryMock) {
+	webhook := models.Webhook{URL: url, Secret: "secret"}
+	webhook.ID = 1
+	event := models.WebhookOutboxEvent{ID: 7, CreatedAt: now, UserID: 1, Type: models.WebhookTodoItemCompleted, Payload: []byte(`{"Title":"item"}`)}
+
+	webhookRepo := &mocks.WebhookRepositoryMock{Deliveries: []models.WebhookDelivery{{
+		ID:            3,
+		WebhookID:     webhook.ID,
+		Webhook:       webhook,
+		EventID:       event.ID,
+		Event:         event,
+		EventType:     event.Type,
+		Status:        models.WebhookDeliveryPending,
+		NextAttemptAt: now,
+	}}}
+
+	dispatcher := NewWebhookDispatcher(webhookRepo, config.WebhookConfig{
+		PollInterval:    time.Second,
+		Timeout:         time.Second,
+		MaxAttempts:     3,
+		RetryBackoff:    time.Minute,
+		MaxRetryBackoff: time.Hour,
+		// the receivers of the tests listen on loopback
+		AllowPrivateTargets: true,
+	})
+	return dispatcher, webhookRepo
+}
+
+func TestWebhookDispatcher_Dispatch(t *testing.T) {
+	receiver := &webhookReceiver{statuses: []int{http.StatusInternalServerError}}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	now := time.Now()
+	dispatcher, webhookRepo := newTestDispatcher(server.URL, now)
+
+	// the first attempt fails and is retried after the backoff
+	assert.NoError(t, dispatcher.Dispatch(context.Background(), now))
+	delivery := webhookRepo.Deliveries[0]
+	assert.Equal(t, models.WebhookDeliveryPending, delivery.Status)
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.Equal(t, http.StatusInternalServerError, delivery.ResponseStatus)
+	assert.Equal(t, "unexpected status 500", delivery.Error)
+	assert.Equal(t, now.Add(time.Minute), delivery.NextAttemptAt)
+
+	assert.NoError(t, dispatcher.Dispatch(context.Background(), now.Add(time.Second)))
+	assert.Len(t, receiver.requests, 1)
+
+	retry := now.Add(time.Minute)
+	assert.NoError(t, dispatcher.Dispatch(context.Background(), retry))
+	delivery = webhookRepo.Deliveries[0]
+	assert.Equal(t, models.WebhookDeliverySucceeded, delivery.Status)
+	assert.Equal(t, 2, delivery.Attempts)
+	assert.Equal(t, http.StatusOK, delivery.ResponseStatus)
+	assert.Empty(t, delivery.Error)
+	assert.Equal(t, retry, *delivery.DeliveredAt)
+
+	assert.Len(t, receiver.requests, 2)
+	r, body := receiver.requests[1], receiver.bodies[1]
+	assert.Equal(t, "POST", r.Method)
+	assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+	assert.Equal(t, models.WebhookTodoItemCompleted, r.Header.Get(WebhookEventHeader))
+	assert.Equal(t, "3", r.Header.Get(WebhookDeliveryHeader))
+	assert.Equal(t, WebhookSignature("secret", body), r.Header.Get(WebhookSignatureHeader))
+	assert.NotEqual(t, WebhookSignature("other", body), r.Header.Get(WebhookSignatureHeader))
+
+	var payload models.WebhookPayload
+	assert.NoError(t, json.Unmarshal(body, &payload))
+	assert.Equal(t, uint(7), payload.EventID)
+	assert.Equal(t, models.WebhookTodoItemCompleted, payload.Type)
+	assert.JSONEq(t, `{"Title":"item"}`, string(payload.Data))
+}
+
+func TestWebhookDispatcher_GivesUp(t *testing.T) {
+	receiver := &webhookReceiver{statuses: []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway}}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	now := time.Now()
+	dispatcher, webhookRepo := newTestDispatcher(server.URL, now)
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, dispatcher.Dispatch(context.Background(), now.Add(time.Duration(i)*time.Hour)))
+	}
+
+	delivery := webhookRepo.Deliveries[0]
+	assert.Equal(t, models.WebhookDeliveryFailed, delivery.Status)
+	assert.Equal(t, 3, delivery.Attempts)
+	assert.Equal(t, http.StatusBadGateway, delivery.ResponseStatus)
+	assert.Nil(t, delivery.DeliveredAt)
+	assert.Len(t, receiver.requests, 3)
+}
+
+func TestWebhookDispatcher_PrivateTargets(t *testing.T) {
+	receiver := &webhookReceiver{}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	now := time.Now()
+	dispatcher, webhookRepo := newTestDispatcher(server.URL, now)
+	dispatcher.Client = NewWebhookDispatcher(webhookRepo, config.WebhookConfig{Timeout: time.Second}).Client
+
+	// the address is checked when connecting, whatever the webhook URL was when it was created
+	assert.NoError(t, dispatcher.Dispatch(context.Background(), now))
+	delivery := webhookRepo.Deliveries[0]
+	assert.Equal(t, models.WebhookDeliveryPending, delivery.Status)
+	assert.Contains(t, delivery.Error, "loopback, private or link-local address")
+	assert.Empty(t, receiver.requests)
+}
+
+func TestWebhookDispatcher_RetryBackoff(t *testing.T) {
+	dispatcher := NewWebhookDispatcher(&mocks.WebhookRepositoryMock{}, config.WebhookConfig{
+		RetryBackoff:    30 * time.Second,
+		MaxRetryBackoff: 5 * time.Minute,
+	})
+
+	assert.Equal(t, 30*time.Second, dispatcher.retryBackoff(1))
+	assert.Equal(t, time.Minute, dispatcher.retryBackoff(2))
+	assert.Equal(t, 4*time.Minute, dispatcher.retryBackoff(4))
+	assert.Equal(t, 5*time.Minute, dispatcher.retryBackoff(5))
+	assert.Equal(t, 5*time.Minute, dispatcher.retryBackoff(100))
+}
@@ -0,0 +1,196 @@
+package webservices
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/url"
+
+	"github.com/danikg/go-todo-rest-api/config"
+	"github.com/danikg/go-todo-rest-api/models"
+	repos "github.com/danikg/go-todo-rest-api/repositories"
+	"github.com/danikg/go-todo-rest-api/services"
+	"github.com/danikg/go-todo-rest-api/utils/tracing"
+)
+
+// WebhookService ...
+type WebhookService struct {
+	WebhookRepo  repos.IWebhookRepository
+	UserRepo     repos.IUserRepository
+	Config       config.WebhookConfig
+	LookupIPAddr func(ctx context.Context, host string) ([]net.IPAddr, error)
+}
+
+// NewWebhookService ...
+func NewWebhookService(webhookRepo repos.IWebhookRepository, userRepo repos.IUserRepository, cfg config.WebhookConfig) *WebhookService {
+	return &WebhookService{
+		WebhookRepo:  webhookRepo,
+		UserRepo:     userRepo,
+		Config:       cfg,
+		LookupIPAddr: net.DefaultResolver.LookupIPAddr,
+	}
+}
+
+// GetAll returns all webhooks of the user without their secrets
+func (t *WebhookService) GetAll(ctx context.Context, userID uint) ([]models.Webhook, error) {
+	ctx, span := tracing.Start(ctx, "WebhookService.GetAll")
+	defer span.End()
+
+	if err := authorize(ctx, userID); err != nil {
+		return []models.Webhook{}, err
+	}
+
+	user, err := t.UserRepo.GetSingle(ctx, userID)
+	if err != nil {
+		return []models.Webhook{}, err
+	}
+
+	webhooks, err := t.WebhookRepo.GetAll(ctx, user.ID)
+	for i := range webhooks {
+		webhooks[i].Secret = ""
+	}
+	return webhooks, err
+}
+
+// GetSingle returns a webhook by id without its secret
+func (t *WebhookService) GetSingle(ctx context.Context, id uint) (models.Webhook, error) {
+	ctx, span := tracing.Start(ctx, "WebhookService.GetSingle")
+	defer span.End()
+
+	webhook, err := t.getOwned(ctx, id)
+	webhook.Secret = ""
+	return webhook, err
+}
+
+// Create creates a new webhook, a secret is generated when none is given.
+// The secret is only returned here, afterwards it is never shown again
+func (t *WebhookService) Create(ctx context.Context, userID uint, webhook *models.Webhook) error {
+	ctx, span := tracing.Start(ctx, "WebhookService.Create")
+	defer span.End()
+
+	if err := authorize(ctx, userID); err != nil {
+		return err
+	}
+	if err := t.validateWebhook(ctx, webhook, true); err != nil {
+		return err
+	}
+
+	user, err := t.UserRepo.GetSingle(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if webhook.Secret == "" {
+		if webhook.Secret, err = newWebhookSecret(); err != nil {
+			return err
+		}
+	}
+	return t.WebhookRepo.Create(ctx, user.ID, webhook)
+}
+
+// Update updates the webhook
+func (t *WebhookService) Update(ctx context.Context, id uint, webhookData *models.Webhook) (models.Webhook, error) {
+	ctx, span := tracing.Start(ctx, "WebhookService.Update")
+	defer span.End()
+
+	if _, err := t.getOwned(ctx, id); err != nil {
+		return models.Webhook{}, err
+	}
+	if err := t.validateWebhook(ctx, webhookData, false); err != nil {
+		return models.Webhook{}, err
+	}
+
+	webhook, err := t.WebhookRepo.Update(ctx, id, webhookData)
+	webhook.Secret = ""
+	return webhook, err
+}
+
+// Delete removes the webhook
+func (t *WebhookService) Delete(ctx context.Context, id uint) error {
+	ctx, span := tracing.Start(ctx, "WebhookService.Delete")
+	defer span.End()
+
+	if _, err := t.getOwned(ctx, id); err != nil {
+		return err
+	}
+	return t.WebhookRepo.Delete(ctx, id)
+}
+
+// GetDeliveries returns the delivery log of the webhook
+func (t *WebhookService) GetDeliveries(ctx context.Context, id uint, page models.Page) ([]models.WebhookDelivery, error) {
+	ctx, span := tracing.Start(ctx, "WebhookService.GetDeliveries")
+	defer span.End()
+
+	webhook, err := t.getOwned(ctx, id)
+	if err != nil {
+		return []models.WebhookDelivery{}, err
+	}
+	return t.WebhookRepo.GetDeliveries(ctx, webhook.ID, page)
+}
+
+// getOwned returns the webhook when it belongs to the authenticated user
+func (t *WebhookService) getOwned(ctx context.Context, id uint) (models.Webhook, error) {
+	webhook, err := t.WebhookRepo.GetSingle(ctx, id)
+	if err != nil {
+		return models.Webhook{}, err
+	}
+	if err = authorize(ctx, webhook.UserID); err != nil {
+		return models.Webhook{}, err
+	}
+	return webhook, nil
+}
+
+// validateWebhook checks the URL and the event types, on updates empty values keep the current ones
+func (t *WebhookService) validateWebhook(ctx context.Context, webhook *models.Webhook, creating bool) error {
+	if creating || webhook.URL != "" {
+		u, err := url.Parse(webhook.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("%w: URL must be an absolute http(s) url", services.ErrInvalidWebhook)
+		}
+		if err = t.checkTarget(ctx, u.Hostname()); err != nil {
+			return err
+		}
+	}
+
+	if creating && len(webhook.Events) == 0 {
+		return fmt.Errorf("%w: no events", services.ErrInvalidWebhook)
+	}
+	for _, event := range webhook.Events {
+		if !models.WebhookEvents(models.WebhookEventTypes).Has(event) {
+			return fmt.Errorf("%w: unknown event %q", services.ErrInvalidWebhook, event)
+		}
+	}
+	return nil
+}
+
+// checkTarget refuses hosts resolving to an address webhooks may not target,
+// the dispatcher checks the addresses again when it connects
+func (t *WebhookService) checkTarget(ctx context.Context, host string) error {
+	if t.Config.AllowPrivateTargets {
+		return nil
+	}
+
+	addrs := []net.IPAddr{{IP: net.ParseIP(host)}}
+	if addrs[0].IP == nil {
+		var err error
+		if addrs, err = t.LookupIPAddr(ctx, host); err != nil {
+			return fmt.Errorf("%w: %v", services.ErrInvalidWebhook, err)
+		}
+	}
+	for _, addr := range addrs {
+		if !publicAddress(addr.IP) {
+			return fmt.Errorf("%w: URL must not target a loopback, private or link-local address", services.ErrInvalidWebhook)
+		}
+	}
+	return nil
+}
+
+func newWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,140 @@
+package webservices
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/danikg/go-todo-rest-api/config"
+	"github.com/danikg/go-todo-rest-api/models"
+	"github.com/danikg/go-todo-rest-api/repositories/mocks"
+	"github.com/danikg/go-todo-rest-api/services"
+	"github.com/danikg/go-todo-rest-api/utils/auth"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestWebhookService resolves example.com to a public and internal.example.com to a private address
+func newTestWebhookService() *WebhookService {
+	webhookService := NewWebhookService(&mocks.WebhookRepositoryMock{}, &mocks.UserRepositoryMock{}, config.WebhookConfig{})
+	webhookService.LookupIPAddr = func(ctx context.Context, host string) ([]net.IPAddr, error) {
+		switch host {
+		case "example.com":
+			return []net.IPAddr{{IP: net.ParseIP("93.184.215.14")}}, nil
+		case "internal.example.com":
+			return []net.IPAddr{{IP: net.ParseIP("93.184.215.14")}, {IP: net.ParseIP("10.0.0.5")}}, nil
+		}
+		return nil, errors.New("no such host")
+	}
+	return webhookService
+}
+
+func TestWebhookService_Create(t *testing.T) {
+	webhookService := newTestWebhookService()
+	events := models.WebhookEvents{models.WebhookTodoItemCompleted}
+
+	webhook := models.Webhook{URL: "https://example.com/hook", Events: events}
+	assert.NoError(t, webhookService.Create(context.Background(), 1, &webhook))
+	assert.Equal(t, uint(2), webhook.ID)
+	assert.Len(t, webhook.Secret, 64)
+
+	webhook = models.Webhook{URL: "https://example.com/hook", Secret: "mine", Events: events}
+	assert.NoError(t, webhookService.Create(context.Background(), 1, &webhook))
+	assert.Equal(t, "mine", webhook.Secret)
+
+	webhook = models.Webhook{URL: "https://example.com/hook", Events: events}
+	assert.Error(t, webhookService.Create(context.Background(), 2, &webhook))
+
+	invalid := []models.Webhook{
+		{Events: events},
+		{URL: "example.com/hook", Events: events},
+		{URL: "ftp://example.com/hook", Events: events},
+		{URL: "https://example.com/hook"},
+		{URL: "https://example.com/hook", Events: models.WebhookEvents{"todo_item.archived"}},
+		{URL: "https://unknown.example.com/hook", Events: events},
+	}
+	for _, webhook := range invalid {
+		assert.ErrorIs(t, webhookService.Create(context.Background(), 1, &webhook), services.ErrInvalidWebhook)
+	}
+}
+
+func TestWebhookService_HidesSecret(t *testing.T) {
+	webhookService := newTestWebhookService()
+
+	webhooks, err := webhookService.GetAll(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Len(t, webhooks, 1)
+	assert.Empty(t, webhooks[0].Secret)
+
+	_, err = webhookService.GetAll(context.Background(), 2)
+	assert.Error(t, err)
+
+	webhook, err := webhookService.GetSingle(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "http://example.com/hook", webhook.URL)
+	assert.Empty(t, webhook.Secret)
+
+	webhook, err = webhookService.Update(context.Background(), 1, &models.Webhook{URL: "https://example.com/new"})
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com/new", webhook.URL)
+	assert.Empty(t, webhook.Secret)
+}
+
+func TestWebhookService_Update(t *testing.T) {
+	webhookService := newTestWebhookService()
+
+	webhook, err := webhookService.Update(context.Background(), 1, &models.Webhook{Events: models.WebhookEvents{models.WebhookTodoItemDeleted}})
+	assert.NoError(t, err)
+	assert.Equal(t, models.WebhookEvents{models.WebhookTodoItemDeleted}, webhook.Events)
+
+	_, err = webhookService.Update(context.Background(), 1, &models.Webhook{URL: "not a url"})
+	assert.ErrorIs(t, err, services.ErrInvalidWebhook)
+
+	_, err = webhookService.Update(context.Background(), 2, &models.Webhook{URL: "https://example.com/new"})
+	assert.Error(t, err)
+}
+
+func TestWebhookService_PrivateTargets(t *testing.T) {
+	webhookService := newTestWebhookService()
+	events := models.WebhookEvents{models.WebhookTodoItemCompleted}
+
+	for _, target := range []string{
+		"http://127.0.0.1:8000/hook",
+		"http://localhost/hook",
+		"http://[::1]/hook",
+		"http://10.1.2.3/hook",
+		"http://192.168.0.1/hook",
+		"http://169.254.169.254/latest/meta-data",
+		"http://0.0.0.0/hook",
+		"https://internal.example.com/hook",
+	} {
+		webhook := models.Webhook{URL: target, Events: events}
+		assert.ErrorIs(t, webhookService.Create(context.Background(), 1, &webhook), services.ErrInvalidWebhook, target)
+	}
+	_, err := webhookService.Update(context.Background(), 1, &models.Webhook{URL: "http://127.0.0.1/hook"})
+	assert.ErrorIs(t, err, services.ErrInvalidWebhook)
+
+	webhookService.Config.AllowPrivateTargets = true
+	webhook := models.Webhook{URL: "http://127.0.0.1:8000/hook", Events: events}
+	assert.NoError(t, webhookService.Create(context.Background(), 1, &webhook))
+}
+
+func TestWebhookService_Forbidden(t *testing.T) {
+	webhookService := newTestWebhookService()
+	ctx := auth.NewContext(context.Background(), 2)
+
+	webhook := models.Webhook{URL: "https://example.com/hook", Events: models.WebhookEvents{models.WebhookTodoItemCompleted}}
+	assert.ErrorIs(t, webhookService.Create(ctx, 1, &webhook), services.ErrForbidden)
+	_, err := webhookService.GetAll(ctx, 1)
+	assert.ErrorIs(t, err, services.ErrForbidden)
+	_, err = webhookService.GetSingle(ctx, 1)
+	assert.ErrorIs(t, err, services.ErrForbidden)
+	_, err = webhookService.Update(ctx, 1, &models.Webhook{URL: "https://example.com/new"})
+	assert.ErrorIs(t, err, services.ErrForbidden)
+	assert.ErrorIs(t, webhookService.Delete(ctx, 1), services.ErrForbidden)
+	_, err = webhookService.GetDeliveries(ctx, 1, models.Page{})
+	assert.ErrorIs(t, err, services.ErrForbidden)
+
+	_, err = webhookService.GetSingle(auth.NewContext(context.Background(), 1), 1)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,146 @@
+package events
+
+import (
+	"sync"
+	"time"
+
+	"github.com/danikg/go-todo-rest-api/models"
+)
+
+// subscriptionBuffer is the number of events a subscriber may lag behind before it is dropped
+const subscriptionBuffer = 64
+
+// Publisher ...
+type Publisher interface {
+	Publish(event models.ChangeEvent)
+}
+
+// Bus is an in-process event bus fanning change events out to the subscribers of their user.
+// It keeps the latest events so that reconnecting clients can resume from their last event id
+type Bus struct {
+	mu          sync.Mutex
+	nextID      uint64
+	history     []models.ChangeEvent
+	size        int
+	subscribers map[*Subscription]bool
+}
+
+// Subscription receives the events of one user, or of all users, on C,
+// C is closed when the subscriber lags too far behind or the subscription is closed
+type Subscription struct {
+	C      chan models.ChangeEvent
+	userID uint
+	all    bool
+	bus    *Bus
+}
+
+// NewBus returns a bus remembering the latest size events.
+// Event ids start at the current time in microseconds so that they keep growing across restarts
+func NewBus(size int) *Bus {
+	return &Bus{
+		nextID:      uint64(time.Now().UnixMicro()),
+		size:        size,
+		subscribers: map[*Subscription]bool{},
+	}
+}
+
+// Publish assigns the next id to the event and hands it to the subscribers of its user
+func (b *Bus) Publish(event models.ChangeEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.nextID++
+	event.ID = b.nextID
+	if b.size > 0 {
+		if len(b.history) == b.size {
+			b.history = append(b.history[:0], b.history[1:]...)
+		}
+		b.history = append(b.history, event)
+	}
+
+	for sub := range b.subscribers {
+		if !sub.receives(event) {
+			continue
+		}
+		select {
+		case sub.C <- event:
+		default:
+			// the client resumes from its last event id after reconnecting
+			b.drop(sub)
+		}
+	}
+}
+
+// Subscribe registers a subscriber for the events of the user. With a lastEventID the remembered
+// events after it are returned for replay, complete is false when some of them are not remembered anymore
+func (b *Bus) Subscribe(userID uint, lastEventID uint64) (sub *Subscription, replay []models.ChangeEvent, complete bool) {
+	return b.subscribe(&Subscription{userID: userID}, lastEventID)
+}
+
+// SubscribeAll registers a subscriber for the events of every user, it is meant for servers without authentication
+func (b *Bus) SubscribeAll(lastEventID uint64) (sub *Subscription, replay []models.ChangeEvent, complete bool) {
+	return b.subscribe(&Subscription{all: true}, lastEventID)
+}
+
+func (b *Bus) subscribe(sub *Subscription, lastEventID uint64) (_ *Subscription, replay []models.ChangeEvent, complete bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	sub.C, sub.bus = make(chan models.ChangeEvent, subscriptionBuffer), b
+	b.subscribers[sub] = true
+
+	if lastEventID == 0 {
+		return sub, nil, true
+	}
+
+	// the events after lastEventID are complete when the oldest remembered one directly follows it
+	oldest := b.nextID + 1
+	if len(b.history) != 0 {
+		oldest = b.history[0].ID
+	}
+	complete = lastEventID <= b.nextID && lastEventID+1 >= oldest
+	for _, event := range b.history {
+		if event.ID > lastEventID && sub.receives(event) {
+			replay = append(replay, event)
+		}
+	}
+	return sub, replay, complete
+}
+
+func (s *Subscription) receives(event models.ChangeEvent) bool {
+	return s.all || s.userID == event.UserID
+}
+
+// Close unregisters the subscription
+func (s *Subscription) Close() {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+
+	if s.bus.subscribers[s] {
+		s.bus.drop(s)
+	}
+}
+
+func (b *Bus) drop(sub *Subscription) {
+	delete(b.subscribers, sub)
+	close(sub.C)
+}
+
+// Buffer holds the published events until they are flushed,
+// changes made in a transaction are only handed to the bus after the commit
+type Buffer struct {
+	events []models.ChangeEvent
+}
+
+// Publish ...
+func (b *Buffer) Publish(event models.ChangeEvent) {
+	b.events = append(b.events, event)
+}
+
+// Flush hands the held events to the publisher in order
+func (b *Buffer) Flush(publisher Publisher) {
+	for _, event := range b.events {
+		publisher.Publish(event)
+	}
+	b.events = nil
+}
@@ -0,0 +1,265 @@
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danikg/go-todo-rest-api/models"
+)
+
+// Export formats
+const (
+	JSON     = "json"
+	CSV      = "csv"
+	Markdown = "markdown"
+)
+
+// Writer renders the data of a user as it is streamed from the db: first the user, then the tags,
+// then every todo list followed by its todo items. Close flushes the output
+type Writer interface {
+	User(user models.User) error
+	Tag(tag models.Tag) error
+	TodoList(todoList models.TodoList) error
+	TodoItem(todoItem models.TodoItem) error
+	Close() error
+}
+
+// NewWriter returns a writer of the format on w, nil for unknown formats
+func NewWriter(format string, w io.Writer) Writer {
+	out := bufio.NewWriter(w)
+	switch format {
+	case JSON:
+		return &jsonWriter{out: out}
+	case CSV:
+		return &csvWriter{out: out, csv: csv.NewWriter(out)}
+	case Markdown:
+		return &markdownWriter{out: out}
+	default:
+		return nil
+	}
+}
+
+// ContentType returns the media type of the format
+func ContentType(format string) string {
+	switch format {
+	case CSV:
+		return "text/csv; charset=utf-8"
+	case Markdown:
+		return "text/markdown; charset=utf-8"
+	default:
+		return "application/json"
+	}
+}
+
+// Extension returns the file extension of the format
+func Extension(format string) string {
+	if format == Markdown {
+		return "md"
+	}
+	return format
+}
+
+// jsonWriter nests the todo items in their lists: {"User":{},"Tags":[],"TodoLists":[{"TodoItems":[]}]}
+type jsonWriter struct {
+	out       *bufio.Writer
+	section   string
+	first     bool
+	list      bool
+	firstItem bool
+}
+
+// jsonTodoItem leaves out the list the todo item is nested in,
+// the nil TodoList shadows the one of the embedded item
+type jsonTodoItem struct {
+	models.TodoItem
+	TodoList *struct{} `json:",omitempty"`
+}
+
+func (j *jsonWriter) User(user models.User) error {
+	user.TodoLists = nil
+	return j.write(`{"User":`, user)
+}
+
+func (j *jsonWriter) Tag(tag models.Tag) error {
+	if err := j.enter("Tags"); err != nil {
+		return err
+	}
+	return j.write(j.separator(&j.first), tag)
+}
+
+func (j *jsonWriter) TodoList(todoList models.TodoList) error {
+	if err := j.enter("TodoLists"); err != nil {
+		return err
+	}
+	prefix := j.separator(&j.first)
+	if j.list {
+		prefix = "]}" + prefix
+	}
+
+	// the list object stays open for its items
+	raw, err := json.Marshal(todoList)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(j.out, `%s%s,"TodoItems":[`, prefix, raw[:len(raw)-1])
+	j.list, j.firstItem = true, true
+	return err
+}
+
+func (j *jsonWriter) TodoItem(todoItem models.TodoItem) error {
+	return j.write(j.separator(&j.firstItem), jsonTodoItem{TodoItem: todoItem})
+}
+
+func (j *jsonWriter) Close() error {
+	if err := j.enter("TodoLists"); err != nil {
+		return err
+	}
+	suffix := "]}\n"
+	if j.list {
+		suffix = "]}" + suffix
+	}
+	if _, err := j.out.WriteString(suffix); err != nil {
+		return err
+	}
+	return j.out.Flush()
+}
+
+// enter opens the array of the section, closing the previous one and writing the empty tags when there were none
+func (j *jsonWriter) enter(section string) error {
+	if j.section == section {
+		return nil
+	}
+
+	var s string
+	switch j.section {
+	case "Tags":
+		s = "]"
+	case "":
+		if section == "TodoLists" {
+			s = `,"Tags":[]`
+		}
+	}
+	j.section, j.first = section, true
+	_, err := fmt.Fprintf(j.out, `%s,"%s":[`, s, section)
+	return err
+}
+
+// separator returns the comma before an array element, none before the first one
+func (j *jsonWriter) separator(first *bool) string {
+	if *first {
+		*first = false
+		return ""
+	}
+	return ","
+}
+
+func (j *jsonWriter) write(prefix string, v interface{}) error {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if _, err = j.out.WriteString(prefix); err != nil {
+		return err
+	}
+	_, err = j.out.Write(raw)
+	return err
+}
+
+// csvHeader names the columns of the csv export, one row per todo item
+var csvHeader = []string{"list_id", "list", "item_id", "title", "description", "completed", "tags", "created_at", "updated_at"}
+
+// csvWriter flattens the todo items, tags are joined with semicolons
+type csvWriter struct {
+	out      *bufio.Writer
+	csv      *csv.Writer
+	todoList models.TodoList
+}
+
+func (c *csvWriter) User(user models.User) error {
+	return c.csv.Write(csvHeader)
+}
+
+func (c *csvWriter) Tag(tag models.Tag) error {
+	return nil
+}
+
+func (c *csvWriter) TodoList(todoList models.TodoList) error {
+	c.todoList = todoList
+	return nil
+}
+
+func (c *csvWriter) TodoItem(todoItem models.TodoItem) error {
+	tags := make([]string, len(todoItem.Tags))
+	for i, tag := range todoItem.Tags {
+		tags[i] = tag.Text
+	}
+	return c.csv.Write([]string{
+		strconv.FormatUint(uint64(c.todoList.ID), 10),
+		c.todoList.Name,
+		strconv.FormatUint(uint64(todoItem.ID), 10),
+		todoItem.Title,
+		todoItem.Description,
+		strconv.FormatBool(todoItem.Completed),
+		strings.Join(tags, ";"),
+		todoItem.CreatedAt.Format(time.RFC3339),
+		todoItem.UpdatedAt.Format(time.RFC3339),
+	})
+}
+
+func (c *csvWriter) Close() error {
+	c.csv.Flush()
+	if err := c.csv.Error(); err != nil {
+		return err
+	}
+	return c.out.Flush()
+}
+
+// markdownWriter renders every todo list as a heading with a checkbox list of its items
+type markdownWriter struct {
+	out *bufio.Writer
+}
+
+func (m *markdownWriter) User(user models.User) error {
+	_, err := fmt.Fprintf(m.out, "# %s\n", markdownLine(user.Username))
+	return err
+}
+
+func (m *markdownWriter) Tag(tag models.Tag) error {
+	return nil
+}
+
+func (m *markdownWriter) TodoList(todoList models.TodoList) error {
+	_, err := fmt.Fprintf(m.out, "\n## %s\n\n", markdownLine(todoList.Name))
+	return err
+}
+
+func (m *markdownWriter) TodoItem(todoItem models.TodoItem) error {
+	check := " "
+	if todoItem.Completed {
+		check = "x"
+	}
+	line := fmt.Sprintf("- [%s] %s", check, markdownLine(todoItem.Title))
+	for _, tag := range todoItem.Tags {
+		line += fmt.Sprintf(" `%s`", strings.ReplaceAll(tag.Text, "`", "'"))
+	}
+	if todoItem.Description != "" {
+		line += "\n  " + markdownLine(todoItem.Description)
+	}
+	_, err := m.out.WriteString(line + "\n")
+	return err
+}
+
+func (m *markdownWriter) Close() error {
+	return m.out.Flush()
+}
+
+// markdownLine keeps a text on a single line
+func markdownLine(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
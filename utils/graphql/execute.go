@@ -0,0 +1,486 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Request is a GraphQL request as sent in the body of POST requests
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Response holds the data of an executed request and the field errors,
+// Data is nil when the request could not be parsed, validated or its variables coerced
+type Response struct {
+	Data   *Map     `json:"data,omitempty"`
+	Errors []*Error `json:"errors,omitempty"`
+}
+
+// Error is a request or field error, Path locates the field in Data
+type Error struct {
+	Message string        `json:"message"`
+	Path    []interface{} `json:"path,omitempty"`
+}
+
+// Map is a result object keeping the keys in the order of the query
+type Map struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func newMap() *Map {
+	return &Map{values: map[string]interface{}{}}
+}
+
+// Get returns the value of the key
+func (m *Map) Get(key string) interface{} {
+	return m.values[key]
+}
+
+func (m *Map) set(key string, v interface{}) {
+	if _, ok := m.values[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = v
+}
+
+// MarshalJSON ...
+func (m *Map) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, key := range m.keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		raw, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		b.Write(raw)
+		b.WriteByte(':')
+		if raw, err = json.Marshal(m.values[key]); err != nil {
+			return nil, err
+		}
+		b.Write(raw)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// Execute runs the request, the fields of a mutation are executed one after the other.
+// Fields are resolved one depth at a time so the Thunks of a Loader are loaded in one batch per depth,
+// a failing field is set to null and reported in Errors
+func (s *Schema) Execute(ctx context.Context, request Request) *Response {
+	doc, err := parse(request.Query)
+	if err != nil {
+		return requestError(err)
+	}
+
+	op, err := selectOperation(doc, request.OperationName)
+	if err != nil {
+		return requestError(err)
+	}
+	root := s.Query
+	if op.kind == "mutation" {
+		if root = s.Mutation; root == nil {
+			return requestError(errors.New("the schema does not support mutations"))
+		}
+	}
+
+	e := &executor{schema: s, doc: doc, op: op, ctx: ctx}
+	if e.variables, err = s.variables(op, request.Variables); err != nil {
+		return requestError(err)
+	}
+	if err = e.validate(root, op.selections, 1, map[string]bool{}); err != nil {
+		return requestError(err)
+	}
+
+	data := newMap()
+	e.run([]*task{{object: root, selections: op.selections, out: data}})
+	return &Response{Data: data, Errors: e.errors}
+}
+
+func requestError(err error) *Response {
+	return &Response{Errors: []*Error{{Message: err.Error()}}}
+}
+
+func selectOperation(doc *document, name string) (*operation, error) {
+	if name == "" {
+		if len(doc.operations) != 1 {
+			return nil, errors.New("the document must contain exactly one operation or the operationName must be given")
+		}
+		return doc.operations[0], nil
+	}
+	for _, op := range doc.operations {
+		if op.name == name {
+			return op, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown operation %q", name)
+}
+
+// variables checks the given variables against the definitions of the operation and adds the defaults,
+// the raw values are kept and coerced with the arguments they are used in
+func (s *Schema) variables(op *operation, given map[string]interface{}) (map[string]interface{}, error) {
+	variables := map[string]interface{}{}
+	for _, definition := range op.variables {
+		if name := namedType(definition.typ); !scalars[name] && s.inputs[name] == nil {
+			return nil, fmt.Errorf("variable $%s has unknown input type %s", definition.name, definition.typ)
+		}
+
+		v, ok := given[definition.name]
+		if !ok {
+			if definition.defaultValue == nil {
+				if _, required := nonNull(definition.typ); required {
+					return nil, fmt.Errorf("variable $%s of type %s was not provided", definition.name, definition.typ)
+				}
+				continue
+			}
+			v = rawValue(definition.defaultValue, nil)
+		}
+		if _, err := s.coerce(definition.typ, v); err != nil {
+			return nil, fmt.Errorf("variable $%s: %w", definition.name, err)
+		}
+		variables[definition.name] = v
+	}
+	return variables, nil
+}
+
+type executor struct {
+	schema    *Schema
+	doc       *document
+	op        *operation
+	ctx       context.Context
+	variables map[string]interface{}
+	errors    []*Error
+	// fields counts the validated fields against MaxFields
+	fields int
+}
+
+// task is a source value completed as object with the selections into out
+type task struct {
+	object     *Object
+	source     interface{}
+	selections []selection
+	out        *Map
+	path       []interface{}
+}
+
+// resolution is a field of a task being resolved
+type resolution struct {
+	out        *Map
+	key        string
+	field      *Field
+	selections []selection
+	path       []interface{}
+	value      interface{}
+	err        error
+}
+
+// validate checks the selections of the object against the schema before anything is resolved
+func (e *executor) validate(object *Object, selections []selection, depth int, spreads map[string]bool) error {
+	if e.schema.MaxDepth > 0 && depth > e.schema.MaxDepth {
+		return fmt.Errorf("the query is nested deeper than %d levels", e.schema.MaxDepth)
+	}
+
+	for _, sel := range selections {
+		switch sel := sel.(type) {
+		case *field:
+			if err := e.validateField(object, sel, depth, spreads); err != nil {
+				return err
+			}
+		case *fragmentSpread:
+			f := e.doc.fragments[sel.name]
+			if f == nil {
+				return fmt.Errorf("unknown fragment %q", sel.name)
+			}
+			if spreads[sel.name] {
+				return fmt.Errorf("fragment %q spreads itself", sel.name)
+			}
+			if f.typeCondition != object.Name {
+				return fmt.Errorf("fragment %q on %s cannot be spread on %s", sel.name, f.typeCondition, object.Name)
+			}
+			spreads[sel.name] = true
+			err := e.validate(object, f.selections, depth, spreads)
+			delete(spreads, sel.name)
+			if err != nil {
+				return err
+			}
+		case *inlineFragment:
+			if sel.typeCondition != "" && sel.typeCondition != object.Name {
+				return fmt.Errorf("fragment on %s cannot be spread on %s", sel.typeCondition, object.Name)
+			}
+			if err := e.validate(object, sel.selections, depth, spreads); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (e *executor) validateField(object *Object, f *field, depth int, spreads map[string]bool) error {
+	if f.name == "__typename" {
+		if len(f.selections) != 0 {
+			return errors.New("field __typename cannot have a selection set")
+		}
+		return nil
+	}
+
+	if e.fields++; e.schema.MaxFields > 0 && e.fields > e.schema.MaxFields {
+		return fmt.Errorf("the query selects more than %d fields", e.schema.MaxFields)
+	}
+	definition := object.fields[f.name]
+	if definition == nil {
+		return fmt.Errorf("cannot query field %q on type %s", f.name, object.Name)
+	}
+	if _, err := e.arguments(definition, f.arguments); err != nil {
+		return err
+	}
+	for _, d := range f.directives {
+		if _, err := e.directiveIf(d); err != nil {
+			return err
+		}
+	}
+
+	child := e.schema.objects[namedType(definition.Type)]
+	switch {
+	case child == nil && len(f.selections) != 0:
+		return fmt.Errorf("field %s.%s of type %s cannot have a selection set", object.Name, f.name, definition.Type)
+	case child != nil && len(f.selections) == 0:
+		return fmt.Errorf("field %s.%s of type %s must have a selection set", object.Name, f.name, definition.Type)
+	case child != nil:
+		return e.validate(child, f.selections, depth+1, spreads)
+	}
+	return nil
+}
+
+// arguments coerces the arguments of the field, arguments that were not given are left out
+func (e *executor) arguments(definition *Field, arguments []*argument) (map[string]interface{}, error) {
+	args := map[string]interface{}{}
+	given := map[string]value{}
+	for _, arg := range arguments {
+		if _, ok := given[arg.name]; ok {
+			return nil, fmt.Errorf("argument %q of field %s is given twice", arg.name, definition.Name)
+		}
+		given[arg.name] = arg.value
+	}
+
+	for _, arg := range definition.Args {
+		v, ok := given[arg.Name]
+		delete(given, arg.Name)
+		if ref, isVariable := v.(variableRef); ok && isVariable {
+			if !e.definesVariable(string(ref)) {
+				return nil, fmt.Errorf("variable $%s is not defined", ref)
+			}
+			// optional variables without a value leave the argument out
+			_, ok = e.variables[string(ref)]
+		}
+		if !ok {
+			if _, required := nonNull(arg.Type); required {
+				return nil, fmt.Errorf("argument %q of field %s of type %s is required", arg.Name, definition.Name, arg.Type)
+			}
+			continue
+		}
+
+		coerced, err := e.schema.coerce(arg.Type, rawValue(v, e.variables))
+		if err != nil {
+			return nil, fmt.Errorf("argument %q of field %s: %w", arg.Name, definition.Name, err)
+		}
+		args[arg.Name] = coerced
+	}
+	for name := range given {
+		return nil, fmt.Errorf("unknown argument %q of field %s", name, definition.Name)
+	}
+	return args, nil
+}
+
+// definesVariable reports whether the operation defines the variable
+func (e *executor) definesVariable(name string) bool {
+	for _, definition := range e.op.variables {
+		if definition.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// included evaluates the @skip and @include directives
+func (e *executor) included(directives []*directive) bool {
+	for _, d := range directives {
+		condition, err := e.directiveIf(d)
+		if err != nil || d.name == "skip" && condition || d.name == "include" && !condition {
+			return false
+		}
+	}
+	return true
+}
+
+func (e *executor) directiveIf(d *directive) (bool, error) {
+	if d.name != "skip" && d.name != "include" {
+		return false, fmt.Errorf("unknown directive @%s", d.name)
+	}
+	if len(d.arguments) != 1 || d.arguments[0].name != "if" {
+		return false, fmt.Errorf("directive @%s takes exactly the argument if", d.name)
+	}
+	condition, err := e.schema.coerce(Boolean+"!", rawValue(d.arguments[0].value, e.variables))
+	if err != nil {
+		return false, fmt.Errorf("directive @%s: %w", d.name, err)
+	}
+	return condition.(bool), nil
+}
+
+// fieldGroup holds the fields selected under the same response key
+type fieldGroup struct {
+	key    string
+	fields []*field
+}
+
+// collect flattens the fragments of the selections and groups the fields by response key
+func (e *executor) collect(selections []selection, groups []*fieldGroup) []*fieldGroup {
+	for _, sel := range selections {
+		switch sel := sel.(type) {
+		case *field:
+			if !e.included(sel.directives) {
+				continue
+			}
+			found := false
+			for _, group := range groups {
+				if group.key == sel.responseKey() {
+					group.fields = append(group.fields, sel)
+					found = true
+					break
+				}
+			}
+			if !found {
+				groups = append(groups, &fieldGroup{key: sel.responseKey(), fields: []*field{sel}})
+			}
+		case *fragmentSpread:
+			if e.included(sel.directives) {
+				groups = e.collect(e.doc.fragments[sel.name].selections, groups)
+			}
+		case *inlineFragment:
+			if e.included(sel.directives) {
+				groups = e.collect(sel.selections, groups)
+			}
+		}
+	}
+	return groups
+}
+
+// run resolves the fields of the tasks and then completes their values, the objects found
+// are the tasks of the next depth
+func (e *executor) run(tasks []*task) {
+	for len(tasks) > 0 {
+		var resolutions []*resolution
+		for _, t := range tasks {
+			for _, group := range e.collect(t.selections, nil) {
+				first := group.fields[0]
+				if first.name == "__typename" {
+					t.out.set(group.key, t.object.Name)
+					continue
+				}
+
+				r := &resolution{
+					out:   t.out,
+					key:   group.key,
+					field: t.object.fields[first.name],
+					path:  appendPath(t.path, group.key),
+				}
+				for _, f := range group.fields {
+					r.selections = append(r.selections, f.selections...)
+				}
+				t.out.set(r.key, nil)
+
+				args, err := e.arguments(r.field, first.arguments)
+				if err != nil {
+					r.err = err
+				} else {
+					r.value, r.err = e.resolve(r.field, t.source, args)
+				}
+				resolutions = append(resolutions, r)
+			}
+		}
+
+		var next []*task
+		for _, r := range resolutions {
+			if thunk, ok := r.value.(Thunk); ok && r.err == nil {
+				r.value, r.err = thunk()
+			}
+			if r.err != nil {
+				e.errors = append(e.errors, &Error{Message: r.err.Error(), Path: r.path})
+				continue
+			}
+			r.out.set(r.key, e.complete(r.field.Type, r.value, r.selections, r.path, &next))
+		}
+		tasks = next
+	}
+}
+
+func (e *executor) resolve(f *Field, source interface{}, args map[string]interface{}) (interface{}, error) {
+	if f.Resolve != nil {
+		return f.Resolve(Params{Context: e.ctx, Source: source, Args: args})
+	}
+
+	v := reflect.Indirect(reflect.ValueOf(source))
+	if v.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("field %s has no resolver", f.Name)
+	}
+	fv := v.FieldByNameFunc(func(name string) bool { return strings.EqualFold(name, f.Name) })
+	if !fv.IsValid() {
+		return nil, fmt.Errorf("field %s has no resolver", f.Name)
+	}
+	return fv.Interface(), nil
+}
+
+// complete converts the resolved value to the type of the field, objects are completed by the tasks added to next
+func (e *executor) complete(typ string, v interface{}, selections []selection, path []interface{}, next *[]*task) interface{} {
+	typ, required := nonNull(typ)
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			break
+		}
+		rv = rv.Elem()
+	}
+
+	if !rv.IsValid() || (rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface || rv.Kind() == reflect.Map) && rv.IsNil() {
+		if required {
+			e.errors = append(e.errors, &Error{Message: "cannot return null for non-null type " + typ + "!", Path: path})
+		}
+		return nil
+	}
+
+	if item, ok := listOf(typ); ok {
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			e.errors = append(e.errors, &Error{Message: "expected a list of " + item, Path: path})
+			return nil
+		}
+		list := make([]interface{}, rv.Len())
+		for i := range list {
+			list[i] = e.complete(item, rv.Index(i).Interface(), selections, appendPath(path, i), next)
+		}
+		return list
+	}
+
+	if object := e.schema.objects[typ]; object != nil {
+		out := newMap()
+		*next = append(*next, &task{object: object, source: rv.Interface(), selections: selections, out: out, path: path})
+		return out
+	}
+	return rv.Interface()
+}
+
+func appendPath(path []interface{}, key interface{}) []interface{} {
+	result := make([]interface{}, len(path)+1)
+	copy(result, path)
+	result[len(path)] = key
+	return result
+}
@@ -0,0 +1,241 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testUser struct {
+	ID        int
+	Name      string
+	Nickname  *string
+	FriendIDs []int
+}
+
+var testUsers = map[int]*testUser{
+	1: {ID: 1, Name: "alice", FriendIDs: []int{2}},
+	2: {ID: 2, Name: "bob", FriendIDs: []int{1}},
+}
+
+// newTestSchema serves the test users, the executor hands the structs to the resolvers as values.
+// The friends of the users of the same depth are fetched in one batch
+func newTestSchema(batches *[][]int) *Schema {
+	friends := NewLoader(func(ctx context.Context, ids []int) (map[int][]*testUser, error) {
+		*batches = append(*batches, ids)
+		result := map[int][]*testUser{}
+		for _, id := range ids {
+			for _, friendID := range testUsers[id].FriendIDs {
+				result[id] = append(result[id], testUsers[friendID])
+			}
+		}
+		return result, nil
+	})
+
+	user := &Object{Name: "User", Fields: []*Field{
+		{Name: "id", Type: "Int!"},
+		{Name: "name", Type: "String!"},
+		{Name: "nickname", Type: "String"},
+		{Name: "friends", Type: "[User!]!", Resolve: func(p Params) (interface{}, error) {
+			return friends.Load(p.Context, p.Source.(testUser).ID), nil
+		}},
+	}}
+	query := &Object{Name: "Query", Fields: []*Field{
+		{Name: "user", Type: "User", Args: []*Arg{{Name: "id", Type: "Int!"}}, Resolve: func(p Params) (interface{}, error) {
+			if user, ok := testUsers[p.Args["id"].(int)]; ok {
+				return user, nil
+			}
+			return nil, nil
+		}},
+		{Name: "echo", Type: "String", Args: []*Arg{
+			{Name: "value", Type: "String"},
+			{Name: "number", Type: "Float"},
+			{Name: "list", Type: "[Int!]"},
+			{Name: "input", Type: "EchoInput"},
+		}, Resolve: func(p Params) (interface{}, error) {
+			data, err := json.Marshal(p.Args)
+			return string(data), err
+		}},
+		{Name: "fail", Type: "String", Resolve: func(p Params) (interface{}, error) {
+			return nil, errors.New("failed")
+		}},
+		{Name: "required", Type: "String!", Resolve: func(p Params) (interface{}, error) {
+			return nil, nil
+		}},
+	}}
+	mutation := &Object{Name: "Mutation", Fields: []*Field{
+		{Name: "rename", Type: "User!", Args: []*Arg{{Name: "id", Type: "Int!"}, {Name: "name", Type: "String!"}},
+			Resolve: func(p Params) (interface{}, error) {
+				user := *testUsers[p.Args["id"].(int)]
+				user.Name = p.Args["name"].(string)
+				return &user, nil
+			}},
+	}}
+	input := &Input{Name: "EchoInput", Fields: []*Arg{{Name: "text", Type: "String!"}, {Name: "count", Type: "Int"}}}
+
+	schema := NewSchema(query, mutation, []*Object{user}, []*Input{input})
+	schema.MaxDepth = 4
+	schema.MaxFields = 20
+	return schema
+}
+
+func TestSchema_Execute(t *testing.T) {
+	tests := []struct {
+		name          string
+		query         string
+		operationName string
+		variables     map[string]interface{}
+		response      string
+	}{
+		{
+			name:     "fields and aliases",
+			query:    `{ user(id: 1) { id name nickname } bob: user(id: 2) { name } nobody: user(id: 3) { name } }`,
+			response: `{"data":{"user":{"id":1,"name":"alice","nickname":null},"bob":{"name":"bob"},"nobody":null}}`,
+		},
+		{
+			name:     "nested lists",
+			query:    `{ user(id: 1) { friends { name friends { name } } } }`,
+			response: `{"data":{"user":{"friends":[{"name":"bob","friends":[{"name":"alice"}]}]}}}`,
+		},
+		{
+			name:     "fragments",
+			query:    `{ user(id: 1) { ...names ... on User { id } ... { __typename } } } fragment names on User { name name }`,
+			response: `{"data":{"user":{"name":"alice","id":1,"__typename":"User"}}}`,
+		},
+		{
+			name:      "directives",
+			query:     `query ($show: Boolean!) { user(id: 1) { id @skip(if: true) name @include(if: $show) ...f @include(if: false) } } fragment f on User { nickname }`,
+			variables: map[string]interface{}{"show": true},
+			response:  `{"data":{"user":{"name":"alice"}}}`,
+		},
+		{
+			name:      "variables",
+			query:     `query ($id: Int!, $text: String!) { user(id: $id) { name } echo(input: {text: $text}) }`,
+			variables: map[string]interface{}{"id": float64(2), "text": "hi"},
+			response:  `{"data":{"user":{"name":"bob"},"echo":"{\"input\":{\"text\":\"hi\"}}"}}`,
+		},
+		{
+			name:     "variable defaults and optional variables",
+			query:    `query ($id: Int = 2, $value: String) { user(id: $id) { name } echo(value: $value) }`,
+			response: `{"data":{"user":{"name":"bob"},"echo":"{}"}}`,
+		},
+		{
+			name:     "literal coercion",
+			query:    `{ echo(value: "é", number: 2, list: 3) }`,
+			response: `{"data":{"echo":"{\"list\":[3],\"number\":2,\"value\":\"é\"}"}}`,
+		},
+		{
+			name:          "operation name",
+			query:         `query A { user(id: 1) { name } } mutation B { rename(id: 1, name: "carol") { name } }`,
+			operationName: "B",
+			response:      `{"data":{"rename":{"name":"carol"}}}`,
+		},
+		{
+			name:     "field errors",
+			query:    `{ fail user(id: 1) { name } }`,
+			response: `{"data":{"fail":null,"user":{"name":"alice"}},"errors":[{"message":"failed","path":["fail"]}]}`,
+		},
+		{
+			name:     "null for a non-null field",
+			query:    `{ required }`,
+			response: `{"data":{"required":null},"errors":[{"message":"cannot return null for non-null type String!","path":["required"]}]}`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var batches [][]int
+			response := newTestSchema(&batches).Execute(context.Background(), Request{Query: tc.query, OperationName: tc.operationName, Variables: tc.variables})
+			data, err := json.Marshal(response)
+			assert.NoError(t, err)
+			assert.JSONEq(t, tc.response, string(data))
+		})
+	}
+}
+
+func TestSchema_ExecuteBatches(t *testing.T) {
+	var batches [][]int
+	response := newTestSchema(&batches).Execute(context.Background(), Request{
+		Query: `{ alice: user(id: 1) { friends { friends { name } } } bob: user(id: 2) { friends { name } } }`,
+	})
+	assert.Empty(t, response.Errors)
+	// the friends of both users are loaded together, the next depth is served from the cache of the loader
+	assert.Equal(t, [][]int{{1, 2}}, batches)
+}
+
+func TestSchema_ExecuteRequestErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		variables map[string]interface{}
+		err       string
+	}{
+		{"syntax error", `{ user(id: 1) { name }`, nil, "syntax error"},
+		{"several operations without a name", `query A { echo } query B { echo }`, nil, "exactly one operation"},
+		{"unknown field", `{ user(id: 1) { email } }`, nil, `cannot query field "email" on type User`},
+		{"missing selection set", `{ user(id: 1) }`, nil, "must have a selection set"},
+		{"selection set on a scalar", `{ echo { name } }`, nil, "cannot have a selection set"},
+		{"missing argument", `{ user { name } }`, nil, `argument "id" of field user of type Int! is required`},
+		{"unknown argument", `{ user(id: 1, name: "a") { name } }`, nil, `unknown argument "name"`},
+		{"argument given twice", `{ user(id: 1, id: 2) { name } }`, nil, "is given twice"},
+		{"wrong argument type", `{ user(id: "1") { name } }`, nil, `expected a value of type Int, found "1"`},
+		{"integer out of range", `{ user(id: 3000000000) { name } }`, nil, "expected a value of type Int"},
+		{"unknown input field", `{ echo(input: {text: "a", other: 1}) }`, nil, "unknown field EchoInput.other"},
+		{"missing input field", `{ echo(input: {count: 1}) }`, nil, "field EchoInput.text of type String! is required"},
+		{"undefined variable", `{ user(id: $id) { name } }`, nil, "variable $id is not defined"},
+		{"missing variable", `query ($id: Int!) { user(id: $id) { name } }`, nil, "variable $id of type Int! was not provided"},
+		{"wrong variable type", `query ($id: Int!) { user(id: $id) { name } }`, map[string]interface{}{"id": "one"}, `variable $id: expected a value of type Int, found "one"`},
+		{"unknown variable type", `query ($id: User) { echo }`, nil, "variable $id has unknown input type User"},
+		{"unknown directive", `{ echo @defer }`, nil, "unknown directive @defer"},
+		{"directive without if", `{ echo @skip }`, nil, "directive @skip takes exactly the argument if"},
+		{"unknown fragment", `{ user(id: 1) { ...missing } }`, nil, `unknown fragment "missing"`},
+		{"fragment on another type", `{ ...f } fragment f on User { name }`, nil, `fragment "f" on User cannot be spread on Query`},
+		{"fragment spreading itself", `{ user(id: 1) { ...f } } fragment f on User { friends { ...f } }`, nil, `fragment "f" spreads itself`},
+		{"too deep", `{ user(id: 1) { friends { friends { friends { friends { name } } } } } }`, nil, "nested deeper than 4 levels"},
+		{"too many fields", `{ user(id: 1) { ` + strings.Repeat("name ", 20) + `} }`, nil, "selects more than 20 fields"},
+		{"too many fields through fragments", `{ a: user(id: 1) { ...f } b: user(id: 1) { ...f } } fragment f on User { ` + strings.Repeat("name ", 10) + `}`, nil, "selects more than 20 fields"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var batches [][]int
+			response := newTestSchema(&batches).Execute(context.Background(), Request{Query: tc.query, Variables: tc.variables})
+			assert.Nil(t, response.Data)
+			if assert.Len(t, response.Errors, 1) {
+				assert.Contains(t, response.Errors[0].Message, tc.err)
+			}
+			assert.Empty(t, batches)
+		})
+	}
+}
+
+func TestSchema_ExecuteWithoutMutations(t *testing.T) {
+	query := &Object{Name: "Query", Fields: []*Field{{Name: "name", Type: "String"}}}
+	response := NewSchema(query, nil, nil, nil).Execute(context.Background(), Request{Query: `mutation { name }`})
+	if assert.Len(t, response.Errors, 1) {
+		assert.Equal(t, "the schema does not support mutations", response.Errors[0].Message)
+	}
+}
+
+func TestNewSchema_UnknownType(t *testing.T) {
+	tests := []struct {
+		name  string
+		field *Field
+		err   string
+	}{
+		{"field type", &Field{Name: "user", Type: "User"}, "graphql: field Query.user has unknown type User"},
+		{"argument type", &Field{Name: "echo", Type: "String", Args: []*Arg{{Name: "input", Type: "[EchoInput!]"}}}, "graphql: Query.echo(input) has unknown input type [EchoInput!]"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			query := &Object{Name: "Query", Fields: []*Field{tc.field}}
+			assert.PanicsWithValue(t, tc.err, func() { NewSchema(query, nil, nil, nil) }, fmt.Sprint(tc.field))
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package graphql
+
+import (
+	"context"
+	"sync"
+)
+
+// Loader batches the loads of the keys requested while one depth of a query is resolved:
+// Load queues the key and returns a Thunk, the first Thunk called fetches every queued key at once.
+// Values are cached for the lifetime of the loader, use one loader per request
+type Loader[K comparable, V any] struct {
+	fetch func(ctx context.Context, keys []K) (map[K]V, error)
+
+	mu      sync.Mutex
+	queued  []K
+	values  map[K]V
+	errs    map[K]error
+	pending map[K]bool
+}
+
+// NewLoader returns a loader fetching the values of many keys with fetch,
+// keys missing in the result get the zero value
+func NewLoader[K comparable, V any](fetch func(ctx context.Context, keys []K) (map[K]V, error)) *Loader[K, V] {
+	return &Loader[K, V]{
+		fetch:   fetch,
+		values:  map[K]V{},
+		errs:    map[K]error{},
+		pending: map[K]bool{},
+	}
+}
+
+// Load queues the key unless it was loaded before
+func (l *Loader[K, V]) Load(ctx context.Context, key K) Thunk {
+	l.mu.Lock()
+	_, loaded := l.values[key]
+	if !loaded && l.errs[key] == nil && !l.pending[key] {
+		l.pending[key] = true
+		l.queued = append(l.queued, key)
+	}
+	l.mu.Unlock()
+
+	return func() (interface{}, error) {
+		return l.get(ctx, key)
+	}
+}
+
+func (l *Loader[K, V]) get(ctx context.Context, key K) (V, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.queued) != 0 {
+		keys := l.queued
+		l.queued = nil
+		values, err := l.fetch(ctx, keys)
+		for _, k := range keys {
+			delete(l.pending, k)
+			if err != nil {
+				l.errs[k] = err
+			} else {
+				l.values[k] = values[k]
+			}
+		}
+	}
+	return l.values[key], l.errs[key]
+}
@@ -0,0 +1,109 @@
+package graphql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	doc, err := parse(`
+		# the comments and commas are ignored
+		query Users($id: Int! = 1, $names: [String!], $input: EchoInput) {
+			first: user(id: $id) { name, ...userFields @include(if: true) }
+			echo(value: "a\"bé\n", number: -1.5e3, list: [1, 2], object: {a: null, b: ENUM})
+			... on Query @skip(if: false) { users { id } }
+		}
+		fragment userFields on User { id }
+		mutation { rename(id: 1, name: "x") { name } }
+	`)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	if assert.Len(t, doc.operations, 2) {
+		query := doc.operations[0]
+		assert.Equal(t, "query", query.kind)
+		assert.Equal(t, "Users", query.name)
+		assert.Equal(t, []*variableDefinition{
+			{name: "id", typ: "Int!", defaultValue: int64(1)},
+			{name: "names", typ: "[String!]"},
+			{name: "input", typ: "EchoInput"},
+		}, query.variables)
+
+		if assert.Len(t, query.selections, 3) {
+			user := query.selections[0].(*field)
+			assert.Equal(t, "first", user.responseKey())
+			assert.Equal(t, "user", user.name)
+			assert.Equal(t, []*argument{{name: "id", value: variableRef("id")}}, user.arguments)
+			if assert.Len(t, user.selections, 2) {
+				spread := user.selections[1].(*fragmentSpread)
+				assert.Equal(t, "userFields", spread.name)
+				assert.Equal(t, []*directive{{name: "include", arguments: []*argument{{name: "if", value: true}}}}, spread.directives)
+			}
+
+			echo := query.selections[1].(*field)
+			assert.Equal(t, "echo", echo.responseKey())
+			assert.Equal(t, []*argument{
+				{name: "value", value: "a\"bé\n"},
+				{name: "number", value: -1.5e3},
+				{name: "list", value: []value{int64(1), int64(2)}},
+				{name: "object", value: objectValue{{name: "a", value: nil}, {name: "b", value: enumValue("ENUM")}}},
+			}, echo.arguments)
+
+			inline := query.selections[2].(*inlineFragment)
+			assert.Equal(t, "Query", inline.typeCondition)
+			assert.Equal(t, "skip", inline.directives[0].name)
+		}
+		assert.Equal(t, "mutation", doc.operations[1].kind)
+		assert.Empty(t, doc.operations[1].name)
+	}
+
+	if assert.Contains(t, doc.fragments, "userFields") {
+		assert.Equal(t, "User", doc.fragments["userFields"].typeCondition)
+		assert.Len(t, doc.fragments["userFields"].selections, 1)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		err   string
+	}{
+		{"empty document", "  # nothing\n", "syntax error at 2:1: empty document"},
+		{"empty selection set", "{ }", "empty selection set"},
+		{"unclosed selection set", "{ user { name }", `expected a name, found end of document`},
+		{"unexpected token", "{ name } }", `unexpected "}"`},
+		{"unknown definition", "subscription { name }", `unexpected "subscription"`},
+		{"empty arguments", "{ user() { name } }", "empty argument list"},
+		{"argument without value", "{ user(id:) { name } }", `unexpected ")"`},
+		{"variable in a default value", "query ($a: Int = $b) { name }", `unexpected "$"`},
+		{"variable without type", "query ($a) { name }", `expected ":", found ")"`},
+		{"unclosed list type", "query ($a: [Int) { name }", `expected "]", found ")"`},
+		{"operation directive", "query @skip(if: true) { name }", "directives on operations are not supported"},
+		{"fragment named on", "fragment on on User { name }", "a fragment cannot be named on"},
+		{"fragment without type condition", "fragment f User { name }", `expected "on", found "User"`},
+		{"fragment defined twice", "{ name } fragment f on Q { a } fragment f on Q { b }", `fragment "f" is defined twice`},
+		{"unterminated string", "{ echo(value: \"abc) }", "unterminated string"},
+		{"string over two lines", "{ echo(value: \"a\nb\") }", "syntax error at 1:15: unterminated string"},
+		{"invalid escape", `{ echo(value: "\x") }`, `invalid escape \x`},
+		{"invalid unicode escape", `{ echo(value: "\u12g4") }`, "invalid unicode escape"},
+		{"block string", `{ echo(value: """a""") }`, "block strings are not supported"},
+		{"leading zero", "{ user(id: 01) { name } }", "unexpected leading zero"},
+		{"number followed by a name", "{ user(id: 1a) { name } }", "invalid number"},
+		{"float without digits", "{ user(id: 1.) { name } }", "invalid number"},
+		{"integer overflow", "{ user(id: 99999999999999999999) { name } }", "invalid integer"},
+		{"two dots", "{ ..f }", `unexpected '.'`},
+		{"unknown character", "{ name ? }", `unexpected '?'`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := parse(tc.query)
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tc.err)
+			}
+		})
+	}
+}
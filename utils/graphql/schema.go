@@ -0,0 +1,202 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Built-in scalar types, Time is an RFC 3339 string
+const (
+	Int     = "Int"
+	Float   = "Float"
+	String  = "String"
+	Boolean = "Boolean"
+	Time    = "Time"
+)
+
+var scalars = map[string]bool{Int: true, Float: true, String: true, Boolean: true, Time: true}
+
+// Schema holds the object and input types of an API, interfaces, unions and enums are not supported
+type Schema struct {
+	Query    *Object
+	Mutation *Object
+	Types    []*Object
+	Inputs   []*Input
+	// MaxDepth limits the nesting of selection sets, 0 disables the limit
+	MaxDepth int
+	// MaxFields limits the fields selected by a query, every spread of a fragment counting again,
+	// 0 disables the limit
+	MaxFields int
+
+	objects map[string]*Object
+	inputs  map[string]*Input
+}
+
+// Object is an object type
+type Object struct {
+	Name        string
+	Description string
+	Fields      []*Field
+
+	fields map[string]*Field
+}
+
+// Field is a field of an object type, Type is written like in the schema language (e.g. [TodoItem!]!).
+// Without Resolve the field returns the struct field of the source whose name matches ignoring case
+type Field struct {
+	Name        string
+	Description string
+	Type        string
+	Args        []*Arg
+	Resolve     func(p Params) (interface{}, error)
+}
+
+// Arg is an argument of a field or a field of an input type
+type Arg struct {
+	Name string
+	Type string
+}
+
+// Input is an input object type
+type Input struct {
+	Name        string
+	Description string
+	Fields      []*Arg
+}
+
+// Params are passed to the resolvers, Args holds the coerced arguments that were given:
+// int for Int, float64, string, bool, time.Time, []interface{} for lists and map[string]interface{} for inputs
+type Params struct {
+	Context context.Context
+	Source  interface{}
+	Args    map[string]interface{}
+}
+
+// Thunk is returned by resolvers deferring their work until every field of the same depth was resolved,
+// see Loader
+type Thunk func() (interface{}, error)
+
+// NewSchema indexes the types of the schema, it panics when a type refers to an unknown type
+func NewSchema(query, mutation *Object, types []*Object, inputs []*Input) *Schema {
+	s := &Schema{
+		Query:    query,
+		Mutation: mutation,
+		Types:    types,
+		Inputs:   inputs,
+		objects:  map[string]*Object{},
+		inputs:   map[string]*Input{},
+	}
+
+	for _, object := range s.allObjects() {
+		s.objects[object.Name] = object
+		object.fields = map[string]*Field{}
+		for _, f := range object.Fields {
+			object.fields[f.Name] = f
+		}
+	}
+	for _, input := range inputs {
+		s.inputs[input.Name] = input
+	}
+
+	for _, object := range s.allObjects() {
+		for _, f := range object.Fields {
+			if name := namedType(f.Type); !scalars[name] && s.objects[name] == nil {
+				panic(fmt.Sprintf("graphql: field %s.%s has unknown type %s", object.Name, f.Name, f.Type))
+			}
+			for _, arg := range f.Args {
+				s.mustBeInputType(object.Name+"."+f.Name+"("+arg.Name+")", arg.Type)
+			}
+		}
+	}
+	for _, input := range inputs {
+		for _, f := range input.Fields {
+			s.mustBeInputType(input.Name+"."+f.Name, f.Type)
+		}
+	}
+	return s
+}
+
+func (s *Schema) mustBeInputType(where, typ string) {
+	if name := namedType(typ); !scalars[name] && s.inputs[name] == nil {
+		panic(fmt.Sprintf("graphql: %s has unknown input type %s", where, typ))
+	}
+}
+
+func (s *Schema) allObjects() []*Object {
+	objects := []*Object{s.Query}
+	if s.Mutation != nil {
+		objects = append(objects, s.Mutation)
+	}
+	return append(objects, s.Types...)
+}
+
+// String prints the schema in the schema definition language
+func (s *Schema) String() string {
+	var b strings.Builder
+	if s.Query.Name != "Query" || s.Mutation != nil && s.Mutation.Name != "Mutation" {
+		b.WriteString("schema {\n  query: " + s.Query.Name + "\n")
+		if s.Mutation != nil {
+			b.WriteString("  mutation: " + s.Mutation.Name + "\n")
+		}
+		b.WriteString("}\n\n")
+	}
+	b.WriteString("scalar Time\n")
+
+	for _, object := range s.allObjects() {
+		b.WriteString("\n")
+		writeDescription(&b, "", object.Description)
+		b.WriteString("type " + object.Name + " {\n")
+		for _, f := range object.Fields {
+			writeDescription(&b, "  ", f.Description)
+			b.WriteString("  " + f.Name)
+			if len(f.Args) != 0 {
+				args := make([]string, len(f.Args))
+				for i, arg := range f.Args {
+					args[i] = arg.Name + ": " + arg.Type
+				}
+				b.WriteString("(" + strings.Join(args, ", ") + ")")
+			}
+			b.WriteString(": " + f.Type + "\n")
+		}
+		b.WriteString("}\n")
+	}
+
+	for _, input := range s.Inputs {
+		b.WriteString("\n")
+		writeDescription(&b, "", input.Description)
+		b.WriteString("input " + input.Name + " {\n")
+		for _, f := range input.Fields {
+			b.WriteString("  " + f.Name + ": " + f.Type + "\n")
+		}
+		b.WriteString("}\n")
+	}
+	return b.String()
+}
+
+func writeDescription(b *strings.Builder, indent, description string) {
+	if description != "" {
+		fmt.Fprintf(b, "%s%q\n", indent, description)
+	}
+}
+
+// namedType strips the list and non-null wrappers of a type
+func namedType(typ string) string {
+	return strings.Trim(typ, "[]!")
+}
+
+// nonNull reports whether the type ends with ! and returns it without
+func nonNull(typ string) (string, bool) {
+	if strings.HasSuffix(typ, "!") {
+		return typ[:len(typ)-1], true
+	}
+	return typ, false
+}
+
+// listOf returns the item type of a list type
+func listOf(typ string) (string, bool) {
+	if strings.HasPrefix(typ, "[") && strings.HasSuffix(typ, "]") {
+		return typ[1 : len(typ)-1], true
+	}
+	return "", false
+}
@@ -0,0 +1,148 @@
+package graphql
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// rawValue converts a literal to the form of a json decoded value, variables are replaced by their raw value
+func rawValue(v value, variables map[string]interface{}) interface{} {
+	switch v := v.(type) {
+	case variableRef:
+		return variables[string(v)]
+	case []value:
+		list := make([]interface{}, len(v))
+		for i, item := range v {
+			list[i] = rawValue(item, variables)
+		}
+		return list
+	case objectValue:
+		object := make(map[string]interface{}, len(v))
+		for _, field := range v {
+			object[field.name] = rawValue(field.value, variables)
+		}
+		return object
+	default:
+		return v
+	}
+}
+
+// coerce converts a json decoded value or a raw literal to the input type
+func (s *Schema) coerce(typ string, v interface{}) (interface{}, error) {
+	typ, required := nonNull(typ)
+	if v == nil {
+		if required {
+			return nil, fmt.Errorf("expected a value of type %s!, found null", typ)
+		}
+		return nil, nil
+	}
+
+	if item, ok := listOf(typ); ok {
+		values, isList := v.([]interface{})
+		if !isList {
+			// a single value is accepted as a list of one
+			values = []interface{}{v}
+		}
+		list := make([]interface{}, len(values))
+		for i, value := range values {
+			coerced, err := s.coerce(item, value)
+			if err != nil {
+				return nil, fmt.Errorf("item %d: %w", i, err)
+			}
+			list[i] = coerced
+		}
+		return list, nil
+	}
+
+	if input := s.inputs[typ]; input != nil {
+		return s.coerceInput(input, v)
+	}
+	return coerceScalar(typ, v)
+}
+
+func (s *Schema) coerceInput(input *Input, v interface{}) (interface{}, error) {
+	values, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected an object of type %s", input.Name)
+	}
+
+	known := map[string]bool{}
+	object := map[string]interface{}{}
+	for _, f := range input.Fields {
+		known[f.Name] = true
+		value, given := values[f.Name]
+		if !given {
+			if _, required := nonNull(f.Type); required {
+				return nil, fmt.Errorf("field %s.%s of type %s is required", input.Name, f.Name, f.Type)
+			}
+			continue
+		}
+		coerced, err := s.coerce(f.Type, value)
+		if err != nil {
+			return nil, fmt.Errorf("field %s.%s: %w", input.Name, f.Name, err)
+		}
+		object[f.Name] = coerced
+	}
+	for name := range values {
+		if !known[name] {
+			return nil, fmt.Errorf("unknown field %s.%s", input.Name, name)
+		}
+	}
+	return object, nil
+}
+
+func coerceScalar(typ string, v interface{}) (interface{}, error) {
+	switch typ {
+	case Int:
+		switch n := v.(type) {
+		case int64:
+			if n >= math.MinInt32 && n <= math.MaxInt32 {
+				return int(n), nil
+			}
+		case float64:
+			if n == math.Trunc(n) && n >= math.MinInt32 && n <= math.MaxInt32 {
+				return int(n), nil
+			}
+		}
+	case Float:
+		switch n := v.(type) {
+		case int64:
+			return float64(n), nil
+		case float64:
+			return n, nil
+		}
+	case String:
+		if s, ok := v.(string); ok {
+			return s, nil
+		}
+	case Boolean:
+		if b, ok := v.(bool); ok {
+			return b, nil
+		}
+	case Time:
+		if s, ok := v.(string); ok {
+			t, err := time.Parse(time.RFC3339, s)
+			if err != nil {
+				return nil, fmt.Errorf("expected an RFC 3339 time, found %q", s)
+			}
+			return t, nil
+		}
+	}
+	return nil, fmt.Errorf("expected a value of type %s, found %s", typ, describeValue(v))
+}
+
+func describeValue(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return fmt.Sprintf("%q", v)
+	case enumValue:
+		return string(v)
+	case map[string]interface{}:
+		return "an object"
+	case []interface{}:
+		return "a list"
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,158 @@
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/danikg/go-todo-rest-api/models"
+)
+
+// ContentType is the media type of the feeds
+const ContentType = "text/calendar; charset=utf-8"
+
+// lineLength is the longest content line in octets, longer lines are folded
+const lineLength = 75
+
+const timestamp = "20060102T150405Z"
+
+// recurrenceParts are the parts of an RRULE value with the pattern of their values
+var recurrenceParts = map[string]*regexp.Regexp{
+	"FREQ":       regexp.MustCompile(`^(SECONDLY|MINUTELY|HOURLY|DAILY|WEEKLY|MONTHLY|YEARLY)$`),
+	"INTERVAL":   regexp.MustCompile(`^[1-9][0-9]*$`),
+	"COUNT":      regexp.MustCompile(`^[1-9][0-9]*$`),
+	"UNTIL":      regexp.MustCompile(`^[0-9]{8}(T[0-9]{6}Z?)?$`),
+	"BYSECOND":   regexp.MustCompile(`^[0-9]{1,2}(,[0-9]{1,2})*$`),
+	"BYMINUTE":   regexp.MustCompile(`^[0-9]{1,2}(,[0-9]{1,2})*$`),
+	"BYHOUR":     regexp.MustCompile(`^[0-9]{1,2}(,[0-9]{1,2})*$`),
+	"BYDAY":      regexp.MustCompile(`^([+-]?[0-9]{0,2}(MO|TU|WE|TH|FR|SA|SU))(,[+-]?[0-9]{0,2}(MO|TU|WE|TH|FR|SA|SU))*$`),
+	"BYMONTHDAY": regexp.MustCompile(`^[+-]?[0-9]{1,2}(,[+-]?[0-9]{1,2})*$`),
+	"BYYEARDAY":  regexp.MustCompile(`^[+-]?[0-9]{1,3}(,[+-]?[0-9]{1,3})*$`),
+	"BYWEEKNO":   regexp.MustCompile(`^[+-]?[0-9]{1,2}(,[+-]?[0-9]{1,2})*$`),
+	"BYMONTH":    regexp.MustCompile(`^[0-9]{1,2}(,[0-9]{1,2})*$`),
+	"BYSETPOS":   regexp.MustCompile(`^[+-]?[0-9]{1,3}(,[+-]?[0-9]{1,3})*$`),
+	"WKST":       regexp.MustCompile(`^(MO|TU|WE|TH|FR|SA|SU)$`),
+}
+
+// ValidateRecurrence checks an RRULE value like FREQ=WEEKLY;BYDAY=MO,
+// the FREQ is required and COUNT and UNTIL exclude each other
+func ValidateRecurrence(rule string) error {
+	seen := map[string]bool{}
+	for _, part := range strings.Split(rule, ";") {
+		name, value, _ := strings.Cut(part, "=")
+		pattern, known := recurrenceParts[name]
+		if !known {
+			return fmt.Errorf("unknown part %q", name)
+		}
+		if seen[name] {
+			return fmt.Errorf("repeated part %s", name)
+		}
+		if !pattern.MatchString(value) {
+			return fmt.Errorf("invalid %s %q", name, value)
+		}
+		seen[name] = true
+	}
+
+	if !seen["FREQ"] {
+		return errors.New("missing FREQ")
+	}
+	if seen["COUNT"] && seen["UNTIL"] {
+		return errors.New("COUNT and UNTIL exclude each other")
+	}
+	return nil
+}
+
+// Write renders the todo items as the VTODO components of a calendar named name,
+// recurring items need a due date which starts the recurrence
+func Write(w io.Writer, name string, todoItems []models.TodoItem) error {
+	out := &writer{out: bufio.NewWriter(w)}
+	out.line("BEGIN", "VCALENDAR")
+	out.line("VERSION", "2.0")
+	out.line("PRODID", "-//go-todo-rest-api//todo feed//EN")
+	out.line("CALSCALE", "GREGORIAN")
+	out.line("METHOD", "PUBLISH")
+	out.line("X-WR-CALNAME", Escape(name))
+
+	for _, todoItem := range todoItems {
+		out.line("BEGIN", "VTODO")
+		out.line("UID", "todo-item-"+strconv.FormatUint(uint64(todoItem.ID), 10)+"@go-todo-rest-api")
+		out.line("DTSTAMP", todoItem.UpdatedAt.UTC().Format(timestamp))
+		out.line("CREATED", todoItem.CreatedAt.UTC().Format(timestamp))
+		out.line("LAST-MODIFIED", todoItem.UpdatedAt.UTC().Format(timestamp))
+		out.line("SUMMARY", Escape(todoItem.Title))
+		if todoItem.Description != "" {
+			out.line("DESCRIPTION", Escape(todoItem.Description))
+		}
+		if todoItem.Due != nil {
+			due := todoItem.Due.UTC().Format(timestamp)
+			if todoItem.Recurrence != "" {
+				out.line("DTSTART", due)
+				out.line("RRULE", todoItem.Recurrence)
+			}
+			out.line("DUE", due)
+		}
+		if todoItem.Completed {
+			out.line("STATUS", "COMPLETED")
+			out.line("COMPLETED", todoItem.UpdatedAt.UTC().Format(timestamp))
+		} else {
+			out.line("STATUS", "NEEDS-ACTION")
+		}
+		if len(todoItem.Tags) > 0 {
+			categories := make([]string, len(todoItem.Tags))
+			for i, tag := range todoItem.Tags {
+				categories[i] = Escape(tag.Text)
+			}
+			out.line("CATEGORIES", strings.Join(categories, ","))
+		}
+		if todoItem.TodoList.Name != "" {
+			out.line("X-TODO-LIST", Escape(todoItem.TodoList.Name))
+		}
+		out.line("END", "VTODO")
+	}
+
+	out.line("END", "VCALENDAR")
+	if out.err != nil {
+		return out.err
+	}
+	return out.out.Flush()
+}
+
+// Escape escapes a TEXT value, backslashes, semicolons, commas and newlines
+func Escape(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`, "\r", `\n`).Replace(text)
+}
+
+// writer writes folded content lines and keeps the first error
+type writer struct {
+	out *bufio.Writer
+	err error
+}
+
+// line writes name:value, lines longer than 75 octets continue on lines starting with a space,
+// they are never split inside a utf-8 character
+func (w *writer) line(name, value string) {
+	if w.err != nil {
+		return
+	}
+
+	line := name + ":" + value
+	limit := lineLength
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		if _, w.err = w.out.WriteString(line[:cut] + "\r\n "); w.err != nil {
+			return
+		}
+		// the leading space counts towards the length of the continuation
+		line, limit = line[cut:], lineLength-1
+	}
+	_, w.err = w.out.WriteString(line + "\r\n")
+}
@@ -0,0 +1,396 @@
+package importer
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/danikg/go-todo-rest-api/models"
+	"github.com/danikg/go-todo-rest-api/utils/ical"
+)
+
+// Import formats
+const (
+	TodoTxt = "todotxt"
+	CSV     = "csv"
+	JSON    = "json"
+)
+
+// ErrUnknownFormat is returned for formats other than todotxt, csv and json
+var ErrUnknownFormat = errors.New("import format must be todotxt, csv or json")
+
+// CSVFields are the fields csv columns are mapped to, by default a column carries the name of its field
+var CSVFields = []string{"list", "title", "description", "completed", "tags"}
+
+// Options ...
+type Options struct {
+	// DefaultList receives the todo items that name no list
+	DefaultList string
+	// Columns maps CSVFields to the csv header names
+	Columns map[string]string
+}
+
+// Parse reads the todo items of the file, lines that cannot be read are reported as import errors,
+// an error is returned when the file as a whole cannot be read
+func Parse(format string, r io.Reader, options Options) ([]models.ImportItem, []models.ImportError, error) {
+	var (
+		items []models.ImportItem
+		errs  []models.ImportError
+		err   error
+	)
+
+	switch format {
+	case TodoTxt:
+		items, errs, err = parseTodoTxt(r)
+	case CSV:
+		items, errs, err = parseCSV(r, options.Columns)
+	case JSON:
+		items, errs, err = parseJSON(r)
+	default:
+		return nil, nil, ErrUnknownFormat
+	}
+
+	for i := range items {
+		if items[i].List = strings.TrimSpace(items[i].List); items[i].List == "" {
+			items[i].List = options.DefaultList
+		}
+	}
+	return items, errs, err
+}
+
+// ParseColumns reads a csv column mapping of the form field:header,field:header
+func ParseColumns(value string) (map[string]string, error) {
+	columns := map[string]string{}
+	if value == "" {
+		return columns, nil
+	}
+
+	for _, pair := range strings.Split(value, ",") {
+		field, header, ok := strings.Cut(pair, ":")
+		field, header = strings.TrimSpace(field), strings.TrimSpace(header)
+		if !ok || header == "" || !knownField(field) {
+			return nil, fmt.Errorf("invalid column mapping %q, expected field:header with a field of %s", pair, strings.Join(CSVFields, ", "))
+		}
+		columns[field] = header
+	}
+	return columns, nil
+}
+
+func knownField(field string) bool {
+	for _, known := range CSVFields {
+		if field == known {
+			return true
+		}
+	}
+	return false
+}
+
+// todoTxtKeyValue matches the key:value extensions of todo.txt, urls are left in the title
+var todoTxtKeyValue = regexp.MustCompile(`^([A-Za-z0-9_-]+):([^:/\s][^:\s]*)$`)
+
+// parseTodoTxt reads a todo.txt file: the first +project names the list, further projects and
+// @contexts become tags, the priority becomes a priority:X tag, due:YYYY-MM-DD sets the due date
+// and the other key:value pairs go to the description
+func parseTodoTxt(r io.Reader) ([]models.ImportItem, []models.ImportError, error) {
+	items := []models.ImportItem{}
+	errs := []models.ImportError{}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64<<10), 1<<20)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		item := parseTodoTxtLine(text)
+		item.Line = line
+		if item.Title == "" {
+			errs = append(errs, models.ImportError{Line: line, Error: "missing title"})
+			continue
+		}
+		items = append(items, item)
+	}
+	return items, errs, scanner.Err()
+}
+
+func parseTodoTxtLine(text string) models.ImportItem {
+	item := models.ImportItem{Tags: []string{}}
+	fields := strings.Fields(text)
+
+	if len(fields) > 0 && fields[0] == "x" {
+		item.Completed = true
+		fields = fields[1:]
+		// the completion date
+		if len(fields) > 0 && isDate(fields[0]) {
+			fields = fields[1:]
+		}
+	}
+	if len(fields) > 0 && isPriority(fields[0]) {
+		item.Tags = append(item.Tags, "priority:"+fields[0][1:2])
+		fields = fields[1:]
+	}
+	// the creation date
+	if len(fields) > 0 && isDate(fields[0]) {
+		fields = fields[1:]
+	}
+
+	title := []string{}
+	extensions := []string{}
+	for _, field := range fields {
+		switch {
+		case len(field) > 1 && field[0] == '+':
+			if item.List == "" {
+				item.List = field[1:]
+			} else {
+				item.Tags = append(item.Tags, field[1:])
+			}
+		case len(field) > 1 && field[0] == '@':
+			item.Tags = append(item.Tags, field[1:])
+		case todoTxtKeyValue.MatchString(field):
+			match := todoTxtKeyValue.FindStringSubmatch(field)
+			due, err := time.Parse("2006-01-02", match[2])
+			switch {
+			case match[1] == "pri":
+				item.Tags = append(item.Tags, "priority:"+match[2])
+			case match[1] == "due" && err == nil:
+				item.Due = &due
+			default:
+				extensions = append(extensions, match[1]+": "+match[2])
+			}
+		default:
+			title = append(title, field)
+		}
+	}
+
+	item.Title = strings.Join(title, " ")
+	item.Description = strings.Join(extensions, "\n")
+	return item
+}
+
+func isDate(field string) bool {
+	_, err := time.Parse("2006-01-02", field)
+	return err == nil
+}
+
+func isPriority(field string) bool {
+	return len(field) == 3 && field[0] == '(' && field[1] >= 'A' && field[1] <= 'Z' && field[2] == ')'
+}
+
+// parseCSV reads a csv file with a header row, the tags column holds tags separated by ; or ,
+func parseCSV(r io.Reader, mapping map[string]string) ([]models.ImportItem, []models.ImportError, error) {
+	items := []models.ImportItem{}
+	errs := []models.ImportError{}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot read the csv header: %w", err)
+	}
+
+	columns := map[string]int{}
+	for _, field := range CSVFields {
+		name, mapped := mapping[field]
+		if !mapped {
+			name = field
+		}
+		for i, column := range header {
+			if strings.EqualFold(strings.TrimSpace(column), name) {
+				columns[field] = i
+				break
+			}
+		}
+		if _, found := columns[field]; !found && (mapped || field == "title") {
+			return nil, nil, fmt.Errorf("the csv header has no %q column", name)
+		}
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line, _ := reader.FieldPos(0)
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, nil, err
+			}
+			errs = append(errs, models.ImportError{Line: parseErr.Line, Error: parseErr.Err.Error()})
+			continue
+		}
+
+		value := func(field string) string {
+			i, ok := columns[field]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		item := models.ImportItem{
+			Line:        line,
+			List:        value("list"),
+			Title:       value("title"),
+			Description: value("description"),
+			Tags:        splitTags(value("tags")),
+		}
+		completed, ok := parseCompleted(value("completed"))
+		switch {
+		case item.Title == "":
+			errs = append(errs, models.ImportError{Line: line, Error: "missing title"})
+		case !ok:
+			errs = append(errs, models.ImportError{Line: line, Error: fmt.Sprintf("invalid completed value %q", value("completed"))})
+		default:
+			item.Completed = completed
+			items = append(items, item)
+		}
+	}
+	return items, errs, nil
+}
+
+func splitTags(value string) []string {
+	tags := []string{}
+	for _, tag := range strings.FieldsFunc(value, func(r rune) bool { return r == ';' || r == ',' }) {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// parseCompleted accepts the usual spellings of yes and no, empty is no
+func parseCompleted(value string) (bool, bool) {
+	switch strings.ToLower(value) {
+	case "", "false", "0", "no", "n", "open", "pending":
+		return false, true
+	case "true", "1", "yes", "y", "x", "done", "completed":
+		return true, true
+	default:
+		return false, false
+	}
+}
+
+// jsonFile is the generic json schema, the TodoLists and TodoItems keys
+// and tag objects of the json export of this API are accepted as well
+type jsonFile struct {
+	Lists     []jsonList
+	TodoLists []jsonList
+}
+
+type jsonList struct {
+	Name      string
+	Items     []jsonItem
+	TodoItems []jsonItem
+}
+
+type jsonItem struct {
+	Title       string
+	Description string
+	Completed   bool
+	Due         string
+	Recurrence  string
+	Tags        []jsonTag
+}
+
+// jsonTag is a tag text or an object with a Text
+type jsonTag string
+
+func (t *jsonTag) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*t = jsonTag(text)
+		return nil
+	}
+
+	var tag struct{ Text string }
+	if err := json.Unmarshal(data, &tag); err != nil {
+		return err
+	}
+	*t = jsonTag(tag.Text)
+	return nil
+}
+
+// parseJSON reads {"Lists":[{"Name":"","Items":[{"Title":"","Description":"","Completed":false,"Due":"","Recurrence":"","Tags":[""]}]}]},
+// the Due is a RFC 3339 time or a YYYY-MM-DD date. The Line of json items is their position in the file
+func parseJSON(r io.Reader) ([]models.ImportItem, []models.ImportError, error) {
+	items := []models.ImportItem{}
+	errs := []models.ImportError{}
+
+	file := jsonFile{}
+	if err := json.NewDecoder(r).Decode(&file); err != nil {
+		return nil, nil, fmt.Errorf("cannot read the json file: %w", err)
+	}
+
+	position := 0
+	for _, list := range append(file.Lists, file.TodoLists...) {
+		for _, entry := range append(list.Items, list.TodoItems...) {
+			position++
+			item := models.ImportItem{
+				Line:        position,
+				List:        list.Name,
+				Title:       strings.TrimSpace(entry.Title),
+				Description: entry.Description,
+				Completed:   entry.Completed,
+				Recurrence:  strings.TrimSpace(entry.Recurrence),
+				Tags:        []string{},
+			}
+			for _, tag := range entry.Tags {
+				if text := strings.TrimSpace(string(tag)); text != "" {
+					item.Tags = append(item.Tags, text)
+				}
+			}
+
+			var err error
+			if item.Due, err = parseJSONDue(entry.Due); err != nil {
+				errs = append(errs, models.ImportError{Line: position, Error: err.Error()})
+				continue
+			}
+			if err = validateJSONRecurrence(item.Recurrence, item.Due); err != nil {
+				errs = append(errs, models.ImportError{Line: position, Error: err.Error()})
+				continue
+			}
+			if item.Title == "" {
+				errs = append(errs, models.ImportError{Line: position, Error: "missing title"})
+				continue
+			}
+			items = append(items, item)
+		}
+	}
+	return items, errs, nil
+}
+
+// parseJSONDue reads a RFC 3339 time or a YYYY-MM-DD date, nil when the value is empty
+func parseJSONDue(value string) (*time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if due, err := time.Parse(layout, value); err == nil {
+			return &due, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid due %q, expected a RFC 3339 time or YYYY-MM-DD", value)
+}
+
+// validateJSONRecurrence checks the rule like the todo item service, recurring items need a due date
+func validateJSONRecurrence(rule string, due *time.Time) error {
+	if rule == "" {
+		return nil
+	}
+	if due == nil {
+		return errors.New("recurrence without due")
+	}
+	if err := ical.ValidateRecurrence(rule); err != nil {
+		return fmt.Errorf("invalid recurrence: %w", err)
+	}
+	return nil
+}
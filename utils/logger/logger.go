@@ -0,0 +1,61 @@
+package logger
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sync"
+)
+
+type (
+	loggerKey struct{}
+	entryKey  struct{}
+)
+
+// entry holds the request attributes that are only known to inner handlers
+type entry struct {
+	mu   sync.Mutex
+	user string
+}
+
+// New returns a logger writing one JSON object per line to w
+func New(w io.Writer) *slog.Logger {
+	return slog.New(slog.NewJSONHandler(w, nil))
+}
+
+// NewContext returns a copy of ctx carrying the request-scoped logger
+func NewContext(ctx context.Context, log *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, log)
+}
+
+// FromContext returns the request-scoped logger or the default one
+func FromContext(ctx context.Context) *slog.Logger {
+	if log, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+		return log
+	}
+	return slog.Default()
+}
+
+// NewEntry returns a copy of ctx where inner handlers can record request attributes
+func NewEntry(ctx context.Context) context.Context {
+	return context.WithValue(ctx, entryKey{}, &entry{})
+}
+
+// SetUser records the user who made the request
+func SetUser(ctx context.Context, user string) {
+	if e, ok := ctx.Value(entryKey{}).(*entry); ok {
+		e.mu.Lock()
+		e.user = user
+		e.mu.Unlock()
+	}
+}
+
+// User returns the user recorded by SetUser
+func User(ctx context.Context) string {
+	if e, ok := ctx.Value(entryKey{}).(*entry); ok {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		return e.user
+	}
+	return ""
+}
@@ -0,0 +1,84 @@
+package metrics
+
+import (
+	"database/sql"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "todo_api"
+
+var (
+	// HTTPRequests counts handled requests by method, route template and status
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Number of HTTP requests handled.",
+	}, []string{"method", "route", "status"})
+
+	// HTTPDuration observes request latencies by method, route template and status
+	HTTPDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency of HTTP requests.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	// TodosCreated counts created todo items
+	TodosCreated = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "todos_created_total",
+		Help:      "Number of todo items created.",
+	})
+
+	// TodosCompleted counts todo items marked as completed
+	TodosCompleted = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "todos_completed_total",
+		Help:      "Number of todo items marked as completed.",
+	})
+
+	// UsersRegistered counts created users
+	UsersRegistered = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "users_registered_total",
+		Help:      "Number of users registered.",
+	})
+
+	// WebhookDeliveries counts webhook delivery attempts by outcome (succeeded, retried, failed)
+	WebhookDeliveries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "webhook_deliveries_total",
+		Help:      "Number of webhook delivery attempts.",
+	}, []string{"outcome"})
+)
+
+// Buffer holds counter increments until they are flushed, changes made in a transaction
+// are only counted after the commit. A nil Buffer increments the counters right away
+type Buffer struct {
+	counters []prometheus.Counter
+}
+
+// Inc ...
+func (b *Buffer) Inc(counter prometheus.Counter) {
+	if b == nil {
+		counter.Inc()
+		return
+	}
+	b.counters = append(b.counters, counter)
+}
+
+// Flush increments the held counters
+func (b *Buffer) Flush() {
+	for _, counter := range b.counters {
+		counter.Inc()
+	}
+	b.counters = nil
+}
+
+// RegisterDB exposes the connection pool stats of db
+func RegisterDB(db *sql.DB, dbName string) error {
+	return prometheus.Register(collectors.NewDBStatsCollector(db, dbName))
+}
@@ -1,9 +1,12 @@
 package route
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 
+	"github.com/danikg/go-todo-rest-api/models"
 	"github.com/gorilla/mux"
 )
 
@@ -12,3 +15,27 @@ func GetRouteVar(r *http.Request, routeVar string) (uint, error) {
 	id, err := strconv.Atoi(mux.Vars(r)[routeVar])
 	return uint(id), err
 }
+
+// GetPage reads the limit and offset query parameters
+func GetPage(r *http.Request) (models.Page, error) {
+	page := models.Page{Limit: models.DefaultPageLimit}
+	query := r.URL.Query()
+
+	if limit := query.Get("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil || value < 1 || value > models.MaxPageLimit {
+			return page, fmt.Errorf("limit must be between 1 and %d", models.MaxPageLimit)
+		}
+		page.Limit = value
+	}
+
+	if offset := query.Get("offset"); offset != "" {
+		value, err := strconv.Atoi(offset)
+		if err != nil || value < 0 {
+			return page, errors.New("offset must be a non-negative integer")
+		}
+		page.Offset = value
+	}
+
+	return page, nil
+}
@@ -0,0 +1,14 @@
+package test
+
+import (
+	"github.com/danikg/go-todo-rest-api/utils/tracing"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// NewSpanExporter installs a global tracer provider recording spans in memory
+func NewSpanExporter() *tracetest.InMemoryExporter {
+	exporter := tracetest.NewInMemoryExporter()
+	tracing.Install(tracing.NewProvider("test", sdktrace.WithSyncer(exporter)))
+	return exporter
+}